@@ -3,17 +3,29 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/ardani/snmp-zte/internal/audit"
+	"github.com/ardani/snmp-zte/internal/auth"
+	"github.com/ardani/snmp-zte/internal/backup"
+	"github.com/ardani/snmp-zte/internal/breaker"
 	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/counter"
 	_ "github.com/ardani/snmp-zte/docs"
 	"github.com/ardani/snmp-zte/internal/handler"
+	"github.com/ardani/snmp-zte/internal/history"
+	"github.com/ardani/snmp-zte/internal/incident"
+	"github.com/ardani/snmp-zte/internal/limiter"
 	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/ratelimit"
 	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/internal/tftp"
 	"github.com/ardani/snmp-zte/pkg/response"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -64,23 +76,130 @@ func main() {
 			Password: cfg.Redis.Password,
 			DB:       cfg.Redis.DB,
 		})
+		defer redisClient.Close()
+		// Client tetap dipakai walau Redis belum tersedia: cache dan rate
+		// limit pulih sendiri saat Redis kembali, status terlihat di /health/ready
 		if err := redisClient.Ping(context.Background()).Err(); err != nil {
-			redisClient = nil
-		} else {
-			defer redisClient.Close()
+			log.Warn().Err(err).Str("addr", cfg.Redis.Addr()).Msg("Redis unavailable, cache disabled until it recovers")
 		}
 	}
 
 	// 4. Inisialisasi Service (Logika Bisnis) dan Handler (Pengelola HTTP)
-	onuService := service.NewONUService(cfg, redisClient)
+	// Batas sesi SNMP/Telnet per OLT, dipakai bersama semua jalur akses ke perangkat
+	deviceLimits := limiter.NewFromConfig(cfg)
+	// Circuit breaker per OLT: OLT yang tidak menjawab ditolak cepat (503)
+	breakers := breaker.NewFromConfig(cfg)
+
+	onuService := service.NewONUService(cfg, redisClient, deviceLimits, breakers)
 	oltService := service.NewOLTService(cfg)
-	onuHandler := handler.NewONUHandler(onuService)
+	confirms := service.NewConfirmations(service.ConfirmationTTL)
+	onuHandler := handler.NewONUHandler(onuService, confirms)
 	oltHandler := handler.NewOLTHandler(oltService)
-	queryHandler := handler.NewQueryHandler()
-	cliHandler := handler.NewCLIHandler()
+	// Sampel counter untuk laju trafik: di Redis agar semua replika memakai
+	// baseline yang sama, selain itu di memori
+	var counterStore counter.Store = counter.NewMemoryStore()
+	if redisClient != nil {
+		counterStore = counter.NewRedisStore(redisClient)
+	}
+	queryHandler := handler.NewQueryHandler(deviceLimits, breakers, counter.NewMeter(counterStore, 0), confirms)
+	healthHandler := handler.NewHealthHandler(oltService, breakers, redisClient)
+
+	userService, err := service.NewUserService(cfg.Auth.UsersFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load users")
+	}
+	userHandler := handler.NewUserHandler(userService)
+
+	apiKeyService, err := service.NewAPIKeyService(cfg.Auth.APIKeysFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load API keys")
+	}
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	// Verifikasi JWT bearer token (opsional)
+	var jwtAuth middleware.TokenAuthenticator
+	if cfg.Auth.JWT.Enabled {
+		verifier, err := auth.NewJWTAuthenticator(cfg.Auth.JWT)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to set up JWT authentication")
+		}
+		jwtAuth = verifier
+	}
+	authMiddleware := middleware.Authenticate(userService, apiKeyService, jwtAuth)
+
+	// IP client hanya diambil dari X-Forwarded-For jika dikirim proxy tepercaya
+	trustedProxies, err := middleware.ParseCIDRs(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid rate_limit.trusted_proxies")
+	}
+
+	// Rate limit per caller. Counter di Redis jika tersedia agar berlaku
+	// bersama di semua replika, selain itu di memori.
+	var rateStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if redisClient != nil {
+		rateStore = ratelimit.NewRedisStore(redisClient)
+	}
+	var rateLimiter *middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter = middleware.NewRateLimiter(rateStore, cfg.RateLimit)
+	}
+	// Login gagal per IP selalu dibatasi, dihitung sebelum autentikasi
+	var loginLimiter *middleware.LoginLimiter
+	if cfg.RateLimit.AuthFailures > 0 {
+		loginLimiter = middleware.NewLoginLimiter(rateStore, cfg.RateLimit.AuthFailures, time.Duration(cfg.RateLimit.WindowSeconds)*time.Second)
+	}
+
+	// Audit log semua operasi tulis (append-only)
+	auditStore, err := audit.NewStore(cfg.Audit.File)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open audit log")
+	}
+	defer auditStore.Close()
+	auditor := middleware.NewAuditor(auditStore, oltService.IDByHost)
+	auditHandler := handler.NewAuditHandler(auditStore)
+
+	backupStore, err := backup.NewStore(cfg.Backup.Dir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open backup store")
+	}
+	backupService := service.NewBackupService(cfg, backupStore, deviceLimits)
+	backupHandler := handler.NewBackupHandler(backupService)
+	cliHandler := handler.NewCLIHandler(backupService, deviceLimits)
+
+	// Riwayat perubahan status ONU untuk skor stabilitas
+	historyStore, err := history.NewStore(cfg.Monitor.File, time.Duration(cfg.Monitor.RetentionDays)*24*time.Hour)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open ONU history")
+	}
+	defer historyStore.Close()
+	incidentStore, err := incident.NewStore(cfg.Monitor.Outage.File)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open incident store")
+	}
+	monitorService := service.NewMonitorService(cfg, onuService, historyStore, incidentStore)
+	stabilityHandler := handler.NewStabilityHandler(monitorService)
+	incidentHandler := handler.NewIncidentHandler(monitorService)
+
+	// Backup config terjadwal dan pemantauan status ONU berjalan di
+	// background sampai server berhenti
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go backupService.Start(bgCtx)
+	go monitorService.Start(bgCtx)
+
+	// Server TFTP internal untuk copy running-config dari/ke OLT
+	if cfg.TFTP.Enabled {
+		tftpServer := tftp.NewServer(backupService)
+		go func() {
+			log.Info().Str("addr", cfg.TFTP.Listen).Msg("Starting TFTP server")
+			if err := tftpServer.ListenAndServe(bgCtx, cfg.TFTP.Listen); err != nil {
+				log.Error().Err(err).Msg("TFTP server error")
+			}
+		}()
+	}
 
 	// 5. Setup Router menggunakan Chi
-	router := setupRouter(oltHandler, onuHandler, queryHandler, cliHandler)
+	router := setupRouter(oltHandler, onuHandler, stabilityHandler, incidentHandler, queryHandler, cliHandler, backupHandler, userHandler, apiKeyHandler, auditHandler, healthHandler, authMiddleware, auditor, oltService, trustedProxies, rateLimiter, loginLimiter)
 
 	server := &http.Server{
 		Addr:         cfg.Server.Addr(),
@@ -95,6 +214,7 @@ func main() {
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
 		log.Info().Msg("Shutting down...")
+		stopBackground()
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		server.Shutdown(ctx)
@@ -107,19 +227,50 @@ func main() {
 	}
 }
 
-func setupRouter(oltHandler *handler.OLTHandler, onuHandler *handler.ONUHandler, queryHandler *handler.QueryHandler, cliHandler *handler.CLIHandler) http.Handler {
-	r := chi.NewRouter()
+func setupRouter(oltHandler *handler.OLTHandler, onuHandler *handler.ONUHandler, stabilityHandler *handler.StabilityHandler, incidentHandler *handler.IncidentHandler, queryHandler *handler.QueryHandler, cliHandler *handler.CLIHandler, backupHandler *handler.BackupHandler, userHandler *handler.UserHandler, apiKeyHandler *handler.APIKeyHandler, auditHandler *handler.AuditHandler, healthHandler *handler.HealthHandler, authMiddleware func(http.Handler) http.Handler, auditor *middleware.Auditor, oltService *service.OLTService, trustedProxies []*net.IPNet, rateLimiter *middleware.RateLimiter, loginLimiter *middleware.LoginLimiter) http.Handler {
+	mux := chi.NewRouter()
 
 	// Menambahkan Middlewares (Fungsi yang berjalan sebelum handler utama)
-	r.Use(chiMiddleware.RequestID)    // Memberikan ID unik untuk setiap request
-	r.Use(chiMiddleware.RealIP)       // Mendapatkan IP asli client
-	r.Use(chiMiddleware.Logger)       // Mencatat log setiap request HTTP
-	r.Use(chiMiddleware.Recoverer)    // Mencegah aplikasi crash jika ada panic
-	r.Use(middleware.DefaultCORS())   // Mengizinkan akses dari domain luar (Cross-Origin Resource Sharing)
-	r.Use(middleware.BasicAuth())     // Autentikasi Basic Auth
-	// r.Use(middleware.NewRateLimiter(20, time.Minute).Middleware) // Batasan 20 request per menit per IP
+	mux.Use(chiMiddleware.RequestID)    // Memberikan ID unik untuk setiap request
+	mux.Use(middleware.RealIP(trustedProxies)) // Mendapatkan IP asli client dari proxy tepercaya
+	mux.Use(chiMiddleware.Logger)       // Mencatat log setiap request HTTP
+	mux.Use(chiMiddleware.Recoverer)    // Mencegah aplikasi crash jika ada panic
+	mux.Use(middleware.DefaultCORS())   // Mengizinkan akses dari domain luar (Cross-Origin Resource Sharing)
+
+	// Liveness dan readiness (Redis) tanpa autentikasi untuk probe orchestrator
+	mux.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+	})
+	mux.Get("/health/ready", healthHandler.Ready)
+
+	// Semua route lain membutuhkan autentikasi
+	r := mux.With()
+	if loginLimiter != nil {
+		r.Use(loginLimiter.Middleware) // Batas login gagal per IP, sebelum verifikasi password
+	}
+	r.Use(authMiddleware)             // Autentikasi Basic Auth, API key atau JWT (user + role)
+	if rateLimiter != nil {
+		r.Use(rateLimiter.Middleware) // Batas request per API key, user atau IP
+	}
 	// r.Use(chiMiddleware.Timeout(90 * time.Second)) // Batas waktu request maksimal 90 detik
 
+	// Permission per route (lihat model.RolePermissions)
+	canRead := middleware.Require(model.PermRead)
+	canProvision := middleware.Require(model.PermProvision)
+	canManageOLTs := middleware.Require(model.PermManageOLTs)
+	canRestore := middleware.Require(model.PermRestoreConfig)
+	canManageUsers := middleware.Require(model.PermManageUsers)
+	canViewAudit := middleware.Require(model.PermViewAudit)
+	hostScope := middleware.RequireHostScope(oltService.IDByHost)
+
+	// Audit log operasi tulis. Dipasang sebelum pengecekan permission agar
+	// percobaan yang ditolak juga tercatat.
+	audited := auditor.Middleware
+	auditedQuery := auditor.When(func(params map[string]interface{}) bool {
+		query, _ := params["query"].(string)
+		return handler.WriteQuery(query)
+	})
+
 	// Endpoint Dasar
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusOK, map[string]interface{}{
@@ -129,12 +280,11 @@ func setupRouter(oltHandler *handler.OLTHandler, onuHandler *handler.ONUHandler,
 		})
 	})
 
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		response.JSON(w, http.StatusOK, map[string]string{"status": "healthy"})
-	})
+	// Kesehatan semua OLT
+	r.With(canRead).Get("/health/olts", healthHandler.OLTs)
 
 	// Statistik Pool Koneksi SNMP
-	r.Get("/stats", queryHandler.PoolStats)
+	r.With(canRead).Get("/stats", queryHandler.PoolStats)
 
 	// Dokumentasi Swagger UI
 	r.Get("/swagger", func(w http.ResponseWriter, r *http.Request) {
@@ -149,140 +299,225 @@ func setupRouter(oltHandler *handler.OLTHandler, onuHandler *handler.ONUHandler,
 
 	// Grup API Versi 1
 	r.Route("/api/v1", func(r chi.Router) {
+		// User yang sedang login
+		r.Get("/auth/me", userHandler.Me)
+
 		// Endpoint "Stateless" (Tanpa simpan kredensial)
-		r.Post("/query", queryHandler.Query)
-		r.Post("/olt-info", queryHandler.OLTInfo)
+		r.With(auditedQuery, canRead, hostScope).Post("/query", queryHandler.Query)
+		r.With(canRead, hostScope).Post("/olt-info", queryHandler.OLTInfo)
 
 		// CLI Commands via Telnet
 		r.Route("/cli", func(r chi.Router) {
-			// System
-			r.Post("/system/clock", cliHandler.ShowClock)
-			
-			// Hardware
-			r.Post("/card", cliHandler.ShowCard)
-			r.Post("/card/slot", cliHandler.ShowCardBySlot)
-			r.Post("/rack", cliHandler.ShowRack)
-			r.Post("/shelf", cliHandler.ShowShelf)
-			r.Post("/subcard", cliHandler.ShowSubCard)
-			r.Post("/fan", cliHandler.ShowFan)
-			r.Post("/power", cliHandler.ShowPowerSupply)
-			r.Post("/temperature", cliHandler.ShowTemperature)
-			
-			// GPON Profiles
-			r.Post("/gpon/tcont", cliHandler.ShowTcontProfile)
-			r.Post("/gpon/onu-type", cliHandler.ShowOnuType)
-			r.Post("/gpon/vlan-profile", cliHandler.ShowVlanProfile)
-			r.Post("/gpon/ip-profile", cliHandler.ShowIPProfile)
-			r.Post("/gpon/sip-profile", cliHandler.ShowSIPProfile)
-			r.Post("/gpon/mgc-profile", cliHandler.ShowMGCProfile)
-			r.Post("/gpon/dial-plan", cliHandler.ShowDialPlanProfile)
-			r.Post("/gpon/voip-accesscode", cliHandler.ShowVoipAccesscodeProfile)
-			r.Post("/gpon/voip-appsrv", cliHandler.ShowVoipAppsrvProfile)
-			
-			// Line & Remote Profiles
-			r.Post("/profile/line/list", cliHandler.ShowLineProfileList)
-			r.Post("/profile/line", cliHandler.ShowLineProfile)
-			r.Post("/profile/remote/list", cliHandler.ShowRemoteProfileList)
-			r.Post("/profile/remote", cliHandler.ShowRemoteProfile)
-			
-			// GPON ONU
-			r.Post("/onu/state", cliHandler.ShowONUState)
-			r.Post("/onu/uncfg", cliHandler.ShowONUUncfg)
-			r.Post("/onu/config", cliHandler.ShowONUConfig)
-			r.Post("/onu/running", cliHandler.ShowONURunning)
-			r.Post("/onu/detail", cliHandler.ShowONUDetail)
-			r.Post("/onu/baseinfo", cliHandler.ShowONUBaseInfo)
-			r.Post("/onu/traffic", cliHandler.ShowONUTraffic)
-			r.Post("/onu/optical", cliHandler.ShowONUOptical)
-			
-			// VLAN
-			r.Post("/vlan/list", cliHandler.ShowVLANList)
-			r.Post("/vlan/id", cliHandler.ShowVLANByID)
-			
-			// Interface
-			r.Post("/interface", cliHandler.ShowInterface)
-			r.Post("/interface/detail", cliHandler.ShowInterfaceByType)
-			r.Post("/interface/mng", cliHandler.ShowMgmtInterface)
-			r.Post("/interface/vlan", cliHandler.ShowInterfaceVLAN)
-			
-			// Service Port
-			r.Post("/service-port", cliHandler.ShowServicePort)
-			
-			// IGMP
-			r.Post("/igmp", cliHandler.ShowIGMP)
-			r.Post("/igmp/mvlan", cliHandler.ShowIGMPMVlan)
-			r.Post("/igmp/mvlan/id", cliHandler.ShowIGMPMVlanByID)
-			r.Post("/igmp/dynamic-member", cliHandler.ShowIGMPDynamicMember)
-			r.Post("/igmp/forwarding-table", cliHandler.ShowIGMPForwardingTable)
-			r.Post("/igmp/interface", cliHandler.ShowIGMPInterface)
-			
-			// Users
-			r.Post("/user/list", cliHandler.ShowUsers)
-			r.Post("/user/online", cliHandler.ShowOnlineUsers)
-			
-			// SNMP
-			r.Post("/snmp/community", cliHandler.ShowSNMPCommunity)
-			r.Post("/snmp/host", cliHandler.ShowSNMPHost)
-			
-			// Configuration
-			r.Post("/config/running", cliHandler.ShowRunningConfig)
-			r.Post("/config/save", cliHandler.SaveConfig)
-			r.Post("/config/backup", cliHandler.BackupConfig)
-			r.Post("/config/restore", cliHandler.RestoreConfig)
-			
+			r.Use(hostScope)
+
+			// READ Operations
+			r.Group(func(r chi.Router) {
+				r.Use(canRead)
+
+				// System
+				r.Post("/system/clock", cliHandler.ShowClock)
+
+				// Hardware
+				r.Post("/card", cliHandler.ShowCard)
+				r.Post("/card/slot", cliHandler.ShowCardBySlot)
+				r.Post("/rack", cliHandler.ShowRack)
+				r.Post("/shelf", cliHandler.ShowShelf)
+				r.Post("/subcard", cliHandler.ShowSubCard)
+				r.Post("/fan", cliHandler.ShowFan)
+				r.Post("/power", cliHandler.ShowPowerSupply)
+				r.Post("/temperature", cliHandler.ShowTemperature)
+
+				// GPON Profiles
+				r.Post("/gpon/tcont", cliHandler.ShowTcontProfile)
+				r.Post("/gpon/onu-type", cliHandler.ShowOnuType)
+				r.Post("/gpon/vlan-profile", cliHandler.ShowVlanProfile)
+				r.Post("/gpon/ip-profile", cliHandler.ShowIPProfile)
+				r.Post("/gpon/sip-profile", cliHandler.ShowSIPProfile)
+				r.Post("/gpon/mgc-profile", cliHandler.ShowMGCProfile)
+				r.Post("/gpon/dial-plan", cliHandler.ShowDialPlanProfile)
+				r.Post("/gpon/voip-accesscode", cliHandler.ShowVoipAccesscodeProfile)
+				r.Post("/gpon/voip-appsrv", cliHandler.ShowVoipAppsrvProfile)
+
+				// Line & Remote Profiles
+				r.Post("/profile/line/list", cliHandler.ShowLineProfileList)
+				r.Post("/profile/line", cliHandler.ShowLineProfile)
+				r.Post("/profile/remote/list", cliHandler.ShowRemoteProfileList)
+				r.Post("/profile/remote", cliHandler.ShowRemoteProfile)
+
+				// GPON ONU
+				r.Post("/onu/state", cliHandler.ShowONUState)
+				r.Post("/onu/uncfg", cliHandler.ShowONUUncfg)
+				r.Post("/onu/config", cliHandler.ShowONUConfig)
+				r.Post("/onu/running", cliHandler.ShowONURunning)
+				r.Post("/onu/detail", cliHandler.ShowONUDetail)
+				r.Post("/onu/baseinfo", cliHandler.ShowONUBaseInfo)
+				r.Post("/onu/traffic", cliHandler.ShowONUTraffic)
+				r.Post("/onu/optical", cliHandler.ShowONUOptical)
+
+				// VLAN
+				r.Post("/vlan/list", cliHandler.ShowVLANList)
+				r.Post("/vlan/id", cliHandler.ShowVLANByID)
+
+				// Interface
+				r.Post("/interface", cliHandler.ShowInterface)
+				r.Post("/interface/detail", cliHandler.ShowInterfaceByType)
+				r.Post("/interface/mng", cliHandler.ShowMgmtInterface)
+				r.Post("/interface/vlan", cliHandler.ShowInterfaceVLAN)
+
+				// Service Port
+				r.Post("/service-port", cliHandler.ShowServicePort)
+
+				// IGMP
+				r.Post("/igmp", cliHandler.ShowIGMP)
+				r.Post("/igmp/mvlan", cliHandler.ShowIGMPMVlan)
+				r.Post("/igmp/mvlan/id", cliHandler.ShowIGMPMVlanByID)
+				r.Post("/igmp/dynamic-member", cliHandler.ShowIGMPDynamicMember)
+				r.Post("/igmp/forwarding-table", cliHandler.ShowIGMPForwardingTable)
+				r.Post("/igmp/interface", cliHandler.ShowIGMPInterface)
+
+				// Users
+				r.Post("/user/list", cliHandler.ShowUsers)
+				r.Post("/user/online", cliHandler.ShowOnlineUsers)
+
+				// SNMP
+				r.Post("/snmp/community", cliHandler.ShowSNMPCommunity)
+				r.Post("/snmp/host", cliHandler.ShowSNMPHost)
+
+				// Configuration
+				r.Post("/config/running", cliHandler.ShowRunningConfig)
+				r.Post("/config/running/parsed", cliHandler.ShowRunningConfigParsed)
+				r.Post("/config/drift", cliHandler.ConfigDrift)
+			})
+
+			// Restore config hanya untuk admin
+			r.With(audited, canRestore).Post("/config/restore", cliHandler.RestoreConfig)
+
 			// WRITE Operations (Provisioning)
-			r.Post("/onu/auth", cliHandler.AuthenticateONU)
-			r.Post("/onu/delete", cliHandler.DeleteONU)
-			r.Post("/onu/rename", cliHandler.RenameONU)
-			r.Post("/onu/reset", cliHandler.ResetONU)
-			
-			// T-CONT & GEM Port
-			r.Post("/tcont/create", cliHandler.CreateTCONT)
-			r.Post("/gemport/create", cliHandler.CreateGEMPort)
-			
-			// Service Port
-			r.Post("/service-port/create", cliHandler.CreateServicePort)
-			r.Post("/service-port/delete", cliHandler.DeleteServicePort)
-			
-			// VLAN
-			r.Post("/vlan/create", cliHandler.CreateVLAN)
-			r.Post("/vlan/delete", cliHandler.DeleteVLAN)
-			r.Post("/vlan/port/add", cliHandler.AddPortToVLAN)
-			
-			// Profile Creation
-			r.Post("/profile/line/create", cliHandler.CreateLineProfile)
-			r.Post("/profile/remote/create", cliHandler.CreateRemoteProfile)
-			r.Post("/profile/vlan/create", cliHandler.CreateVLANProfile)
-			r.Post("/profile/tcont/create", cliHandler.CreateTCONTProfile)
-			
-			// IGMP/Multicast
-			r.Post("/igmp/enable", cliHandler.EnableIGMP)
-			r.Post("/mvlan/create", cliHandler.CreateMVLAN)
-			r.Post("/mvlan/group/add", cliHandler.AddMVLANGroup)
+			r.Group(func(r chi.Router) {
+				r.Use(audited, canProvision)
+
+				// Configuration
+				r.Post("/config/save", cliHandler.SaveConfig)
+				r.Post("/config/backup", cliHandler.BackupConfig)
+
+				// ONU
+				r.Post("/onu/auth", cliHandler.AuthenticateONU)
+				r.Post("/onu/delete", cliHandler.DeleteONU)
+				r.Post("/onu/rename", cliHandler.RenameONU)
+				r.Post("/onu/reset", cliHandler.ResetONU)
+
+				// T-CONT & GEM Port
+				r.Post("/tcont/create", cliHandler.CreateTCONT)
+				r.Post("/gemport/create", cliHandler.CreateGEMPort)
+
+				// Service Port
+				r.Post("/service-port/create", cliHandler.CreateServicePort)
+				r.Post("/service-port/delete", cliHandler.DeleteServicePort)
+
+				// VLAN
+				r.Post("/vlan/create", cliHandler.CreateVLAN)
+				r.Post("/vlan/delete", cliHandler.DeleteVLAN)
+				r.Post("/vlan/port/add", cliHandler.AddPortToVLAN)
+
+				// Profile Creation
+				r.Post("/profile/line/create", cliHandler.CreateLineProfile)
+				r.Post("/profile/remote/create", cliHandler.CreateRemoteProfile)
+				r.Post("/profile/vlan/create", cliHandler.CreateVLANProfile)
+				r.Post("/profile/tcont/create", cliHandler.CreateTCONTProfile)
+
+				// IGMP/Multicast
+				r.Post("/igmp/enable", cliHandler.EnableIGMP)
+				r.Post("/mvlan/create", cliHandler.CreateMVLAN)
+				r.Post("/mvlan/group/add", cliHandler.AddMVLANGroup)
+			})
 		})
 
 		// Pengelolaan Data OLT (CRUD) + Operasi ONU
 		r.Route("/olts", func(r chi.Router) {
-			r.Get("/", oltHandler.List)
-			r.Post("/", oltHandler.Create)
-			
+			r.With(canRead).Get("/", oltHandler.List)
+			r.With(audited, canManageOLTs).Post("/", oltHandler.Create)
+
 			// Operasi untuk satu OLT (Get/Update/Delete + ONU operations)
 			r.Route("/{olt_id}", func(r chi.Router) {
 				// CRUD OLT
-				r.Get("/", oltHandler.Get)
-				r.Put("/", oltHandler.Update)
-				r.Delete("/", oltHandler.Delete)
-				
+				r.With(canRead).Get("/", oltHandler.Get)
+				r.With(audited, canManageOLTs).Put("/", oltHandler.Update)
+				r.With(audited, canManageOLTs).Delete("/", oltHandler.Delete)
+
+				// Status kesehatan (SNMP, Telnet, circuit breaker)
+				r.With(canRead).Get("/health", healthHandler.OLT)
+
+				// Backup running-config (versi, diff)
+				r.With(canRead).Get("/backups", backupHandler.List)
+				r.With(audited, canProvision).Post("/backups", backupHandler.Create)
+				r.With(canRead).Get("/backups/diff", backupHandler.Diff)
+				r.With(canRead).Get("/backups/{version_id}", backupHandler.Get)
+
+				// Top-N ONU berdasarkan laju trafik (seluruh OLT atau ?board=&pon=)
+				r.With(canRead).Get("/traffic/top", onuHandler.TopTraffic)
+				// Laporan kapasitas dan utilisasi PON
+				r.With(canRead).Get("/capacity", onuHandler.Capacity)
+				// Analisis budget optik dan outlier loss per PON
+				r.With(canRead).Get("/optical", onuHandler.Optical)
+				// Stabilitas ONU dari riwayat status (butuh monitor.enabled)
+				r.With(canRead).Get("/stability", stabilityHandler.OLT)
+				// Insiden gangguan massal di OLT ini
+				r.With(canRead).Get("/incidents", incidentHandler.List)
+
 				// ONU Operations
 				r.Route("/board/{board_id}/pon/{pon_id}", func(r chi.Router) {
+					r.Use(canRead)
 					r.Get("/", onuHandler.List)              // List ONU di satu port PON
 					r.Delete("/cache", onuHandler.ClearCache) // Bersihkan cache
 					r.Get("/empty", onuHandler.EmptySlots)    // Cek slot kosong
 					r.Get("/onu/{onu_id}", onuHandler.Detail) // Detail ONU spesifik
+					r.With(audited, canProvision).Post("/onu/{onu_id}/actions", onuHandler.Action) // Reboot, factory reset, enable/disable
+					r.Get("/onu/{onu_id}/eth", onuHandler.EthPorts) // Status port Ethernet (UNI) ONU
+					r.With(audited, canProvision).Put("/onu/{onu_id}/eth/{port}", onuHandler.SetEthPort) // Lock/unlock port Ethernet ONU
+					r.Get("/onu/{onu_id}/wan", onuHandler.WAN)       // Koneksi WAN/PPPoE ONU (CLI)
+					r.Get("/onu/{onu_id}/wifi", onuHandler.WiFi)     // SSID WiFi ONU (CLI)
+					r.Get("/onu/{onu_id}/config", onuHandler.Config) // Konfigurasi pon-onu-mng ONU (CLI)
+					r.With(audited, canProvision).Put("/onu/{onu_id}/wan/{index}/pppoe", onuHandler.SetPPPoE) // Atur kredensial PPPoE
+					r.With(audited, canProvision).Put("/onu/{onu_id}/wifi/{ssid}", onuHandler.SetWiFi)        // Ubah SSID/password WiFi
+					r.Get("/traffic/top", onuHandler.TopTraffic) // Top-N trafik di PON ini
+					r.Get("/stability", stabilityHandler.OLT)     // Stabilitas ONU di PON ini
+					r.Get("/onu/{onu_id}/stability", stabilityHandler.ONU) // Riwayat status dan stabilitas ONU
 				})
 			})
 		})
+
+		// Insiden gangguan massal semua OLT (sesuai scope user)
+		r.Route("/incidents", func(r chi.Router) {
+			r.Use(canRead)
+			r.Get("/", incidentHandler.List)
+			r.Get("/{incident_id}", incidentHandler.Get)
+		})
+
+		// Pengelolaan User API (khusus admin)
+		r.Route("/users", func(r chi.Router) {
+			r.Use(canManageUsers)
+			r.Get("/", userHandler.List)
+			r.With(audited).Post("/", userHandler.Create)
+			r.Get("/{username}", userHandler.Get)
+			r.With(audited).Put("/{username}", userHandler.Update)
+			r.With(audited).Delete("/{username}", userHandler.Delete)
+		})
+
+		// API key untuk integrasi (khusus admin)
+		r.Route("/api-keys", func(r chi.Router) {
+			r.Use(canManageUsers)
+			r.Get("/", apiKeyHandler.List)
+			r.With(audited).Post("/", apiKeyHandler.Create)
+			r.With(audited).Delete("/{key_id}", apiKeyHandler.Revoke)
+		})
+
+		// Audit log operasi tulis (khusus admin)
+		r.Route("/audit", func(r chi.Router) {
+			r.Use(canViewAudit)
+			r.Get("/", auditHandler.List)
+			r.Get("/export", auditHandler.Export)
+		})
 	})
 
-	return r
+	return mux
 }
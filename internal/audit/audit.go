@@ -0,0 +1,215 @@
+// Package audit mencatat setiap operasi tulis (siapa, dari mana, ke OLT mana,
+// perintah apa yang dikirim dan hasilnya) ke log append-only.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hasil operasi
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Protokol perintah yang dikirim ke OLT
+const (
+	ProtocolCLI  = "cli"
+	ProtocolSNMP = "snmp"
+)
+
+// Redacted pengganti nilai parameter sensitif
+const Redacted = "***"
+
+// Entry satu catatan audit
+type Entry struct {
+	ID         int64                  `json:"id"`
+	Time       time.Time              `json:"time"`
+	User       string                 `json:"user"`
+	Role       string                 `json:"role,omitempty"`
+	AuthMethod string                 `json:"auth_method,omitempty"`
+	TokenID    string                 `json:"token_id,omitempty"`
+	SourceIP   string                 `json:"source_ip"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Route      string                 `json:"route,omitempty"`
+	Target     Target                 `json:"target"`
+	Params     map[string]interface{} `json:"params,omitempty"` // Body request yang sudah disanitasi
+	Commands   []Command              `json:"commands,omitempty"`
+	Status     int                    `json:"status"`
+	Result     string                 `json:"result"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+}
+
+// Target OLT/board/PON/ONU yang dituju operasi
+type Target struct {
+	OLTID string `json:"olt_id,omitempty"`
+	Host  string `json:"host,omitempty"`
+	Board int    `json:"board,omitempty"`
+	PON   int    `json:"pon,omitempty"`
+	ONU   int    `json:"onu,omitempty"`
+}
+
+// Command satu perintah CLI atau SNMP SET yang dikirim ke OLT
+type Command struct {
+	Time     time.Time `json:"time"`
+	Protocol string    `json:"protocol"`
+	Target   string    `json:"target,omitempty"`
+	Command  string    `json:"command"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Recorder mengumpulkan perintah yang dikirim selama satu request
+type Recorder struct {
+	mu       sync.Mutex
+	commands []Command
+}
+
+// Commands mengembalikan salinan perintah yang tercatat
+func (r *Recorder) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Command(nil), r.commands...)
+}
+
+func (r *Recorder) add(c Command) {
+	r.mu.Lock()
+	r.commands = append(r.commands, c)
+	r.mu.Unlock()
+}
+
+type contextKey struct{}
+
+// WithRecorder memasang recorder ke context. Perintah yang dikirim lewat
+// context ini (cli.Client.Execute, SNMP SET driver) akan tercatat.
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, rec)
+}
+
+// RecordCommand mencatat perintah ke recorder di ctx (no-op jika tidak ada)
+func RecordCommand(ctx context.Context, protocol, target, command string, err error) {
+	if ctx == nil {
+		return
+	}
+	rec, _ := ctx.Value(contextKey{}).(*Recorder)
+	if rec == nil {
+		return
+	}
+	c := Command{
+		Time:     time.Now().UTC(),
+		Protocol: protocol,
+		Target:   target,
+		Command:  RedactCommand(command),
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	rec.add(c)
+}
+
+// RecordSNMPSet mencatat satu SNMP SET dalam format "SET {oid} {type} {value}"
+func RecordSNMPSet(ctx context.Context, target, oid, typ string, value interface{}, err error) {
+	if s, ok := value.(string); ok {
+		value = fmt.Sprintf("%q", s)
+	}
+	RecordCommand(ctx, ProtocolSNMP, target, fmt.Sprintf("SET %s %s %v", oid, typ, value), err)
+}
+
+// sensitiveKeys potongan nama field yang nilainya tidak boleh masuk log
+var sensitiveKeys = []string{"password", "passwd", "secret", "community", "token", "key", "credential"}
+
+// Sensitive true jika nilai field bernama key harus disembunyikan
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandSecrets kata kunci CLI yang diikuti nilai rahasia
+var commandSecrets = map[string]bool{"password": true, "secret": true, "key": true, "community": true}
+
+// RedactCommand mengganti kata setelah kata kunci rahasia (mis.
+// "password xxx", "wpa2-psk key xxx") dengan Redacted
+func RedactCommand(command string) string {
+	fields := strings.Fields(command)
+	redacted := false
+	for i := 0; i+1 < len(fields); i++ {
+		if commandSecrets[strings.ToLower(fields[i])] {
+			fields[i+1] = Redacted
+			redacted = true
+			i++
+		}
+	}
+	if !redacted {
+		return command
+	}
+	return strings.Join(fields, " ")
+}
+
+// minRedactValue panjang minimal secret yang disembunyikan berdasarkan
+// nilai. Secret yang lebih pendek (mis. "1") bisa sama dengan port atau
+// VLAN di command, sehingga hanya disembunyikan oleh RedactCommand.
+const minRedactValue = 4
+
+// RedactValues mengganti setiap kata command yang sama persis dengan salah
+// satu secrets dengan Redacted. Dipakai saat nilai rahasia diketahui pasti
+// (mis. password PPPoE), sehingga tidak bergantung pada kata kunci seperti
+// RedactCommand. Secret di bawah minRedactValue karakter diabaikan.
+func RedactValues(command string, secrets ...string) string {
+	fields := strings.Fields(command)
+	redacted := false
+	for i, f := range fields {
+		for _, secret := range secrets {
+			if len(secret) >= minRedactValue && f == secret {
+				fields[i] = Redacted
+				redacted = true
+				break
+			}
+		}
+	}
+	if !redacted {
+		return command
+	}
+	return strings.Join(fields, " ")
+}
+
+// Sanitize menyalin params dan mengganti nilai field sensitif (termasuk di
+// object/array bersarang) dengan Redacted.
+func Sanitize(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if Sensitive(k) {
+			out[k] = Redacted
+			continue
+		}
+		out[k] = sanitizeValue(v)
+	}
+	return out
+}
+
+func sanitizeValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return Sanitize(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = sanitizeValue(item)
+		}
+		return out
+	}
+	return v
+}
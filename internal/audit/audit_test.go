@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{name: "nil", params: nil, want: nil},
+		{
+			name:   "field sensitif",
+			params: map[string]interface{}{"host": "10.0.0.1", "password": "s3cr3t", "SNMP_Community": "private", "api_key": "szk_x", "wifi_key": "k", "slot": float64(1)},
+			want:   map[string]interface{}{"host": "10.0.0.1", "password": Redacted, "SNMP_Community": Redacted, "api_key": Redacted, "wifi_key": Redacted, "slot": float64(1)},
+		},
+		{
+			name: "object dan array bersarang",
+			params: map[string]interface{}{
+				"desired": map[string]interface{}{
+					"onus": []interface{}{
+						map[string]interface{}{"onu_id": float64(3), "password": "12345678"},
+						"teks",
+					},
+				},
+				"credentials": map[string]interface{}{"user": "admin"},
+			},
+			want: map[string]interface{}{
+				"desired": map[string]interface{}{
+					"onus": []interface{}{
+						map[string]interface{}{"onu_id": float64(3), "password": Redacted},
+						"teks",
+					},
+				},
+				"credentials": Redacted,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.params); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Sanitize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("input tidak diubah", func(t *testing.T) {
+		nested := map[string]interface{}{"secret": "x"}
+		params := map[string]interface{}{"password": "p", "nested": nested}
+		Sanitize(params)
+		if params["password"] != "p" || nested["secret"] != "x" {
+			t.Fatalf("params modified: %#v", params)
+		}
+	})
+}
+
+func TestRedactCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"show gpon onu state gpon-olt_1/1/1", "show gpon onu state gpon-olt_1/1/1"},
+		{"  tidak   diubah  ", "  tidak   diubah  "},
+		{"wan-ip 1 mode pppoe username user1 password s3cr3t vlan-profile v143 host 1", "wan-ip 1 mode pppoe username user1 password *** vlan-profile v143 host 1"},
+		{"ssid auth wpa wifi_0/1 wpa2-psk key abcdefgh", "ssid auth wpa wifi_0/1 wpa2-psk key ***"},
+		{"snmp-server community private view AllView rw", "snmp-server community *** view AllView rw"},
+		{"tacacs-server host 10.0.0.9 SECRET t4c4cs", "tacacs-server host 10.0.0.9 SECRET ***"},
+		{"username admin password", "username admin password"},
+	}
+	for _, tt := range tests {
+		if got := RedactCommand(tt.command); got != tt.want {
+			t.Errorf("RedactCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRedactValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		secrets []string
+		want    string
+	}{
+		{"tanpa secret", "username key password s3cr3t", nil, "username key password s3cr3t"},
+		{"secret kosong diabaikan", "onu 1 type ZTE-F660 sn ZTEG00000001", []string{""}, "onu 1 type ZTE-F660 sn ZTEG00000001"},
+		{"username kata kunci", "wan-ip 1 mode pppoe username key password s3cr3tPass vlan-profile v host 1", []string{"s3cr3tPass"}, "wan-ip 1 mode pppoe username key password *** vlan-profile v host 1"},
+		{"hanya kata yang sama persis", "ssid ctrl wifi_0/1 name community-net", []string{"community"}, "ssid ctrl wifi_0/1 name community-net"},
+		{"beberapa secret", "set user alpha123 pass beta4567", []string{"alpha123", "beta4567"}, "set user *** pass ***"},
+		{"muncul berulang", "x hunter22 y hunter22", []string{"hunter22"}, "x *** y ***"},
+		{"secret 4 karakter", "wan-ip 1 mode pppoe username u password abcd", []string{"abcd"}, "wan-ip 1 mode pppoe username u password ***"},
+		{"secret pendek tidak menghapus port", "wan-ip 1 mode pppoe username u password 1", []string{"1"}, "wan-ip 1 mode pppoe username u password 1"},
+		{"secret pendek tidak menghapus vlan", "ssid auth wep wifi_0/1 key 100 vlan 100", []string{"100"}, "ssid auth wep wifi_0/1 key 100 vlan 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactValues(tt.command, tt.secrets...); got != tt.want {
+				t.Fatalf("RedactValues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRedactShortSecret secret pendek yang dilewati RedactValues tetap
+// disembunyikan berdasarkan posisi oleh RedactCommand, tanpa mengubah angka
+// lain yang bernilai sama
+func TestRedactShortSecret(t *testing.T) {
+	tests := []struct {
+		command string
+		secret  string
+		want    string
+	}{
+		{"wan-ip 1 mode pppoe username u password 1", "1", "wan-ip 1 mode pppoe username u password ***"},
+		{"ssid auth wep wifi_0/1 key 100 vlan 100", "100", "ssid auth wep wifi_0/1 key *** vlan 100"},
+	}
+	for _, tt := range tests {
+		if got := RedactCommand(RedactValues(tt.command, tt.secret)); got != tt.want {
+			t.Errorf("RedactCommand(RedactValues(%q)) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRecordCommand(t *testing.T) {
+	// Tanpa recorder di context tidak panic
+	RecordCommand(context.Background(), ProtocolCLI, "10.0.0.1", "password x", nil)
+
+	rec := &Recorder{}
+	ctx := WithRecorder(context.Background(), rec)
+	RecordCommand(ctx, ProtocolCLI, "10.0.0.1", "snmp-server community private ro", errors.New("timeout"))
+	RecordSNMPSet(ctx, "10.0.0.1", ".1.3.6.1.4.1.3902", "OctetString", "pelanggan", nil)
+
+	got := rec.Commands()
+	if len(got) != 2 {
+		t.Fatalf("commands = %+v", got)
+	}
+	if got[0].Command != "snmp-server community *** ro" || got[0].Error != "timeout" || got[0].Protocol != ProtocolCLI {
+		t.Errorf("cli command = %+v", got[0])
+	}
+	if got[1].Command != `SET .1.3.6.1.4.1.3902 OctetString "pelanggan"` || got[1].Protocol != ProtocolSNMP {
+		t.Errorf("snmp command = %+v", got[1])
+	}
+}
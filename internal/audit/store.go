@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Batas jumlah entry per query
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// maxLine batas panjang satu baris log (entry dengan banyak perintah CLI)
+const maxLine = 4 << 20
+
+// Filter kriteria pencarian entry. Field kosong tidak dipakai.
+type Filter struct {
+	User   string
+	OLTID  string
+	Method string
+	Path   string // Prefix path request
+	Result string
+	Since  time.Time
+	Until  time.Time
+	Before int64    // Hanya entry dengan ID < Before (paging mundur)
+	OLTs   []string // Hanya entry untuk OLT ini (scope user), kosong = semua
+	Limit  int
+}
+
+func (f *Filter) match(e *Entry) bool {
+	switch {
+	case f.User != "" && e.User != f.User:
+		return false
+	case f.OLTID != "" && e.Target.OLTID != f.OLTID:
+		return false
+	case f.Method != "" && !strings.EqualFold(e.Method, f.Method):
+		return false
+	case f.Path != "" && !strings.HasPrefix(e.Path, f.Path):
+		return false
+	case f.Result != "" && e.Result != f.Result:
+		return false
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	case f.Before > 0 && e.ID >= f.Before:
+		return false
+	case len(f.OLTs) > 0 && !slices.Contains(f.OLTs, e.Target.OLTID):
+		return false
+	}
+	return true
+}
+
+// Store log audit append-only dalam format JSON Lines (satu entry per
+// baris). Entry tidak pernah diubah atau dihapus lewat API.
+type Store struct {
+	path   string
+	mu     sync.Mutex
+	file   *os.File
+	lastID int64
+}
+
+// NewStore membuka (atau membuat) file log audit di path
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	s := &Store{path: path, file: f}
+	err = s.scan(func(e *Entry, _ []byte) bool {
+		s.lastID = max(s.lastID, e.ID)
+		return true
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append menambahkan entry ke log. ID dan waktu diisi otomatis.
+func (s *Store) Append(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.ID = s.lastID + 1
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	s.lastID = e.ID
+	return nil
+}
+
+// Query mengembalikan entry yang cocok dengan filter, terbaru lebih dulu
+func (s *Store) Query(f Filter) ([]Entry, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	// Simpan hanya limit entry terakhir yang cocok (ring buffer)
+	ring := make([]Entry, 0, limit)
+	next := 0
+	err := s.scan(func(e *Entry, _ []byte) bool {
+		if !f.match(e) {
+			return true
+		}
+		if len(ring) < limit {
+			ring = append(ring, *e)
+		} else {
+			ring[next] = *e
+			next = (next + 1) % limit
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Entry, 0, len(ring))
+	for i := len(ring) - 1; i >= 0; i-- {
+		out = append(out, ring[(next+i)%len(ring)])
+	}
+	return out, nil
+}
+
+// Export menulis semua entry yang cocok dengan filter (tanpa limit) ke w
+// sebagai JSON Lines, urut dari yang terlama.
+func (s *Store) Export(w io.Writer, f Filter) error {
+	var werr error
+	err := s.scan(func(e *Entry, line []byte) bool {
+		if !f.match(e) {
+			return true
+		}
+		if _, werr = w.Write(line); werr == nil {
+			_, werr = w.Write([]byte{'\n'})
+		}
+		return werr == nil
+	})
+	if werr != nil {
+		return werr
+	}
+	return err
+}
+
+// Close menutup file log
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// scan membaca log dari awal dan memanggil fn untuk setiap entry sampai fn
+// mengembalikan false. Baris yang rusak (mis. terpotong saat crash) dilewati.
+func (s *Store) scan(fn func(e *Entry, line []byte) bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxLine)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if !fn(&e, line) {
+			return nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
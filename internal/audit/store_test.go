@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+// newTestStore membuat store dengan 10 entry: ID ganjil ke olt-a, genap ke
+// olt-b, entry ke-i dicatat testTime + i menit
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	for i := 1; i <= 10; i++ {
+		e := &Entry{
+			Time:   testTime.Add(time.Duration(i) * time.Minute),
+			User:   "operator",
+			Method: "POST",
+			Path:   "/api/v1/cli/onu/auth",
+			Result: ResultSuccess,
+			Target: Target{OLTID: "olt-b"},
+		}
+		if i%2 == 1 {
+			e.Target.OLTID = "olt-a"
+		}
+		if i%5 == 0 {
+			e.User = "admin"
+			e.Method = "DELETE"
+			e.Path = "/api/v1/olts/olt-b"
+			e.Result = ResultFailure
+		}
+		if err := s.Append(e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != int64(i) {
+			t.Fatalf("entry %d got ID %d", i, e.ID)
+		}
+	}
+	return s, path
+}
+
+func entryIDs(entries []Entry) []int64 {
+	ids := []int64{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestStoreQuery(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{"semua terbaru dulu", Filter{}, []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{"limit", Filter{Limit: 3}, []int64{10, 9, 8}},
+		{"before", Filter{Before: 8, Limit: 3}, []int64{7, 6, 5}},
+		{"before di awal log", Filter{Before: 2, Limit: 3}, []int64{1}},
+		{"olt_id", Filter{OLTID: "olt-a"}, []int64{9, 7, 5, 3, 1}},
+		{"scope OLT user", Filter{OLTs: []string{"olt-b"}, Limit: 2}, []int64{10, 8}},
+		{"scope tanpa OLT yang cocok", Filter{OLTs: []string{"olt-c"}}, []int64{}},
+		{"user", Filter{User: "admin"}, []int64{10, 5}},
+		{"method tanpa memperhatikan huruf", Filter{Method: "delete"}, []int64{10, 5}},
+		{"prefix path", Filter{Path: "/api/v1/cli/"}, []int64{9, 8, 7, 6, 4, 3, 2, 1}},
+		{"result", Filter{Result: ResultFailure}, []int64{10, 5}},
+		{"rentang waktu", Filter{Since: testTime.Add(3 * time.Minute), Until: testTime.Add(6 * time.Minute)}, []int64{5, 4, 3}},
+		{"limit maksimum", Filter{Limit: MaxLimit + 1}, []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := entryIDs(got); !slices.Equal(ids, tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	t.Run("paging dengan before", func(t *testing.T) {
+		var all []int64
+		f := Filter{OLTs: []string{"olt-a"}, Limit: 2}
+		for {
+			page, err := s.Query(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			all = append(all, entryIDs(page)...)
+			f.Before = page[len(page)-1].ID
+		}
+		if want := []int64{9, 7, 5, 3, 1}; !slices.Equal(all, want) {
+			t.Fatalf("pages = %v, want %v", all, want)
+		}
+	})
+}
+
+func TestStoreReopen(t *testing.T) {
+	s, path := newTestStore(t)
+	s.Close()
+
+	// Baris rusak (mis. terpotong saat crash) dilewati
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":11,"user":"trunc`)
+	f.Close()
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	e := &Entry{User: "operator"}
+	if err := reopened.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 11 || e.Time.IsZero() {
+		t.Fatalf("entry = %+v, want ID 11 with time", e)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("audit log mode = %v, %v", info.Mode().Perm(), err)
+	}
+}
+
+func TestStoreExport(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	var buf bytes.Buffer
+	if err := s.Export(&buf, Filter{OLTs: []string{"olt-b"}, Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// JSON Lines, terlama dulu, tanpa limit
+	var ids []int64
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", sc.Text(), err)
+		}
+		if e.Target.OLTID != "olt-b" {
+			t.Fatalf("entry outside scope exported: %+v", e)
+		}
+		ids = append(ids, e.ID)
+	}
+	if want := []int64{2, 4, 6, 8, 10}; !slices.Equal(ids, want) {
+		t.Fatalf("exported ids = %v, want %v", ids, want)
+	}
+}
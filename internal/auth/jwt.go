@@ -0,0 +1,329 @@
+// Package auth memverifikasi JWT bearer token (HS256/RS256) yang diterbitkan
+// identity provider eksternal dan memetakannya menjadi identitas caller.
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidToken dikembalikan untuk semua token yang gagal diverifikasi
+var ErrInvalidToken = errors.New("invalid token")
+
+// jwksCheckInterval jarak minimal pengecekan perubahan file JWKS
+const jwksCheckInterval = 30 * time.Second
+
+// Claims payload JWT
+type Claims map[string]interface{}
+
+// JWTAuthenticator memverifikasi JWT dan mengubah claim menjadi model.User
+type JWTAuthenticator struct {
+	cfg    config.JWTConfig
+	secret []byte
+	leeway time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey // kid -> key dari JWKS
+	jwksModTime time.Time
+	jwksChecked time.Time
+
+	now func() time.Time
+}
+
+// NewJWTAuthenticator membuat verifier JWT. Minimal salah satu dari
+// hs256_secret atau jwks_file harus diisi.
+func NewJWTAuthenticator(cfg config.JWTConfig) (*JWTAuthenticator, error) {
+	if cfg.HS256Secret == "" && cfg.JWKSFile == "" {
+		return nil, errors.New("jwt: hs256_secret or jwks_file is required")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "sub"
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "role"
+	}
+	if cfg.OLTsClaim == "" {
+		cfg.OLTsClaim = "olts"
+	}
+	if cfg.LeewaySeconds == 0 {
+		cfg.LeewaySeconds = 60
+	}
+
+	a := &JWTAuthenticator{
+		cfg:    cfg,
+		secret: []byte(cfg.HS256Secret),
+		leeway: time.Duration(cfg.LeewaySeconds) * time.Second,
+		keys:   map[string]*rsa.PublicKey{},
+		now:    time.Now,
+	}
+	if cfg.JWKSFile != "" {
+		if err := a.loadJWKS(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// AuthenticateToken memverifikasi token dan mengembalikan identitas caller
+func (a *JWTAuthenticator) AuthenticateToken(token string) (*model.User, error) {
+	claims, err := a.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := claims[a.cfg.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, a.cfg.UsernameClaim)
+	}
+	role, _ := claims[a.cfg.RoleClaim].(string)
+	if !model.ValidRole(role) {
+		return nil, fmt.Errorf("%w: invalid %s claim", ErrInvalidToken, a.cfg.RoleClaim)
+	}
+
+	user := &model.User{
+		Username:   username,
+		Role:       role,
+		OLTs:       stringList(claims[a.cfg.OLTsClaim]),
+		AuthMethod: model.AuthJWT,
+	}
+	user.TokenID, _ = claims["jti"].(string)
+	return user, nil
+}
+
+// Verify memeriksa signature, exp, nbf, iss dan aud lalu mengembalikan claims
+func (a *JWTAuthenticator) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: bad header", ErrInvalidToken)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidToken)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if len(a.secret) == 0 {
+			return nil, fmt.Errorf("%w: HS256 not enabled", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, a.secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	case "RS256":
+		if a.cfg.JWKSFile == "" {
+			return nil, fmt.Errorf("%w: RS256 not enabled", ErrInvalidToken)
+		}
+		if !a.verifyRS256(header.Kid, signed, sig) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	default:
+		// "none" dan algoritma lain selalu ditolak
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: bad payload", ErrInvalidToken)
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims Claims) error {
+	now := a.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(a.leeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	if a.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.cfg.Issuer {
+			return fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+		}
+	}
+	if a.cfg.Audience != "" {
+		found := false
+		for _, aud := range stringList(claims["aud"]) {
+			if aud == a.cfg.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: wrong audience", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+func (a *JWTAuthenticator) verifyRS256(kid string, signed, sig []byte) bool {
+	a.reloadJWKS()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	digest := sha256.Sum256(signed)
+	if kid != "" {
+		key, ok := a.keys[kid]
+		return ok && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	// Token tanpa kid: coba semua key
+	for _, key := range a.keys {
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadJWKS memuat ulang file JWKS jika berubah (rotasi key tanpa restart)
+func (a *JWTAuthenticator) reloadJWKS() {
+	a.mu.RLock()
+	due := a.now().Sub(a.jwksChecked) >= jwksCheckInterval
+	a.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	info, err := os.Stat(a.cfg.JWKSFile)
+
+	a.mu.Lock()
+	a.jwksChecked = a.now()
+	changed := err == nil && !info.ModTime().Equal(a.jwksModTime)
+	a.mu.Unlock()
+
+	if changed {
+		if err := a.loadJWKS(); err != nil {
+			log.Warn().Err(err).Str("file", a.cfg.JWKSFile).Msg("Failed to reload JWKS, keeping previous keys")
+		}
+	}
+}
+
+func (a *JWTAuthenticator) loadJWKS() error {
+	info, err := os.Stat(a.cfg.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("jwt: failed to read JWKS: %w", err)
+	}
+	data, err := os.ReadFile(a.cfg.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("jwt: failed to read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.keys = keys
+	a.jwksModTime = info.ModTime()
+	a.jwksChecked = a.now()
+	a.mu.Unlock()
+	return nil
+}
+
+// parseJWKS mengambil semua key RSA dari dokumen JWKS (RFC 7517)
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("jwt: failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for i, k := range doc.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: bad modulus in JWKS key %q", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwt: bad exponent in JWKS key %q", k.Kid)
+		}
+
+		kid := k.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		keys[kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: JWKS contains no RSA signing keys")
+	}
+	return keys, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringList menerima claim berupa string tunggal atau array string
+func stringList(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		if t == "" {
+			return nil
+		}
+		return []string{t}
+	case []interface{}:
+		var out []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
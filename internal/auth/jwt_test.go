@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+const testSecret = "hs256-test-secret"
+
+var testNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func segment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret []byte, header, claims map[string]interface{}) string {
+	t.Helper()
+	signed := segment(t, header) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header := map[string]interface{}{"alg": "RS256", "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	signed := segment(t, header) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// validClaims claim yang lolos semua pemeriksaan pada testNow
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "noc-1",
+		"role": model.RoleOperator,
+		"olts": []string{"olt-a"},
+		"iss":  "https://idp.example",
+		"aud":  []string{"other", "snmp-zte"},
+		"exp":  testNow.Add(time.Hour).Unix(),
+		"nbf":  testNow.Add(-time.Minute).Unix(),
+		"jti":  "token-1",
+	}
+}
+
+// writeJWKS menulis public key ke file JWKS dengan mtime tertentu
+func writeJWKS(t *testing.T, path string, mtime time.Time, keys map[string]*rsa.PrivateKey) {
+	t.Helper()
+	var doc struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, k := range keys {
+		doc.Keys = append(doc.Keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newTestAuthenticator(t *testing.T, cfg config.JWTConfig) *JWTAuthenticator {
+	t.Helper()
+	cfg.Issuer = "https://idp.example"
+	cfg.Audience = "snmp-zte"
+	a, err := NewJWTAuthenticator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return testNow }
+	a.jwksChecked = testNow // Dimuat konstruktor dengan jam asli
+	return a
+}
+
+func TestVerifyHS256Claims(t *testing.T) {
+	a := newTestAuthenticator(t, config.JWTConfig{HS256Secret: testSecret})
+	hs := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+
+	tests := []struct {
+		name   string
+		modify func(c map[string]interface{})
+		ok     bool
+	}{
+		{"valid", func(c map[string]interface{}) {}, true},
+		{"tanpa exp", func(c map[string]interface{}) { delete(c, "exp") }, false},
+		{"exp bukan angka", func(c map[string]interface{}) { c["exp"] = "tomorrow" }, false},
+		{"expired", func(c map[string]interface{}) { c["exp"] = testNow.Add(-2 * time.Minute).Unix() }, false},
+		{"expired dalam leeway", func(c map[string]interface{}) { c["exp"] = testNow.Add(-30 * time.Second).Unix() }, true},
+		{"nbf di masa depan", func(c map[string]interface{}) { c["nbf"] = testNow.Add(5 * time.Minute).Unix() }, false},
+		{"nbf dalam leeway", func(c map[string]interface{}) { c["nbf"] = testNow.Add(30 * time.Second).Unix() }, true},
+		{"issuer lain", func(c map[string]interface{}) { c["iss"] = "https://evil.example" }, false},
+		{"tanpa issuer", func(c map[string]interface{}) { delete(c, "iss") }, false},
+		{"audience lain", func(c map[string]interface{}) { c["aud"] = "other" }, false},
+		{"audience string tunggal", func(c map[string]interface{}) { c["aud"] = "snmp-zte" }, true},
+		{"tanpa audience", func(c map[string]interface{}) { delete(c, "aud") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+			_, err := a.Verify(signHS256(t, []byte(testSecret), hs, claims))
+			if tt.ok && err != nil {
+				t.Fatalf("Verify() = %v, want ok", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	t.Run("secret lain", func(t *testing.T) {
+		if _, err := a.Verify(signHS256(t, []byte("other"), hs, validClaims())); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify() = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+func TestVerifyRejectsAlgNone(t *testing.T) {
+	a := newTestAuthenticator(t, config.JWTConfig{HS256Secret: testSecret})
+	for _, alg := range []string{"none", "None", "", "HS512"} {
+		header := segment(t, map[string]interface{}{"alg": alg, "typ": "JWT"})
+		for _, sig := range []string{"", base64.RawURLEncoding.EncodeToString([]byte("x"))} {
+			token := header + "." + segment(t, validClaims()) + "." + sig
+			if _, err := a.Verify(token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("alg %q sig %q: Verify() = %v, want ErrInvalidToken", alg, sig, err)
+			}
+		}
+	}
+}
+
+func TestVerifyAlgorithmConfusion(t *testing.T) {
+	key := generateKey(t)
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwks, testNow, map[string]*rsa.PrivateKey{"k1": key})
+
+	// Public key yang dikenal penyerang dipakai sebagai secret HMAC
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	confused := map[string]interface{}{"alg": "HS256", "typ": "JWT", "kid": "k1"}
+
+	t.Run("HS256 ditolak jika hanya JWKS", func(t *testing.T) {
+		a := newTestAuthenticator(t, config.JWTConfig{JWKSFile: jwks})
+		for _, secret := range [][]byte{publicPEM, der, key.N.Bytes()} {
+			if _, err := a.Verify(signHS256(t, secret, confused, validClaims())); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() = %v, want ErrInvalidToken", err)
+			}
+		}
+		if _, err := a.Verify(signRS256(t, key, "k1", validClaims())); err != nil {
+			t.Fatalf("RS256 token: %v", err)
+		}
+	})
+
+	t.Run("HS256 dengan public key sebagai secret", func(t *testing.T) {
+		a := newTestAuthenticator(t, config.JWTConfig{HS256Secret: testSecret, JWKSFile: jwks})
+		if _, err := a.Verify(signHS256(t, publicPEM, confused, validClaims())); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify() = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("RS256 ditolak jika hanya HS256", func(t *testing.T) {
+		a := newTestAuthenticator(t, config.JWTConfig{HS256Secret: testSecret})
+		if _, err := a.Verify(signRS256(t, key, "k1", validClaims())); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify() = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+func TestVerifyRS256Kid(t *testing.T) {
+	k1, other := generateKey(t), generateKey(t)
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwks, testNow, map[string]*rsa.PrivateKey{"k1": k1})
+	a := newTestAuthenticator(t, config.JWTConfig{JWKSFile: jwks})
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"kid dikenal", signRS256(t, k1, "k1", validClaims()), true},
+		{"tanpa kid", signRS256(t, k1, "", validClaims()), true},
+		{"kid tidak dikenal", signRS256(t, k1, "k9", validClaims()), false},
+		{"key lain dengan kid dikenal", signRS256(t, other, "k1", validClaims()), false},
+		{"key lain tanpa kid", signRS256(t, other, "", validClaims()), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.Verify(tt.token)
+			if tt.ok != (err == nil) {
+				t.Fatalf("Verify() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	oldKey, newKey := generateKey(t), generateKey(t)
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwks, testNow, map[string]*rsa.PrivateKey{"old": oldKey})
+
+	a := newTestAuthenticator(t, config.JWTConfig{JWKSFile: jwks})
+	now := testNow
+	a.now = func() time.Time { return now }
+
+	oldToken := signRS256(t, oldKey, "old", validClaims())
+	newToken := signRS256(t, newKey, "new", validClaims())
+	if _, err := a.Verify(oldToken); err != nil {
+		t.Fatalf("old key before rotation: %v", err)
+	}
+
+	// Rotasi: file diganti, tetapi baru dibaca setelah jwksCheckInterval
+	writeJWKS(t, jwks, testNow.Add(time.Minute), map[string]*rsa.PrivateKey{"new": newKey})
+	if _, err := a.Verify(newToken); err == nil {
+		t.Fatal("new key accepted before check interval")
+	}
+
+	now = now.Add(jwksCheckInterval)
+	if _, err := a.Verify(newToken); err != nil {
+		t.Fatalf("new key after reload: %v", err)
+	}
+	if _, err := a.Verify(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("old key after rotation: Verify() = %v, want ErrInvalidToken", err)
+	}
+
+	// JWKS rusak: key sebelumnya tetap dipakai
+	if err := os.WriteFile(jwks, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(jwks, testNow.Add(2*time.Minute), testNow.Add(2*time.Minute))
+	now = now.Add(jwksCheckInterval)
+	if _, err := a.Verify(newToken); err != nil {
+		t.Fatalf("new key after broken reload: %v", err)
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	a := newTestAuthenticator(t, config.JWTConfig{HS256Secret: testSecret})
+	hs := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+
+	user, err := a.AuthenticateToken(signHS256(t, []byte(testSecret), hs, validClaims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "noc-1" || user.Role != model.RoleOperator || user.AuthMethod != model.AuthJWT ||
+		user.TokenID != "token-1" || len(user.OLTs) != 1 || user.OLTs[0] != "olt-a" {
+		t.Fatalf("user = %+v", user)
+	}
+
+	for name, modify := range map[string]func(c map[string]interface{}){
+		"tanpa sub":          func(c map[string]interface{}) { delete(c, "sub") },
+		"role tidak dikenal": func(c map[string]interface{}) { c["role"] = "root" },
+	} {
+		claims := validClaims()
+		modify(claims)
+		if _, err := a.AuthenticateToken(signHS256(t, []byte(testSecret), hs, claims)); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("%s: err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
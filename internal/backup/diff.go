@@ -0,0 +1,272 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEditDistance membatasi kerja algoritma Myers. Jika dua config berbeda
+// lebih dari ini, bagian tengah yang berbeda dianggap diganti seluruhnya.
+const maxEditDistance = 2000
+
+// DiffLine satu baris hasil diff
+type DiffLine struct {
+	Op   string `json:"op"` // " " sama, "+" ditambah, "-" dihapus
+	Text string `json:"text"`
+}
+
+// Hunk potongan diff beserta konteksnya (format unified diff)
+type Hunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// Diff hasil perbandingan dua versi config
+type Diff struct {
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Hunks   []Hunk `json:"hunks"`
+	Unified string `json:"unified"`
+}
+
+// Compare membuat diff berbasis baris dari config lama ke config baru,
+// dengan context baris konteks di sekitar setiap perubahan.
+func Compare(oldText, newText string, context int) Diff {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	ops := diffLines(a, b)
+
+	d := Diff{Hunks: []Hunk{}}
+	for _, op := range ops {
+		switch op.Op {
+		case "+":
+			d.Added++
+		case "-":
+			d.Removed++
+		}
+	}
+	d.Hunks = buildHunks(ops, context)
+
+	var sb strings.Builder
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		for _, l := range h.Lines {
+			sb.WriteString(l.Op)
+			sb.WriteString(l.Text)
+			sb.WriteString("\n")
+		}
+	}
+	d.Unified = sb.String()
+	return d
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines menghasilkan edit script lengkap dari a ke b.
+func diffLines(a, b []string) []DiffLine {
+	// Buang prefix dan suffix yang sama; pada config biasanya hanya
+	// sebagian kecil di tengah yang berubah.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []DiffLine
+	for _, l := range a[:prefix] {
+		ops = append(ops, DiffLine{Op: " ", Text: l})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, DiffLine{Op: " ", Text: l})
+	}
+	return ops
+}
+
+// myers mengimplementasikan algoritma diff O(ND) Eugene W. Myers.
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	max := n + m
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		if d > maxEditDistance {
+			return replaceAll(a, b)
+		}
+
+		// Simpan V sebelum langkah d untuk backtracking (hanya rentang -d..d)
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrack(trace [][]int, a, b []string) []DiffLine {
+	var ops []DiffLine
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		get := func(k int) int {
+			if k < -d || k > d {
+				return 0
+			}
+			return snapshot[k+d]
+		}
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, DiffLine{Op: " ", Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, DiffLine{Op: "+", Text: b[y-1]})
+			} else {
+				ops = append(ops, DiffLine{Op: "-", Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []DiffLine {
+	ops := make([]DiffLine, 0, len(a)+len(b))
+	for _, l := range a {
+		ops = append(ops, DiffLine{Op: "-", Text: l})
+	}
+	for _, l := range b {
+		ops = append(ops, DiffLine{Op: "+", Text: l})
+	}
+	return ops
+}
+
+// buildHunks mengelompokkan perubahan yang berdekatan menjadi hunk.
+func buildHunks(ops []DiffLine, context int) []Hunk {
+	hunks := []Hunk{}
+
+	i := 0
+	for i < len(ops) {
+		// Cari perubahan berikutnya
+		for i < len(ops) && ops[i].Op == " " {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Perluas hunk selama jarak antar perubahan <= 2*context
+		end := i
+		for end < len(ops) {
+			if ops[end].Op != " " {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Op == " " {
+				run++
+			}
+			if run < len(ops) && run-end <= 2*context {
+				end = run
+				continue
+			}
+			end += context
+			if end > len(ops) {
+				end = len(ops)
+			}
+			break
+		}
+
+		// Hitung nomor baris awal hunk
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:start] {
+			if op.Op != "+" {
+				oldLine++
+			}
+			if op.Op != "-" {
+				newLine++
+			}
+		}
+
+		h := Hunk{OldStart: oldLine, NewStart: newLine, Lines: ops[start:end]}
+		for _, op := range h.Lines {
+			if op.Op != "+" {
+				h.OldLines++
+			}
+			if op.Op != "-" {
+				h.NewLines++
+			}
+		}
+		// Seperti diff -u: rentang kosong menunjuk baris sebelumnya
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		hunks = append(hunks, h)
+		i = end
+	}
+
+	return hunks
+}
@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		old, new    string
+		context     int
+		wantAdded   int
+		wantRemoved int
+		wantUnified string
+	}{
+		{name: "identik", old: "a\nb\n", new: "a\nb\n", context: 3},
+		{name: "keduanya kosong", context: 3},
+		{name: "hanya beda newline akhir dan CRLF", old: "a\r\nb", new: "a\nb\n\n", context: 3},
+		{
+			name: "dari file kosong", new: "a\nb\n", context: 3,
+			wantAdded:   2,
+			wantUnified: "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "menjadi file kosong", old: "a\nb\n", context: 3,
+			wantRemoved: 2,
+			wantUnified: "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "satu baris berubah", old: "a\nb\nc\nd\ne\nf\ng\n", new: "a\nb\nc\nX\ne\nf\ng\n", context: 1,
+			wantAdded: 1, wantRemoved: 1,
+			wantUnified: "@@ -3,3 +3,3 @@\n c\n-d\n+X\n e\n",
+		},
+		{
+			name: "baris ditambah di akhir", old: "a\nb\n", new: "a\nb\nc\n", context: 1,
+			wantAdded:   1,
+			wantUnified: "@@ -2,1 +2,2 @@\n b\n+c\n",
+		},
+		{
+			name: "perubahan berdekatan digabung", old: "1\n2\n3\n4\n5\n6\n", new: "1\nX\n3\n4\nY\n6\n", context: 1,
+			wantAdded: 2, wantRemoved: 2,
+			wantUnified: "@@ -1,6 +1,6 @@\n 1\n-2\n+X\n 3\n 4\n-5\n+Y\n 6\n",
+		},
+		{
+			name: "perubahan berjauhan dipisah", old: "1\n2\n3\n4\n5\n6\n7\n8\n", new: "X\n2\n3\n4\n5\n6\n7\nY\n", context: 1,
+			wantAdded: 2, wantRemoved: 2,
+			wantUnified: "@@ -1,2 +1,2 @@\n-1\n+X\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+Y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(tt.old, tt.new, tt.context)
+			if d.Added != tt.wantAdded || d.Removed != tt.wantRemoved {
+				t.Errorf("added/removed = %d/%d, want %d/%d", d.Added, d.Removed, tt.wantAdded, tt.wantRemoved)
+			}
+			if d.Unified != tt.wantUnified {
+				t.Errorf("unified =\n%s\nwant\n%s", d.Unified, tt.wantUnified)
+			}
+			if d.Hunks == nil {
+				t.Error("hunks must be an empty slice, not nil")
+			}
+			if strings.Count(d.Unified, "@@ -") != len(d.Hunks) {
+				t.Errorf("%d hunks, unified:\n%s", len(d.Hunks), d.Unified)
+			}
+		})
+	}
+}
+
+// TestCompareApplies memastikan diff mengubah config lama menjadi config
+// baru untuk perubahan yang diselingi baris sama.
+func TestCompareApplies(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 0; i < 200; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line %d", i))
+		switch {
+		case i%17 == 0:
+			newLines = append(newLines, fmt.Sprintf("changed %d", i))
+		case i%23 == 0:
+			// dihapus
+		default:
+			newLines = append(newLines, fmt.Sprintf("line %d", i))
+		}
+		if i%31 == 0 {
+			newLines = append(newLines, fmt.Sprintf("inserted %d", i))
+		}
+	}
+
+	ops := diffLines(oldLines, newLines)
+	var gotOld, gotNew []string
+	for _, op := range ops {
+		if op.Op != "+" {
+			gotOld = append(gotOld, op.Text)
+		}
+		if op.Op != "-" {
+			gotNew = append(gotNew, op.Text)
+		}
+	}
+	if strings.Join(gotOld, "\n") != strings.Join(oldLines, "\n") || strings.Join(gotNew, "\n") != strings.Join(newLines, "\n") {
+		t.Fatal("edit script does not reproduce both versions")
+	}
+}
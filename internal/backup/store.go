@@ -0,0 +1,387 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Layout direktori store:
+//
+//	{dir}/objects/{sha256}      isi config (content-addressed, dipakai bersama)
+//	{dir}/olts/{olt_id}.json    daftar versi per OLT
+//
+// Config yang sama persis hanya disimpan sekali walaupun muncul di banyak
+// versi atau banyak OLT.
+
+// ErrVersionNotFound dikembalikan saat versi backup tidak ditemukan
+var ErrVersionNotFound = errors.New("backup version not found")
+
+// Sumber sebuah versi backup
+const (
+	SourceScheduled = "scheduled"
+	SourceManual    = "manual"
+	SourceUpload    = "tftp" // Diunggah OLT ke server TFTP internal
+)
+
+// Version metadata satu versi backup
+type Version struct {
+	ID        int       `json:"id"`
+	OLTID     string    `json:"olt_id"`
+	Hash      string    `json:"sha256"`
+	Size      int       `json:"size"`
+	Source    string    `json:"source"`
+	Filename  string    `json:"filename,omitempty"` // Nama file asli untuk upload TFTP
+	CreatedAt time.Time `json:"created_at"`
+
+	// Unsaved true jika running-config berbeda dengan startup-config saat
+	// versi ini diambil (ada perubahan yang belum di-"write").
+	Unsaved *bool `json:"unsaved_changes,omitempty"`
+}
+
+// Meta keterangan versi yang disimpan lewat Save
+type Meta struct {
+	Source   string
+	Filename string
+	Unsaved  *bool
+}
+
+// Retention kebijakan penghapusan versi lama
+type Retention struct {
+	KeepVersions int           // Versi terbaru yang selalu disimpan (0 = tanpa batas)
+	MaxAge       time.Duration // Versi di luar KeepVersions yang lebih tua dari ini dihapus (0 = tanpa batas)
+}
+
+// Store penyimpanan backup config berbasis file
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore membuat store di direktori dir (dibuat jika belum ada).
+// Config berisi password dan community SNMP, jadi direktori hanya bisa
+// dibaca pemilik proses; direktori lama dari versi sebelumnya ikut
+// diperketat.
+func NewStore(dir string) (*Store, error) {
+	for _, sub := range []string{"objects", "olts"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+	for _, d := range []string{dir, filepath.Join(dir, "objects"), filepath.Join(dir, "olts")} {
+		if err := os.Chmod(d, 0700); err != nil {
+			return nil, fmt.Errorf("failed to secure backup directory: %w", err)
+		}
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir mengembalikan direktori root store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save menyimpan content sebagai versi baru milik oltID.
+// Jika content sama dengan versi terakhir, tidak ada versi baru yang dibuat
+// dan versi terakhir dikembalikan dengan created=false.
+func (s *Store) Save(oltID string, content []byte, meta Meta) (Version, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	if n := len(versions); n > 0 && versions[n-1].Hash == hash {
+		// Perbarui status unsaved karena bisa berubah tanpa isi config berubah
+		if meta.Unsaved != nil {
+			versions[n-1].Unsaved = meta.Unsaved
+			if err := s.write(oltID, versions); err != nil {
+				return Version{}, false, err
+			}
+		}
+		return versions[n-1], false, nil
+	}
+
+	if err := s.writeObject(hash, content); err != nil {
+		return Version{}, false, err
+	}
+
+	v := Version{
+		ID:        1,
+		OLTID:     oltID,
+		Hash:      hash,
+		Size:      len(content),
+		Source:    meta.Source,
+		Filename:  meta.Filename,
+		CreatedAt: time.Now().UTC(),
+		Unsaved:   meta.Unsaved,
+	}
+	if n := len(versions); n > 0 {
+		v.ID = versions[n-1].ID + 1
+	}
+
+	versions = append(versions, v)
+	if err := s.write(oltID, versions); err != nil {
+		return Version{}, false, err
+	}
+	return v, true, nil
+}
+
+// List mengembalikan semua versi milik oltID, terbaru lebih dulu
+func (s *Store) List(oltID string) ([]Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Version, len(versions))
+	for i, v := range versions {
+		result[len(versions)-1-i] = v
+	}
+	return result, nil
+}
+
+// Latest mengembalikan versi terbaru milik oltID
+func (s *Store) Latest(oltID string) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return Version{}, err
+	}
+	if len(versions) == 0 {
+		return Version{}, ErrVersionNotFound
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Get mengembalikan metadata dan isi satu versi
+func (s *Store) Get(oltID string, id int) (Version, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return Version{}, nil, err
+	}
+	for _, v := range versions {
+		if v.ID == id {
+			content, err := os.ReadFile(s.objectPath(v.Hash))
+			if err != nil {
+				return Version{}, nil, fmt.Errorf("failed to read backup object: %w", err)
+			}
+			return v, content, nil
+		}
+	}
+	return Version{}, nil, ErrVersionNotFound
+}
+
+// FindByHash mengembalikan versi terbaru milik oltID dengan checksum hash
+func (s *Store) FindByHash(oltID, hash string) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return Version{}, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Hash == hash {
+			return versions[i], nil
+		}
+	}
+	return Version{}, ErrVersionNotFound
+}
+
+// Object mengembalikan isi config berdasarkan checksum sha256
+func (s *Store) Object(hash string) ([]byte, error) {
+	if !IsHash(hash) {
+		return nil, ErrVersionNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, err := os.ReadFile(s.objectPath(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup object: %w", err)
+	}
+	return content, nil
+}
+
+// Prune menerapkan kebijakan retensi pada oltID dan menghapus object yang
+// tidak lagi direferensikan. Versi terbaru tidak pernah dihapus.
+// Mengembalikan jumlah versi yang dihapus.
+func (s *Store) Prune(oltID string, policy Retention) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(oltID)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) <= 1 {
+		return 0, nil
+	}
+
+	cutoff := time.Time{}
+	if policy.MaxAge > 0 {
+		cutoff = time.Now().UTC().Add(-policy.MaxAge)
+	}
+
+	// Versi dihapus jika berada di luar KeepVersions terbaru dan (bila
+	// MaxAge diisi) sudah kedaluwarsa. Tanpa KeepVersions, hanya MaxAge
+	// yang berlaku.
+	var kept []Version
+	for i, v := range versions {
+		fromNewest := len(versions) - 1 - i
+		excess := policy.KeepVersions > 0 && fromNewest >= policy.KeepVersions
+		expired := !cutoff.IsZero() && v.CreatedAt.Before(cutoff)
+
+		remove := false
+		switch {
+		case fromNewest == 0:
+			remove = false
+		case policy.KeepVersions > 0:
+			remove = excess && (cutoff.IsZero() || expired)
+		default:
+			remove = expired
+		}
+		if !remove {
+			kept = append(kept, v)
+		}
+	}
+
+	removed := len(versions) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := s.write(oltID, kept); err != nil {
+		return 0, err
+	}
+	return removed, s.collectGarbage()
+}
+
+// collectGarbage menghapus object yang tidak direferensikan versi mana pun
+func (s *Store) collectGarbage() error {
+	used := make(map[string]bool)
+	for _, oltID := range s.oltIDs() {
+		versions, err := s.load(oltID)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			used[v.Hash] = true
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, "objects"))
+	if err != nil {
+		return fmt.Errorf("failed to read backup objects: %w", err)
+	}
+	for _, e := range entries {
+		if !used[e.Name()] {
+			os.Remove(filepath.Join(s.dir, "objects", e.Name()))
+		}
+	}
+	return nil
+}
+
+func (s *Store) oltIDs() []string {
+	entries, _ := os.ReadDir(filepath.Join(s.dir, "olts"))
+	var ids []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// IsHash true jika s adalah checksum sha256 dalam hex (huruf kecil)
+func IsHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) indexPath(oltID string) string {
+	return filepath.Join(s.dir, "olts", filepath.Base(oltID)+".json")
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash)
+}
+
+func (s *Store) load(oltID string) ([]Version, error) {
+	data, err := os.ReadFile(s.indexPath(oltID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup index: %w", err)
+	}
+
+	var versions []Version
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse backup index: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *Store) write(oltID string, versions []Version) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup index: %w", err)
+	}
+	return writeFileAtomic(s.indexPath(oltID), data)
+}
+
+func (s *Store) writeObject(hash string, content []byte) error {
+	path := s.objectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil // Sudah ada, content-addressed
+	}
+	return writeFileAtomic(path, content)
+}
+
+// writeFileAtomic menulis ke file sementara lalu rename, agar file tidak
+// pernah setengah tertulis jika proses berhenti di tengah jalan.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStorePermissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	// Direktori lama dengan permission longgar ikut diperketat
+	if err := os.MkdirAll(filepath.Join(dir, "objects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _, err := store.Save("olt1", []byte("snmp-server community secret ro"), Meta{Source: SourceManual})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]os.FileMode{
+		dir:                           0700,
+		filepath.Join(dir, "objects"): 0700,
+		filepath.Join(dir, "olts"):    0700,
+		store.objectPath(v.Hash):      0600,
+		store.indexPath("olt1"):       0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %o, want %o", path, got, want)
+		}
+	}
+}
+
+// saveVersions menyimpan n versi berbeda untuk oltID dengan umur age*(n-i)
+// (versi terakhir paling baru)
+func saveVersions(t *testing.T, store *Store, oltID string, n int, age time.Duration) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		content := fmt.Sprintf("hostname %s\n! versi %d\n", oltID, i)
+		if _, _, err := store.Save(oltID, []byte(content), Meta{Source: SourceScheduled}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions, err := store.load(oltID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i := range versions {
+		versions[i].CreatedAt = now.Add(-age * time.Duration(len(versions)-i))
+	}
+	if err := store.write(oltID, versions); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func versionIDs(t *testing.T, store *Store, oltID string) []int {
+	t.Helper()
+	versions, err := store.List(oltID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, v := range versions {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func TestPrune(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name        string
+		versions    int
+		policy      Retention
+		wantRemoved int
+		wantIDs     []int
+	}{
+		{"simpan N terbaru", 5, Retention{KeepVersions: 3}, 2, []int{5, 4, 3}},
+		{"jumlah di bawah batas", 2, Retention{KeepVersions: 3}, 0, []int{2, 1}},
+		{"di luar N tetapi belum kedaluwarsa", 5, Retention{KeepVersions: 2, MaxAge: 4*day + time.Hour}, 1, []int{5, 4, 3, 2}},
+		{"hanya max age", 5, Retention{MaxAge: 2*day + time.Hour}, 3, []int{5, 4}},
+		{"versi terbaru kedaluwarsa tetap disimpan", 3, Retention{MaxAge: time.Hour}, 2, []int{3}},
+		{"satu-satunya versi tidak dihapus", 1, Retention{KeepVersions: 1, MaxAge: time.Hour}, 0, []int{1}},
+		{"tanpa kebijakan", 4, Retention{}, 0, []int{4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			saveVersions(t, store, "olt1", tt.versions, day)
+			saveVersions(t, store, "olt2", 5, day)
+
+			removed, err := store.Prune("olt1", tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed = %d, want %d", removed, tt.wantRemoved)
+			}
+			if got := versionIDs(t, store, "olt1"); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("olt1 versions = %v, want %v", got, tt.wantIDs)
+			}
+			// Retensi berlaku per OLT
+			if got := versionIDs(t, store, "olt2"); len(got) != 5 {
+				t.Errorf("olt2 versions = %v, want 5 versions", got)
+			}
+		})
+	}
+}
+
+// TestPruneCollectsObjects memastikan object versi yang dihapus ikut
+// dihapus, kecuali masih dipakai versi lain (OLT lain dengan config sama).
+func TestPruneCollectsObjects(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _, err := store.Save("olt1", []byte("config lama\n"), Meta{Source: SourceManual})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, _, err := store.Save("olt1", []byte("config bersama\n"), Meta{Source: SourceManual})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Save("olt1", []byte("config baru\n"), Meta{Source: SourceManual}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Save("olt2", []byte("config bersama\n"), Meta{Source: SourceManual}); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, err := store.Prune("olt1", Retention{KeepVersions: 1}); err != nil || removed != 2 {
+		t.Fatalf("Prune() = %d, %v", removed, err)
+	}
+	if _, err := store.Object(old.Hash); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("unreferenced object still present: %v", err)
+	}
+	if _, err := store.Object(shared.Hash); err != nil {
+		t.Errorf("object shared with olt2 removed: %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+// Package breaker menyediakan circuit breaker per OLT. Setelah beberapa
+// timeout SNMP berturut-turut circuit terbuka dan request ke OLT tersebut
+// langsung ditolak (503 "OLT unreachable") tanpa menunggu timeout gosnmp.
+// Setelah OpenTimeout satu request menjalankan probe ringan (GET sysUpTime);
+// jika berhasil circuit ditutup kembali.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+)
+
+// State kondisi circuit
+type State string
+
+const (
+	Closed   State = "closed"    // Normal, request diteruskan ke OLT
+	Open     State = "open"      // OLT dianggap tidak terjangkau, request ditolak
+	HalfOpen State = "half_open" // Probe sedang berjalan
+)
+
+// Error penolakan karena circuit OLT terbuka
+type Error struct {
+	Host       string
+	RetryAfter time.Duration // Sisa waktu sampai probe berikutnya
+	LastError  string        // Error terakhir yang membuat circuit terbuka
+}
+
+func (e *Error) Error() string {
+	msg := "OLT unreachable: " + e.Host + " is not responding to SNMP"
+	if e.LastError != "" {
+		msg += " (" + e.LastError + ")"
+	}
+	return msg
+}
+
+// Settings pengaturan circuit breaker
+type Settings struct {
+	FailureThreshold int           // Timeout berturut-turut sebelum circuit terbuka
+	OpenTimeout      time.Duration // Lama circuit terbuka sebelum probe
+	ProbeTimeout     time.Duration // Batas waktu probe
+}
+
+// FromConfig mengubah config.BreakerConfig menjadi Settings
+func FromConfig(c config.BreakerConfig) Settings {
+	return Settings{
+		FailureThreshold: c.FailureThreshold,
+		OpenTimeout:      time.Duration(c.OpenSeconds) * time.Second,
+		ProbeTimeout:     time.Duration(c.ProbeTimeoutSeconds) * time.Second,
+	}
+}
+
+// Status kondisi circuit satu OLT
+type Status struct {
+	Host                string    `json:"host"`
+	State               State     `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitzero"`
+	LastSuccess         time.Time `json:"last_success,omitzero"`
+	OpenedAt            time.Time `json:"opened_at,omitzero"`
+	NextProbe           time.Time `json:"next_probe,omitzero"`
+	Trips               int64     `json:"trips_total"` // Berapa kali circuit terbuka sejak start
+}
+
+// Set menyimpan circuit untuk setiap host
+type Set struct {
+	settings Settings
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+	now      func() time.Time
+}
+
+// New membuat kumpulan circuit breaker
+func New(settings Settings) *Set {
+	return &Set{
+		settings: settings,
+		breakers: make(map[string]*Breaker),
+		now:      time.Now,
+	}
+}
+
+// NewFromConfig membuat kumpulan circuit breaker dari cfg
+func NewFromConfig(cfg *config.Config) *Set {
+	return New(FromConfig(cfg.Breaker))
+}
+
+// For mengembalikan circuit untuk host (dibuat jika belum ada)
+func (s *Set) For(host string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[host]
+	if !ok {
+		b = &Breaker{host: host, set: s, state: Closed}
+		s.breakers[host] = b
+	}
+	return b
+}
+
+// Status mengembalikan kondisi circuit host. Host yang belum pernah diakses
+// dilaporkan closed.
+func (s *Set) Status(host string) Status {
+	s.mu.Lock()
+	b, ok := s.breakers[host]
+	s.mu.Unlock()
+	if !ok {
+		return Status{Host: host, State: Closed}
+	}
+	return b.Status()
+}
+
+// Stats mengembalikan kondisi semua circuit yang pernah diakses
+func (s *Set) Stats() []Status {
+	s.mu.Lock()
+	breakers := make([]*Breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		breakers = append(breakers, b)
+	}
+	s.mu.Unlock()
+
+	stats := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		stats = append(stats, b.Status())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// Breaker circuit satu host. Method pada *Breaker nil tidak melakukan apa-apa
+// sehingga driver bisa dipakai tanpa circuit breaker.
+type Breaker struct {
+	host string
+	set  *Set
+
+	mu          sync.Mutex
+	state       State
+	failures    int
+	lastErr     string
+	lastFailure time.Time
+	lastSuccess time.Time
+	openedAt    time.Time
+	trips       int64
+}
+
+// Allow dipanggil sebelum memulai operasi ke OLT. Jika circuit terbuka dan
+// OpenTimeout sudah lewat, probe dijalankan (hanya oleh satu request);
+// request lain tetap ditolak sampai probe selesai.
+func (b *Breaker) Allow(ctx context.Context, probe func(ctx context.Context) error) error {
+	if b == nil {
+		return nil
+	}
+	settings := b.set.settings
+
+	b.mu.Lock()
+	switch {
+	case b.state == Closed:
+		b.mu.Unlock()
+		return nil
+	case b.state == HalfOpen || b.set.now().Before(b.openedAt.Add(settings.OpenTimeout)):
+		err := b.errLocked()
+		b.mu.Unlock()
+		return err
+	}
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	probeCtx, cancel := context.WithTimeout(ctx, settings.ProbeTimeout)
+	err := probe(probeCtx)
+	cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		if ctx.Err() != nil {
+			// Request dibatalkan, bukan bukti OLT mati: biarkan request lain mencoba
+			b.state = Open
+			return ctx.Err()
+		}
+		b.lastErr = err.Error()
+		b.lastFailure = b.set.now()
+		b.openLocked()
+		return b.errLocked()
+	}
+	b.closeLocked()
+	return nil
+}
+
+// Check mengembalikan *Error jika circuit tidak closed. Dipanggil driver di
+// antara PDU agar operasi panjang (walk, loop GET) berhenti begitu circuit
+// terbuka.
+func (b *Breaker) Check() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Closed {
+		return nil
+	}
+	return b.errLocked()
+}
+
+// Record mencatat hasil satu request SNMP. Hanya timeout yang dihitung
+// sebagai kegagalan; respons apa pun (termasuk error dari agent SNMP)
+// membuktikan OLT terjangkau.
+func (b *Breaker) Record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		if b.state == Closed {
+			b.failures = 0
+		}
+		b.lastSuccess = b.set.now()
+		return
+	}
+	if !IsTimeout(err) {
+		return
+	}
+	b.lastErr = err.Error()
+	b.lastFailure = b.set.now()
+	if b.state != Closed {
+		return
+	}
+	b.failures++
+	if b.failures >= max(b.set.settings.FailureThreshold, 1) {
+		b.openLocked()
+	}
+}
+
+// Status mengembalikan kondisi circuit
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := Status{
+		Host:                b.host,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastErr,
+		LastFailure:         b.lastFailure,
+		LastSuccess:         b.lastSuccess,
+		Trips:               b.trips,
+	}
+	if b.state != Closed {
+		st.OpenedAt = b.openedAt
+		st.NextProbe = b.openedAt.Add(b.set.settings.OpenTimeout)
+	}
+	return st
+}
+
+func (b *Breaker) openLocked() {
+	if b.state == Closed {
+		b.trips++
+	}
+	b.state = Open
+	b.openedAt = b.set.now()
+}
+
+func (b *Breaker) closeLocked() {
+	b.state = Closed
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.lastSuccess = b.set.now()
+}
+
+func (b *Breaker) errLocked() *Error {
+	retry := b.openedAt.Add(b.set.settings.OpenTimeout).Sub(b.set.now())
+	return &Error{
+		Host:       b.host,
+		RetryAfter: max(retry, time.Second),
+		LastError:  b.lastErr,
+	}
+}
+
+// IsTimeout true jika err adalah timeout menunggu respons OLT. Error ctx
+// (request dibatalkan atau lewat deadline) bukan kesalahan OLT.
+func IsTimeout(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	// gosnmp: "request timeout (after N retries)"
+	return strings.Contains(err.Error(), "request timeout")
+}
+
+// Done dipanggil setelah operasi ke OLT selesai. Jika circuit terbuka
+// selama operasi berjalan, sebagian request dilewati dan hasilnya tidak
+// lengkap, sehingga error circuit yang dikembalikan.
+func (b *Breaker) Done(err error) error {
+	if err != nil {
+		return err
+	}
+	return b.Check()
+}
@@ -0,0 +1,181 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+var errTimeout = errors.New("request timeout (after 1 retries)")
+
+// testSet membuat Set dengan jam yang bisa dimajukan
+func testSet(threshold int) (*Set, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(Settings{FailureThreshold: threshold, OpenTimeout: 30 * time.Second, ProbeTimeout: time.Second})
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func okProbe(context.Context) error { return nil }
+
+func TestThresholdOpensCircuit(t *testing.T) {
+	s, _ := testSet(3)
+	b := s.For("10.0.0.1")
+
+	b.Record(errTimeout)
+	b.Record(errTimeout)
+	b.Record(nil) // Respons di antara timeout mereset hitungan
+	b.Record(errTimeout)
+	b.Record(errTimeout)
+	b.Record(errors.New("noSuchName")) // Error agent bukan timeout
+	if st := b.Status(); st.State != Closed || st.ConsecutiveFailures != 2 {
+		t.Fatalf("status = %s failures %d, want closed 2", st.State, st.ConsecutiveFailures)
+	}
+
+	b.Record(errTimeout)
+	st := b.Status()
+	if st.State != Open || st.Trips != 1 {
+		t.Fatalf("status = %s trips %d, want open 1", st.State, st.Trips)
+	}
+
+	var be *Error
+	if err := b.Allow(context.Background(), okProbe); !errors.As(err, &be) {
+		t.Fatalf("Allow() = %v, want *Error", err)
+	}
+	if be.RetryAfter != 30*time.Second || be.LastError != errTimeout.Error() {
+		t.Fatalf("error = %+v", be)
+	}
+	if err := b.Check(); err == nil {
+		t.Fatal("Check() = nil on open circuit")
+	}
+	if err := b.Done(nil); err == nil {
+		t.Fatal("Done(nil) = nil on open circuit")
+	}
+}
+
+func TestHalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		probeErr  error
+		wantErr   bool
+		wantState State
+	}{
+		{"probe berhasil menutup circuit", nil, false, Closed},
+		{"probe gagal membuka lagi", errTimeout, true, Open},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, now := testSet(1)
+			b := s.For("10.0.0.1")
+			b.Record(errTimeout)
+
+			*now = now.Add(30 * time.Second)
+			probed := 0
+			err := b.Allow(context.Background(), func(ctx context.Context) error {
+				probed++
+				// Selama probe request lain ditolak
+				if st := b.Status(); st.State != HalfOpen {
+					t.Errorf("state during probe = %s, want half_open", st.State)
+				}
+				if err := b.Allow(ctx, okProbe); err == nil {
+					t.Error("concurrent Allow() during probe = nil")
+				}
+				if _, ok := ctx.Deadline(); !ok {
+					t.Error("probe ctx without deadline")
+				}
+				return tt.probeErr
+			})
+			if probed != 1 {
+				t.Fatalf("probe called %d times", probed)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Allow() = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			st := b.Status()
+			if st.State != tt.wantState || st.Trips != 1 {
+				t.Fatalf("status = %s trips %d, want %s 1", st.State, st.Trips, tt.wantState)
+			}
+			if tt.wantState == Open && !st.OpenedAt.Equal(*now) {
+				t.Fatalf("opened_at = %v, want reset to %v", st.OpenedAt, *now)
+			}
+			if tt.wantState == Closed && st.ConsecutiveFailures != 0 {
+				t.Fatalf("failures = %d after close", st.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+func TestProbeCancelled(t *testing.T) {
+	s, now := testSet(1)
+	b := s.For("10.0.0.1")
+	b.Record(errTimeout)
+	*now = now.Add(30 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := b.Allow(ctx, func(probeCtx context.Context) error {
+		cancel()
+		<-probeCtx.Done()
+		return probeCtx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Allow() = %v, want context.Canceled", err)
+	}
+
+	// Pembatalan bukan bukti OLT mati: openedAt tidak diperbarui sehingga
+	// request berikutnya langsung boleh menjalankan probe
+	st := b.Status()
+	if st.State != Open || st.LastError != errTimeout.Error() {
+		t.Fatalf("status = %+v", st)
+	}
+	if err := b.Allow(context.Background(), okProbe); err != nil {
+		t.Fatalf("next Allow() = %v, want probe to run", err)
+	}
+	if st := b.Status(); st.State != Closed {
+		t.Fatalf("state = %s, want closed", st.State)
+	}
+}
+
+type netTimeout struct{}
+
+func (netTimeout) Error() string   { return "i/o timeout" }
+func (netTimeout) Timeout() bool   { return true }
+func (netTimeout) Temporary() bool { return true }
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"gosnmp request timeout", errTimeout, true},
+		{"gosnmp dibungkus", fmt.Errorf("walk: %w", errTimeout), true},
+		{"net.Error timeout", netTimeout{}, true},
+		{"deadline socket", fmt.Errorf("read: %w", os.ErrDeadlineExceeded), true},
+		{"ctx dibatalkan", context.Canceled, false},
+		{"ctx deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+		{"error agent", errors.New("noSuchName"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTimeout(tt.err); got != tt.want {
+				t.Fatalf("IsTimeout(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilBreaker(t *testing.T) {
+	var b *Breaker
+	b.Record(errTimeout)
+	if err := b.Allow(context.Background(), okProbe); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Check(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ardani/snmp-zte/internal/audit"
 )
 
 // Client untuk koneksi Telnet ke OLT ZTE
@@ -48,7 +51,7 @@ func New(cfg Config) *Client {
 
 // Connect melakukan koneksi Telnet ke OLT
 func (c *Client) Connect() error {
-	addr := fmt.Sprintf("%s:%d", c.host, c.port)
+	addr := net.JoinHostPort(c.host, strconv.Itoa(c.port))
 	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
 	if err != nil {
 		return fmt.Errorf("telnet connection failed: %w", err)
@@ -98,6 +101,19 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// LocalIP mengembalikan alamat IP lokal koneksi Telnet, yaitu alamat
+// server ini seperti yang terlihat dari OLT (kosong jika belum terhubung)
+func (c *Client) LocalIP() string {
+	if c.conn == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(c.conn.LocalAddr().String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
 // send mengirim data ke koneksi
 func (c *Client) send(data string) error {
 	_, err := c.conn.Write([]byte(data + "\r\n"))
@@ -144,10 +160,20 @@ func (c *Client) readUntil(patterns ...string) (string, error) {
 }
 
 // Execute menjalankan command dan mengembalikan output
+// Perintah yang terkirim dicatat ke audit recorder di ctx (jika ada).
 func (c *Client) Execute(ctx context.Context, cmd string) (string, error) {
+	return c.ExecuteSecret(ctx, cmd)
+}
+
+// ExecuteSecret sama seperti Execute, tetapi kata command yang sama dengan
+// salah satu secrets (password, key WiFi) disembunyikan di audit log.
+func (c *Client) ExecuteSecret(ctx context.Context, cmd string, secrets ...string) (output string, err error) {
 	if c.conn == nil {
 		return "", fmt.Errorf("not connected")
 	}
+	defer func() {
+		audit.RecordCommand(ctx, audit.ProtocolCLI, c.host, audit.RedactValues(cmd, secrets...), err)
+	}()
 
 	// Clear buffer first
 	buf := make([]byte, 8192)
@@ -163,7 +189,7 @@ func (c *Client) Execute(ctx context.Context, cmd string) (string, error) {
 	time.Sleep(500 * time.Millisecond)
 	
 	// Use longer timeout for commands that might take time
-	output, err := c.readWithPagination(cmd)
+	output, err = c.readWithPagination(cmd)
 	if err != nil {
 		return "", err
 	}
@@ -208,6 +234,13 @@ func (c *Client) readWithPagination(cmd string) (string, error) {
 		if n > 0 {
 			result.Write(buf[:n])
 		}
+		// Koneksi ditutup OLT: tidak ada lagi yang bisa ditunggu
+		if err == io.EOF {
+			if strings.Contains(result.String(), "#") {
+				return result.String(), nil
+			}
+			return result.String(), fmt.Errorf("connection closed by %s", c.host)
+		}
 	}
 
 	return result.String(), fmt.Errorf("timeout after 30s")
@@ -273,7 +306,10 @@ func (c *Client) IsConnected() bool {
 
 // TestConnection mengetes koneksi ke OLT
 func TestConnection(cfg Config) error {
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	if cfg.Port == 0 {
+		cfg.Port = 23
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
 	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
 	if err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
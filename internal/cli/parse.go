@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// PARSING FRAMEWORK
+// ============================================================
+//
+// Output CLI ZTE pada dasarnya hanya punya dua bentuk:
+//   1. Tabel dengan kolom rata (column-aligned), contoh: show card,
+//      show gpon onu state, show igmp dynamic-member.
+//   2. Blok "Key : Value", contoh: show card slotno, show onu detail-info.
+// Semua parser di zte_c320.go dibangun di atas dua helper di bawah ini.
+
+// tableRow adalah satu baris tabel dengan kunci berupa judul kolom.
+type tableRow map[string]string
+
+// parseTable mem-parsing output tabel berkolom rata.
+// columns adalah judul kolom persis seperti di baris header (boleh mengandung
+// spasi, misal "Admin State"). Posisi judul di header dipakai sebagai batas
+// kolom, sehingga nilai yang mengandung spasi tetap terbaca utuh.
+// Baris pemisah (----, ====), baris kosong dan header yang berulang (karena
+// paginasi --More--) dilewati. Mengembalikan nil jika header tidak ditemukan.
+func parseTable(output string, columns ...string) []tableRow {
+	lines := strings.Split(strings.ReplaceAll(output, "\t", "    "), "\n")
+
+	headerIdx := -1
+	var starts []int
+	for i, line := range lines {
+		if s := columnStarts(line, columns); s != nil {
+			headerIdx = i
+			starts = s
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return nil
+	}
+
+	var rows []tableRow
+	for _, line := range lines[headerIdx+1:] {
+		line = strings.TrimRight(line, " \r")
+		if strings.TrimSpace(line) == "" || isSeparatorLine(line) {
+			continue
+		}
+		if columnStarts(line, columns) != nil {
+			continue // Header berulang
+		}
+		rows = append(rows, splitRow(line, columns, starts))
+	}
+	return rows
+}
+
+// columnStarts mengembalikan posisi awal setiap judul kolom di baris header,
+// atau nil jika baris tersebut bukan header.
+func columnStarts(line string, columns []string) []int {
+	starts := make([]int, len(columns))
+	offset := 0
+	for i, col := range columns {
+		idx := indexWord(line, col, offset)
+		if idx < 0 {
+			return nil
+		}
+		starts[i] = idx
+		offset = idx + len(col)
+	}
+	return starts
+}
+
+// indexWord mencari word mulai dari posisi offset, hanya jika word berdiri
+// sendiri (diapit spasi atau awal/akhir baris). "Vlan" tidak cocok dengan
+// "UserVlan".
+func indexWord(line, word string, offset int) int {
+	for offset <= len(line) {
+		idx := strings.Index(line[offset:], word)
+		if idx < 0 {
+			return -1
+		}
+		pos := offset + idx
+		end := pos + len(word)
+		if (pos == 0 || line[pos-1] == ' ') && (end == len(line) || line[end] == ' ') {
+			return pos
+		}
+		offset = pos + 1
+	}
+	return -1
+}
+
+// splitRow memecah satu baris data sesuai posisi kolom.
+func splitRow(line string, columns []string, starts []int) tableRow {
+	row := make(tableRow, len(columns))
+
+	// Jika jumlah field sama dengan jumlah kolom, pecah per spasi saja.
+	// Cara ini toleran terhadap nilai yang sedikit bergeser dari header.
+	fields := strings.Fields(line)
+	if len(fields) == len(columns) {
+		for i, col := range columns {
+			row[col] = fields[i]
+		}
+		return row
+	}
+
+	bounds := make([]int, len(starts)+1)
+	for i, s := range starts {
+		bounds[i] = alignBoundary(line, s)
+	}
+	bounds[len(starts)] = len(line)
+	bounds[0] = 0
+
+	for i, col := range columns {
+		from, to := bounds[i], bounds[i+1]
+		if from > len(line) {
+			from = len(line)
+		}
+		if to > len(line) {
+			to = len(line)
+		}
+		if to < from {
+			to = from
+		}
+		row[col] = strings.TrimSpace(line[from:to])
+	}
+	return row
+}
+
+// alignBoundary menggeser batas kolom ke kiri jika batas tersebut memotong
+// sebuah kata (nilai lebih panjang ke kiri dari judul kolomnya).
+func alignBoundary(line string, pos int) int {
+	if pos >= len(line) {
+		return len(line)
+	}
+	for pos > 0 && line[pos-1] != ' ' && line[pos] != ' ' {
+		pos--
+	}
+	return pos
+}
+
+// isSeparatorLine mendeteksi garis pemisah seperti "-----" atau "=====".
+func isSeparatorLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	for _, c := range trimmed {
+		if c != '-' && c != '=' && c != ' ' && c != '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// kvBlock adalah hasil parsing blok "Key : Value" dengan kunci ternormalisasi.
+type kvBlock map[string]string
+
+// get mengembalikan nilai kunci pertama yang ditemukan dari daftar kunci.
+// Kunci dicocokkan tanpa membedakan huruf besar/kecil, spasi, '-' dan '_',
+// sehingga "Config-Type", "Config Type" dan "config_type" dianggap sama.
+func (b kvBlock) get(keys ...string) string {
+	for _, k := range keys {
+		if v, ok := b[normalizeKey(k)]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// getInt sama seperti get tetapi mengonversi angka di awal nilai ke int.
+// Contoh: "12%" -> 12, "1520m" -> 1520.
+func (b kvBlock) getInt(keys ...string) int {
+	return leadingInt(b.get(keys...))
+}
+
+// parseKeyValue mem-parsing seluruh output sebagai satu blok "Key : Value".
+// Jika sebuah kunci muncul lebih dari sekali, nilai pertama yang dipakai.
+func parseKeyValue(output string) kvBlock {
+	block := make(kvBlock)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		if _, exists := block[key]; !exists {
+			block[key] = value
+		}
+	}
+	return block
+}
+
+// parseKeyValueBlocks memecah output menjadi beberapa blok "Key : Value".
+// Blok baru dimulai setiap kali startKey muncul; baris sebelum startKey
+// pertama diabaikan.
+func parseKeyValueBlocks(output, startKey string) []kvBlock {
+	var blocks []kvBlock
+	var current kvBlock
+	start := normalizeKey(startKey)
+
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		if key == start {
+			if current != nil {
+				blocks = append(blocks, current)
+			}
+			current = make(kvBlock)
+		}
+		if current == nil {
+			continue
+		}
+		if _, exists := current[key]; !exists {
+			current[key] = value
+		}
+	}
+	if current != nil {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// splitKeyValue memecah satu baris "Key : Value" pada titik dua pertama.
+func splitKeyValue(line string) (string, string, bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key := normalizeKey(parts[0])
+	if key == "" {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(parts[1]), true
+}
+
+// normalizeKey menyeragamkan penulisan kunci.
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	key = strings.NewReplacer("-", " ", "_", " ").Replace(key)
+	return strings.Join(strings.Fields(key), " ")
+}
+
+// leadingInt mengambil angka bulat di awal string ("12%" -> 12).
+func leadingInt(s string) int {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || (end == 0 && s[end] == '-')) {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// splitList memecah daftar yang dipisah koma menjadi slice tanpa elemen kosong.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
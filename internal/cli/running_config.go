@@ -0,0 +1,527 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ardani/snmp-zte/internal/audit"
+)
+
+// ============================================================
+// RUNNING-CONFIG PARSER
+// ============================================================
+//
+// Running-config C320 terdiri dari blok-blok yang diawali header
+// (interface ..., pon-onu-mng ..., vlan N, gpon, onu-profile ...) dan
+// diakhiri "!" atau "$". Indentasi tidak bisa diandalkan karena
+// cleanOutput sudah membuang spasi di awal baris, jadi parser hanya
+// mengenali header dan terminator.
+
+// RunningConfig hasil parsing show running-config
+type RunningConfig struct {
+	Hostname      string                `json:"hostname,omitempty"`
+	VLANs         []ConfigVLAN          `json:"vlans,omitempty"`
+	TCONTProfiles []ConfigTCONTProfile  `json:"tcont_profiles,omitempty"`
+	VLANProfiles  []ConfigVLANProfile   `json:"vlan_profiles,omitempty"`
+	ONUTypes      []string              `json:"onu_types,omitempty"`
+	Profiles      []ConfigProfile       `json:"profiles,omitempty"`
+	OLTInterfaces []ConfigOLTInterface  `json:"olt_interfaces,omitempty"`
+	ONUInterfaces []ConfigONUInterface  `json:"onu_interfaces,omitempty"`
+	ONUManagement []ConfigONUManagement `json:"onu_management,omitempty"`
+	Interfaces    []ConfigSection       `json:"interfaces,omitempty"`
+	Global        []string              `json:"global,omitempty"`
+}
+
+// ConfigVLAN blok "vlan {id}"
+type ConfigVLAN struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ConfigTCONTProfile baris "profile tcont {name} type {type} ..."
+type ConfigTCONTProfile struct {
+	Name    string `json:"name"`
+	Type    int    `json:"type"`
+	Fixed   int    `json:"fixed_kbps,omitempty"`
+	Assured int    `json:"assured_kbps,omitempty"`
+	Maximum int    `json:"maximum_kbps,omitempty"`
+}
+
+// ConfigVLANProfile baris "onu profile vlan {name} tag-mode {mode} cvlan {id}"
+type ConfigVLANProfile struct {
+	Name     string `json:"name"`
+	TagMode  string `json:"tag_mode,omitempty"`
+	CVLAN    int    `json:"cvlan,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// ConfigProfile blok "onu-profile gpon line|remote {name}"
+type ConfigProfile struct {
+	Kind  string   `json:"kind"`
+	Name  string   `json:"name"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+// ConfigSection blok generik yang tidak dimodelkan khusus
+type ConfigSection struct {
+	Name  string   `json:"name"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+// ConfigOLTInterface blok "interface gpon-olt_{rack}/{shelf}/{slot}"
+type ConfigOLTInterface struct {
+	Name        string      `json:"name"`
+	Rack        int         `json:"rack"`
+	Shelf       int         `json:"shelf"`
+	Slot        int         `json:"slot"`
+	Description string      `json:"description,omitempty"`
+	Shutdown    bool        `json:"shutdown"`
+	ONUs        []ConfigONU `json:"onus,omitempty"`
+	Other       []string    `json:"other,omitempty"`
+}
+
+// ConfigONU baris "onu {id} type {type} sn {sn}" di interface gpon-olt.
+// Password (pw/LOID) hanya untuk perbandingan drift dan tidak ikut di JSON.
+type ConfigONU struct {
+	ID       int    `json:"onu_id"`
+	Type     string `json:"type"`
+	AuthMode string `json:"auth_mode"`
+	SN       string `json:"sn,omitempty"`
+	Password string `json:"-"`
+}
+
+// ConfigONUInterface blok "interface gpon-onu_{rack}/{shelf}/{slot}:{onu_id}"
+type ConfigONUInterface struct {
+	Name         string              `json:"name"`
+	Rack         int                 `json:"rack"`
+	Shelf        int                 `json:"shelf"`
+	Slot         int                 `json:"slot"`
+	ONUID        int                 `json:"onu_id"`
+	ONUName      string              `json:"onu_name,omitempty"`
+	Description  string              `json:"description,omitempty"`
+	TCONTs       []ConfigTCONT       `json:"tconts,omitempty"`
+	GEMPorts     []ConfigGEMPort     `json:"gemports,omitempty"`
+	ServicePorts []ConfigServicePort `json:"service_ports,omitempty"`
+	Other        []string            `json:"other,omitempty"`
+}
+
+// ConfigTCONT baris "tcont {id} name {name} profile {profile}"
+type ConfigTCONT struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Profile string `json:"profile"`
+}
+
+// ConfigGEMPort baris "gemport {id} name {name} tcont {tcont_id}"
+type ConfigGEMPort struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name,omitempty"`
+	TCONT int    `json:"tcont"`
+}
+
+// ConfigServicePort baris "service-port {id} vport {vport} user-vlan {vlan} vlan {vlan}"
+type ConfigServicePort struct {
+	ID       int `json:"id"`
+	Vport    int `json:"vport"`
+	UserVLAN int `json:"user_vlan"`
+	VLAN     int `json:"vlan"`
+}
+
+// ConfigONUManagement blok "pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}"
+type ConfigONUManagement struct {
+	Name      string              `json:"name"`
+	Rack      int                 `json:"rack"`
+	Shelf     int                 `json:"shelf"`
+	Slot      int                 `json:"slot"`
+	ONUID     int                 `json:"onu_id"`
+	Services  []ConfigONUService  `json:"services,omitempty"`
+	VLANPorts []ConfigONUVLANPort `json:"vlan_ports,omitempty"`
+	WANs      []ConfigONUWAN      `json:"wans,omitempty"`
+	SSIDs     []ConfigONUSSID     `json:"ssids,omitempty"`
+	Other     []string            `json:"other,omitempty"`
+}
+
+// ConfigONUService baris "service {name} gemport {id} vlan {vlan}"
+type ConfigONUService struct {
+	Name    string `json:"name"`
+	GEMPort int    `json:"gemport"`
+	VLAN    int    `json:"vlan,omitempty"`
+}
+
+// ConfigONUVLANPort baris "vlan port {port} mode {mode} vlan {vlan}"
+type ConfigONUVLANPort struct {
+	Port string `json:"port"`
+	Mode string `json:"mode"`
+	VLAN int    `json:"vlan,omitempty"`
+}
+
+// ConfigONUWAN baris "wan-ip {index} mode {mode} [username {user} password
+// {pass}] vlan-profile {profile} host {host}". Password tidak disimpan.
+type ConfigONUWAN struct {
+	Index       int    `json:"index"`
+	Mode        string `json:"mode"`
+	Username    string `json:"username,omitempty"`
+	VLANProfile string `json:"vlan_profile,omitempty"`
+	Host        int    `json:"host,omitempty"`
+}
+
+// ConfigONUSSID gabungan baris "ssid ctrl {wifi} name {name}" dan
+// "ssid auth {type} {wifi} {mode} key {key}" per interface WiFi. Key tidak
+// disimpan.
+type ConfigONUSSID struct {
+	Interface string `json:"interface"`
+	Name      string `json:"name,omitempty"`
+	AuthMode  string `json:"auth_mode,omitempty"`
+}
+
+// ONUConfig gabungan semua konfigurasi satu ONU di running-config
+type ONUConfig struct {
+	Registration *ConfigONU           `json:"registration,omitempty"`
+	Interface    *ConfigONUInterface  `json:"interface,omitempty"`
+	Management   *ConfigONUManagement `json:"management,omitempty"`
+	VLANs        []int                `json:"vlans"`
+}
+
+// ShowRunningConfigParsed menampilkan running config dalam bentuk terstruktur
+// Command: show running-config
+func (z *ZTEC320Client) ShowRunningConfigParsed(ctx context.Context) (*RunningConfig, error) {
+	output, err := z.ShowRunningConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRunningConfig(output), nil
+}
+
+// ParseRunningConfig mem-parsing teks running-config C320.
+// Fungsi ini juga dipakai untuk file config hasil backup.
+func ParseRunningConfig(output string) *RunningConfig {
+	cfg := &RunningConfig{}
+
+	var header string
+	var body []string
+	flush := func() {
+		if header != "" {
+			cfg.addBlock(header, body)
+		}
+		header, body = "", nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case line == "!" || line == "$" || line == "end":
+			flush()
+		case isBlockHeader(line):
+			flush()
+			header = line
+		case header != "":
+			body = append(body, line)
+		default:
+			cfg.addGlobal(line)
+		}
+	}
+	flush()
+
+	return cfg
+}
+
+// isBlockHeader mengenali baris pembuka blok.
+func isBlockHeader(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "interface", "pon-onu-mng", "gpon", "pon":
+		return true
+	case "vlan":
+		// "vlan 143" adalah blok, "vlan port ..." (di pon-onu-mng) bukan
+		return len(fields) == 2 && leadingInt(fields[1]) > 0
+	case "onu-profile":
+		return len(fields) >= 4
+	}
+	return false
+}
+
+func (c *RunningConfig) addGlobal(line string) {
+	fields := strings.Fields(line)
+	switch {
+	case fields[0] == "hostname" && len(fields) > 1:
+		c.Hostname = fields[1]
+	case c.addProfileLine(line):
+	default:
+		c.Global = append(c.Global, line)
+	}
+}
+
+// addProfileLine menangani baris profile yang bisa muncul di level global
+// maupun di dalam blok gpon. Mengembalikan true jika baris dikenali.
+func (c *RunningConfig) addProfileLine(line string) bool {
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) >= 3 && fields[0] == "profile" && fields[1] == "tcont":
+		p := ConfigTCONTProfile{Name: fields[2]}
+		p.Type = leadingInt(fieldAfter(fields, "type"))
+		p.Fixed = leadingInt(fieldAfter(fields, "fixed"))
+		p.Assured = leadingInt(fieldAfter(fields, "assured"))
+		p.Maximum = leadingInt(fieldAfter(fields, "maximum"))
+		c.TCONTProfiles = append(c.TCONTProfiles, p)
+		return true
+	case len(fields) >= 4 && fields[0] == "onu" && fields[1] == "profile" && fields[2] == "vlan":
+		c.VLANProfiles = append(c.VLANProfiles, ConfigVLANProfile{
+			Name:     fields[3],
+			TagMode:  fieldAfter(fields, "tag-mode"),
+			CVLAN:    leadingInt(fieldAfter(fields, "cvlan")),
+			Priority: leadingInt(fieldAfter(fields, "cvlan-priority")),
+		})
+		return true
+	case len(fields) >= 2 && fields[0] == "onu-type":
+		c.ONUTypes = append(c.ONUTypes, fields[1])
+		return true
+	}
+	return false
+}
+
+func (c *RunningConfig) addBlock(header string, body []string) {
+	fields := strings.Fields(header)
+	switch fields[0] {
+	case "vlan":
+		v := ConfigVLAN{ID: leadingInt(fields[1])}
+		for _, line := range body {
+			key, value := splitCommand(line)
+			switch key {
+			case "name":
+				v.Name = value
+			case "description":
+				v.Description = value
+			}
+		}
+		c.VLANs = append(c.VLANs, v)
+
+	case "gpon", "pon":
+		for _, line := range body {
+			c.addProfileLine(line)
+		}
+
+	case "onu-profile":
+		c.Profiles = append(c.Profiles, ConfigProfile{
+			Kind:  fields[2],
+			Name:  fields[3],
+			Lines: body,
+		})
+
+	case "pon-onu-mng":
+		c.ONUManagement = append(c.ONUManagement, parseONUManagementBlock(fields[1], body))
+
+	case "interface":
+		name := strings.Join(fields[1:], "")
+		switch {
+		case strings.HasPrefix(name, "gpon-olt_"):
+			c.OLTInterfaces = append(c.OLTInterfaces, parseOLTInterfaceBlock(name, body))
+		case strings.HasPrefix(name, "gpon-onu_"):
+			c.ONUInterfaces = append(c.ONUInterfaces, parseONUInterfaceBlock(name, body))
+		default:
+			c.Interfaces = append(c.Interfaces, ConfigSection{Name: name, Lines: body})
+		}
+	}
+}
+
+func parseOLTInterfaceBlock(name string, body []string) ConfigOLTInterface {
+	iface := ConfigOLTInterface{Name: name}
+	fmt.Sscanf(strings.TrimPrefix(name, "gpon-olt_"), "%d/%d/%d", &iface.Rack, &iface.Shelf, &iface.Slot)
+
+	for _, line := range body {
+		fields := strings.Fields(line)
+		switch {
+		case line == "shutdown":
+			iface.Shutdown = true
+		case line == "no shutdown":
+			iface.Shutdown = false
+		case fields[0] == "description":
+			_, iface.Description = splitCommand(line)
+		case fields[0] == "onu" && len(fields) >= 4 && fields[2] == "type":
+			onu := ConfigONU{
+				ID:   leadingInt(fields[1]),
+				Type: fields[3],
+			}
+			// onu 1 type ZTE-F660 sn ZTEGC8A31F02
+			// onu 2 type ZTE-F660 pw 12345678
+			// onu 3 type ZTE-F660 loid LOID123
+			if len(fields) >= 6 {
+				onu.AuthMode = fields[4]
+				if onu.AuthMode == "sn" {
+					onu.SN = fields[5]
+				} else {
+					onu.Password = fields[5]
+				}
+			}
+			iface.ONUs = append(iface.ONUs, onu)
+		default:
+			iface.Other = append(iface.Other, line)
+		}
+	}
+	return iface
+}
+
+func parseONUInterfaceBlock(name string, body []string) ConfigONUInterface {
+	iface := ConfigONUInterface{Name: name}
+	fmt.Sscanf(strings.TrimPrefix(name, "gpon-onu_"), "%d/%d/%d:%d", &iface.Rack, &iface.Shelf, &iface.Slot, &iface.ONUID)
+
+	for _, line := range body {
+		fields := strings.Fields(line)
+		switch {
+		case fields[0] == "name":
+			_, iface.ONUName = splitCommand(line)
+		case fields[0] == "description":
+			_, iface.Description = splitCommand(line)
+		case fields[0] == "tcont" && len(fields) >= 2:
+			iface.TCONTs = append(iface.TCONTs, ConfigTCONT{
+				ID:      leadingInt(fields[1]),
+				Name:    fieldAfter(fields, "name"),
+				Profile: fieldAfter(fields, "profile"),
+			})
+		case fields[0] == "gemport" && len(fields) >= 2 && fieldAfter(fields, "tcont") != "":
+			iface.GEMPorts = append(iface.GEMPorts, ConfigGEMPort{
+				ID:    leadingInt(fields[1]),
+				Name:  fieldAfter(fields, "name"),
+				TCONT: leadingInt(fieldAfter(fields, "tcont")),
+			})
+		case fields[0] == "service-port" && len(fields) >= 2:
+			iface.ServicePorts = append(iface.ServicePorts, ConfigServicePort{
+				ID:       leadingInt(fields[1]),
+				Vport:    leadingInt(fieldAfter(fields, "vport")),
+				UserVLAN: leadingInt(fieldAfter(fields, "user-vlan")),
+				VLAN:     leadingInt(fieldAfter(fields, "vlan")),
+			})
+		default:
+			iface.Other = append(iface.Other, line)
+		}
+	}
+	return iface
+}
+
+func parseONUManagementBlock(name string, body []string) ConfigONUManagement {
+	mng := ConfigONUManagement{Name: name}
+	fmt.Sscanf(strings.TrimPrefix(name, "gpon-onu_"), "%d/%d/%d:%d", &mng.Rack, &mng.Shelf, &mng.Slot, &mng.ONUID)
+
+	for _, line := range body {
+		fields := strings.Fields(line)
+		switch {
+		case fields[0] == "service" && len(fields) >= 2:
+			mng.Services = append(mng.Services, ConfigONUService{
+				Name:    fields[1],
+				GEMPort: leadingInt(fieldAfter(fields, "gemport")),
+				VLAN:    leadingInt(fieldAfter(fields, "vlan")),
+			})
+		case len(fields) >= 3 && fields[0] == "vlan" && fields[1] == "port":
+			mng.VLANPorts = append(mng.VLANPorts, ConfigONUVLANPort{
+				Port: fields[2],
+				Mode: fieldAfter(fields, "mode"),
+				VLAN: leadingInt(fieldAfter(fields[3:], "vlan")),
+			})
+		case len(fields) >= 4 && fields[0] == "wan-ip" && fields[2] == "mode":
+			mng.WANs = append(mng.WANs, ConfigONUWAN{
+				Index:       leadingInt(fields[1]),
+				Mode:        fields[3],
+				Username:    fieldAfter(fields, "username"),
+				VLANProfile: fieldAfter(fields, "vlan-profile"),
+				Host:        leadingInt(fieldAfter(fields, "host")),
+			})
+		case len(fields) >= 4 && fields[0] == "ssid" && fields[1] == "ctrl":
+			mng.ssid(fields[2]).Name = fieldAfter(fields, "name")
+		case len(fields) >= 5 && fields[0] == "ssid" && fields[1] == "auth":
+			mng.ssid(fields[3]).AuthMode = fields[4]
+		default:
+			// Baris lain bisa berisi kredensial (mis. mgmt-ip ... password)
+			mng.Other = append(mng.Other, audit.RedactCommand(line))
+		}
+	}
+	return mng
+}
+
+// ssid mengembalikan entri SSID untuk interface WiFi, dibuat jika belum ada
+func (mng *ConfigONUManagement) ssid(iface string) *ConfigONUSSID {
+	for i := range mng.SSIDs {
+		if mng.SSIDs[i].Interface == iface {
+			return &mng.SSIDs[i]
+		}
+	}
+	mng.SSIDs = append(mng.SSIDs, ConfigONUSSID{Interface: iface})
+	return &mng.SSIDs[len(mng.SSIDs)-1]
+}
+
+// ONU mengumpulkan konfigurasi satu ONU dari semua blok terkait.
+// Mengembalikan nil jika ONU tidak ada di running-config.
+func (c *RunningConfig) ONU(rack, shelf, slot, onuID int) *ONUConfig {
+	result := &ONUConfig{}
+	vlans := make(map[int]bool)
+
+	for i := range c.OLTInterfaces {
+		olt := &c.OLTInterfaces[i]
+		if olt.Rack != rack || olt.Shelf != shelf || olt.Slot != slot {
+			continue
+		}
+		for j := range olt.ONUs {
+			if olt.ONUs[j].ID == onuID {
+				result.Registration = &olt.ONUs[j]
+			}
+		}
+	}
+
+	for i := range c.ONUInterfaces {
+		iface := &c.ONUInterfaces[i]
+		if iface.Rack == rack && iface.Shelf == shelf && iface.Slot == slot && iface.ONUID == onuID {
+			result.Interface = iface
+			for _, sp := range iface.ServicePorts {
+				vlans[sp.VLAN] = true
+			}
+		}
+	}
+
+	for i := range c.ONUManagement {
+		mng := &c.ONUManagement[i]
+		if mng.Rack == rack && mng.Shelf == shelf && mng.Slot == slot && mng.ONUID == onuID {
+			result.Management = mng
+			for _, s := range mng.Services {
+				vlans[s.VLAN] = true
+			}
+			for _, p := range mng.VLANPorts {
+				vlans[p.VLAN] = true
+			}
+		}
+	}
+
+	if result.Registration == nil && result.Interface == nil && result.Management == nil {
+		return nil
+	}
+
+	result.VLANs = []int{}
+	for v := range vlans {
+		if v > 0 {
+			result.VLANs = append(result.VLANs, v)
+		}
+	}
+	sort.Ints(result.VLANs)
+	return result
+}
+
+// splitCommand memecah "name pelanggan 01" menjadi ("name", "pelanggan 01").
+func splitCommand(line string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+// fieldAfter mengembalikan kata setelah keyword pertama, atau "" jika tidak ada.
+func fieldAfter(fields []string, keyword string) string {
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == keyword {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
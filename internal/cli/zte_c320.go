@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ardani/snmp-zte/internal/audit"
 )
 
 // ZTEC320Client khusus untuk ZTE C320 CLI commands
@@ -30,6 +32,11 @@ func (z *ZTEC320Client) Close() error {
 	return z.client.Close()
 }
 
+// LocalIP mengembalikan alamat IP lokal koneksi ke OLT
+func (z *ZTEC320Client) LocalIP() string {
+	return z.client.LocalIP()
+}
+
 // Execute menjalankan command mentah (wrapper for client.Execute)
 func (z *ZTEC320Client) Execute(ctx context.Context, cmd string) (string, error) {
 	return z.client.Execute(ctx, cmd)
@@ -242,151 +249,165 @@ func (z *ZTEC320Client) ShowRunningConfig(ctx context.Context) (string, error) {
 	return output, nil
 }
 
+// ShowStartupConfig menampilkan config yang tersimpan (hasil "write")
+// Command: show startup-config
+func (z *ZTEC320Client) ShowStartupConfig(ctx context.Context) (string, error) {
+	output, err := z.client.Execute(ctx, "show startup-config")
+	if err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
 // ============================================================
 // PARSING FUNCTIONS
 // ============================================================
 
 func (z *ZTEC320Client) parseUncfgONU(output string) []UncfgONU {
 	var onus []UncfgONU
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "OnuIndex") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "OnuIndex", "Sn", "State") {
+		if row["OnuIndex"] == "" || row["Sn"] == "" {
 			continue
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 3 {
-			onus = append(onus, UncfgONU{
-				Index: fields[0],
-				SN:    fields[1],
-				State: fields[2],
-			})
-		}
+		onus = append(onus, UncfgONU{
+			Index: row["OnuIndex"],
+			SN:    row["Sn"],
+			State: row["State"],
+		})
 	}
-
 	return onus
 }
 
 func (z *ZTEC320Client) parseONUState(output string) []ONUInfo {
 	var onus []ONUInfo
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "OnuIndex") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "OnuIndex", "Admin State", "OMCC State", "O7 State", "Phase State") {
+		// Lewati baris ringkasan seperti "ONU Number: 3/3"
+		if !strings.Contains(row["OnuIndex"], ":") || row["Phase State"] == "" {
 			continue
 		}
+		onus = append(onus, ONUInfo{
+			Index:      row["OnuIndex"],
+			AdminState: row["Admin State"],
+			OmccState:  row["OMCC State"],
+			O7State:    row["O7 State"],
+			PhaseState: row["Phase State"],
+		})
+	}
+	return onus
+}
 
-		fields := strings.Fields(line)
-		if len(fields) >= 5 {
-			onus = append(onus, ONUInfo{
-				Index:      fields[0],
-				AdminState: fields[1],
-				OmccState:  fields[2],
-				O7State:    fields[3],
-				PhaseState: fields[4],
-			})
+func (z *ZTEC320Client) parseONUBaseInfo(output string) []ONUInfo {
+	var onus []ONUInfo
+	for _, row := range parseTable(output, "OnuIndex", "Type", "Mode", "AuthInfo", "State") {
+		if !strings.Contains(row["OnuIndex"], ":") {
+			continue
 		}
+		onus = append(onus, ONUInfo{
+			Index: row["OnuIndex"],
+			Type:  row["Type"],
+			SN:    strings.TrimPrefix(row["AuthInfo"], "SN:"),
+			State: row["State"],
+		})
 	}
-
 	return onus
 }
 
 func (z *ZTEC320Client) parseCardInfo(output string) []CardInfo {
 	var cards []CardInfo
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Rack") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "Rack", "Shelf", "Slot", "CfgType", "RealType", "Port", "HardVer", "SoftVer", "Status") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
 			continue
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 9 {
-			rack, _ := strconv.Atoi(fields[0])
-			shelf, _ := strconv.Atoi(fields[1])
-			slot, _ := strconv.Atoi(fields[2])
-			port, _ := strconv.Atoi(fields[5])
-
-			cards = append(cards, CardInfo{
-				Rack:     rack,
-				Shelf:    shelf,
-				Slot:     slot,
-				CfgType:  fields[3],
-				RealType: fields[4],
-				Port:     port,
-				HardVer:  fields[6],
-				SoftVer:  fields[7],
-				Status:   fields[8],
-			})
-		}
+		shelf, _ := strconv.Atoi(row["Shelf"])
+		slot, _ := strconv.Atoi(row["Slot"])
+		port, _ := strconv.Atoi(row["Port"])
+
+		cards = append(cards, CardInfo{
+			Rack:     rack,
+			Shelf:    shelf,
+			Slot:     slot,
+			CfgType:  row["CfgType"],
+			RealType: row["RealType"],
+			Port:     port,
+			HardVer:  row["HardVer"],
+			SoftVer:  row["SoftVer"],
+			Status:   row["Status"],
+		})
 	}
-
 	return cards
 }
 
 func (z *ZTEC320Client) parseCardDetail(output string) []CardInfo {
-	var cards []CardInfo
-	card := CardInfo{}
+	kv := parseKeyValue(output)
+	card := CardInfo{
+		Rack:      kv.getInt("Rack"),
+		Shelf:     kv.getInt("Shelf"),
+		Slot:      kv.getInt("Slot"),
+		CfgType:   kv.get("Config-Type"),
+		RealType:  kv.get("Real-Type"),
+		Port:      kv.getInt("Port-Number"),
+		HardVer:   kv.get("PCB-VER", "Hardware-VER"),
+		SoftVer:   kv.get("Software-VER"),
+		Status:    kv.get("Status"),
+		CPUUsage:  kv.get("Cpu-Usage"),
+		MemUsage:  kv.get("Mem-Usage"),
+		Uptime:    kv.get("Uptime"),
+		SerialNum: kv.get("Serial-Number"),
+	}
 
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Config-Type") {
-			card.CfgType = z.extractValue(line)
-		} else if strings.Contains(line, "Real-Type") {
-			card.RealType = z.extractValue(line)
-		} else if strings.Contains(line, "Status") {
-			card.Status = z.extractValue(line)
-		} else if strings.Contains(line, "Software-VER") {
-			card.SoftVer = z.extractValue(line)
-		} else if strings.Contains(line, "PCB-VER") {
-			card.HardVer = z.extractValue(line)
-		} else if strings.Contains(line, "Cpu-Usage") {
-			card.CPUUsage = z.extractValue(line)
-		} else if strings.Contains(line, "Mem-Usage") {
-			card.MemUsage = z.extractValue(line)
-		} else if strings.Contains(line, "Uptime") {
-			card.Uptime = z.extractValue(line)
-		} else if strings.Contains(line, "Serial-Number") {
-			card.SerialNum = z.extractValue(line)
-		}
-	}
-
-	if card.CfgType != "" {
-		cards = append(cards, card)
+	if card.CfgType == "" {
+		return nil
 	}
-	return cards
+	return []CardInfo{card}
 }
 
 func (z *ZTEC320Client) parseTCONTProfile(output string) []TCONTProfile {
 	var profiles []TCONTProfile
 	var current *TCONTProfile
+	expectValues := false
 
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Name") {
+		if line == "" {
+			continue
+		}
+		if key, value, ok := splitKeyValue(line); ok && (key == "name" || key == "profile name") {
 			if current != nil {
 				profiles = append(profiles, *current)
 			}
-			current = &TCONTProfile{
-				Name: z.extractValue(line),
+			current = &TCONTProfile{Name: value}
+			expectValues = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] == "Type" {
+			// Format lama: nilai berada di baris yang sama ("Type 4 0 0 10240").
+			// Format baru: baris ini hanya header, nilai di baris berikutnya.
+			if len(fields) >= 4 && leadingInt(fields[1]) > 0 {
+				fields = fields[1:]
+			} else {
+				expectValues = true
+				continue
 			}
-		} else if current != nil && strings.HasPrefix(line, "Type") {
-			fields := strings.Fields(line)
+		} else if !expectValues {
+			continue
+		}
+
+		if len(fields) >= 3 {
+			current.Type = leadingInt(fields[0])
+			current.FBW = leadingInt(fields[1])
+			current.ABW = leadingInt(fields[2])
 			if len(fields) >= 4 {
-				current.Type, _ = strconv.Atoi(fields[1])
-				current.FBW, _ = strconv.Atoi(fields[2])
-				current.ABW, _ = strconv.Atoi(fields[3])
-				if len(fields) >= 5 {
-					current.MBW, _ = strconv.Atoi(fields[4])
-				}
+				current.MBW = leadingInt(fields[3])
 			}
 		}
+		expectValues = false
 	}
 
 	if current != nil {
@@ -396,96 +417,62 @@ func (z *ZTEC320Client) parseTCONTProfile(output string) []TCONTProfile {
 }
 
 func (z *ZTEC320Client) parseONUType(output string) *ONUTypeInfo {
-	info := &ONUTypeInfo{}
-
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Onu type name") {
-			info.Name = z.extractValue(line)
-		} else if strings.Contains(line, "Pon type") {
-			info.PonType = z.extractValue(line)
-		} else if strings.Contains(line, "Description") {
-			info.Description = z.extractValue(line)
-		} else if strings.Contains(line, "Max tcont") {
-			info.MaxTcont, _ = strconv.Atoi(z.extractValue(line))
-		} else if strings.Contains(line, "Max gemport") {
-			info.MaxGemport, _ = strconv.Atoi(z.extractValue(line))
-		} else if strings.Contains(line, "Max switch per slot") {
-			info.MaxSwitchPerSlot, _ = strconv.Atoi(z.extractValue(line))
-		} else if strings.Contains(line, "Max flow per switch") {
-			info.MaxFlowPerSwitch, _ = strconv.Atoi(z.extractValue(line))
-		}
+	kv := parseKeyValue(output)
+	return &ONUTypeInfo{
+		Name:             kv.get("Onu type name", "ONU type"),
+		PonType:          kv.get("Pon type"),
+		Description:      kv.get("Description"),
+		MaxTcont:         kv.getInt("Max tcont"),
+		MaxGemport:       kv.getInt("Max gemport"),
+		MaxSwitchPerSlot: kv.getInt("Max switch per slot"),
+		MaxFlowPerSwitch: kv.getInt("Max flow per switch"),
 	}
-
-	return info
 }
 
 func (z *ZTEC320Client) parseONUTypeList(output string) []string {
 	var types []string
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Onu type") || strings.HasPrefix(line, "---") {
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) > 0 && strings.HasPrefix(fields[0], "ZTE") {
-			types = append(types, fields[0])
+	for _, row := range parseTable(output, "Onu type name", "Pon type", "Description") {
+		if name := row["Onu type name"]; name != "" {
+			types = append(types, name)
 		}
 	}
-
 	return types
 }
 
 func (z *ZTEC320Client) parseFanInfo(output string) *FanInfo {
-	info := &FanInfo{}
-
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "FanControlType") {
-			info.ControlType = z.extractValue(line)
-		} else if strings.Contains(line, "TemperatureThreshold") {
-			info.TempThreshold = z.extractValue(line)
-		} else if strings.Contains(line, "FanSpeedLevelPercent") {
-			info.FanSpeedPercent = z.extractValue(line)
-		} else if strings.Contains(line, "HighTemperatureThreshold") {
-			info.HighTempThreshold = z.extractValue(line)
-		} else if strings.Contains(line, "Environment Temperature") {
-			info.EnvironmentTemperature = z.extractValue(line)
-		}
+	kv := parseKeyValue(output)
+	return &FanInfo{
+		ControlType:            kv.get("FanControlType"),
+		TempThreshold:          kv.get("TemperatureThreshold"),
+		FanSpeedPercent:        kv.get("FanSpeedLevelPercent"),
+		HighTempThreshold:      kv.get("HighTemperatureThreshold"),
+		EnvironmentTemperature: kv.get("Environment Temperature"),
 	}
-
-	return info
 }
 
 func (z *ZTEC320Client) parseSystemInfo(output string) *SystemInfo {
-	info := &SystemInfo{}
-
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Product name") {
-			info.Name = z.extractValue(line)
-		} else if strings.Contains(line, "uptime") {
-			info.Uptime = z.extractValue(line)
+	kv := parseKeyValue(output)
+	info := &SystemInfo{
+		Name:        kv.get("Product name"),
+		Description: kv.get("Description", "Software version"),
+		Uptime:      kv.get("System uptime", "Uptime"),
+		Time:        kv.get("System time", "Current time"),
+	}
+
+	// Beberapa firmware mencetak "System uptime is 10 days, 3 hours ..."
+	if info.Uptime == "" {
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if idx := strings.Index(line, "uptime is "); idx >= 0 {
+				info.Uptime = strings.TrimSpace(line[idx+len("uptime is "):])
+				break
+			}
 		}
 	}
 
 	return info
 }
 
-func (z *ZTEC320Client) extractValue(line string) string {
-	// Extract value after colon
-	parts := strings.SplitN(line, ":", 2)
-	if len(parts) == 2 {
-		return strings.TrimSpace(parts[1])
-	}
-	return ""
-}
-
 // ============================================================
 // CONFIGURATION COMMANDS
 // ============================================================
@@ -589,6 +576,96 @@ func (z *ZTEC320Client) ResetONU(ctx context.Context, rack, shelf, slot, onuID i
 	return nil
 }
 
+// SetONUEthPortState mengaktifkan (unlock) atau menonaktifkan (lock) port
+// Ethernet ONU
+// Command: pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}, interface eth eth_0/{port} state lock|unlock
+func (z *ZTEC320Client) SetONUEthPortState(ctx context.Context, rack, shelf, slot, onuID, port int, enabled bool) error {
+	state := "lock"
+	if enabled {
+		state = "unlock"
+	}
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("pon-onu-mng gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID),
+		fmt.Sprintf("interface eth eth_0/%d state %s", port, state),
+		"exit",
+		"exit",
+	}
+
+	for _, cmd := range commands {
+		_, err := z.client.Execute(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("command '%s' failed: %w", cmd, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// SetONUPPPoE mengatur koneksi WAN PPPoE ONU
+// Command: pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}, wan-ip {index} mode pppoe username {user} password {pass} vlan-profile {profile} host {host}
+func (z *ZTEC320Client) SetONUPPPoE(ctx context.Context, rack, shelf, slot, onuID, index int, username, password, vlanProfile string, host int) error {
+	return z.onuMng(ctx, rack, shelf, slot, onuID, []string{password},
+		fmt.Sprintf("wan-ip %d mode pppoe username %s password %s vlan-profile %s host %d", index, username, password, vlanProfile, host),
+	)
+}
+
+// SetONUSSID mengubah nama dan/atau password WPA2 SSID ONU. Nilai kosong
+// tidak diubah.
+// Command: pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}, ssid ctrl wifi_0/{n} name {name}, ssid auth wpa wifi_0/{n} wpa2-psk key {password}
+func (z *ZTEC320Client) SetONUSSID(ctx context.Context, rack, shelf, slot, onuID, ssid int, name, password string) error {
+	var commands []string
+	if name != "" {
+		commands = append(commands, fmt.Sprintf("ssid ctrl wifi_0/%d name %s", ssid, name))
+	}
+	if password != "" {
+		commands = append(commands, fmt.Sprintf("ssid auth wpa wifi_0/%d wpa2-psk key %s", ssid, password))
+	}
+	return z.onuMng(ctx, rack, shelf, slot, onuID, []string{password}, commands...)
+}
+
+// SetONUService memetakan service ONU ke GEM port dan VLAN
+// Command: pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}, service {name} gemport {gemport} vlan {vlan}
+func (z *ZTEC320Client) SetONUService(ctx context.Context, rack, shelf, slot, onuID int, name string, gemport, vlan int) error {
+	cmd := fmt.Sprintf("service %s gemport %d", name, gemport)
+	if vlan > 0 {
+		cmd += fmt.Sprintf(" vlan %d", vlan)
+	}
+	return z.onuMng(ctx, rack, shelf, slot, onuID, nil, cmd)
+}
+
+// SetONUVLANPort mengatur mode VLAN port ethernet/WiFi ONU
+// Command: pon-onu-mng gpon-onu_{rack}/{shelf}/{slot}:{onu_id}, vlan port {port} mode {mode} [vlan {vlan}]
+func (z *ZTEC320Client) SetONUVLANPort(ctx context.Context, rack, shelf, slot, onuID int, port, mode string, vlan int) error {
+	cmd := fmt.Sprintf("vlan port %s mode %s", port, mode)
+	if vlan > 0 {
+		cmd += fmt.Sprintf(" vlan %d", vlan)
+	}
+	return z.onuMng(ctx, rack, shelf, slot, onuID, nil, cmd)
+}
+
+// onuMng menjalankan perintah di mode pon-onu-mng satu ONU. Nilai secrets
+// disembunyikan di audit log dan di pesan error perintah yang gagal.
+func (z *ZTEC320Client) onuMng(ctx context.Context, rack, shelf, slot, onuID int, secrets []string, lines ...string) error {
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("pon-onu-mng gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID),
+	}
+	commands = append(commands, lines...)
+	commands = append(commands, "exit", "exit")
+
+	for _, cmd := range commands {
+		_, err := z.client.ExecuteSecret(ctx, cmd, secrets...)
+		if err != nil {
+			return fmt.Errorf("command '%s' failed: %w", audit.RedactCommand(audit.RedactValues(cmd, secrets...)), err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return nil
+}
+
 // ============================================================
 // WRITE OPERATIONS - T-CONT & GEM PORT
 // ============================================================
@@ -968,7 +1045,7 @@ func (z *ZTEC320Client) ShowGPONONUBaseInfo(ctx context.Context, rack, shelf, sl
 	if err != nil {
 		return nil, err
 	}
-	return z.parseONUState(output), nil
+	return z.parseONUBaseInfo(output), nil
 }
 
 // ONUTraffic informasi traffic ONU
@@ -1004,17 +1081,114 @@ type ONUOptical struct {
 	ONUTemp      string `json:"onu_temp"`
 	Voltage      string `json:"voltage"`
 	BiasCurrent  string `json:"bias_current"`
+	OLTRxPower   string `json:"olt_rx_power"` // Daya dari ONU yang diterima OLT (upstream)
 }
 
-// ShowONUOptical menampilkan optical info ONU
+// ShowONUOptical menampilkan optical info ONU, dilengkapi RX power di sisi
+// OLT dari tabel atenuasi PON. RX sisi OLT bersifat tambahan: jika perintah
+// atenuasi gagal (tidak didukung firmware, timeout), olt_rx_power kosong.
 // Command: show onu optical-info gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+// Command: show pon power attenuation gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
 func (z *ZTEC320Client) ShowONUOptical(ctx context.Context, rack, shelf, slot, onuID int) (*ONUOptical, error) {
 	cmd := fmt.Sprintf("show onu optical-info gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
 	output, err := z.client.Execute(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
-	return z.parseONUOptical(output), nil
+	optical := z.parseONUOptical(output)
+
+	cmd = fmt.Sprintf("show pon power attenuation gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	if output, err := z.client.Execute(ctx, cmd); err == nil {
+		optical.OLTRxPower = parsePowerAttenuation(output).OLTRxPower
+	}
+	return optical, nil
+}
+
+// ONUEthPort status port Ethernet (UNI) ONU
+type ONUEthPort struct {
+	Interface  string `json:"interface"`
+	Port       int    `json:"port"`
+	AdminState string `json:"admin_state"` // enable atau disable
+	LinkState  string `json:"link_state"`  // up atau down
+	Speed      string `json:"speed"`
+	Duplex     string `json:"duplex"`
+	SpeedMode  string `json:"speed_mode"`
+}
+
+// ShowONUEthPorts menampilkan status semua port Ethernet ONU lewat OMCI
+// Command: show gpon remote-onu interface eth gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+func (z *ZTEC320Client) ShowONUEthPorts(ctx context.Context, rack, shelf, slot, onuID int) ([]ONUEthPort, error) {
+	cmd := fmt.Sprintf("show gpon remote-onu interface eth gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	output, err := z.client.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return z.parseONUEthPorts(output), nil
+}
+
+// ONUWAN koneksi WAN ONU (PPPoE/DHCP/static). Password tidak ditampilkan.
+type ONUWAN struct {
+	Index        int    `json:"index"`
+	Name         string `json:"name"`
+	Mode         string `json:"mode"`
+	Status       string `json:"status"`
+	Username     string `json:"username"`
+	IPAddress    string `json:"ip_address"`
+	SubnetMask   string `json:"subnet_mask"`
+	Gateway      string `json:"gateway"`
+	PrimaryDNS   string `json:"primary_dns"`
+	SecondaryDNS string `json:"secondary_dns"`
+	MACAddress   string `json:"mac_address"`
+	VLAN         int    `json:"vlan"`
+}
+
+// ShowONUWAN menampilkan koneksi WAN ONU lewat OMCI
+// Command: show gpon remote-onu wan-ip gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+func (z *ZTEC320Client) ShowONUWAN(ctx context.Context, rack, shelf, slot, onuID int) ([]ONUWAN, error) {
+	cmd := fmt.Sprintf("show gpon remote-onu wan-ip gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	output, err := z.client.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return z.parseONUWAN(output), nil
+}
+
+// ONUSSID satu SSID WiFi ONU. Password tidak ditampilkan.
+type ONUSSID struct {
+	Interface  string `json:"interface"`
+	Index      int    `json:"index"`
+	Name       string `json:"name"`
+	State      string `json:"state"` // enable atau disable
+	Hidden     bool   `json:"hidden"`
+	AuthMode   string `json:"auth_mode"`
+	Encryption string `json:"encryption"`
+	Standard   string `json:"standard"`
+	Channel    string `json:"channel"`
+	Clients    int    `json:"clients"`
+}
+
+// ShowONUWiFi menampilkan SSID WiFi ONU lewat OMCI
+// Command: show gpon remote-onu wifi gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+func (z *ZTEC320Client) ShowONUWiFi(ctx context.Context, rack, shelf, slot, onuID int) ([]ONUSSID, error) {
+	cmd := fmt.Sprintf("show gpon remote-onu wifi gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	output, err := z.client.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return z.parseONUWiFi(output), nil
+}
+
+// ShowONURunningConfig menampilkan konfigurasi satu ONU (interface dan
+// pon-onu-mng) dalam bentuk terstruktur. Mengembalikan nil jika ONU tidak
+// punya konfigurasi.
+// Command: show onu running config gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+func (z *ZTEC320Client) ShowONURunningConfig(ctx context.Context, rack, shelf, slot, onuID int) (*ONUConfig, error) {
+	cmd := fmt.Sprintf("show onu running config gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	output, err := z.client.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRunningConfig(output).ONU(rack, shelf, slot, onuID), nil
 }
 
 // ============================================================
@@ -1056,6 +1230,41 @@ func (z *ZTEC320Client) ShowSubCard(ctx context.Context) ([]SubCardInfo, error)
 	return z.parseSubCard(output), nil
 }
 
+// RackInfo informasi rack
+type RackInfo struct {
+	Rack   int    `json:"rack"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// ShowRack menampilkan rack
+// Command: show rack
+func (z *ZTEC320Client) ShowRack(ctx context.Context) ([]RackInfo, error) {
+	output, err := z.client.Execute(ctx, "show rack")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseRack(output), nil
+}
+
+// ShelfInfo informasi shelf
+type ShelfInfo struct {
+	Rack        int    `json:"rack"`
+	Shelf       int    `json:"shelf"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// ShowShelf menampilkan shelf
+// Command: show shelf
+func (z *ZTEC320Client) ShowShelf(ctx context.Context) ([]ShelfInfo, error) {
+	output, err := z.client.Execute(ctx, "show shelf")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseShelf(output), nil
+}
+
 // ============================================================
 // PRIORITY 3: GPON PROFILES
 // ============================================================
@@ -1118,6 +1327,24 @@ func (z *ZTEC320Client) ShowMGCProfile(ctx context.Context, name string) (*MGCPr
 	return z.parseMGCProfile(output), nil
 }
 
+// VlanProfile informasi ONU VLAN profile
+type VlanProfile struct {
+	Name     string `json:"name"`
+	TagMode  string `json:"tag_mode"`
+	CVLAN    int    `json:"cvlan"`
+	Priority int    `json:"priority"`
+}
+
+// ShowVlanProfile menampilkan ONU VLAN profiles
+// Command: show gpon onu profile vlan
+func (z *ZTEC320Client) ShowVlanProfile(ctx context.Context) ([]VlanProfile, error) {
+	output, err := z.client.Execute(ctx, "show gpon onu profile vlan")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseVlanProfile(output), nil
+}
+
 // ============================================================
 // PRIORITY 4: LINE & REMOTE PROFILES
 // ============================================================
@@ -1253,22 +1480,82 @@ func (z *ZTEC320Client) ShowIGMPMVlanByID(ctx context.Context, id int) (*IGMPMVl
 	return nil, fmt.Errorf("mvlan not found")
 }
 
+// IGMPStatus status global IGMP
+type IGMPStatus struct {
+	Status        string `json:"status"`
+	Mode          string `json:"mode,omitempty"`
+	Version       string `json:"version,omitempty"`
+	MaxGroups     int    `json:"max_groups,omitempty"`
+	CurrentGroups int    `json:"current_groups"`
+	QueryInterval int    `json:"query_interval,omitempty"`
+	FastLeave     string `json:"fast_leave,omitempty"`
+}
+
+// ShowIGMP menampilkan status IGMP
+// Command: show igmp
+func (z *ZTEC320Client) ShowIGMP(ctx context.Context) (*IGMPStatus, error) {
+	output, err := z.client.Execute(ctx, "show igmp")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseIGMPStatus(output), nil
+}
+
+// IGMPMember informasi IGMP dynamic member
+type IGMPMember struct {
+	MVLAN        int    `json:"mvlan"`
+	Group        string `json:"group"`
+	Port         string `json:"port"`
+	VLAN         int    `json:"vlan"`
+	LastReporter string `json:"last_reporter"`
+	Timeout      int    `json:"timeout"`
+}
+
 // ShowIGMPDynamicMember menampilkan IGMP dynamic membership
 // Command: show igmp dynamic-member
-func (z *ZTEC320Client) ShowIGMPDynamicMember(ctx context.Context) (string, error) {
-	return z.client.Execute(ctx, "show igmp dynamic-member")
+func (z *ZTEC320Client) ShowIGMPDynamicMember(ctx context.Context) ([]IGMPMember, error) {
+	output, err := z.client.Execute(ctx, "show igmp dynamic-member")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseIGMPDynamicMember(output), nil
+}
+
+// IGMPForwardingEntry informasi entri IGMP forwarding table
+type IGMPForwardingEntry struct {
+	MVLAN  int      `json:"mvlan"`
+	Group  string   `json:"group"`
+	Source string   `json:"source"`
+	Ports  []string `json:"ports,omitempty"`
 }
 
 // ShowIGMPForwardingTable menampilkan IGMP forwarding table
 // Command: show igmp forwarding-table
-func (z *ZTEC320Client) ShowIGMPForwardingTable(ctx context.Context) (string, error) {
-	return z.client.Execute(ctx, "show igmp forwarding-table")
+func (z *ZTEC320Client) ShowIGMPForwardingTable(ctx context.Context) ([]IGMPForwardingEntry, error) {
+	output, err := z.client.Execute(ctx, "show igmp forwarding-table")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseIGMPForwardingTable(output), nil
+}
+
+// IGMPInterface informasi IGMP interface
+type IGMPInterface struct {
+	Interface string `json:"interface"`
+	Status    string `json:"status"`
+	Mode      string `json:"mode"`
+	MaxGroup  int    `json:"max_group"`
+	VLAN      int    `json:"vlan,omitempty"`
 }
 
 // ShowIGMPInterface menampilkan IGMP interface config
 // Command: show igmp interface
-func (z *ZTEC320Client) ShowIGMPInterface(ctx context.Context) (string, error) {
-	return z.client.Execute(ctx, "show igmp interface")
+func (z *ZTEC320Client) ShowIGMPInterface(ctx context.Context) ([]IGMPInterface, error) {
+	output, err := z.client.Execute(ctx, "show igmp interface")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseIGMPInterface(output), nil
 }
 
 // ============================================================
@@ -1300,10 +1587,48 @@ func (z *ZTEC320Client) ShowInterfaceByType(ctx context.Context, ifName string)
 	return z.parseInterfaceStats(output), nil
 }
 
+// ServicePort informasi service port ONU
+type ServicePort struct {
+	ID          int    `json:"id"`
+	Vport       int    `json:"vport"`
+	UserVLAN    int    `json:"user_vlan"`
+	VLAN        int    `json:"vlan"`
+	Status      string `json:"status"`
+	Description string `json:"description,omitempty"`
+}
+
+// ShowServicePort menampilkan service port ONU
+// Command: show service-port interface gpon-onu_{rack}/{shelf}/{slot}:{onu_id}
+func (z *ZTEC320Client) ShowServicePort(ctx context.Context, rack, shelf, slot, onuID int) ([]ServicePort, error) {
+	cmd := fmt.Sprintf("show service-port interface gpon-onu_%d/%d/%d:%d", rack, shelf, slot, onuID)
+	output, err := z.client.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return z.parseServicePort(output), nil
+}
+
 // ============================================================
 // PRIORITY 8: USER MANAGEMENT
 // ============================================================
 
+// LocalUser informasi user lokal OLT
+type LocalUser struct {
+	Username  string `json:"username"`
+	Privilege int    `json:"privilege"`
+	Status    string `json:"status,omitempty"`
+}
+
+// ShowLocalUsers menampilkan user lokal OLT
+// Command: show username
+func (z *ZTEC320Client) ShowLocalUsers(ctx context.Context) ([]LocalUser, error) {
+	output, err := z.client.Execute(ctx, "show username")
+	if err != nil {
+		return nil, err
+	}
+	return z.parseLocalUsers(output), nil
+}
+
 // OnlineUser informasi user online
 type OnlineUser struct {
 	Username  string `json:"username"`
@@ -1491,213 +1816,338 @@ func (z *ZTEC320Client) ShowTemperature(ctx context.Context) ([]TemperatureInfo,
 // ============================================================
 
 func (z *ZTEC320Client) parseONUDetail(output string) *ONUDetail {
-	detail := &ONUDetail{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "ONU Name") {
-			detail.ONUName = z.extractValue(line)
-		} else if strings.Contains(line, "ONU Type") {
-			detail.ONUType = z.extractValue(line)
-		} else if strings.Contains(line, "SN") && !strings.Contains(line, "Serial") {
-			detail.ONUSN = z.extractValue(line)
-		} else if strings.Contains(line, "Admin State") {
-			detail.AdminState = z.extractValue(line)
-		} else if strings.Contains(line, "Phase State") {
-			detail.PhaseState = z.extractValue(line)
-		} else if strings.Contains(line, "Channel") {
-			detail.ChannelState = z.extractValue(line)
-		} else if strings.Contains(line, "Authentication") {
-			detail.Authentication = z.extractValue(line)
-		} else if strings.Contains(line, "OMCC") {
-			detail.Omcc = z.extractValue(line)
-		} else if strings.Contains(line, "FEC Up") {
-			detail.FecUp = z.extractValue(line)
-		} else if strings.Contains(line, "FEC Down") {
-			detail.FecDown = z.extractValue(line)
-		} else if strings.Contains(line, "Line Profile") {
-			detail.LineProfile = z.extractValue(line)
-		} else if strings.Contains(line, "Remote Profile") {
-			detail.RemoteProfile = z.extractValue(line)
-		} else if strings.Contains(line, "Description") {
-			detail.Description = z.extractValue(line)
-		} else if strings.Contains(line, "Last Down Reason") {
-			detail.LastDownReason = z.extractValue(line)
-		} else if strings.Contains(line, "Last Down Time") {
-			detail.LastDownTime = z.extractValue(line)
+	kv := parseKeyValue(output)
+	detail := &ONUDetail{
+		ONUName:           kv.get("ONU Name", "Name"),
+		ONUType:           kv.get("ONU Type", "Type"),
+		ONUSN:             kv.get("Serial number", "SN"),
+		AdminState:        kv.get("Admin state"),
+		PhaseState:        kv.get("Phase state"),
+		ChannelState:      kv.get("Current channel", "Channel"),
+		Authentication:    kv.get("Authentication mode", "Authentication"),
+		Omcc:              kv.get("OMCC", "ONU Status"),
+		VoipState:         kv.get("VoIP state"),
+		VoipPortNum:       kv.get("VoIP port number"),
+		FecUp:             kv.get("FEC Up", "FEC"),
+		FecDown:           kv.get("FEC Down", "FEC actual mode"),
+		LineProfile:       kv.get("Line Profile"),
+		RemoteProfile:     kv.get("Remote Profile", "Service Profile"),
+		Description:       kv.get("Description"),
+		LastDownReason:    kv.get("Last Down Reason"),
+		LastDownTime:      kv.get("Last Down Time"),
+		DyingGasp:         kv.get("Dying Gasp"),
+		BatteryBackup:     kv.get("Battery backup"),
+		ONUVersion:        kv.get("ONU version", "Version"),
+		EquipmentID:       kv.get("Equipment ID"),
+		TrafficScheduling: kv.get("Traffic scheduling", "DBA Mode"),
+	}
+
+	// Riwayat online/offline di bagian bawah detail-info:
+	//    1   2024-03-01 08:00:12    2024-03-02 10:11:12     DyingGasp
+	// Ambil entri offline terakhir jika OLT tidak mencetak "Last Down ..."
+	if detail.LastDownTime == "" {
+		for _, h := range parseONUHistory(output) {
+			if h.OfflineTime != "" {
+				detail.LastDownTime = h.OfflineTime
+				detail.LastDownReason = h.Cause
+			}
 		}
 	}
-	
+
 	return detail
 }
 
+// onuHistoryEntry adalah satu baris riwayat Authpass/Offline di detail-info.
+type onuHistoryEntry struct {
+	AuthpassTime string
+	OfflineTime  string
+	Cause        string
+}
+
+func parseONUHistory(output string) []onuHistoryEntry {
+	var entries []onuHistoryEntry
+	for _, row := range parseTable(output, "Authpass Time", "OfflineTime", "Cause") {
+		// Kolom pertama berisi nomor urut, buang dari waktu authpass
+		fields := strings.Fields(row["Authpass Time"])
+		if len(fields) == 3 {
+			fields = fields[1:]
+		}
+		entry := onuHistoryEntry{
+			AuthpassTime: strings.Join(fields, " "),
+			OfflineTime:  row["OfflineTime"],
+			Cause:        row["Cause"],
+		}
+		if strings.HasPrefix(entry.OfflineTime, "0000-00-00") {
+			entry.OfflineTime = ""
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 func (z *ZTEC320Client) parseONUDistance(output string) []ONUDistance {
 	var distances []ONUDistance
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "ONU") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "ONU", "Type", "Distance", "EqD") {
+		if row["ONU"] == "" || row["Distance"] == "" {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 4 {
-			distances = append(distances, ONUDistance{
-				ONUID:     fields[0],
-				ONUType:   fields[1],
-				Distance:  fields[2],
-				Equalizer: fields[3],
-			})
-		}
+		distances = append(distances, ONUDistance{
+			ONUID:     row["ONU"],
+			ONUType:   row["Type"],
+			Distance:  row["Distance"],
+			Equalizer: row["EqD"],
+		})
 	}
-	
 	return distances
 }
 
 func (z *ZTEC320Client) parseONUTraffic(output string) *ONUTraffic {
-	traffic := &ONUTraffic{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "gpon-onu") {
-			traffic.Interface = strings.Fields(line)[0]
-		} else if strings.Contains(line, "Tx-rate") {
-			traffic.TxRate = z.extractValue(line)
-		} else if strings.Contains(line, "Rx-rate") {
-			traffic.RxRate = z.extractValue(line)
+	kv := parseKeyValue(output)
+	return &ONUTraffic{
+		Interface: kv.get("Interface"),
+		TxRate:    kv.get("Tx-rate(bps)", "Tx-rate"),
+		RxRate:    kv.get("Rx-rate(bps)", "Rx-rate"),
+		TxPkts:    kv.get("Tx-packets", "Tx-pkts"),
+		RxPkts:    kv.get("Rx-packets", "Rx-pkts"),
+		TxBytes:   kv.get("Tx-bytes"),
+		RxBytes:   kv.get("Rx-bytes"),
+	}
+}
+
+func (z *ZTEC320Client) parseONUEthPorts(output string) []ONUEthPort {
+	var ports []ONUEthPort
+	for _, b := range parseKeyValueBlocks(output, "Interface") {
+		port := ONUEthPort{
+			Interface:  b.get("Interface"),
+			AdminState: b.get("Operate status", "Operator status", "Admin status"),
+			SpeedMode:  b.get("Speed status"),
+		}
+		if i := strings.LastIndex(port.Interface, "/"); i >= 0 {
+			port.Port = leadingInt(port.Interface[i+1:])
+		}
+		// Beberapa firmware hanya menampilkan State lock/unlock
+		if port.AdminState == "" {
+			switch b.get("State") {
+			case "lock":
+				port.AdminState = "disable"
+			case "unlock":
+				port.AdminState = "enable"
+			}
 		}
+
+		// Phy state: full-1000, half-100, linkdown atau unknown
+		port.LinkState = "down"
+		duplex, speed, ok := strings.Cut(strings.ToLower(b.get("Phy state")), "-")
+		if ok && (duplex == "full" || duplex == "half") {
+			port.LinkState = "up"
+			port.Duplex = duplex
+			port.Speed = speed + "M"
+		}
+		ports = append(ports, port)
 	}
-	
-	return traffic
+	return ports
 }
 
-func (z *ZTEC320Client) parseONUOptical(output string) *ONUOptical {
-	optical := &ONUOptical{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "ONU Name") {
-			optical.ONUName = z.extractValue(line)
-		} else if strings.Contains(line, "ONU Type") {
-			optical.ONUType = z.extractValue(line)
-		} else if strings.Contains(line, "SN") && !strings.Contains(line, "Serial") {
-			optical.ONUSN = z.extractValue(line)
-		} else if strings.Contains(line, "Optical Power") || strings.Contains(line, "Tx Power") {
-			optical.OpticalPower = z.extractValue(line)
-		} else if strings.Contains(line, "Rx Power") {
-			optical.RxPower = z.extractValue(line)
-		} else if strings.Contains(line, "Temperature") {
-			optical.ONUTemp = z.extractValue(line)
-		} else if strings.Contains(line, "Voltage") {
-			optical.Voltage = z.extractValue(line)
-		} else if strings.Contains(line, "Bias Current") {
-			optical.BiasCurrent = z.extractValue(line)
+func (z *ZTEC320Client) parseONUWAN(output string) []ONUWAN {
+	var wans []ONUWAN
+	for _, b := range parseKeyValueBlocks(output, "Index") {
+		wans = append(wans, ONUWAN{
+			Index:        b.getInt("Index"),
+			Name:         b.get("Name"),
+			Mode:         strings.ToLower(b.get("Mode", "Connection type")),
+			Status:       b.get("Connection status", "Status"),
+			Username:     b.get("Username", "PPPoE username"),
+			IPAddress:    b.get("IP address", "IP"),
+			SubnetMask:   b.get("Subnet mask", "Mask"),
+			Gateway:      b.get("Default gateway", "Gateway"),
+			PrimaryDNS:   b.get("Primary DNS"),
+			SecondaryDNS: b.get("Secondary DNS"),
+			MACAddress:   b.get("MAC address", "MAC"),
+			VLAN:         b.getInt("Vlan ID", "VLAN"),
+		})
+	}
+	return wans
+}
+
+func (z *ZTEC320Client) parseONUWiFi(output string) []ONUSSID {
+	var ssids []ONUSSID
+	for _, b := range parseKeyValueBlocks(output, "SSID") {
+		ssid := ONUSSID{
+			Interface:  b.get("SSID"),
+			Name:       b.get("Name", "SSID name"),
+			State:      b.get("State", "Admin state"),
+			Hidden:     b.get("Hide") == "enable",
+			AuthMode:   b.get("Auth mode", "Authentication"),
+			Encryption: b.get("Encryption"),
+			Standard:   b.get("Standard"),
+			Channel:    b.get("Channel"),
+			Clients:    b.getInt("Associated devices", "Clients"),
 		}
+		if i := strings.LastIndex(ssid.Interface, "/"); i >= 0 {
+			ssid.Index = leadingInt(ssid.Interface[i+1:])
+		}
+		ssids = append(ssids, ssid)
+	}
+	return ssids
+}
+
+func (z *ZTEC320Client) parseONUOptical(output string) *ONUOptical {
+	kv := parseKeyValue(output)
+	optical := &ONUOptical{
+		ONUName:      kv.get("ONU Name", "Name"),
+		ONUType:      kv.get("ONU Type", "Type"),
+		ONUSN:        kv.get("SN", "Serial number"),
+		OpticalPower: kv.get("Optical Power"),
+		TxPower:      kv.get("Tx Power"),
+		RxPower:      kv.get("Rx Power"),
+		ONUTemp:      kv.get("Temperature"),
+		Voltage:      kv.get("Voltage"),
+		BiasCurrent:  kv.get("Bias Current"),
+	}
+	if optical.OpticalPower == "" {
+		optical.OpticalPower = optical.TxPower
 	}
-	
 	return optical
 }
 
+// PowerAttenuation daya optik kedua sisi link dari show pon power attenuation
+type PowerAttenuation struct {
+	OLTRxPower      string `json:"olt_rx_power"`
+	ONUTxPower      string `json:"onu_tx_power"`
+	UpAttenuation   string `json:"up_attenuation"`
+	OLTTxPower      string `json:"olt_tx_power"`
+	ONURxPower      string `json:"onu_rx_power"`
+	DownAttenuation string `json:"down_attenuation"`
+}
+
+// parsePowerAttenuation membaca baris up/down tabel atenuasi:
+//
+//	 up      Rx :-24.123(dbm)      Tx:2.165(dbm)        26.288(dB)
+//	 down    Tx :5.706(dbm)        Rx:-20.000(dbm)      25.706(dB)
+func parsePowerAttenuation(output string) *PowerAttenuation {
+	result := &PowerAttenuation{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(strings.ReplaceAll(line, ":", " "))
+		if len(fields) < 6 {
+			continue
+		}
+		olt, onu, att := fields[2], fields[4], fields[5]
+		switch strings.ToLower(fields[0]) {
+		case "up":
+			result.OLTRxPower, result.ONUTxPower, result.UpAttenuation = olt, onu, att
+		case "down":
+			result.OLTTxPower, result.ONURxPower, result.DownAttenuation = olt, onu, att
+		}
+	}
+	return result
+}
+
 // ============================================================
 // PARSER FUNCTIONS - PRIORITY 2
 // ============================================================
 
 func (z *ZTEC320Client) parseSubCard(output string) []SubCardInfo {
 	var subcards []SubCardInfo
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Rack") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "Rack", "Shelf", "Slot", "SubSlot", "Type", "Status") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 5 {
-			rack, _ := strconv.Atoi(fields[0])
-			shelf, _ := strconv.Atoi(fields[1])
-			slot, _ := strconv.Atoi(fields[2])
-			subslot, _ := strconv.Atoi(fields[3])
-			subcards = append(subcards, SubCardInfo{
-				Rack:    rack,
-				Shelf:   shelf,
-				Slot:    slot,
-				SubSlot: subslot,
-				Type:    fields[4],
-				Status:  fields[5],
-			})
-		}
+		shelf, _ := strconv.Atoi(row["Shelf"])
+		slot, _ := strconv.Atoi(row["Slot"])
+		subslot, _ := strconv.Atoi(row["SubSlot"])
+		subcards = append(subcards, SubCardInfo{
+			Rack:    rack,
+			Shelf:   shelf,
+			Slot:    slot,
+			SubSlot: subslot,
+			Type:    row["Type"],
+			Status:  row["Status"],
+		})
 	}
-	
 	return subcards
 }
 
+func (z *ZTEC320Client) parseRack(output string) []RackInfo {
+	var racks []RackInfo
+	for _, row := range parseTable(output, "Rack", "RackType", "Status") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
+			continue
+		}
+		racks = append(racks, RackInfo{
+			Rack:   rack,
+			Type:   row["RackType"],
+			Status: row["Status"],
+		})
+	}
+	return racks
+}
+
+func (z *ZTEC320Client) parseShelf(output string) []ShelfInfo {
+	var shelves []ShelfInfo
+	for _, row := range parseTable(output, "Rack", "Shelf", "ShelfType", "Description") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
+			continue
+		}
+		shelf, _ := strconv.Atoi(row["Shelf"])
+		shelves = append(shelves, ShelfInfo{
+			Rack:        rack,
+			Shelf:       shelf,
+			Type:        row["ShelfType"],
+			Description: row["Description"],
+		})
+	}
+	return shelves
+}
+
 // ============================================================
 // PARSER FUNCTIONS - PRIORITY 3
 // ============================================================
 
 func (z *ZTEC320Client) parseIPProfile(output string) *IPProfile {
-	profile := &IPProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
-		} else if strings.Contains(line, "IP Address") {
-			profile.IPAddress = z.extractValue(line)
-		} else if strings.Contains(line, "Mask") {
-			profile.Mask = z.extractValue(line)
-		} else if strings.Contains(line, "Gateway") {
-			profile.Gateway = z.extractValue(line)
-		}
+	kv := parseKeyValue(output)
+	return &IPProfile{
+		Name:      kv.get("Profile Name"),
+		IPAddress: kv.get("IP Address"),
+		Mask:      kv.get("Mask", "Subnet Mask"),
+		Gateway:   kv.get("Gateway"),
 	}
-	
-	return profile
 }
 
 func (z *ZTEC320Client) parseSIPProfile(output string) *SIPProfile {
-	profile := &SIPProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
-		} else if strings.Contains(line, "Proxy Server") {
-			profile.ProxyServer = z.extractValue(line)
-		} else if strings.Contains(line, "Registrar") {
-			profile.Registrar = z.extractValue(line)
-		} else if strings.Contains(line, "Outbound") {
-			profile.Outbound = z.extractValue(line)
-		}
+	kv := parseKeyValue(output)
+	return &SIPProfile{
+		Name:        kv.get("Profile Name"),
+		ProxyServer: kv.get("Proxy Server"),
+		Registrar:   kv.get("Registrar", "Registrar Server"),
+		Outbound:    kv.get("Outbound", "Outbound Server"),
 	}
-	
-	return profile
 }
 
 func (z *ZTEC320Client) parseMGCProfile(output string) *MGCProfile {
-	profile := &MGCProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
-		} else if strings.Contains(line, "MGC1 IP") {
-			profile.MGC1IP = z.extractValue(line)
-		} else if strings.Contains(line, "MGC1 Port") {
-			profile.MGC1Port = z.extractValue(line)
-		} else if strings.Contains(line, "MGC2 IP") {
-			profile.MGC2IP = z.extractValue(line)
-		} else if strings.Contains(line, "MGC2 Port") {
-			profile.MGC2Port = z.extractValue(line)
-		}
+	kv := parseKeyValue(output)
+	return &MGCProfile{
+		Name:     kv.get("Profile Name"),
+		MGC1IP:   kv.get("MGC1 IP"),
+		MGC1Port: kv.get("MGC1 Port"),
+		MGC2IP:   kv.get("MGC2 IP"),
+		MGC2Port: kv.get("MGC2 Port"),
+	}
+}
+
+func (z *ZTEC320Client) parseVlanProfile(output string) []VlanProfile {
+	var profiles []VlanProfile
+	for _, kv := range parseKeyValueBlocks(output, "Profile name") {
+		profiles = append(profiles, VlanProfile{
+			Name:     kv.get("Profile name"),
+			TagMode:  kv.get("Tag mode"),
+			CVLAN:    kv.getInt("CVLAN"),
+			Priority: kv.getInt("CVLAN priority"),
+		})
 	}
-	
-	return profile
+	return profiles
 }
 
 // ============================================================
@@ -1706,47 +2156,42 @@ func (z *ZTEC320Client) parseMGCProfile(output string) *MGCProfile {
 
 func (z *ZTEC320Client) parseProfileList(output string) []string {
 	var profiles []string
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Profile") || strings.HasPrefix(line, "---") {
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) > 0 {
+	for _, row := range parseTable(output, "Profile Name") {
+		// Kolom lain (Ref-Count, dsb) tidak dipakai, ambil kata pertama saja
+		if fields := strings.Fields(row["Profile Name"]); len(fields) > 0 {
 			profiles = append(profiles, fields[0])
 		}
 	}
-	
 	return profiles
 }
 
 func (z *ZTEC320Client) parseLineProfile(output string) *LineProfile {
-	profile := &LineProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	profile := &LineProfile{
+		Name: parseKeyValue(output).get("Profile Name"),
+	}
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
+		if strings.HasPrefix(line, "tcont ") {
+			profile.TCONTList = append(profile.TCONTList, line)
+		} else if strings.HasPrefix(line, "gemport ") {
+			profile.GEMPortList = append(profile.GEMPortList, line)
 		}
 	}
-	
 	return profile
 }
 
 func (z *ZTEC320Client) parseRemoteProfile(output string) *RemoteProfile {
-	profile := &RemoteProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	profile := &RemoteProfile{
+		Name: parseKeyValue(output).get("Profile Name"),
+	}
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
+		if strings.HasPrefix(line, "vlan ") || strings.HasPrefix(line, "vlan-filter") {
+			profile.VLANList = append(profile.VLANList, line)
+		} else if strings.HasPrefix(line, "service ") {
+			profile.ServiceList = append(profile.ServiceList, line)
 		}
 	}
-	
 	return profile
 }
 
@@ -1756,25 +2201,18 @@ func (z *ZTEC320Client) parseRemoteProfile(output string) *RemoteProfile {
 
 func (z *ZTEC320Client) parseVLANList(output string) []VLANInfo {
 	var vlans []VLANInfo
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "VLAN") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "VLAN", "Name", "Type", "Ports") {
+		id, err := strconv.Atoi(row["VLAN"])
+		if err != nil {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			id, err := strconv.Atoi(fields[0])
-			if err == nil {
-				vlans = append(vlans, VLANInfo{
-					ID:   id,
-					Type: fields[1],
-				})
-			}
-		}
+		vlans = append(vlans, VLANInfo{
+			ID:    id,
+			Name:  row["Name"],
+			Type:  row["Type"],
+			Ports: splitList(row["Ports"]),
+		})
 	}
-	
 	return vlans
 }
 
@@ -1784,91 +2222,186 @@ func (z *ZTEC320Client) parseVLANList(output string) []VLANInfo {
 
 func (z *ZTEC320Client) parseIGMPMVlan(output string) []IGMPMVlanInfo {
 	var mvlan []IGMPMVlanInfo
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "MVLAN") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "MVLAN", "Source IP", "Work Mode") {
+		id, err := strconv.Atoi(row["MVLAN"])
+		if err != nil {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 1 {
-			id, err := strconv.Atoi(fields[0])
-			if err == nil {
-				mvlan = append(mvlan, IGMPMVlanInfo{
-					MVLANID: id,
-				})
-			}
-		}
+		mvlan = append(mvlan, IGMPMVlanInfo{
+			MVLANID:  id,
+			SourceIP: row["Source IP"],
+			WorkMode: row["Work Mode"],
+		})
 	}
-	
 	return mvlan
 }
 
 func (z *ZTEC320Client) parseIGMPMVlanDetail(output string) *IGMPMVlanInfo {
-	mvlan := &IGMPMVlanInfo{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "MVLAN ID") {
-			mvlan.MVLANID, _ = strconv.Atoi(z.extractValue(line))
-		} else if strings.Contains(line, "Source IP") {
-			mvlan.SourceIP = z.extractValue(line)
-		} else if strings.Contains(line, "Work Mode") {
-			mvlan.WorkMode = z.extractValue(line)
-		}
+	kv := parseKeyValue(output)
+	mvlan := &IGMPMVlanInfo{
+		MVLANID:   kv.getInt("MVLAN ID"),
+		SourceIP:  kv.get("Source IP"),
+		WorkMode:  kv.get("Work Mode"),
+		GroupList: splitList(kv.get("Group List")),
 	}
-	
+
 	if mvlan.MVLANID > 0 {
 		return mvlan
 	}
 	return nil
 }
 
+func (z *ZTEC320Client) parseIGMPStatus(output string) *IGMPStatus {
+	kv := parseKeyValue(output)
+	return &IGMPStatus{
+		Status:        kv.get("IGMP Status", "IGMP"),
+		Mode:          kv.get("IGMP Mode", "Mode"),
+		Version:       kv.get("IGMP Version", "Version"),
+		MaxGroups:     kv.getInt("Max Groups", "Maximum Groups"),
+		CurrentGroups: kv.getInt("Current Groups"),
+		QueryInterval: kv.getInt("Query Interval"),
+		FastLeave:     kv.get("Fast Leave"),
+	}
+}
+
+func (z *ZTEC320Client) parseIGMPDynamicMember(output string) []IGMPMember {
+	var members []IGMPMember
+	for _, row := range parseTable(output, "MVLAN", "Group", "Port", "Vlan", "Last-Reporter", "Timeout") {
+		mvlan, err := strconv.Atoi(row["MVLAN"])
+		if err != nil {
+			continue
+		}
+		members = append(members, IGMPMember{
+			MVLAN:        mvlan,
+			Group:        row["Group"],
+			Port:         row["Port"],
+			VLAN:         leadingInt(row["Vlan"]),
+			LastReporter: row["Last-Reporter"],
+			Timeout:      leadingInt(row["Timeout"]),
+		})
+	}
+	return members
+}
+
+func (z *ZTEC320Client) parseIGMPForwardingTable(output string) []IGMPForwardingEntry {
+	var entries []IGMPForwardingEntry
+	for _, row := range parseTable(output, "MVLAN", "Group", "Source", "Port") {
+		mvlan, err := strconv.Atoi(row["MVLAN"])
+		if err != nil {
+			continue
+		}
+		entries = append(entries, IGMPForwardingEntry{
+			MVLAN:  mvlan,
+			Group:  row["Group"],
+			Source: row["Source"],
+			Ports:  splitList(row["Port"]),
+		})
+	}
+	return entries
+}
+
+func (z *ZTEC320Client) parseIGMPInterface(output string) []IGMPInterface {
+	var ifaces []IGMPInterface
+	for _, row := range parseTable(output, "Interface", "Status", "Mode", "MaxGroup", "Vlan") {
+		if row["Interface"] == "" {
+			continue
+		}
+		ifaces = append(ifaces, IGMPInterface{
+			Interface: row["Interface"],
+			Status:    row["Status"],
+			Mode:      row["Mode"],
+			MaxGroup:  leadingInt(row["MaxGroup"]),
+			VLAN:      leadingInt(row["Vlan"]),
+		})
+	}
+	return ifaces
+}
+
 // ============================================================
 // PARSER FUNCTIONS - PRIORITY 7
 // ============================================================
 
 func (z *ZTEC320Client) parseInterfaceStats(output string) *InterfaceStats {
-	stats := &InterfaceStats{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	kv := parseKeyValue(output)
+	stats := &InterfaceStats{
+		TxRate:    kv.get("Output rate"),
+		RxRate:    kv.get("Input rate"),
+		TxPackets: kv.get("Output packets"),
+		RxPackets: kv.get("Input packets"),
+		TxBytes:   kv.get("Output bytes"),
+		RxBytes:   kv.get("Input bytes"),
+		TxErrors:  kv.get("Output errors"),
+		RxErrors:  kv.get("Input errors"),
+	}
+
+	// Baris pertama: "gpon-olt_1/1/1 is up, line protocol is up"
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Interface") && !strings.Contains(line, "Input") {
-			stats.Name = strings.Fields(line)[0]
-		} else if strings.Contains(line, "Status") {
-			stats.Status = z.extractValue(line)
+		if idx := strings.Index(line, " is "); idx > 0 && !strings.Contains(line, ":") {
+			stats.Name = line[:idx]
+			if p := strings.Index(line, "line protocol is "); p >= 0 {
+				stats.Status = strings.TrimRight(strings.TrimSpace(line[p+len("line protocol is "):]), ".")
+			} else {
+				stats.Status = strings.TrimRight(strings.TrimSpace(line[idx+len(" is "):]), ".,")
+			}
+			break
 		}
 	}
-	
+
 	return stats
 }
 
+func (z *ZTEC320Client) parseServicePort(output string) []ServicePort {
+	var ports []ServicePort
+	for _, row := range parseTable(output, "Index", "Vport", "UserVlan", "Vlan", "Status", "Description") {
+		id, err := strconv.Atoi(row["Index"])
+		if err != nil {
+			continue
+		}
+		ports = append(ports, ServicePort{
+			ID:          id,
+			Vport:       leadingInt(row["Vport"]),
+			UserVLAN:    leadingInt(row["UserVlan"]),
+			VLAN:        leadingInt(row["Vlan"]),
+			Status:      row["Status"],
+			Description: row["Description"],
+		})
+	}
+	return ports
+}
+
 // ============================================================
 // PARSER FUNCTIONS - PRIORITY 8
 // ============================================================
 
 func (z *ZTEC320Client) parseOnlineUsers(output string) []OnlineUser {
 	var users []OnlineUser
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Username") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "Line") {
+	for _, row := range parseTable(output, "Username", "IP", "Login Time", "From") {
+		if row["Username"] == "" {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 3 {
-			users = append(users, OnlineUser{
-				Username:  fields[0],
-				IP:        fields[1],
-				LoginTime: fields[2],
-			})
+		users = append(users, OnlineUser{
+			Username:  row["Username"],
+			IP:        row["IP"],
+			LoginTime: row["Login Time"],
+			From:      row["From"],
+		})
+	}
+	return users
+}
+
+func (z *ZTEC320Client) parseLocalUsers(output string) []LocalUser {
+	var users []LocalUser
+	for _, row := range parseTable(output, "Username", "Privilege", "Status") {
+		if row["Username"] == "" {
+			continue
 		}
+		users = append(users, LocalUser{
+			Username:  row["Username"],
+			Privilege: leadingInt(row["Privilege"]),
+			Status:    row["Status"],
+		})
 	}
-	
 	return users
 }
 
@@ -1903,131 +2436,128 @@ func ValidateSN(sn string) bool {
 }
 
 func (z *ZTEC320Client) parseDialPlanProfile(output string) *DialPlanProfile {
-	profile := &DialPlanProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	kv := parseKeyValue(output)
+	profile := &DialPlanProfile{
+		Name:    kv.get("Profile Name"),
+		Timeout: kv.get("Timeout", "Critical Timeout"),
+	}
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
+		if strings.HasPrefix(line, "digit ") || strings.HasPrefix(line, "digitmap ") {
+			profile.Digits = append(profile.Digits, strings.TrimSpace(line[strings.Index(line, " "):]))
 		}
 	}
-	
 	return profile
 }
 
 func (z *ZTEC320Client) parseVoipAccesscodeProfile(output string) *VoipAccesscodeProfile {
-	profile := &VoipAccesscodeProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	profile := &VoipAccesscodeProfile{
+		Name: parseKeyValue(output).get("Profile Name"),
+	}
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
+		if strings.HasPrefix(line, "accesscode ") {
+			profile.Accesscode = append(profile.Accesscode, strings.TrimPrefix(line, "accesscode "))
 		}
 	}
-	
 	return profile
 }
 
 func (z *ZTEC320Client) parseVoipAppsrvProfile(output string) *VoipAppsrvProfile {
-	profile := &VoipAppsrvProfile{}
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
+	profile := &VoipAppsrvProfile{
+		Name: parseKeyValue(output).get("Profile Name"),
+	}
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Profile Name") {
-			profile.Name = z.extractValue(line)
+		if strings.HasPrefix(line, "app ") {
+			profile.Apps = append(profile.Apps, strings.TrimPrefix(line, "app "))
 		}
 	}
-	
 	return profile
 }
 
 func (z *ZTEC320Client) parseSNMPCommunity(output string) []SNMPCommunity {
 	var communities []SNMPCommunity
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Community") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "Community", "Access") {
+		if row["Community"] == "" {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			communities = append(communities, SNMPCommunity{
-				Community: fields[0],
-				Access:    fields[1],
-			})
-		}
+		communities = append(communities, SNMPCommunity{
+			Community: row["Community"],
+			Access:    strings.Fields(row["Access"] + " ")[0],
+		})
 	}
-	
 	return communities
 }
 
 func (z *ZTEC320Client) parseSNMPHost(output string) []SNMPHost {
 	var hosts []SNMPHost
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Host") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "Host", "Port", "Community", "Version") {
+		if row["Host"] == "" {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 1 {
-			hosts = append(hosts, SNMPHost{
-				Host: fields[0],
-			})
-		}
+		hosts = append(hosts, SNMPHost{
+			Host:      row["Host"],
+			Port:      row["Port"],
+			Community: row["Community"],
+			Version:   row["Version"],
+		})
 	}
-	
 	return hosts
 }
 
 func (z *ZTEC320Client) parsePowerSupply(output string) []PowerSupplyInfo {
 	var supplies []PowerSupplyInfo
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Rack") || strings.HasPrefix(line, "---") {
+	for _, row := range parseTable(output, "Rack", "Shelf", "Slot", "Status", "Voltage", "Current") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) >= 4 {
-			rack, _ := strconv.Atoi(fields[0])
-			shelf, _ := strconv.Atoi(fields[1])
-			slot, _ := strconv.Atoi(fields[2])
-			supplies = append(supplies, PowerSupplyInfo{
-				Rack:   rack,
-				Shelf:  shelf,
-				Slot:   slot,
-				Status: fields[3],
-			})
-		}
+		shelf, _ := strconv.Atoi(row["Shelf"])
+		slot, _ := strconv.Atoi(row["Slot"])
+		supplies = append(supplies, PowerSupplyInfo{
+			Rack:    rack,
+			Shelf:   shelf,
+			Slot:    slot,
+			Status:  row["Status"],
+			Voltage: row["Voltage"],
+			Current: row["Current"],
+		})
 	}
-	
 	return supplies
 }
 
 func (z *ZTEC320Client) parseTemperature(output string) []TemperatureInfo {
+	// Format tabel (show temperature)
 	var temps []TemperatureInfo
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Temperature") || strings.Contains(line, "Temp") {
-			// Extract temperature value
-			if strings.Contains(line, ":") {
-				value := z.extractValue(line)
-				temps = append(temps, TemperatureInfo{
-					Temperature: value,
-					Status:      "normal",
-				})
-			}
+	for _, row := range parseTable(output, "Rack", "Shelf", "Slot", "Temperature", "Status") {
+		rack, err := strconv.Atoi(row["Rack"])
+		if err != nil {
+			continue
+		}
+		shelf, _ := strconv.Atoi(row["Shelf"])
+		slot, _ := strconv.Atoi(row["Slot"])
+		temps = append(temps, TemperatureInfo{
+			Rack:        rack,
+			Shelf:       shelf,
+			Slot:        slot,
+			Temperature: row["Temperature"],
+			Status:      row["Status"],
+		})
+	}
+	if len(temps) > 0 {
+		return temps
+	}
+
+	// Fallback format key-value (show fan)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := splitKeyValue(line)
+		if ok && strings.Contains(key, "temp") && !strings.Contains(key, "threshold") {
+			temps = append(temps, TemperatureInfo{
+				Temperature: value,
+				Status:      "normal",
+			})
 		}
 	}
-	
 	return temps
 }
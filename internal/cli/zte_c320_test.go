@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Jalankan `go test ./internal/cli -update` untuk menulis ulang file golden
+// setelah parser sengaja diubah.
+var update = flag.Bool("update", false, "update golden files")
+
+// TestParsers memastikan setiap parser menghasilkan struktur yang sama
+// dengan file golden. Input diambil dari testdata/<name>.txt (output CLI
+// apa adanya), hasil dibandingkan dengan testdata/<name>.golden (JSON).
+func TestParsers(t *testing.T) {
+	z := &ZTEC320Client{}
+
+	tests := []struct {
+		name  string
+		parse func(string) any
+	}{
+		{"show_gpon_onu_uncfg", func(s string) any { return z.parseUncfgONU(s) }},
+		{"show_gpon_onu_state", func(s string) any { return z.parseONUState(s) }},
+		{"show_gpon_onu_baseinfo", func(s string) any { return z.parseONUBaseInfo(s) }},
+		{"show_card", func(s string) any { return z.parseCardInfo(s) }},
+		{"show_card_slotno", func(s string) any { return z.parseCardDetail(s) }},
+		{"show_gpon_profile_tcont", func(s string) any { return z.parseTCONTProfile(s) }},
+		{"show_gpon_profile_tcont_inline", func(s string) any { return z.parseTCONTProfile(s) }},
+		{"show_onu_type_gpon_name", func(s string) any { return z.parseONUType(s) }},
+		{"show_onu_type_gpon", func(s string) any { return z.parseONUTypeList(s) }},
+		{"show_fan", func(s string) any { return z.parseFanInfo(s) }},
+		{"show_version", func(s string) any { return z.parseSystemInfo(s) }},
+		{"show_onu_detail_info", func(s string) any { return z.parseONUDetail(s) }},
+		{"show_gpon_onu_distance", func(s string) any { return z.parseONUDistance(s) }},
+		{"show_onu_traffic", func(s string) any { return z.parseONUTraffic(s) }},
+		{"show_onu_optical_info", func(s string) any { return z.parseONUOptical(s) }},
+		{"show_gpon_remote_onu_interface_eth", func(s string) any { return z.parseONUEthPorts(s) }},
+		{"show_gpon_remote_onu_wan_ip", func(s string) any { return z.parseONUWAN(s) }},
+		{"show_gpon_remote_onu_wifi", func(s string) any { return z.parseONUWiFi(s) }},
+		{"show_onu_running_config", func(s string) any { return ParseRunningConfig(s).ONU(1, 1, 1, 1) }},
+		{"show_pon_power_attenuation", func(s string) any { return parsePowerAttenuation(s) }},
+		{"show_subcard", func(s string) any { return z.parseSubCard(s) }},
+		{"show_rack", func(s string) any { return z.parseRack(s) }},
+		{"show_shelf", func(s string) any { return z.parseShelf(s) }},
+		{"show_gpon_onu_profile_ip", func(s string) any { return z.parseIPProfile(s) }},
+		{"show_gpon_onu_profile_sip", func(s string) any { return z.parseSIPProfile(s) }},
+		{"show_gpon_onu_profile_mgc", func(s string) any { return z.parseMGCProfile(s) }},
+		{"show_gpon_onu_profile_vlan", func(s string) any { return z.parseVlanProfile(s) }},
+		{"show_pon_onu_profile_line_list", func(s string) any { return z.parseProfileList(s) }},
+		{"show_pon_onu_profile_line", func(s string) any { return z.parseLineProfile(s) }},
+		{"show_pon_onu_profile_remote", func(s string) any { return z.parseRemoteProfile(s) }},
+		{"show_vlan", func(s string) any { return z.parseVLANList(s) }},
+		{"show_igmp", func(s string) any { return z.parseIGMPStatus(s) }},
+		{"show_igmp_mvlan", func(s string) any { return z.parseIGMPMVlan(s) }},
+		{"show_igmp_mvlan_id", func(s string) any { return z.parseIGMPMVlanDetail(s) }},
+		{"show_igmp_dynamic_member", func(s string) any { return z.parseIGMPDynamicMember(s) }},
+		{"show_igmp_forwarding_table", func(s string) any { return z.parseIGMPForwardingTable(s) }},
+		{"show_igmp_interface", func(s string) any { return z.parseIGMPInterface(s) }},
+		{"show_interface", func(s string) any { return z.parseInterfaceStats(s) }},
+		{"show_service_port", func(s string) any { return z.parseServicePort(s) }},
+		{"show_users", func(s string) any { return z.parseOnlineUsers(s) }},
+		{"show_username", func(s string) any { return z.parseLocalUsers(s) }},
+		{"show_gpon_onu_profile_dial_plan", func(s string) any { return z.parseDialPlanProfile(s) }},
+		{"show_gpon_onu_profile_voip_accesscode", func(s string) any { return z.parseVoipAccesscodeProfile(s) }},
+		{"show_gpon_onu_profile_voip_appsrv", func(s string) any { return z.parseVoipAppsrvProfile(s) }},
+		{"show_snmp_community", func(s string) any { return z.parseSNMPCommunity(s) }},
+		{"show_snmp_host", func(s string) any { return z.parseSNMPHost(s) }},
+		{"show_power", func(s string) any { return z.parsePowerSupply(s) }},
+		{"show_temperature", func(s string) any { return z.parseTemperature(s) }},
+		{"show_running_config", func(s string) any { return ParseRunningConfig(s) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", tt.name+".txt"))
+			if err != nil {
+				t.Fatalf("read input: %v", err)
+			}
+
+			got, err := json.MarshalIndent(tt.parse(string(input)), "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden: %v (run with -update to create)", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("output mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func TestParseTableEmpty(t *testing.T) {
+	if rows := parseTable("%Code 32310-GPONSRV : No related information to show.", "OnuIndex", "Sn"); rows != nil {
+		t.Errorf("expected nil rows, got %v", rows)
+	}
+}
+
+func TestRunningConfigONU(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "show_running_config.txt"))
+	if err != nil {
+		t.Fatalf("read input: %v", err)
+	}
+	cfg := ParseRunningConfig(string(input))
+
+	onu := cfg.ONU(1, 1, 1, 1)
+	if onu == nil {
+		t.Fatal("expected ONU 1/1/1:1 to be found")
+	}
+	if got := fmt.Sprint(onu.VLANs); got != "[143 200]" {
+		t.Errorf("VLANs = %s, want [143 200]", got)
+	}
+	if onu.Registration == nil || onu.Registration.SN != "ZTEGC8A31F02" {
+		t.Errorf("unexpected registration: %+v", onu.Registration)
+	}
+
+	// Password pw/LOID tetap terbaca untuk drift, tetapi tidak ikut di JSON
+	pw := cfg.ONU(1, 1, 1, 3)
+	if pw == nil || pw.Registration == nil || pw.Registration.Password != "12345678" {
+		t.Errorf("unexpected pw registration: %+v", pw)
+	}
+	if out, _ := json.Marshal(cfg); strings.Contains(string(out), "12345678") {
+		t.Error("ONU password exposed in running-config JSON")
+	}
+
+	if cfg.ONU(1, 1, 1, 99) != nil {
+		t.Error("expected nil for unknown ONU")
+	}
+}
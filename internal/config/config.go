@@ -10,9 +10,17 @@ import (
 
 // Config merepresentasikan konfigurasi aplikasi (Server, Redis, dan daftar OLT).
 type Config struct {
-	Server  ServerConfig  `json:"server"`
-	Redis   RedisConfig   `json:"redis"`
-	OLTs    []OLTConfig   `json:"olts"`
+	Server    ServerConfig    `json:"server"`
+	Redis     RedisConfig     `json:"redis"`
+	Backup    BackupConfig    `json:"backup"`
+	TFTP      TFTPConfig      `json:"tftp"`
+	Auth      AuthConfig      `json:"auth"`
+	Audit     AuditConfig     `json:"audit"`
+	Limits    DeviceLimits    `json:"limits"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Breaker   BreakerConfig   `json:"circuit_breaker"`
+	Monitor   MonitorConfig   `json:"monitor"`
+	OLTs      []OLTConfig     `json:"olts"`
 }
 
 // ServerConfig merepresentasikan konfigurasi server HTTP
@@ -37,6 +45,153 @@ func (c RedisConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// BackupConfig merepresentasikan konfigurasi backup running-config terjadwal
+type BackupConfig struct {
+	Enabled         bool   `json:"enabled"`
+	Dir             string `json:"dir"`              // Direktori penyimpanan (default: data/backups)
+	IntervalMinutes int    `json:"interval_minutes"` // Jarak antar backup (default: 1440 = 1 hari)
+	KeepVersions    int    `json:"keep_versions"`    // Versi terbaru yang selalu disimpan (default: 30)
+	MaxAgeDays      int    `json:"max_age_days"`     // Versi di luar keep_versions lebih tua dari ini dihapus (0 = langsung)
+}
+
+// TFTPConfig merepresentasikan konfigurasi server TFTP internal untuk
+// copy running-config dari/ke OLT. Root server adalah backup store.
+type TFTPConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Listen        string `json:"listen"`         // Alamat UDP (default: :69)
+	AdvertiseHost string `json:"advertise_host"` // Alamat yang dipakai OLT (default: IP lokal koneksi Telnet)
+}
+
+// AuthConfig merepresentasikan konfigurasi autentikasi API
+type AuthConfig struct {
+	UsersFile   string    `json:"users_file"`    // File user dan role (default: config/users.json)
+	APIKeysFile string    `json:"api_keys_file"` // File API key (default: config/api_keys.json)
+	JWT         JWTConfig `json:"jwt"`
+}
+
+// AuditConfig merepresentasikan konfigurasi audit log operasi tulis
+type AuditConfig struct {
+	File string `json:"file"` // File JSON Lines append-only (default: data/audit/audit.jsonl)
+}
+
+// RateLimitConfig merepresentasikan batas request API per caller (API key,
+// user, atau IP). Counter disimpan di Redis jika tersedia agar berlaku
+// bersama di semua replika.
+type RateLimitConfig struct {
+	Enabled        bool         `json:"enabled"`
+	Limit          int          `json:"limit"`           // Request per window per caller (default: 120)
+	WindowSeconds  int          `json:"window_seconds"`  // Default: 60
+	TrustedProxies []string     `json:"trusted_proxies"` // CIDR proxy yang boleh mengirim X-Forwarded-For (default: loopback)
+	Routes         []RouteLimit `json:"routes,omitempty"`
+
+	// Login gagal per IP per window sebelum IP ditolak (default: 10,
+	// negatif = tanpa batas). Berlaku walaupun enabled=false.
+	AuthFailures int `json:"auth_failures"`
+}
+
+// RouteLimit batas tambahan untuk route dengan prefix path tertentu,
+// dihitung terpisah per caller di samping batas global
+type RouteLimit struct {
+	Path          string `json:"path"`                     // Prefix path, mis. /api/v1/query
+	Method        string `json:"method,omitempty"`         // Kosong = semua method
+	Limit         int    `json:"limit"`
+	WindowSeconds int    `json:"window_seconds,omitempty"` // Default: window global
+}
+
+// BreakerConfig merepresentasikan circuit breaker per OLT: setelah timeout
+// SNMP berturut-turut request ke OLT langsung ditolak sampai probe berhasil
+type BreakerConfig struct {
+	FailureThreshold    int `json:"failure_threshold"`     // Timeout berturut-turut sebelum circuit terbuka (default: 3)
+	OpenSeconds         int `json:"open_seconds"`          // Lama circuit terbuka sebelum probe sysUpTime (default: 30)
+	ProbeTimeoutSeconds int `json:"probe_timeout_seconds"` // Default: 2
+}
+
+// MonitorConfig merepresentasikan pemantauan status ONU di background untuk
+// riwayat online/offline dan skor stabilitas
+type MonitorConfig struct {
+	Enabled         bool         `json:"enabled"`
+	IntervalSeconds int          `json:"interval_seconds"` // Jarak antar poll status per OLT (default: 60)
+	File            string       `json:"file"`             // Riwayat perubahan status JSON Lines (default: data/monitor/onu_events.jsonl)
+	RetentionDays   int          `json:"retention_days"`   // Default: 30
+	Outage          OutageConfig `json:"outage"`
+}
+
+// OutageConfig merepresentasikan deteksi gangguan massal: ONU yang turun
+// hampir bersamaan digabung menjadi satu insiden
+type OutageConfig struct {
+	WindowSeconds int     `json:"window_seconds"` // ONU turun dalam rentang ini dianggap serentak (default: 180)
+	MinONUs       int     `json:"min_onus"`       // ONU turun minimum per PON (default: 4)
+	MinRatio      float64 `json:"min_ratio"`      // Porsi ONU online di PON yang turun (default: 0.5)
+	ResolveRatio  float64 `json:"resolve_ratio"`  // Porsi ONU terdampak yang online kembali agar insiden selesai (default: 0.8)
+	File          string  `json:"file"`           // Default: data/monitor/incidents.json
+}
+
+// DeviceLimits batas beban per OLT untuk melindungi CPU perangkat. Sesi
+// SNMP adalah satu operasi driver (mis. satu list ONU) dari connect sampai
+// close. Nilai 0 pada override per OLT berarti memakai nilai global.
+type DeviceLimits struct {
+	MaxConcurrentSNMP     int     `json:"max_concurrent_snmp,omitempty"`      // Default: 4
+	SNMPSessionsPerSecond float64 `json:"snmp_sessions_per_second,omitempty"` // Sesi SNMP baru per detik, bukan PDU (default: 10)
+	SNMPBurst             int     `json:"snmp_burst,omitempty"`               // Sesi SNMP baru beruntun (default: 20)
+
+	// Deprecated: nama lama snmp_sessions_per_second, hanya dibaca
+	SNMPRequestsPerSecond float64 `json:"snmp_requests_per_second,omitempty"`
+	MaxTelnetSessions     int     `json:"max_telnet_sessions,omitempty"`      // Default: 2
+	QueueTimeoutSeconds   int     `json:"queue_timeout_seconds,omitempty"`    // Lama maksimal antre (default: 10)
+	MaxQueue              int     `json:"max_queue,omitempty"`                // Request yang boleh antre per OLT (default: 50)
+}
+
+// merge mengisi field kosong dari fallback
+func (l DeviceLimits) merge(fallback DeviceLimits) DeviceLimits {
+	if l.MaxConcurrentSNMP == 0 {
+		l.MaxConcurrentSNMP = fallback.MaxConcurrentSNMP
+	}
+	if l.SNMPSessionsPerSecond == 0 {
+		l.SNMPSessionsPerSecond = l.SNMPRequestsPerSecond
+	}
+	l.SNMPRequestsPerSecond = 0
+	if l.SNMPSessionsPerSecond == 0 {
+		l.SNMPSessionsPerSecond = fallback.SNMPSessionsPerSecond
+	}
+	if l.SNMPBurst == 0 {
+		l.SNMPBurst = fallback.SNMPBurst
+	}
+	if l.MaxTelnetSessions == 0 {
+		l.MaxTelnetSessions = fallback.MaxTelnetSessions
+	}
+	if l.QueueTimeoutSeconds == 0 {
+		l.QueueTimeoutSeconds = fallback.QueueTimeoutSeconds
+	}
+	if l.MaxQueue == 0 {
+		l.MaxQueue = fallback.MaxQueue
+	}
+	return l
+}
+
+// defaultDeviceLimits batas bawaan jika tidak diatur di konfigurasi
+var defaultDeviceLimits = DeviceLimits{
+	MaxConcurrentSNMP:     4,
+	SNMPSessionsPerSecond: 10,
+	SNMPBurst:             20,
+	MaxTelnetSessions:     2,
+	QueueTimeoutSeconds:   10,
+	MaxQueue:              50,
+}
+
+// JWTConfig merepresentasikan verifikasi JWT bearer token dari identity
+// provider eksternal. HS256 memakai hs256_secret, RS256 memakai jwks_file.
+type JWTConfig struct {
+	Enabled       bool   `json:"enabled"`
+	HS256Secret   string `json:"hs256_secret,omitempty"`
+	JWKSFile      string `json:"jwks_file,omitempty"`
+	Issuer        string `json:"issuer,omitempty"`         // Wajib sama dengan claim iss jika diisi
+	Audience      string `json:"audience,omitempty"`       // Wajib ada di claim aud jika diisi
+	UsernameClaim string `json:"username_claim,omitempty"` // Default: sub
+	RoleClaim     string `json:"role_claim,omitempty"`     // Default: role
+	OLTsClaim     string `json:"olts_claim,omitempty"`     // Default: olts
+	LeewaySeconds int    `json:"leeway_seconds,omitempty"` // Toleransi selisih jam untuk exp/nbf (default: 60)
+}
+
 // OLTConfig merepresentasikan konfigurasi perangkat OLT
 type OLTConfig struct {
 	ID          string `json:"id"`
@@ -47,6 +202,15 @@ type OLTConfig struct {
 	Community   string `json:"community"`
 	BoardCount  int    `json:"board_count"`
 	PonPerBoard int    `json:"pon_per_board"`
+
+	// Kredensial Telnet, dipakai oleh fitur yang berjalan tanpa request
+	// (misal backup terjadwal). Kosong = default client CLI.
+	CLIPort     int    `json:"cli_port,omitempty"`
+	CLIUsername string `json:"cli_username,omitempty"`
+	CLIPassword string `json:"cli_password,omitempty"`
+
+	// Limits override batas beban global untuk OLT ini
+	Limits *DeviceLimits `json:"limits,omitempty"`
 }
 
 var (
@@ -96,6 +260,73 @@ func loadConfig() (*Config, error) {
 	if cfg.Server.Port == 0 {
 		cfg.Server.Port = 8080
 	}
+	if cfg.Backup.Dir == "" {
+		cfg.Backup.Dir = "data/backups"
+	}
+	if cfg.Backup.IntervalMinutes == 0 {
+		cfg.Backup.IntervalMinutes = 1440
+	}
+	if cfg.Backup.KeepVersions == 0 {
+		cfg.Backup.KeepVersions = 30
+	}
+	if cfg.TFTP.Listen == "" {
+		cfg.TFTP.Listen = ":69"
+	}
+	if cfg.Auth.UsersFile == "" {
+		cfg.Auth.UsersFile = "config/users.json"
+	}
+	if cfg.Auth.APIKeysFile == "" {
+		cfg.Auth.APIKeysFile = "config/api_keys.json"
+	}
+	if cfg.Audit.File == "" {
+		cfg.Audit.File = "data/audit/audit.jsonl"
+	}
+	cfg.Limits = cfg.Limits.merge(defaultDeviceLimits)
+	if cfg.RateLimit.Limit == 0 {
+		cfg.RateLimit.Limit = 120
+	}
+	if cfg.RateLimit.WindowSeconds == 0 {
+		cfg.RateLimit.WindowSeconds = 60
+	}
+	if cfg.RateLimit.AuthFailures == 0 {
+		cfg.RateLimit.AuthFailures = 10
+	}
+	if cfg.RateLimit.TrustedProxies == nil {
+		cfg.RateLimit.TrustedProxies = []string{"127.0.0.1/32", "::1/128"}
+	}
+	if cfg.Breaker.FailureThreshold == 0 {
+		cfg.Breaker.FailureThreshold = 3
+	}
+	if cfg.Breaker.OpenSeconds == 0 {
+		cfg.Breaker.OpenSeconds = 30
+	}
+	if cfg.Breaker.ProbeTimeoutSeconds == 0 {
+		cfg.Breaker.ProbeTimeoutSeconds = 2
+	}
+	if cfg.Monitor.IntervalSeconds == 0 {
+		cfg.Monitor.IntervalSeconds = 60
+	}
+	if cfg.Monitor.File == "" {
+		cfg.Monitor.File = "data/monitor/onu_events.jsonl"
+	}
+	if cfg.Monitor.RetentionDays == 0 {
+		cfg.Monitor.RetentionDays = 30
+	}
+	if cfg.Monitor.Outage.WindowSeconds == 0 {
+		cfg.Monitor.Outage.WindowSeconds = 180
+	}
+	if cfg.Monitor.Outage.MinONUs == 0 {
+		cfg.Monitor.Outage.MinONUs = 4
+	}
+	if cfg.Monitor.Outage.MinRatio == 0 {
+		cfg.Monitor.Outage.MinRatio = 0.5
+	}
+	if cfg.Monitor.Outage.ResolveRatio == 0 {
+		cfg.Monitor.Outage.ResolveRatio = 0.8
+	}
+	if cfg.Monitor.Outage.File == "" {
+		cfg.Monitor.Outage.File = "data/monitor/incidents.json"
+	}
 
 	return &cfg, nil
 }
@@ -111,6 +342,45 @@ func createDefaultConfig() (*Config, error) {
 			Port: 6379,
 			DB:   0,
 		},
+		Backup: BackupConfig{
+			Dir:             "data/backups",
+			IntervalMinutes: 1440,
+			KeepVersions:    30,
+		},
+		TFTP: TFTPConfig{
+			Listen: ":69",
+		},
+		Auth: AuthConfig{
+			UsersFile:   "config/users.json",
+			APIKeysFile: "config/api_keys.json",
+		},
+		Audit: AuditConfig{
+			File: "data/audit/audit.jsonl",
+		},
+		Limits: defaultDeviceLimits,
+		RateLimit: RateLimitConfig{
+			Limit:          120,
+			WindowSeconds:  60,
+			TrustedProxies: []string{"127.0.0.1/32", "::1/128"},
+			AuthFailures:   10,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold:    3,
+			OpenSeconds:         30,
+			ProbeTimeoutSeconds: 2,
+		},
+		Monitor: MonitorConfig{
+			IntervalSeconds: 60,
+			File:            "data/monitor/onu_events.jsonl",
+			RetentionDays:   30,
+			Outage: OutageConfig{
+				WindowSeconds: 180,
+				MinONUs:       4,
+				MinRatio:      0.5,
+				ResolveRatio:  0.8,
+				File:          "data/monitor/incidents.json",
+			},
+		},
 		OLTs: []OLTConfig{},
 	}
 
@@ -158,6 +428,27 @@ func (c *Config) GetOLT(id string) (*OLTConfig, error) {
 	return nil, fmt.Errorf("OLT not found: %s", id)
 }
 
+// FindOLTByIP mengembalikan konfigurasi OLT berdasarkan alamat IP
+func (c *Config) FindOLTByIP(ip string) (*OLTConfig, error) {
+	for _, olt := range c.OLTs {
+		if olt.IPAddress == ip {
+			return &olt, nil
+		}
+	}
+	return nil, fmt.Errorf("OLT not found: %s", ip)
+}
+
+// DeviceLimitsFor mengembalikan batas beban untuk host: override OLT yang
+// terdaftar dengan IP tersebut, sisanya dari batas global.
+func (c *Config) DeviceLimitsFor(host string) DeviceLimits {
+	for _, olt := range c.OLTs {
+		if olt.IPAddress == host && olt.Limits != nil {
+			return olt.Limits.merge(c.Limits)
+		}
+	}
+	return c.Limits
+}
+
 // AddOLT menambah konfigurasi OLT baru
 func (c *Config) AddOLT(olt OLTConfig) error {
 	// Periksa apakah ID sudah ada
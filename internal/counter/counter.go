@@ -0,0 +1,138 @@
+// Package counter menghitung laju (bit/detik, paket/detik) dari counter
+// SNMP kumulatif. Sampel terakhir per counter disimpan di Redis agar semua
+// replika memakai baseline yang sama, atau di memori untuk satu instance.
+package counter
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+const (
+	// DefaultTTL lama sampel disimpan. Poll yang lebih jarang dari ini
+	// dianggap sampel pertama; Counter32 bisa wrap lebih dari sekali.
+	DefaultTTL = 15 * time.Minute
+
+	// MinInterval jarak minimum antar sampel. Poll yang lebih rapat tidak
+	// menghasilkan laju karena counter OLT diperbarui per beberapa detik.
+	MinInterval = time.Second
+
+	// uptimeSlack toleransi selisih jam antar replika saat membandingkan
+	// sysUpTime dengan waktu nyata
+	uptimeSlack = 5 * time.Second
+)
+
+// Sample satu pembacaan counter
+type Sample struct {
+	At     time.Time         `json:"at"`
+	Uptime uint32            `json:"uptime,omitempty"` // sysUpTime OLT (1/100 detik), 0 jika tidak diketahui
+	Bits   int               `json:"bits"`             // 32 (Counter32) atau 64 (Counter64)
+	Values map[string]uint64 `json:"values"`
+}
+
+// Store menyimpan sampel terakhir per key
+type Store interface {
+	// Swap menyimpan s dan mengembalikan sampel sebelumnya (nil jika
+	// belum ada atau sudah kedaluwarsa).
+	Swap(ctx context.Context, key string, s Sample, ttl time.Duration) (*Sample, error)
+}
+
+// Meter menghitung laju dari sampel berturut-turut
+type Meter struct {
+	store Store
+	ttl   time.Duration
+}
+
+// NewMeter membuat meter dengan store sampel
+func NewMeter(store Store, ttl time.Duration) *Meter {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Meter{store: store, ttl: ttl}
+}
+
+// Rates menyimpan s sebagai sampel terbaru dan mengembalikan laju per detik
+// setiap nilai dibanding sampel sebelumnya. Hasilnya nil jika belum ada
+// sampel sebelumnya, jaraknya terlalu dekat, atau OLT reboot di antaranya.
+func (m *Meter) Rates(ctx context.Context, key string, s Sample) (map[string]float64, time.Duration, error) {
+	prev, err := m.store.Swap(ctx, key, s, m.ttl)
+	if err != nil || prev == nil {
+		return nil, 0, err
+	}
+	interval := s.At.Sub(prev.At)
+	if interval < MinInterval || rebooted(*prev, s, interval) {
+		return nil, 0, nil
+	}
+
+	rates := make(map[string]float64, len(s.Values))
+	for name, cur := range s.Values {
+		old, ok := prev.Values[name]
+		if !ok {
+			continue
+		}
+		delta, ok := Delta(old, cur, s.Bits)
+		if !ok {
+			// Counter di-reset (misal ONU register ulang): tidak ada laju
+			return nil, 0, nil
+		}
+		rates[name] = float64(delta) / interval.Seconds()
+	}
+	return rates, interval, nil
+}
+
+// Traffic menghitung laju bit dan paket dari counter octet dan paket.
+// Hasilnya nil selama belum ada laju yang valid.
+func (m *Meter) Traffic(ctx context.Context, key string, meta model.CounterSample, rxBytes, txBytes, rxPkts, txPkts int64) (*model.TrafficRate, error) {
+	rates, interval, err := m.Rates(ctx, key, Sample{
+		At:     meta.At,
+		Uptime: meta.Uptime,
+		Bits:   meta.Bits,
+		Values: map[string]uint64{
+			"rx_bytes":   uint64(rxBytes),
+			"tx_bytes":   uint64(txBytes),
+			"rx_packets": uint64(rxPkts),
+			"tx_packets": uint64(txPkts),
+		},
+	})
+	if err != nil || rates == nil {
+		return nil, err
+	}
+	return &model.TrafficRate{
+		RxBps:    rates["rx_bytes"] * 8,
+		TxBps:    rates["tx_bytes"] * 8,
+		RxPps:    rates["rx_packets"],
+		TxPps:    rates["tx_packets"],
+		Interval: interval.Seconds(),
+	}, nil
+}
+
+// Delta selisih counter dari prev ke cur. Counter32 yang lebih kecil dari
+// sebelumnya dianggap wrap sekali melewati 2^32. Counter64 tidak wrap dalam
+// praktik, jadi penurunan berarti counter di-reset (ok false).
+func Delta(prev, cur uint64, bits int) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if bits == 32 && prev <= math.MaxUint32 {
+		return cur + (math.MaxUint32 + 1) - prev, true
+	}
+	return 0, false
+}
+
+// rebooted true jika sysUpTime turun, atau naik jauh lebih sedikit dari
+// waktu yang berlalu (OLT reboot lalu hidup lagi di antara dua poll).
+// sysUpTime yang wrap setelah 497 hari juga terbaca sebagai reboot sehingga
+// satu poll tidak menghasilkan laju.
+func rebooted(prev, cur Sample, interval time.Duration) bool {
+	if prev.Uptime == 0 || cur.Uptime == 0 {
+		return false
+	}
+	if cur.Uptime < prev.Uptime {
+		return true
+	}
+	up := time.Duration(cur.Uptime-prev.Uptime) * 10 * time.Millisecond
+	return up+uptimeSlack < interval
+}
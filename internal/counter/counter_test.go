@@ -0,0 +1,98 @@
+package counter
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		bits      int
+		want      uint64
+		ok        bool
+	}{
+		{"naik", 100, 250, 32, 150, true},
+		{"tetap", 100, 100, 64, 0, true},
+		{"counter32 wrap", math.MaxUint32 - 9, 5, 32, 15, true},
+		{"counter64 turun berarti reset", 1 << 40, 5, 64, 0, false},
+		{"nilai di atas 32 bit bukan wrap counter32", 1 << 40, 5, 32, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Delta(tt.prev, tt.cur, tt.bits)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Delta(%d, %d, %d) = %d, %v; want %d, %v", tt.prev, tt.cur, tt.bits, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sample := func(after time.Duration, uptime uint32) model.CounterSample {
+		return model.CounterSample{At: start.Add(after), Uptime: uptime, Bits: 32}
+	}
+
+	m := NewMeter(NewMemoryStore(), 0)
+	ctx := context.Background()
+
+	// Sampel pertama belum punya laju
+	rate, err := m.Traffic(ctx, "olt:pon:1/1", sample(0, 100000), 1000, 2000, 10, 20)
+	if err != nil || rate != nil {
+		t.Fatalf("sampel pertama: rate=%+v err=%v, want nil", rate, err)
+	}
+
+	// 10 detik kemudian: +12500 byte RX = 10000 bps
+	rate, err = m.Traffic(ctx, "olt:pon:1/1", sample(10*time.Second, 101000), 13500, 2000, 110, 20)
+	if err != nil || rate == nil {
+		t.Fatalf("sampel kedua: rate=%+v err=%v", rate, err)
+	}
+	want := model.TrafficRate{RxBps: 10000, TxBps: 0, RxPps: 10, TxPps: 0, Interval: 10}
+	if *rate != want {
+		t.Errorf("rate = %+v, want %+v", *rate, want)
+	}
+
+	// Counter32 wrap tetap menghasilkan laju positif
+	rate, _ = m.Traffic(ctx, "olt:pon:1/1", sample(20*time.Second, 102000), 3500, 2000, 110, 20)
+	if rate == nil || rate.RxBps != float64(math.MaxUint32+1-10000)*8/10 {
+		t.Errorf("wrap: rate = %+v", rate)
+	}
+
+	// sysUpTime turun: OLT reboot, counter mulai dari nol
+	rate, _ = m.Traffic(ctx, "olt:pon:1/1", sample(30*time.Second, 500), 100, 100, 1, 1)
+	if rate != nil {
+		t.Errorf("reboot (uptime turun): rate = %+v, want nil", rate)
+	}
+
+	// Reboot di antara poll: uptime hanya naik 20 detik dalam 5 menit
+	rate, _ = m.Traffic(ctx, "olt:pon:1/1", sample(330*time.Second, 2500), 200, 200, 2, 2)
+	if rate != nil {
+		t.Errorf("reboot (uptime tertinggal): rate = %+v, want nil", rate)
+	}
+
+	// Poll terlalu rapat tidak menghasilkan laju
+	rate, _ = m.Traffic(ctx, "olt:pon:1/1", sample(330*time.Second+100*time.Millisecond, 2510), 300, 300, 3, 3)
+	if rate != nil {
+		t.Errorf("interval < MinInterval: rate = %+v, want nil", rate)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Swap(ctx, "k", Sample{At: now}, time.Minute)
+	now = now.Add(2 * time.Minute)
+	prev, err := s.Swap(ctx, "k", Sample{At: now}, time.Minute)
+	if err != nil || prev != nil {
+		t.Errorf("sampel kedaluwarsa: prev=%+v err=%v, want nil", prev, err)
+	}
+}
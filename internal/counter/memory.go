@@ -0,0 +1,57 @@
+package counter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan sampel di memori proses. Setiap instance API punya
+// baseline sendiri.
+type MemoryStore struct {
+	mu      sync.Mutex
+	samples map[string]memorySample
+	calls   int
+	now     func() time.Time
+}
+
+type memorySample struct {
+	sample  Sample
+	expires time.Time
+}
+
+// NewMemoryStore membuat store sampel di memori
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		samples: make(map[string]memorySample),
+		now:     time.Now,
+	}
+}
+
+// Swap mengimplementasikan Store
+func (s *MemoryStore) Swap(ctx context.Context, key string, sample Sample, ttl time.Duration) (*Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var prev *Sample
+	if old, ok := s.samples[key]; ok && now.Before(old.expires) {
+		prev = &old.sample
+	}
+	s.samples[key] = memorySample{sample: sample, expires: now.Add(ttl)}
+
+	s.calls++
+	if s.calls%1000 == 0 {
+		s.sweep(now)
+	}
+	return prev, nil
+}
+
+// sweep menghapus sampel yang sudah kedaluwarsa
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, old := range s.samples {
+		if !now.Before(old.expires) {
+			delete(s.samples, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package counter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore menyimpan sampel di Redis sehingga semua replika API memakai
+// baseline yang sama
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore membuat store sampel Redis
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, prefix: "counter:"}
+}
+
+// Swap mengimplementasikan Store. SET ... GET bersifat atomik sehingga dua
+// poll bersamaan tidak memakai sampel sebelumnya yang sama.
+func (s *RedisStore) Swap(ctx context.Context, key string, sample Sample, ttl time.Duration) (*Sample, error) {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.client.SetArgs(ctx, s.prefix+key, data, redis.SetArgs{TTL: ttl, Get: true}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("counter sample swap failed: %w", err)
+	}
+	var prev Sample
+	if err := json.Unmarshal([]byte(old), &prev); err != nil {
+		// Format lama atau rusak: anggap belum ada sampel
+		return nil, nil
+	}
+	return &prev, nil
+}
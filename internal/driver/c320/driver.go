@@ -2,23 +2,28 @@ package c320
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ardani/snmp-zte/internal/audit"
+	"github.com/ardani/snmp-zte/internal/breaker"
 	"github.com/ardani/snmp-zte/internal/driver"
 	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/snmp"
 	"github.com/gosnmp/gosnmp"
 )
 
 // Driver mengimplementasikan driver.Driver untuk ZTE C320.
+// Setiap operasi membuka koneksi SNMP sendiri yang terikat ke ctx request,
+// sehingga Driver aman dipakai bersamaan oleh beberapa request.
 type Driver struct {
-	client    *gosnmp.GoSNMP
 	snmpHost  string
 	snmpPort  uint16
 	community string
-	connected bool
+	breaker   *breaker.Breaker
 }
 
 // New membuat instance driver C320 baru.
@@ -30,6 +35,12 @@ func New(host string, port uint16, community string) *Driver {
 	}
 }
 
+// SetBreaker memasang circuit breaker OLT. Setiap request SNMP dicatat ke
+// circuit dan operasi berhenti begitu circuit terbuka.
+func (d *Driver) SetBreaker(b *breaker.Breaker) {
+	d.breaker = b
+}
+
 // GetModelName mengembalikan nama model.
 func (d *Driver) GetModelName() string {
 	return "C320"
@@ -40,9 +51,27 @@ func (d *Driver) GetModelInfo() driver.ModelInfo {
 	return ModelInfo()
 }
 
-// Connect membuka koneksi SNMP ke OLT.
+// Connect memeriksa konfigurasi koneksi SNMP ke OLT. Koneksi sebenarnya
+// dibuka per operasi.
 func (d *Driver) Connect() error {
-	d.client = &gosnmp.GoSNMP{
+	client := d.newClient()
+	if err := client.Connect(); err != nil {
+		return fmt.Errorf("SNMP connect failed: %w", err)
+	}
+	return client.Conn.Close()
+}
+
+// Close tidak melakukan apa-apa; setiap operasi menutup koneksinya sendiri.
+func (d *Driver) Close() error {
+	return nil
+}
+
+// getBatch jumlah OID per GET multi-OID, agar respons tetap muat dalam satu
+// datagram UDP
+const getBatch = 32
+
+func (d *Driver) newClient() *gosnmp.GoSNMP {
+	return &gosnmp.GoSNMP{
 		Target:    d.snmpHost,
 		Port:      d.snmpPort,
 		Community: d.community,
@@ -51,20 +80,35 @@ func (d *Driver) Connect() error {
 		Retries:   2,               // Coba lagi 2 kali jika gagal
 		MaxOids:   60,
 	}
+}
 
-	if err := d.client.Connect(); err != nil {
+// Probe memeriksa OLT menjawab SNMP dengan satu GET sysUpTime tanpa retry,
+// memakai koneksi sendiri dengan batas waktu dari ctx.
+func (d *Driver) Probe(ctx context.Context) error {
+	timeout := 2 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	client := &gosnmp.GoSNMP{
+		Target:    d.snmpHost,
+		Port:      d.snmpPort,
+		Community: d.community,
+		Version:   gosnmp.Version2c,
+		Timeout:   timeout,
+		Retries:   0,
+		Context:   ctx,
+	}
+	if err := client.Connect(); err != nil {
 		return fmt.Errorf("SNMP connect failed: %w", err)
 	}
+	defer client.Conn.Close()
 
-	d.connected = true
-	return nil
-}
-
-// Close menutup koneksi SNMP.
-func (d *Driver) Close() error {
-	if d.client != nil && d.client.Conn != nil {
-		d.connected = false
-		return d.client.Conn.Close()
+	result, err := client.Get([]string{SysUptimeOID})
+	if err != nil {
+		return fmt.Errorf("sysUpTime probe failed: %w", err)
+	}
+	if len(result.Variables) == 0 || result.Variables[0].Type == gosnmp.NoSuchObject {
+		return fmt.Errorf("no result for OID: %s", SysUptimeOID)
 	}
 	return nil
 }
@@ -86,11 +130,11 @@ func (d *Driver) ValidateOnuID(onuID int) bool {
 
 // GetONUList mengambil daftar ONU untuk Board/PON tertentu.
 func (d *Driver) GetONUList(ctx context.Context, boardID, ponID int) ([]model.ONUInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	cfg := GenerateBoardPonOID(boardID, ponID)
 	
@@ -100,7 +144,7 @@ func (d *Driver) GetONUList(ctx context.Context, boardID, ponID int) ([]model.ON
 	// 1. SNMP Walk untuk mendapatkan daftar Nama & ID ONU yang aktif di port tersebut.
 	// OID di sini spesifik untuk ZTE, digabung dengan ID Board & PON.
 	oid := BaseOID1 + cfg.OnuIDNameOID
-	err := d.client.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(oid, func(pdu gosnmp.SnmpPDU) error {
 		// Dari Nama OID yang didapat, kita ambil angka terakhirnya sebagai ID ONU.
 		onuID := extractOnuID(pdu.Name)
 		if onuID == 0 {
@@ -126,48 +170,53 @@ func (d *Driver) GetONUList(ctx context.Context, boardID, ponID int) ([]model.ON
 		onuIDStr := strconv.Itoa(onuID)
 		
 		// Ambil Tipe ONU
-		if val, err := d.snmpGet(BaseOID2 + cfg.OnuTypeOID + "." + onuIDStr); err == nil {
+		if val, err := sess.get(BaseOID2 + cfg.OnuTypeOID + "." + onuIDStr); err == nil {
 			info.Type = extractString(val)
 		}
 
 		// Ambil Serial Number
-		if val, err := d.snmpGet(BaseOID1 + cfg.OnuSerialNumberOID + "." + onuIDStr); err == nil {
+		if val, err := sess.get(BaseOID1 + cfg.OnuSerialNumberOID + "." + onuIDStr); err == nil {
 			info.SerialNumber = extractSerialNumber(val)
 		}
 
 		// Ambil Kekuatan Sinyal (RX Power)
-		if val, err := d.snmpGet(BaseOID1 + cfg.OnuRxPowerOID + "." + onuIDStr + ".1"); err == nil {
+		if val, err := sess.get(BaseOID1 + cfg.OnuRxPowerOID + "." + onuIDStr + ".1"); err == nil {
 			info.RXPower = convertPower(val)
 		}
 
 		// Ambil Kekuatan Sinyal (TX Power)
-		if val, err := d.snmpGet(BaseOID2 + cfg.OnuTxPowerOID + "." + onuIDStr + ".1"); err == nil {
+		if val, err := sess.get(BaseOID2 + cfg.OnuTxPowerOID + "." + onuIDStr + ".1"); err == nil {
 			info.TXPower = convertPower(val)
 		}
 
+		// Ambil daya optik yang diterima OLT dari ONU (upstream)
+		if val, err := sess.get(oltRxPowerOID(boardID, ponID, onuID)); err == nil {
+			info.OLTRxPower = formatOLTRxPower(val)
+		}
+
 		// Ambil Jarak (Distance)
-		if val, err := d.snmpGet(BaseOID1 + cfg.OnuGponOpticalDistanceOID + "." + onuIDStr); err == nil {
+		if val, err := sess.get(BaseOID1 + cfg.OnuGponOpticalDistanceOID + "." + onuIDStr); err == nil {
 			info.Distance = fmt.Sprintf("%v", val)
 		}
 
 		// Ambil Status (Online/Offline)
-		if val, err := d.snmpGet(BaseOID1 + cfg.OnuStatusOID + "." + onuIDStr); err == nil {
+		if val, err := sess.get(BaseOID1 + cfg.OnuStatusOID + "." + onuIDStr); err == nil {
 			info.Status = convertStatus(val)
 		}
 
 		onuList = append(onuList, *info)
 	}
 
-	return onuList, nil
+	return onuList, sess.err()
 }
 
 // GetONUDetail mengambil informasi detail untuk satu ONU tunggal.
 func (d *Driver) GetONUDetail(ctx context.Context, boardID, ponID, onuID int) (*model.ONUDetail, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	cfg := GenerateBoardPonOID(boardID, ponID)
 	onuIDStr := strconv.Itoa(onuID)
@@ -181,80 +230,94 @@ func (d *Driver) GetONUDetail(ctx context.Context, boardID, ponID, onuID int) (*
 	}
 
 	// Ambil Nama
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuIDNameOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuIDNameOID + "." + onuIDStr); err == nil {
 		detail.Name = extractString(val)
 	}
 
 	// Ambil Tipe
-	if val, err := d.snmpGet(BaseOID2 + cfg.OnuTypeOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID2 + cfg.OnuTypeOID + "." + onuIDStr); err == nil {
 		detail.Type = extractString(val)
 	}
 
 	// Ambil Serial Number
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuSerialNumberOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuSerialNumberOID + "." + onuIDStr); err == nil {
 		detail.SerialNumber = extractSerialNumber(val)
 	}
 
 	// Ambil Sinyal RX
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuRxPowerOID + "." + onuIDStr + ".1"); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuRxPowerOID + "." + onuIDStr + ".1"); err == nil {
 		detail.RXPower = convertPower(val)
 	}
 
 	// Ambil Sinyal TX
-	if val, err := d.snmpGet(BaseOID2 + cfg.OnuTxPowerOID + "." + onuIDStr + ".1"); err == nil {
+	if val, err := sess.get(BaseOID2 + cfg.OnuTxPowerOID + "." + onuIDStr + ".1"); err == nil {
 		detail.TXPower = convertPower(val)
 	}
 
+	// Ambil Sinyal RX di sisi OLT
+	if val, err := sess.get(oltRxPowerOID(boardID, ponID, onuID)); err == nil {
+		detail.OLTRxPower = formatOLTRxPower(val)
+	}
+
 	// Ambil Status
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuStatusOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuStatusOID + "." + onuIDStr); err == nil {
 		detail.Status = convertStatus(val)
 	}
 
 	// Ambil Alamat IP
-	if val, err := d.snmpGet(BaseOID2 + cfg.OnuIPAddressOID + "." + onuIDStr + ".1"); err == nil {
+	if val, err := sess.get(BaseOID2 + cfg.OnuIPAddressOID + "." + onuIDStr + ".1"); err == nil {
 		detail.IPAddress = extractString(val)
 	}
 
 	// Ambil Deskripsi
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuDescriptionOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuDescriptionOID + "." + onuIDStr); err == nil {
 		detail.Description = extractString(val)
 	}
 
 	// Ambil Waktu Terakhir Online
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuLastOnlineOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuLastOnlineOID + "." + onuIDStr); err == nil {
 		detail.LastOnline = convertDateTime(val)
 	}
 
 	// Ambil Waktu Terakhir Offline
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuLastOfflineOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuLastOfflineOID + "." + onuIDStr); err == nil {
 		detail.LastOffline = convertDateTime(val)
 	}
 
 	// Ambil Alasan Offline
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuLastOfflineReasonOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuLastOfflineReasonOID + "." + onuIDStr); err == nil {
 		detail.OfflineReason = convertOfflineReason(val)
 	}
 
 	// Ambil Jarak
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuGponOpticalDistanceOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuGponOpticalDistanceOID + "." + onuIDStr); err == nil {
 		detail.Distance = fmt.Sprintf("%v", val)
 	}
 
-	// Hitung Uptime
-	if detail.LastOnline != "" {
-		detail.Uptime = calculateUptime(detail.LastOnline)
+	// Hitung Uptime dan lama down terakhir terhadap jam OLT (hrSystemDate).
+	// Jika OLT tidak mendukungnya dipakai jam server, dengan asumsi zona
+	// waktu OLT sama dengan server.
+	now := time.Now()
+	if val, err := sess.get(HrSystemDateOID); err == nil {
+		if t, ok := parseDateAndTime(val); ok {
+			now = t
+		}
+	}
+	if detail.Status == model.StatusOnline.String() {
+		detail.Uptime = calculateUptime(detail.LastOnline, now)
 	}
+	detail.LastDownTimeDuration = calculateDownDuration(detail.LastOffline, detail.LastOnline)
 
-	return detail, nil
+	return detail, sess.err()
 }
 
 // GetEmptySlots mengambil slot ONU yang masih kosong.
 func (d *Driver) GetEmptySlots(ctx context.Context, boardID, ponID int) ([]model.ONUSlot, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	cfg := GenerateBoardPonOID(boardID, ponID)
 	
@@ -262,7 +325,7 @@ func (d *Driver) GetEmptySlots(ctx context.Context, boardID, ponID int) ([]model
 	usedIDs := make(map[int]bool)
 	
 	oid := BaseOID1 + cfg.OnuIDNameOID
-	err := d.client.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(oid, func(pdu gosnmp.SnmpPDU) error {
 		onuID := extractOnuID(pdu.Name)
 		if onuID > 0 {
 			usedIDs[onuID] = true
@@ -286,54 +349,54 @@ func (d *Driver) GetEmptySlots(ctx context.Context, boardID, ponID int) ([]model
 		}
 	}
 
-	return emptySlots, nil
+	return emptySlots, sess.err()
 }
 
 // GetSystemInfo mengambil informasi sistem OLT.
 func (d *Driver) GetSystemInfo(ctx context.Context) (*driver.SystemInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	info := &driver.SystemInfo{}
 
 	// Deskripsi Sistem
-	if val, err := d.snmpGet("1.3.6.1.2.1.1.1.0"); err == nil {
+	if val, err := sess.get("1.3.6.1.2.1.1.1.0"); err == nil {
 		info.Description = extractString(val)
 	}
 
 	// Nama Sistem
-	if val, err := d.snmpGet("1.3.6.1.2.1.1.5.0"); err == nil {
+	if val, err := sess.get("1.3.6.1.2.1.1.5.0"); err == nil {
 		info.Name = extractString(val)
 	}
 
 	// Uptime Sistem
-	if val, err := d.snmpGet("1.3.6.1.2.1.1.3.0"); err == nil {
+	if val, err := sess.get("1.3.6.1.2.1.1.3.0"); err == nil {
 		info.Uptime = fmt.Sprintf("%v", val)
 	}
 
 	// Kontak Sistem
-	if val, err := d.snmpGet("1.3.6.1.2.1.1.4.0"); err == nil {
+	if val, err := sess.get("1.3.6.1.2.1.1.4.0"); err == nil {
 		info.Contact = extractString(val)
 	}
 
 	// Lokasi Sistem
-	if val, err := d.snmpGet("1.3.6.1.2.1.1.6.0"); err == nil {
+	if val, err := sess.get("1.3.6.1.2.1.1.6.0"); err == nil {
 		info.Location = extractString(val)
 	}
 
-	return info, nil
+	return info, sess.err()
 }
 
 // GetBoardInfo mengambil informasi board/kartu.
 func (d *Driver) GetBoardInfo(ctx context.Context, boardID int) (*model.BoardInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	info := &model.BoardInfo{BoardID: boardID}
 
@@ -342,14 +405,14 @@ func (d *Driver) GetBoardInfo(ctx context.Context, boardID int) (*model.BoardInf
 
 	// Ambil Real Type (String) - Use this as primary type
 	realTypeOID := fmt.Sprintf("%s.%d", BaseOID3+CardRealTypePrefix, boardID)
-	if val, err := d.snmpGet(realTypeOID); err == nil {
+	if val, err := sess.get(realTypeOID); err == nil {
 		info.RealType = extractString(val)
 		info.Type = info.RealType // Use RealType as Type display
 	}
 
 	// Ambil Status Kartu
 	statusOID := fmt.Sprintf("%s.%d", BaseOID3+CardStatusPrefix, boardID)
-	if val, err := d.snmpGet(statusOID); err == nil {
+	if val, err := sess.get(statusOID); err == nil {
 		if intVal := extractInt(val); intVal > 0 {
 			info.Status = model.CardStatus(intVal).String()
 		}
@@ -357,29 +420,29 @@ func (d *Driver) GetBoardInfo(ctx context.Context, boardID int) (*model.BoardInf
 
 	// Ambil Port Count
 	portCountOID := fmt.Sprintf("%s.%d", BaseOID3+CardPortCountPrefix, boardID)
-	if val, err := d.snmpGet(portCountOID); err == nil {
+	if val, err := sess.get(portCountOID); err == nil {
 		info.PortCount = extractInt(val)
 	}
 
 	// Ambil Beban CPU
 	cpuOID := fmt.Sprintf("%s.%d", BaseOID3+CardCpuLoadPrefix, boardID)
-	if val, err := d.snmpGet(cpuOID); err == nil {
+	if val, err := sess.get(cpuOID); err == nil {
 		info.CpuLoad = extractInt(val)
 	}
 
 	// Ambil Penggunaan Memori
 	memOID := fmt.Sprintf("%s.%d", BaseOID3+CardMemUsagePrefix, boardID)
-	if val, err := d.snmpGet(memOID); err == nil {
+	if val, err := sess.get(memOID); err == nil {
 		info.MemUsage = extractInt(val)
 	}
 
 	// Ambil Software Version
 	softVerOID := fmt.Sprintf("%s.%d", BaseOID3+CardSoftVerPrefix, boardID)
-	if val, err := d.snmpGet(softVerOID); err == nil {
+	if val, err := sess.get(softVerOID); err == nil {
 		info.SoftVer = extractString(val)
 	}
 
-	return info, nil
+	return info, sess.err()
 }
 
 // GetAllBoards mengambil semua informasi board.
@@ -388,6 +451,9 @@ func (d *Driver) GetAllBoards(ctx context.Context) ([]model.BoardInfo, error) {
 	for i := 1; i <= MaxBoards; i++ {
 		info, err := d.GetBoardInfo(ctx, i)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			continue
 		}
 		boards = append(boards, *info)
@@ -397,11 +463,11 @@ func (d *Driver) GetAllBoards(ctx context.Context) ([]model.BoardInfo, error) {
 
 // GetONUTraffic mengambil statistik trafik ONU (Placeholder - butuh OID spesifik).
 func (d *Driver) GetONUTraffic(ctx context.Context, boardID, ponID, onuID int) (*model.ONUTraffic, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	traffic := &model.ONUTraffic{
 		Board:     boardID,
@@ -410,49 +476,191 @@ func (d *Driver) GetONUTraffic(ctx context.Context, boardID, ponID, onuID int) (
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
 
-	// Hitung indeks interface
-	var baseOnuID int
-	if boardID == 1 {
-		baseOnuID = Board1OnuIDBase
-	} else {
-		baseOnuID = Board2OnuIDBase
-	}
-
-	// Rumus indeks interface: base + ponOffset + onuID
-	// Setiap PON memiliki 256 indeks yang dialokasikan
-	ponOffset := (ponID - 1) * 256
-	interfaceIndex := baseOnuID + ponOffset + onuID
+	interfaceIndex := onuIfIndex(boardID, ponID, onuID)
 
 	// Standard IF-MIB OIDs
 	ifInOctetsOID := fmt.Sprintf(".1.3.6.1.2.1.2.2.1.10.%d", interfaceIndex)
 	ifOutOctetsOID := fmt.Sprintf(".1.3.6.1.2.1.2.2.1.16.%d", interfaceIndex)
+	ifInUcastPktsOID := fmt.Sprintf(".1.3.6.1.2.1.2.2.1.11.%d", interfaceIndex)
+	ifOutUcastPktsOID := fmt.Sprintf(".1.3.6.1.2.1.2.2.1.17.%d", interfaceIndex)
+
+	var raw []interface{}
 
 	// Ambil byte RX
-	if val, err := d.snmpGet(ifInOctetsOID); err == nil {
+	if val, err := sess.get(ifInOctetsOID); err == nil {
 		traffic.RxBytes = extractCounter64(val)
+		raw = append(raw, val)
 	}
 
 	// Ambil byte TX
-	if val, err := d.snmpGet(ifOutOctetsOID); err == nil {
+	if val, err := sess.get(ifOutOctetsOID); err == nil {
 		traffic.TxBytes = extractCounter64(val)
+		raw = append(raw, val)
+	}
+
+	// Ambil paket RX/TX (unicast)
+	if val, err := sess.get(ifInUcastPktsOID); err == nil {
+		traffic.RxPackets = extractCounter64(val)
+		raw = append(raw, val)
+	}
+	if val, err := sess.get(ifOutUcastPktsOID); err == nil {
+		traffic.TxPackets = extractCounter64(val)
+		raw = append(raw, val)
+	}
+
+	traffic.Sample = sess.sample(raw...)
+	return traffic, sess.err()
+}
+
+// GetONUTrafficCounters membaca counter octet dan paket beberapa ONU di satu
+// PON sekaligus (GET multi-OID), untuk sampling banyak ONU dalam satu sesi.
+func (d *Driver) GetONUTrafficCounters(ctx context.Context, boardID, ponID int, onuIDs []int) ([]model.ONUTraffic, error) {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
-	return traffic, nil
+	// Urutan kolom IF-MIB: ifInOctets, ifOutOctets, ifInUcastPkts, ifOutUcastPkts
+	columns := []string{"10", "16", "11", "17"}
+	oids := make([]string, 0, len(onuIDs)*len(columns))
+	for _, onuID := range onuIDs {
+		idx := onuIfIndex(boardID, ponID, onuID)
+		for _, col := range columns {
+			oids = append(oids, fmt.Sprintf(".1.3.6.1.2.1.2.2.1.%s.%d", col, idx))
+		}
+	}
+	vals, err := sess.getAll(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := sess.sample(vals...)
+	now := time.Now().UTC().Format(time.RFC3339)
+	traffic := make([]model.ONUTraffic, len(onuIDs))
+	for i, onuID := range onuIDs {
+		v := vals[i*len(columns):]
+		traffic[i] = model.ONUTraffic{
+			Board:     boardID,
+			PON:       ponID,
+			ONUID:     onuID,
+			RxBytes:   extractCounter64(v[0]),
+			TxBytes:   extractCounter64(v[1]),
+			RxPackets: extractCounter64(v[2]),
+			TxPackets: extractCounter64(v[3]),
+			Timestamp: now,
+			Sample:    sample,
+		}
+	}
+	return traffic, sess.err()
+}
+
+// GetOLTRxPower membaca daya optik yang diterima OLT dari beberapa ONU di
+// satu PON (dBm). ONU tanpa sinyal tidak ada di hasil.
+func (d *Driver) GetOLTRxPower(ctx context.Context, boardID, ponID int, onuIDs []int) (map[int]float64, error) {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.close()
+
+	oids := make([]string, len(onuIDs))
+	for i, onuID := range onuIDs {
+		oids[i] = oltRxPowerOID(boardID, ponID, onuID)
+	}
+	vals, err := sess.getAll(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	power := make(map[int]float64, len(onuIDs))
+	for i, onuID := range onuIDs {
+		if dbm, ok := convertOLTRxPower(vals[i]); ok {
+			power[onuID] = dbm
+		}
+	}
+	return power, sess.err()
+}
+
+// GetONUStatuses membaca status semua ONU di satu PON dengan satu walk
+// tabel status, untuk pemantauan berkala yang tidak butuh detail lain.
+func (d *Driver) GetONUStatuses(ctx context.Context, boardID, ponID int) (map[int]string, error) {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.close()
+
+	cfg := GenerateBoardPonOID(boardID, ponID)
+	statuses := make(map[int]string)
+	err = sess.walk(BaseOID1+cfg.OnuStatusOID, func(pdu gosnmp.SnmpPDU) error {
+		if onuID := extractOnuID(pdu.Name); onuID > 0 {
+			statuses[onuID] = convertStatus(pdu.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SNMP walk failed: %w", err)
+	}
+	return statuses, sess.err()
+}
+
+// GetONUOfflineReasons membaca alasan offline terakhir beberapa ONU di satu
+// PON. ONU yang tidak terbaca tidak ada di hasil.
+func (d *Driver) GetONUOfflineReasons(ctx context.Context, boardID, ponID int, onuIDs []int) (map[int]string, error) {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.close()
+
+	cfg := GenerateBoardPonOID(boardID, ponID)
+	oids := make([]string, len(onuIDs))
+	for i, onuID := range onuIDs {
+		oids[i] = fmt.Sprintf("%s%s.%d", BaseOID1, cfg.OnuLastOfflineReasonOID, onuID)
+	}
+	vals, err := sess.getAll(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := make(map[int]string, len(onuIDs))
+	for i, onuID := range onuIDs {
+		if vals[i] != nil {
+			reasons[onuID] = convertOfflineReason(vals[i])
+		}
+	}
+	return reasons, sess.err()
+}
+
+// oltRxPowerOID OID daya optik yang diterima OLT dari satu ONU
+func oltRxPowerOID(boardID, ponID, onuID int) string {
+	return fmt.Sprintf("%s%s.%d", BaseOID3, OnuOLTRxPowerOID, onuIfIndex(boardID, ponID, onuID))
+}
+
+// onuIfIndex indeks interface (ifIndex) ONU.
+// Rumus: base board + ponOffset + onuID, setiap PON memiliki 256 indeks.
+func onuIfIndex(boardID, ponID, onuID int) int {
+	baseOnuID := Board2OnuIDBase
+	if boardID == 1 {
+		baseOnuID = Board1OnuIDBase
+	}
+	return baseOnuID + (ponID-1)*256 + onuID
 }
 
 // GetInterfaceStats mengambil statistik interface.
 func (d *Driver) GetInterfaceStats(ctx context.Context) ([]model.InterfaceStats, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	var stats []model.InterfaceStats
 	indexMap := make(map[int]*model.InterfaceStats)
 
 	// Walk deskripsi interface
-	d.client.Walk("1.3.6.1.2.1.2.2.1.2", func(pdu gosnmp.SnmpPDU) error {
+	sess.walk("1.3.6.1.2.1.2.2.1.2", func(pdu gosnmp.SnmpPDU) error {
 		idx := extractLastOIDPart(pdu.Name)
 		if idx > 0 {
 			indexMap[idx] = &model.InterfaceStats{
@@ -464,7 +672,7 @@ func (d *Driver) GetInterfaceStats(ctx context.Context) ([]model.InterfaceStats,
 	})
 
 	// Walk status interface
-	d.client.Walk("1.3.6.1.2.1.2.2.1.8", func(pdu gosnmp.SnmpPDU) error {
+	sess.walk("1.3.6.1.2.1.2.2.1.8", func(pdu gosnmp.SnmpPDU) error {
 		idx := extractLastOIDPart(pdu.Name)
 		if stat, ok := indexMap[idx]; ok {
 			if intVal, ok := pdu.Value.(int); ok {
@@ -479,7 +687,7 @@ func (d *Driver) GetInterfaceStats(ctx context.Context) ([]model.InterfaceStats,
 	})
 
 	// Walk byte RX
-	d.client.Walk("1.3.6.1.2.1.2.2.1.10", func(pdu gosnmp.SnmpPDU) error {
+	sess.walk("1.3.6.1.2.1.2.2.1.10", func(pdu gosnmp.SnmpPDU) error {
 		idx := extractLastOIDPart(pdu.Name)
 		if stat, ok := indexMap[idx]; ok {
 			stat.RxBytes = extractCounter64(pdu.Value)
@@ -488,7 +696,7 @@ func (d *Driver) GetInterfaceStats(ctx context.Context) ([]model.InterfaceStats,
 	})
 
 	// Walk byte TX
-	d.client.Walk("1.3.6.1.2.1.2.2.1.16", func(pdu gosnmp.SnmpPDU) error {
+	sess.walk("1.3.6.1.2.1.2.2.1.16", func(pdu gosnmp.SnmpPDU) error {
 		idx := extractLastOIDPart(pdu.Name)
 		if stat, ok := indexMap[idx]; ok {
 			stat.TxBytes = extractCounter64(pdu.Value)
@@ -501,22 +709,22 @@ func (d *Driver) GetInterfaceStats(ctx context.Context) ([]model.InterfaceStats,
 		stats = append(stats, *stat)
 	}
 
-	return stats, nil
+	return stats, sess.err()
 }
 
 // GetFanInfo mengambil informasi fan
 func (d *Driver) GetFanInfo(ctx context.Context) ([]map[string]interface{}, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	var fans []map[string]interface{}
 	fanMap := make(map[int]map[string]interface{})
 
 	// Walk tabel fan untuk mendapatkan indeks
-	err := d.client.Walk(FanTableOID, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(FanTableOID, func(pdu gosnmp.SnmpPDU) error {
 		// Ekstrak indeks fan dari OID
 		parts := strings.Split(pdu.Name, ".")
 		if len(parts) < 1 {
@@ -558,7 +766,7 @@ func (d *Driver) GetFanInfo(ctx context.Context) ([]map[string]interface{}, erro
 
 		// Ambil tingkat kecepatan
 		speedOID := fmt.Sprintf("%s.%d", FanSpeedLevelOID, idx)
-		if val, err := d.snmpGet(speedOID); err == nil {
+		if val, err := sess.get(speedOID); err == nil {
 			if intVal := extractInt(val); intVal > 0 {
 				fan["speed_level"] = intVal
 				// Konversi ke string
@@ -579,7 +787,7 @@ func (d *Driver) GetFanInfo(ctx context.Context) ([]map[string]interface{}, erro
 
 		// Ambil status
 		statusOID := fmt.Sprintf("%s.%d", FanStatusOID, idx)
-		if val, err := d.snmpGet(statusOID); err == nil {
+		if val, err := sess.get(statusOID); err == nil {
 			if intVal := extractInt(val); intVal == 1 {
 				fan["status"] = "Normal"
 			} else {
@@ -589,7 +797,7 @@ func (d *Driver) GetFanInfo(ctx context.Context) ([]map[string]interface{}, erro
 
 		// Ambil status keberadaan (present)
 		presentOID := fmt.Sprintf("%s.%d", FanPresentOID, idx)
-		if val, err := d.snmpGet(presentOID); err == nil {
+		if val, err := sess.get(presentOID); err == nil {
 			if extractInt(val) == 1 {
 				fan["present"] = true
 			} else {
@@ -600,43 +808,43 @@ func (d *Driver) GetFanInfo(ctx context.Context) ([]map[string]interface{}, erro
 		fans = append(fans, fan)
 	}
 
-	return fans, nil
+	return fans, sess.err()
 }
 
 // GetTemperatureInfo mengambil informasi suhu OLT
 func (d *Driver) GetTemperatureInfo(ctx context.Context) (*model.TemperatureInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	info := &model.TemperatureInfo{
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
 	// Ambil suhu sistem/ambient
-	if val, err := d.snmpGet(TempSystemOID); err == nil {
+	if val, err := sess.get(TempSystemOID); err == nil {
 		info.System = extractInt(val)
 	}
 
 	// Ambil suhu CPU/board
-	if val, err := d.snmpGet(TempCPUOID); err == nil {
+	if val, err := sess.get(TempCPUOID); err == nil {
 		info.CPU = extractInt(val)
 	}
 
-	return info, nil
+	return info, sess.err()
 }
 
 // GetONUBandwidth mengambil bandwidth SLA per ONU
 // Note: Per-ONU bandwidth tidak tersedia via SNMP, hanya profile table
 // Returns profile info if available
 func (d *Driver) GetONUBandwidth(ctx context.Context, boardID, ponID, onuID int) (*model.ONUBandwidth, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	cfg := GenerateBoardPonOID(boardID, ponID)
 	onuIDStr := strconv.Itoa(onuID)
@@ -648,23 +856,23 @@ func (d *Driver) GetONUBandwidth(ctx context.Context, boardID, ponID, onuID int)
 	}
 
 	// Ambil nama ONU
-	if val, err := d.snmpGet(BaseOID1 + cfg.OnuIDNameOID + "." + onuIDStr); err == nil {
+	if val, err := sess.get(BaseOID1 + cfg.OnuIDNameOID + "." + onuIDStr); err == nil {
 		bw.Name = extractString(val)
 	}
 
 	// Note: Bandwidth values tidak tersedia via SNMP
 	// Harus query profile table atau CLI untuk data ini
 
-	return bw, nil
+	return bw, sess.err()
 }
 
 // GetPonPortStats mengambil statistik traffic per PON port
 func (d *Driver) GetPonPortStats(ctx context.Context, boardID, ponID int) (*model.PONPortStats, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	// Calculate PON port index
 	ponIndex := GetPonIndexBase(boardID) + (ponID - 1)
@@ -675,41 +883,48 @@ func (d *Driver) GetPonPortStats(ctx context.Context, boardID, ponID int) (*mode
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
 
+	var raw []interface{}
+
 	// Ambil RX bytes
 	oid := fmt.Sprintf("%s%s.%d", BaseOID3, PonRxOctetsOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		stats.RxBytes = extractCounter64(val)
+		raw = append(raw, val)
 	}
 
 	// Ambil TX bytes
 	oid = fmt.Sprintf("%s%s.%d", BaseOID3, PonTxOctetsOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		stats.TxBytes = extractCounter64(val)
+		raw = append(raw, val)
 	}
 
 	// Ambil RX packets
 	oid = fmt.Sprintf("%s%s.%d", BaseOID3, PonRxPktsOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		stats.RxPackets = extractCounter64(val)
+		raw = append(raw, val)
 	}
 
 	// Ambil TX packets
 	oid = fmt.Sprintf("%s%s.%d", BaseOID3, PonTxPktsOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		stats.TxPackets = extractCounter64(val)
+		raw = append(raw, val)
 	}
 
-	return stats, nil
+	stats.Sample = sess.sample(raw...)
+	return stats, sess.err()
 }
 
 // GetONUErrors mengambil error counter per PON port (not per ONU)
 // Note: Per-ONU error counters tidak tersedia, menggunakan PON-level stats
 func (d *Driver) GetONUErrors(ctx context.Context, boardID, ponID, onuID int) (*model.ONUErrors, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	// Calculate PON port index
 	ponIndex := GetPonIndexBase(boardID) + (ponID - 1)
@@ -724,33 +939,33 @@ func (d *Driver) GetONUErrors(ctx context.Context, boardID, ponID, onuID int) (*
 	// Ambil PON-level error stats
 	// RX Discards
 	oid := fmt.Sprintf("%s%s.%d", BaseOID3, PonRxPktsDiscardOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		errs.DroppedFrames = extractCounter64(val)
 	}
 
 	// RX Errors
 	oid = fmt.Sprintf("%s%s.%d", BaseOID3, PonRxPktsErrOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		errs.CrcErrors = extractCounter64(val)
 	}
 
 	// CRC Errors
 	oid = fmt.Sprintf("%s%s.%d", BaseOID3, PonRxCRCAlignErrorsOID, ponIndex)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		errs.FecErrors = extractCounter64(val)
 	}
 
-	return errs, nil
+	return errs, sess.err()
 }
 
 // GetVoltageInfo mengambil informasi voltage/power supply
 // Note: Voltage OID tidak tersedia di firmware ini
 func (d *Driver) GetVoltageInfo(ctx context.Context) (*model.VoltageInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	info := &model.VoltageInfo{
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
@@ -759,13 +974,94 @@ func (d *Driver) GetVoltageInfo(ctx context.Context) (*model.VoltageInfo, error)
 	// Note: Voltage OIDs tidak tersedia di OLT ini
 	// Return empty values
 
-	return info, nil
+	return info, sess.err()
 }
 
-// snmpGet melakukan permintaan SNMP GET.
-func (d *Driver) snmpGet(oid string) (interface{}, error) {
-	result, err := d.client.Get([]string{oid})
-	if err != nil {
+// session koneksi SNMP untuk satu operasi driver. Request berhenti begitu
+// ctx selesai (dibatalkan client atau lewat deadline) atau circuit OLT
+// terbuka.
+type session struct {
+	ctx     context.Context
+	client  *gosnmp.GoSNMP
+	breaker *breaker.Breaker
+	host    string
+	unbind  func()
+	aborted error // Alasan operasi berhenti di tengah jalan
+}
+
+// open membuka session yang terikat ke ctx
+func (d *Driver) open(ctx context.Context) (*session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	client := d.newClient()
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("SNMP connect failed: %w", err)
+	}
+	return &session{
+		ctx:     ctx,
+		client:  client,
+		breaker: d.breaker,
+		host:    d.snmpHost,
+		unbind:  snmp.Bind(ctx, client),
+	}, nil
+}
+
+func (s *session) close() {
+	s.unbind()
+	s.client.Conn.Close()
+}
+
+// err mengembalikan alasan operasi berhenti sebelum selesai (ctx selesai
+// atau circuit terbuka). Method yang mengabaikan error per OID memakainya
+// agar hasil yang tidak lengkap tidak dikembalikan sebagai sukses.
+func (s *session) err() error {
+	return s.aborted
+}
+
+// check menghentikan session jika ctx selesai atau circuit terbuka
+func (s *session) check() error {
+	if s.aborted != nil {
+		return s.aborted
+	}
+	if err := s.ctx.Err(); err != nil {
+		s.aborted = err
+	} else if err := s.breaker.Check(); err != nil {
+		s.aborted = err
+	}
+	return s.aborted
+}
+
+// result mencatat hasil request ke circuit. Error karena ctx selesai bukan
+// kesalahan OLT sehingga tidak dicatat.
+func (s *session) result(err error) error {
+	if err == nil {
+		s.breaker.Record(nil)
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// Socket bisa timeout tepat di deadline sebelum ctx.Err() terisi
+		s.aborted = err
+		return err
+	}
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		s.aborted = ctxErr
+		return ctxErr
+	}
+	s.breaker.Record(err)
+	if cerr := s.check(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// get melakukan permintaan SNMP GET.
+func (s *session) get(oid string) (interface{}, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	result, err := s.client.Get([]string{oid})
+	if err := s.result(err); err != nil {
 		return nil, err
 	}
 	if len(result.Variables) == 0 {
@@ -774,6 +1070,107 @@ func (d *Driver) snmpGet(oid string) (interface{}, error) {
 	return result.Variables[0].Value, nil
 }
 
+// getAll melakukan SNMP GET untuk banyak OID, dipecah per getBatch OID per
+// request. Nilai dikembalikan sesuai urutan oids; OID yang tidak ada bernilai nil.
+func (s *session) getAll(oids []string) ([]interface{}, error) {
+	vals := make([]interface{}, 0, len(oids))
+	for start := 0; start < len(oids); start += getBatch {
+		if err := s.check(); err != nil {
+			return nil, err
+		}
+		end := min(start+getBatch, len(oids))
+		result, err := s.client.Get(oids[start:end])
+		if err := s.result(err); err != nil {
+			return nil, err
+		}
+		if len(result.Variables) != end-start {
+			return nil, fmt.Errorf("expected %d values, got %d", end-start, len(result.Variables))
+		}
+		for _, v := range result.Variables {
+			switch v.Type {
+			case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.Null:
+				vals = append(vals, nil)
+			default:
+				vals = append(vals, v.Value)
+			}
+		}
+	}
+	return vals, nil
+}
+
+// sample metadata sampel counter setelah counter dibaca: waktu baca,
+// sysUpTime untuk deteksi reboot, dan lebar counter dari tipe nilainya
+// (gosnmp membaca Counter32 sebagai uint dan Counter64 sebagai uint64).
+func (s *session) sample(counters ...interface{}) model.CounterSample {
+	sample := model.CounterSample{At: time.Now(), Bits: 64}
+	for _, val := range counters {
+		if val == nil {
+			continue
+		}
+		if _, ok := val.(uint64); !ok {
+			sample.Bits = 32
+		}
+	}
+	if val, err := s.get(SysUptimeOID); err == nil {
+		if ticks, ok := val.(uint32); ok {
+			sample.Uptime = ticks
+		}
+	}
+	return sample
+}
+
+// walk melakukan SNMP WALK dan berhenti di antara PDU jika ctx selesai atau
+// circuit terbuka
+func (s *session) walk(oid string, fn func(gosnmp.SnmpPDU) error) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	err := snmp.Walk(s.ctx, s.client, oid, func(pdu gosnmp.SnmpPDU) error {
+		if err := s.check(); err != nil {
+			return err
+		}
+		return fn(pdu)
+	})
+	if s.aborted != nil {
+		return s.aborted
+	}
+	return s.result(err)
+}
+
+// set performs SNMP SET operation with integer value.
+// The SET is recorded to the audit recorder in ctx, if any.
+func (s *session) set(oid string, value int) error {
+	return s.setPDU(gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Integer, Value: value}, "INTEGER")
+}
+
+// setString performs SNMP SET operation with string value
+func (s *session) setString(oid, value string) error {
+	return s.setPDU(gosnmp.SnmpPDU{Name: oid, Type: gosnmp.OctetString, Value: value}, "STRING")
+}
+
+func (s *session) setPDU(pdu gosnmp.SnmpPDU, typ string) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	result, err := s.client.Set([]gosnmp.SnmpPDU{pdu})
+	err = s.result(err)
+	if err == nil && result != nil && result.Error != gosnmp.NoError {
+		err = setError(pdu.Name, result.Error)
+	}
+	audit.RecordSNMPSet(s.ctx, s.host, pdu.Name, typ, pdu.Value, err)
+	return err
+}
+
+// setError error status dari jawaban SET. OID yang tidak ada atau tidak
+// bisa ditulis berarti operasi tidak didukung firmware OLT.
+func setError(oid string, status gosnmp.SNMPError) error {
+	switch status {
+	case gosnmp.NoSuchName, gosnmp.ReadOnly, gosnmp.NoAccess, gosnmp.NoCreation, gosnmp.NotWritable:
+		return fmt.Errorf("%w: SET %s returned %s", driver.ErrNotSupported, oid, status)
+	}
+	return fmt.Errorf("SET %s returned %s", oid, status)
+}
+
 // Fungsi Pembantu (Helpers)
 
 func extractOnuID(oid string) int {
@@ -880,6 +1277,29 @@ func convertPower(val interface{}) string {
 	return fmt.Sprintf("%.2f", result)
 }
 
+// convertOLTRxPower mengubah nilai 0.001 dBm menjadi dBm. Nol dan nilai di
+// luar rentang optik (misal -80000 atau 65535 saat ONU tidak mengirim) ditolak.
+func convertOLTRxPower(val interface{}) (float64, bool) {
+	if val == nil {
+		return 0, false
+	}
+	dbm := float64(extractInt(val)) / 1000
+	if dbm == 0 || dbm < -50 || dbm > 10 {
+		return 0, false
+	}
+	return dbm, true
+}
+
+// formatOLTRxPower seperti convertOLTRxPower dalam format string ONUInfo;
+// "0.00" jika tidak terbaca, sama dengan convertPower.
+func formatOLTRxPower(val interface{}) string {
+	dbm, ok := convertOLTRxPower(val)
+	if !ok {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", dbm)
+}
+
 func convertStatus(val interface{}) string {
 	intVal, ok := val.(int)
 	if !ok {
@@ -911,8 +1331,75 @@ func convertDateTime(val interface{}) string {
 	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", year, month, day, hour, minute, second)
 }
 
-func calculateUptime(lastOnline string) string {
-	return lastOnline
+// dateTimeLayout format tanggal hasil convertDateTime
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// parseDateAndTime membaca DateAndTime SNMP (8 atau 11 byte) sebagai jam
+// lokal OLT. Zona waktu diabaikan karena LastOnline/LastOffline juga tanpa
+// zona.
+func parseDateAndTime(val interface{}) (time.Time, bool) {
+	bytes, ok := val.([]byte)
+	if !ok || (len(bytes) != 8 && len(bytes) != 11) {
+		return time.Time{}, false
+	}
+	return parseOLTTime(convertDateTime(bytes[:8]))
+}
+
+// parseOLTTime membaca waktu hasil convertDateTime. Tanggal kosong
+// ("0000-00-00 ...") berarti belum pernah terjadi.
+func parseOLTTime(s string) (time.Time, bool) {
+	t, err := time.ParseInLocation(dateTimeLayout, s, time.Local)
+	if err != nil || t.Year() < 2000 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// calculateUptime lama ONU online sejak lastOnline sampai now (jam OLT).
+// Kosong jika waktu tidak terbaca atau jam OLT di belakang lastOnline.
+func calculateUptime(lastOnline string, now time.Time) string {
+	since, ok := parseOLTTime(lastOnline)
+	if !ok {
+		return ""
+	}
+	// Bandingkan sebagai jam dinding: waktu OLT tidak membawa zona
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.Local)
+	if now.Before(since) {
+		return ""
+	}
+	return formatDuration(now.Sub(since))
+}
+
+// calculateDownDuration lama offline terakhir: dari lastOffline sampai ONU
+// online kembali. Kosong jika ONU belum online lagi sejak offline terakhir.
+func calculateDownDuration(lastOffline, lastOnline string) string {
+	down, ok := parseOLTTime(lastOffline)
+	if !ok {
+		return ""
+	}
+	up, ok := parseOLTTime(lastOnline)
+	if !ok || up.Before(down) {
+		return ""
+	}
+	return formatDuration(up.Sub(down))
+}
+
+// formatDuration menulis durasi sebagai "3d 4h 5m 6s"
+func formatDuration(d time.Duration) string {
+	secs := int64(d.Seconds())
+	days, secs := secs/86400, secs%86400
+	hours, secs := secs/3600, secs%3600
+	mins, secs := secs/60, secs%60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm %ds", days, hours, mins, secs)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm %ds", hours, mins, secs)
+	}
+	if mins > 0 {
+		return fmt.Sprintf("%dm %ds", mins, secs)
+	}
+	return fmt.Sprintf("%ds", secs)
 }
 
 // ==================== PROVISIONING METHODS ====================
@@ -920,18 +1407,24 @@ func calculateUptime(lastOnline string) string {
 // CreateONU creates a new ONU on specified PON port
 // OID: .28.1.1.9.{oltId}.{onuId} SET with RowStatus = 4 (createAndGo)
 func (d *Driver) CreateONU(ctx context.Context, boardID, ponID, onuID int, name string) error {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer sess.close()
+
 	oltID := CalculateOltID(boardID, ponID)
 	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuRowStatusOID, oltID, onuID)
 	
 	// Set RowStatus = 4 (createAndGo)
-	if err := d.snmpSet(oid, 4); err != nil {
+	if err := sess.set(oid, 4); err != nil {
 		return fmt.Errorf("failed to create ONU: %w", err)
 	}
 	
 	// If name provided, set the name
 	if name != "" {
 		nameOID := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuNameOID, oltID, onuID)
-		if err := d.snmpSetString(nameOID, name); err != nil {
+		if err := sess.setString(nameOID, name); err != nil {
 			// Name failed but ONU created, return error with context
 			return fmt.Errorf("ONU created but failed to set name: %w", err)
 		}
@@ -943,11 +1436,17 @@ func (d *Driver) CreateONU(ctx context.Context, boardID, ponID, onuID int, name
 // DeleteONU deletes an ONU from specified PON port
 // OID: .28.1.1.9.{oltId}.{onuId} SET with RowStatus = 6 (destroy)
 func (d *Driver) DeleteONU(ctx context.Context, boardID, ponID, onuID int) error {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer sess.close()
+
 	oltID := CalculateOltID(boardID, ponID)
 	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuRowStatusOID, oltID, onuID)
 	
 	// Set RowStatus = 6 (destroy)
-	if err := d.snmpSet(oid, 6); err != nil {
+	if err := sess.set(oid, 6); err != nil {
 		return fmt.Errorf("failed to delete ONU: %w", err)
 	}
 	
@@ -957,10 +1456,16 @@ func (d *Driver) DeleteONU(ctx context.Context, boardID, ponID, onuID int) error
 // RenameONU renames an existing ONU
 // OID: .28.1.1.2.{oltId}.{onuId} SET with new name
 func (d *Driver) RenameONU(ctx context.Context, boardID, ponID, onuID int, name string) error {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer sess.close()
+
 	oltID := CalculateOltID(boardID, ponID)
 	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuNameOID, oltID, onuID)
 	
-	if err := d.snmpSetString(oid, name); err != nil {
+	if err := sess.setString(oid, name); err != nil {
 		return fmt.Errorf("failed to rename ONU: %w", err)
 	}
 	
@@ -971,25 +1476,80 @@ func (d *Driver) RenameONU(ctx context.Context, boardID, ponID, onuID int, name
 // OID: .28.1.1.8.{oltId}.{onuId} GET
 // Returns: 1 = offline/deactive, 2 = online/omciready
 func (d *Driver) GetONUStatus(ctx context.Context, boardID, ponID, onuID int) (int, error) {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer sess.close()
+
 	oltID := CalculateOltID(boardID, ponID)
 	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuTargetStateOID, oltID, onuID)
 	
-	val, err := d.snmpGet(oid)
+	val, err := sess.get(oid)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get ONU status: %w", err)
 	}
 	
-	return extractInt(val), nil
+	return extractInt(val), sess.err()
+}
+
+// RebootONU me-reboot ONU lewat zxGponOntActionsTable
+// OID: .50.11.3.1.1.{oltId}.{onuId} SET 1
+func (d *Driver) RebootONU(ctx context.Context, boardID, ponID, onuID int) error {
+	return d.onuAction(ctx, OnuRebootOID, boardID, ponID, onuID, 1)
+}
+
+// RestoreONU mengembalikan ONU ke setelan pabrik lewat zxGponOntActionsTable
+// OID: .50.11.3.1.2.{oltId}.{onuId} SET 1
+func (d *Driver) RestoreONU(ctx context.Context, boardID, ponID, onuID int) error {
+	return d.onuAction(ctx, OnuRestoreOID, boardID, ponID, onuID, 1)
+}
+
+// SetONUAdminState mengaktifkan atau menonaktifkan ONU. ONU yang dinonaktifkan
+// tetap terdaftar tetapi tidak dilayani OLT.
+// OID: .28.1.1.8.{oltId}.{onuId} SET 2 (active) atau 1 (deactive)
+func (d *Driver) SetONUAdminState(ctx context.Context, boardID, ponID, onuID int, enabled bool) error {
+	state := TargetStateDeactive
+	if enabled {
+		state = TargetStateActive
+	}
+	return d.onuAction(ctx, OnuTargetStateOID, boardID, ponID, onuID, state)
+}
+
+// GetONUEthPorts membaca status port Ethernet ONU. Belum ada OID tabel UNI
+// yang terverifikasi di firmware C320 sehingga selalu ErrNotSupported;
+// service memakai CLI sebagai gantinya.
+func (d *Driver) GetONUEthPorts(ctx context.Context, boardID, ponID, onuID int) ([]model.ONUEthPort, error) {
+	return nil, driver.ErrNotSupported
+}
+
+// SetONUEthPortState mengaktifkan/menonaktifkan port Ethernet ONU. Lihat
+// GetONUEthPorts: belum ada OID yang terverifikasi.
+func (d *Driver) SetONUEthPortState(ctx context.Context, boardID, ponID, onuID, port int, enabled bool) error {
+	return driver.ErrNotSupported
+}
+
+// onuAction SET satu nilai integer pada tabel ONU berindeks {oltId}.{onuId}
+func (d *Driver) onuAction(ctx context.Context, field string, boardID, ponID, onuID, value int) error {
+	sess, err := d.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer sess.close()
+
+	oltID := CalculateOltID(boardID, ponID)
+	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, field, oltID, onuID)
+	return sess.set(oid, value)
 }
 
 // GetDistance gets ONU distance information
 // OID: .11.4.1.2.{oltId}.{onuId} GET
 func (d *Driver) GetDistance(ctx context.Context, boardID, ponID, onuID int) (*model.ONUDistance, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	oltID := CalculateOltID(boardID, ponID)
 	
@@ -1001,34 +1561,34 @@ func (d *Driver) GetDistance(ctx context.Context, boardID, ponID, onuID int) (*m
 
 	// Get Distance (meters)
 	oid := fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuDistanceOID, oltID, onuID)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		distance.Distance = extractInt(val)
 	}
 
 	// Get EQD (Equalized Delay)
 	oid = fmt.Sprintf("%s.3%s.%d.%d", BaseOID2, OnuEQDOID, oltID, onuID)
-	if val, err := d.snmpGet(oid); err == nil {
+	if val, err := sess.get(oid); err == nil {
 		distance.EQD = extractInt(val)
 	}
 
-	return distance, nil
+	return distance, sess.err()
 }
 
 // GetVLANList gets list of all VLANs
 // OID: .1.3.6.1.2.1.17.7.1.4.3.1.1 (Standard IF-MIB)
 func (d *Driver) GetVLANList(ctx context.Context) (*model.VLANList, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	vlanList := &model.VLANList{
 		VLANs: []model.VLANInfo{},
 	}
 
 	// Walk VLAN name table
-	err := d.client.Walk(VlanNameBase, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(VlanNameBase, func(pdu gosnmp.SnmpPDU) error {
 		if pdu.Value != nil {
 			// Extract VLAN ID from OID
 			oidParts := splitOID(pdu.Name)
@@ -1050,20 +1610,20 @@ func (d *Driver) GetVLANList(ctx context.Context) (*model.VLANList, error) {
 	}
 
 	vlanList.Count = len(vlanList.VLANs)
-	return vlanList, nil
+	return vlanList, sess.err()
 }
 
 // GetVLANInfo gets information about a specific VLAN
 // OID: .1.3.6.1.2.1.17.7.1.4.3.1.1.{vlanId}
 func (d *Driver) GetVLANInfo(ctx context.Context, vlanID int) (*model.VLANInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	oid := fmt.Sprintf("%s.%d", VlanNameBase, vlanID)
-	val, err := d.snmpGet(oid)
+	val, err := sess.get(oid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VLAN info: %w", err)
 	}
@@ -1077,11 +1637,11 @@ func (d *Driver) GetVLANInfo(ctx context.Context, vlanID int) (*model.VLANInfo,
 // GetProfileList gets list of all bandwidth profiles
 // OID: .26.1.1.* (under BaseOID2.3)
 func (d *Driver) GetProfileList(ctx context.Context) (*model.ProfileList, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	profileList := &model.ProfileList{
 		Profiles: []model.ProfileInfo{},
@@ -1089,7 +1649,7 @@ func (d *Driver) GetProfileList(ctx context.Context) (*model.ProfileList, error)
 
 	// Walk profile name table
 	oid := BaseOID2 + ".3" + ProfileNameOID
-	err := d.client.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(oid, func(pdu gosnmp.SnmpPDU) error {
 		if pdu.Value != nil {
 			// Extract profile index from OID
 			profileIndex := extractLastOIDPart(pdu.Name)
@@ -1102,17 +1662,17 @@ func (d *Driver) GetProfileList(ctx context.Context) (*model.ProfileList, error)
 			
 			// Get other fields
 			fixedOid := fmt.Sprintf("%s.3%s.%d", BaseOID2, ProfileFixedBWOID, profileIndex)
-			if val, err := d.snmpGet(fixedOid); err == nil {
+			if val, err := sess.get(fixedOid); err == nil {
 				profile.FixedBW = extractInt(val)
 			}
 			
 			assuredOid := fmt.Sprintf("%s.3%s.%d", BaseOID2, ProfileAssuredBWOID, profileIndex)
-			if val, err := d.snmpGet(assuredOid); err == nil {
+			if val, err := sess.get(assuredOid); err == nil {
 				profile.AssuredBW = extractInt(val)
 			}
 			
 			maxOid := fmt.Sprintf("%s.3%s.%d", BaseOID2, ProfileMaxBWOID, profileIndex)
-			if val, err := d.snmpGet(maxOid); err == nil {
+			if val, err := sess.get(maxOid); err == nil {
 				profile.MaxBW = extractInt(val)
 			}
 			
@@ -1126,17 +1686,17 @@ func (d *Driver) GetProfileList(ctx context.Context) (*model.ProfileList, error)
 	}
 
 	profileList.Count = len(profileList.Profiles)
-	return profileList, nil
+	return profileList, sess.err()
 }
 
 // GetPONInfo gets PON port information
 // Uses legacy OIDs from Phase 1
 func (d *Driver) GetPONInfo(ctx context.Context, boardID, ponID int) (*model.PONInfo, error) {
-	if !d.connected {
-		if err := d.Connect(); err != nil {
-			return nil, err
-		}
+	sess, err := d.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer sess.close()
 
 	cfg := GenerateBoardPonOID(boardID, ponID)
 	
@@ -1147,7 +1707,7 @@ func (d *Driver) GetPONInfo(ctx context.Context, boardID, ponID int) (*model.PON
 
 	// Get ONU count by walking ONU list
 	onuCount := 0
-	err := d.client.Walk(BaseOID1+cfg.OnuIDNameOID, func(pdu gosnmp.SnmpPDU) error {
+	err = sess.walk(BaseOID1+cfg.OnuIDNameOID, func(pdu gosnmp.SnmpPDU) error {
 		if pdu.Value != nil && extractString(pdu.Value) != "" {
 			onuCount++
 		}
@@ -1161,48 +1721,16 @@ func (d *Driver) GetPONInfo(ctx context.Context, boardID, ponID int) (*model.PON
 	ponIndex := GetPonIndexBase(boardID) + (ponID - 1)
 	
 	rxOid := fmt.Sprintf("%s%s.%d", BaseOID3, PonRxOctetsOID, ponIndex)
-	if val, err := d.snmpGet(rxOid); err == nil {
+	if val, err := sess.get(rxOid); err == nil {
 		ponInfo.RxBytes = extractCounter64(val)
 	}
 	
 	txOid := fmt.Sprintf("%s%s.%d", BaseOID3, PonTxOctetsOID, ponIndex)
-	if val, err := d.snmpGet(txOid); err == nil {
+	if val, err := sess.get(txOid); err == nil {
 		ponInfo.TxBytes = extractCounter64(val)
 	}
 
-	return ponInfo, nil
-}
-
-// snmpSet performs SNMP SET operation with integer value
-func (d *Driver) snmpSet(oid string, value int) error {
-	pdu := gosnmp.SnmpPDU{
-		Name:  oid,
-		Type:  gosnmp.Integer,
-		Value: value,
-	}
-	
-	_, err := d.client.Set([]gosnmp.SnmpPDU{pdu})
-	if err != nil {
-		return err
-	}
-	
-	return nil
-}
-
-// snmpSetString performs SNMP SET operation with string value
-func (d *Driver) snmpSetString(oid, value string) error {
-	pdu := gosnmp.SnmpPDU{
-		Name:  oid,
-		Type:  gosnmp.OctetString,
-		Value: value,
-	}
-	
-	_, err := d.client.Set([]gosnmp.SnmpPDU{pdu})
-	if err != nil {
-		return err
-	}
-	
-	return nil
+	return ponInfo, sess.err()
 }
 
 // Pastikan Driver mengimplementasikan interface driver.Driver
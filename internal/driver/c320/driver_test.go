@@ -0,0 +1,132 @@
+package c320
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/breaker"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/snmp/snmptest"
+	"github.com/gosnmp/gosnmp"
+)
+
+// Timeout driver 5 detik x 3 percobaan; semua test di sini harus selesai
+// jauh lebih cepat karena ctx.
+const returnLimit = 500 * time.Millisecond
+
+// checkGoroutines memastikan tidak ada goroutine SNMP yang tertinggal
+func checkGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d > %d\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestGetONUListCancel: walk ONU berhenti saat request dibatalkan dan agent
+// tidak menerima PDU lagi setelah method kembali.
+func TestGetONUListCancel(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Endless, 2*time.Millisecond)
+	d := New(agent.Host, agent.Port, "public")
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	list, err := d.GetONUList(ctx, 1, 1)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if list != nil {
+		t.Fatalf("hasil sebagian dikembalikan: %d ONU", len(list))
+	}
+	if elapsed > returnLimit {
+		t.Fatalf("GetONUList kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+
+	before := agent.Requests()
+	time.Sleep(100 * time.Millisecond)
+	if after := agent.Requests(); after != before {
+		t.Fatalf("agent masih menerima request: %d -> %d", before, after)
+	}
+	checkGoroutines(t, baseline)
+}
+
+// TestGetONUDetailDeadline: deadline request menghentikan rangkaian GET
+// (bukan 12 GET x timeout), dan timeout karena deadline request tidak
+// dihitung sebagai kegagalan OLT oleh circuit breaker.
+func TestGetONUDetailDeadline(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Silent, 0)
+	breakers := breaker.New(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, ProbeTimeout: time.Second})
+	d := New(agent.Host, agent.Port, "public")
+	d.SetBreaker(breakers.For(agent.Host))
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := d.GetONUDetail(ctx, 1, 1, 1)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > returnLimit {
+		t.Fatalf("GetONUDetail kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+	if n := agent.Requests(); n != 1 {
+		t.Fatalf("agent menerima %d request, want 1", n)
+	}
+	if st := breakers.Status(agent.Host); st.State != breaker.Closed {
+		t.Fatalf("circuit = %s, want closed", st.State)
+	}
+	checkGoroutines(t, baseline)
+}
+
+// TestGetONUDetailComplete: tanpa pembatalan semua GET dalam satu session
+// berhasil dan hasilnya dikembalikan tanpa error.
+func TestGetONUDetailComplete(t *testing.T) {
+	// Agent Endless menjawab setiap GET dengan nilai 1
+	agent := snmptest.NewAgent(t, snmptest.Endless, 0)
+	d := New(agent.Host, agent.Port, "public")
+
+	info, err := d.GetONUDetail(context.Background(), 1, 1, 1)
+	if err != nil {
+		t.Fatalf("GetONUDetail: %v", err)
+	}
+	if info.Name != "1" {
+		t.Fatalf("Name = %q, want %q", info.Name, "1")
+	}
+}
+
+// TestSetError: SET ke OID yang tidak ada atau read-only berarti operasi
+// tidak didukung firmware, error status lain tetap error biasa.
+func TestSetError(t *testing.T) {
+	tests := []struct {
+		status      gosnmp.SNMPError
+		unsupported bool
+	}{
+		{gosnmp.NoSuchName, true},
+		{gosnmp.NotWritable, true},
+		{gosnmp.NoCreation, true},
+		{gosnmp.WrongValue, false},
+		{gosnmp.GenErr, false},
+	}
+	for _, tt := range tests {
+		err := setError(".1.2.3", tt.status)
+		if got := errors.Is(err, driver.ErrNotSupported); got != tt.unsupported {
+			t.Errorf("setError(%s) = %v, unsupported %v, want %v", tt.status, err, got, tt.unsupported)
+		}
+	}
+}
@@ -30,6 +30,17 @@ const (
 	OnuIsAutoUpdateOID = ".28.1.1.11" // IsAutoUpdate
 	OnuRegModeOID      = ".28.1.1.12" // RegMode
 
+	// === ONU ACTIONS (.1012.3.50.11.3.1) - zxGponOntActionsTable ===
+	// Index {oltId}.{onuId} seperti tabel .50.11.2. Belum terverifikasi di OLT
+	// riset (docs/OID_RESEARCH_FINAL.md); firmware yang tidak mendukung
+	// menolak SET dan driver mengembalikan driver.ErrNotSupported.
+	OnuRebootOID  = ".50.11.3.1.1" // Reboot (SET 1)
+	OnuRestoreOID = ".50.11.3.1.2" // Restore factory default (SET 1)
+
+	// Nilai TargetState untuk enable/disable ONU
+	TargetStateDeactive = 1
+	TargetStateActive   = 2
+
 	// === DISTANCE (.1012.3.11.4.1) ===
 	DistanceBase       = ".11.4.1" // Under BaseOID2.3
 	OnuEQDOID          = ".11.4.1.1" // Equalized Delay
@@ -63,6 +74,11 @@ const (
 	PonTxOctetsOID         = ".1010.5.4.1.17" // TX Bytes (Counter64)
 	PonTxPktsOID           = ".1010.5.4.1.18" // TX Packets (Counter64)
 
+	// === OPTICAL MODULE (.1015.1010.11.2.1) - ZTE-AN-OPTICAL-MODULE-MIB ===
+	// Daya optik yang diterima OLT dari setiap ONU (arah upstream), indeks
+	// ifIndex ONU, satuan 0.001 dBm
+	OnuOLTRxPowerOID = ".1010.11.2.1.2" // Under BaseOID3
+
 	// === VLAN (.2.1.17.7.1.4.3) - Standard IF-MIB ===
 	VlanNameBase = ".1.3.6.1.2.1.17.7.1.4.3.1.1" // VLAN Names
 	VlanPVIDBase = ".1.3.6.1.2.1.17.7.1.4.5.1.1" // Port PVID
@@ -98,6 +114,9 @@ const (
 	SysContactOID = ".1.3.6.1.2.1.1.4.0"
 	SysLocationOID = ".1.3.6.1.2.1.1.6.0"
 
+	// Jam OLT (HOST-RESOURCES-MIB hrSystemDate, DateAndTime)
+	HrSystemDateOID = ".1.3.6.1.2.1.25.1.2.0"
+
 	// OID untuk Fan
 	FanTableOID       = ".1.3.6.1.4.1.3902.1015.2.1.3.10.10.10"
 	FanSpeedLevelOID  = ".1.3.6.1.4.1.3902.1015.2.1.3.10.10.10.1.3"
@@ -131,6 +150,10 @@ const (
 	MaxBoards       = 4
 	MaxPonPerBoard  = 16
 	MaxOnuPerPon    = 128
+
+	// Line rate GPON (ITU-T G.984) dalam kbps
+	PonDownstreamKbps = 2488320
+	PonUpstreamKbps   = 1244160
 )
 
 // ModelInfo mengembalikan informasi model C320.
@@ -141,6 +164,9 @@ func ModelInfo() driver.ModelInfo {
 		MaxBoards:      MaxBoards,
 		MaxPonPerBoard: MaxPonPerBoard,
 		MaxOnuPerPon:   MaxOnuPerPon,
+
+		PonDownstreamKbps: PonDownstreamKbps,
+		PonUpstreamKbps:   PonUpstreamKbps,
 	}
 }
 
@@ -2,10 +2,15 @@ package driver
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ardani/snmp-zte/internal/model"
 )
 
+// ErrNotSupported dikembalikan saat OLT menolak operasi karena OID tidak ada
+// atau tidak bisa ditulis di firmware tersebut
+var ErrNotSupported = errors.New("operation not supported by OLT")
+
 // Interface Driver mendefinisikan metode yang harus diimplementasikan oleh semua driver OLT
 type Driver interface {
 	// Metadata
@@ -17,6 +22,10 @@ type Driver interface {
 	GetONUDetail(ctx context.Context, boardID, ponID, onuID int) (*model.ONUDetail, error)
 	GetEmptySlots(ctx context.Context, boardID, ponID int) ([]model.ONUSlot, error)
 	GetONUTraffic(ctx context.Context, boardID, ponID, onuID int) (*model.ONUTraffic, error)
+	GetONUTrafficCounters(ctx context.Context, boardID, ponID int, onuIDs []int) ([]model.ONUTraffic, error)
+	GetOLTRxPower(ctx context.Context, boardID, ponID int, onuIDs []int) (map[int]float64, error)
+	GetONUStatuses(ctx context.Context, boardID, ponID int) (map[int]string, error)
+	GetONUOfflineReasons(ctx context.Context, boardID, ponID int, onuIDs []int) (map[int]string, error)
 	GetONUBandwidth(ctx context.Context, boardID, ponID, onuID int) (*model.ONUBandwidth, error)
 
 	// Info OLT
@@ -35,6 +44,13 @@ type Driver interface {
 	DeleteONU(ctx context.Context, boardID, ponID, onuID int) error
 	RenameONU(ctx context.Context, boardID, ponID, onuID int, name string) error
 	GetONUStatus(ctx context.Context, boardID, ponID, onuID int) (int, error)
+
+	// Manajemen remote ONU
+	RebootONU(ctx context.Context, boardID, ponID, onuID int) error
+	RestoreONU(ctx context.Context, boardID, ponID, onuID int) error // Kembali ke setelan pabrik
+	SetONUAdminState(ctx context.Context, boardID, ponID, onuID int, enabled bool) error
+	GetONUEthPorts(ctx context.Context, boardID, ponID, onuID int) ([]model.ONUEthPort, error)
+	SetONUEthPortState(ctx context.Context, boardID, ponID, onuID, port int, enabled bool) error
 	
 	// Statistics
 	GetDistance(ctx context.Context, boardID, ponID, onuID int) (*model.ONUDistance, error)
@@ -55,6 +71,7 @@ type Driver interface {
 	// Koneksi
 	Connect() error
 	Close() error
+	Probe(ctx context.Context) error // GET sysUpTime untuk cek OLT menjawab SNMP
 }
 
 // ModelInfo merepresentasikan informasi model OLT
@@ -64,6 +81,9 @@ type ModelInfo struct {
 	MaxBoards      int    `json:"max_boards"`
 	MaxPonPerBoard int    `json:"max_pon_per_board"`
 	MaxOnuPerPon   int    `json:"max_onu_per_pon"`
+
+	PonDownstreamKbps int64 `json:"pon_downstream_kbps"` // Line rate PON
+	PonUpstreamKbps   int64 `json:"pon_upstream_kbps"`
 }
 
 // SystemInfo merepresentasikan informasi sistem OLT
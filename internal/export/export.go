@@ -0,0 +1,326 @@
+// Package export menulis daftar (ONU, slot, statistik) sebagai CSV atau
+// XLSX untuk dibuka di spreadsheet. Kolom diambil dari field struct sesuai
+// urutan deklarasi dan tag json, sehingga urutannya stabil dan sama dengan
+// respons JSON. Tag `unit` (misal `unit:"dBm"`) ditambahkan ke judul kolom
+// dan menandai nilai string yang ditulis sebagai angka.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format hasil export
+type Format string
+
+const (
+	JSON Format = "json"
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// Content type untuk setiap format
+const (
+	ContentTypeCSV  = "text/csv"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ErrUnsupported data tidak berbentuk struct atau slice struct
+var ErrUnsupported = errors.New("data cannot be exported as a table")
+
+// Negotiate menentukan format dari parameter ?format= (prioritas) atau
+// header Accept. Tanpa keduanya hasilnya JSON.
+func Negotiate(r *http.Request) (Format, error) {
+	switch f := strings.ToLower(r.URL.Query().Get("format")); f {
+	case "":
+	case string(JSON), string(CSV), string(XLSX):
+		return Format(f), nil
+	default:
+		return "", fmt.Errorf("Invalid format %q (supported: json, csv, xlsx)", f)
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case ContentTypeCSV:
+			return CSV, nil
+		case ContentTypeXLSX:
+			return XLSX, nil
+		case "application/json", "*/*":
+			return JSON, nil
+		}
+	}
+	return JSON, nil
+}
+
+// Locale pemisah desimal dan kolom CSV
+type Locale struct {
+	Decimal   byte // Pemisah desimal angka
+	Delimiter rune // Pemisah kolom CSV
+}
+
+var (
+	// LocaleEN 1234.5 dipisah koma
+	LocaleEN = Locale{Decimal: '.', Delimiter: ','}
+	// LocaleID 1234,5 dipisah titik koma (Excel dengan regional Indonesia)
+	LocaleID = Locale{Decimal: ',', Delimiter: ';'}
+)
+
+// ParseLocale membaca parameter ?locale= (en atau id, default en)
+func ParseLocale(r *http.Request) (Locale, error) {
+	switch l := strings.ToLower(r.URL.Query().Get("locale")); l {
+	case "", "en":
+		return LocaleEN, nil
+	case "id":
+		return LocaleID, nil
+	default:
+		return Locale{}, fmt.Errorf("Invalid locale %q (supported: en, id)", l)
+	}
+}
+
+// Write mengirim data (slice struct, struct, atau pointer ke struct) sebagai
+// file CSV/XLSX. Baris ditulis langsung ke w satu per satu. name dipakai
+// sebagai nama file tanpa ekstensi.
+func Write(w http.ResponseWriter, format Format, name string, data interface{}, locale Locale) error {
+	rows, t, err := rowsOf(data)
+	if err != nil {
+		return err
+	}
+	cols := columns(t)
+
+	filename := name + "." + string(format)
+	switch format {
+	case CSV:
+		w.Header().Set("Content-Type", ContentTypeCSV+"; charset=utf-8")
+	case XLSX:
+		w.Header().Set("Content-Type", ContentTypeXLSX)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	var tw tableWriter
+	if format == CSV {
+		tw = newCSVWriter(w, locale)
+	} else {
+		tw = newXLSXWriter(w)
+	}
+
+	header := make([]cell, len(cols))
+	for i, c := range cols {
+		header[i] = cell{text: c.title()}
+	}
+	if err := tw.header(header); err != nil {
+		return err
+	}
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		for row.Kind() == reflect.Pointer {
+			row = row.Elem()
+		}
+		cells := make([]cell, len(cols))
+		for j, c := range cols {
+			cells[j] = c.value(row)
+		}
+		if err := tw.row(cells); err != nil {
+			return err
+		}
+	}
+	return tw.close()
+}
+
+// rowsOf mengubah data menjadi slice struct beserta tipe elemennya
+func rowsOf(data interface{}) (reflect.Value, reflect.Type, error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, nil, ErrUnsupported
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		single := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+		single.Index(0).Set(v)
+		v = single
+	}
+	if v.Kind() != reflect.Slice {
+		return v, nil, ErrUnsupported
+	}
+	t := v.Type().Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return v, nil, ErrUnsupported
+	}
+	return v, t, nil
+}
+
+// column satu kolom tabel
+type column struct {
+	name  string
+	unit  string
+	index []int
+}
+
+func (c column) title() string {
+	if c.unit == "" {
+		return c.name
+	}
+	return c.name + " (" + c.unit + ")"
+}
+
+// columns mengikuti aturan encoding/json: field embedded diratakan dan field
+// yang lebih dangkal menimpa field bernama sama dari struct embedded, di
+// posisi kolom yang pertama kali muncul.
+func columns(t reflect.Type) []column {
+	var cols []column
+	depth := map[string]int{}
+	pos := map[string]int{}
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			idx := append(append([]int(nil), index...), i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, idx)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			col := column{name: name, unit: f.Tag.Get("unit"), index: idx}
+			if p, ok := pos[name]; ok {
+				if len(idx) < depth[name] {
+					cols[p] = col
+					depth[name] = len(idx)
+				}
+				continue
+			}
+			pos[name] = len(cols)
+			depth[name] = len(idx)
+			cols = append(cols, col)
+		}
+	}
+	walk(t, nil)
+	return cols
+}
+
+// cell nilai satu sel: angka atau teks
+type cell struct {
+	text  string
+	num   float64
+	isNum bool
+}
+
+func (c column) value(row reflect.Value) cell {
+	v := row.FieldByIndex(c.index)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return cell{}
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cell{num: float64(v.Int()), isNum: true}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cell{num: float64(v.Uint()), isNum: true}
+	case reflect.Float32, reflect.Float64:
+		return cell{num: v.Float(), isNum: true}
+	case reflect.Bool:
+		return cell{text: strconv.FormatBool(v.Bool())}
+	case reflect.String:
+		s := v.String()
+		// Nilai berunit (power, jarak) disimpan sebagai string di model
+		if c.unit != "" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return cell{num: f, isNum: true}
+			}
+		}
+		return cell{text: s}
+	}
+	if s, ok := v.Interface().([]string); ok {
+		return cell{text: strings.Join(s, ", ")}
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return cell{}
+		}
+		return cell{text: t.Format(time.RFC3339)}
+	}
+	b, _ := json.Marshal(v.Interface())
+	return cell{text: string(b)}
+}
+
+type tableWriter interface {
+	header(cells []cell) error
+	row(cells []cell) error
+	close() error
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	locale Locale
+	record []string
+}
+
+func newCSVWriter(w io.Writer, locale Locale) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = locale.Delimiter
+	return &csvWriter{w: cw, locale: locale}
+}
+
+func (c *csvWriter) header(cells []cell) error {
+	return c.row(cells)
+}
+
+func (c *csvWriter) row(cells []cell) error {
+	c.record = c.record[:0]
+	for _, v := range cells {
+		if v.isNum {
+			s := strconv.FormatFloat(v.num, 'f', -1, 64)
+			if c.locale.Decimal != '.' {
+				s = strings.Replace(s, ".", string(c.locale.Decimal), 1)
+			}
+			c.record = append(c.record, s)
+			continue
+		}
+		c.record = append(c.record, escapeFormula(v.text))
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula mencegah teks (misal nama ONU "=HYPERLINK(...)") dijalankan
+// sebagai formula saat CSV dibuka di spreadsheet
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,264 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exportBase struct {
+	Board int    `json:"board"`
+	Name  string `json:"name"`
+}
+
+// exportRow memakai struct embedded, field yang menimpa field embedded, tag
+// unit dan field yang tidak diekspor
+type exportRow struct {
+	exportBase
+	Name    string    `json:"name_override"`
+	ID      int       `json:"onu_id"`
+	RXPower string    `json:"rx_power" unit:"dBm"`
+	Ratio   float64   `json:"ratio"`
+	Online  bool      `json:"online"`
+	Tags    []string  `json:"tags"`
+	Seen    time.Time `json:"seen"`
+	Secret  string    `json:"-"`
+	note    string
+}
+
+func exportRows() []exportRow {
+	return []exportRow{
+		{exportBase{1, "dasar"}, "pelanggan-001", 3, "-20.5", 0.25, true, []string{"a", "b"}, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), "x", ""},
+		{exportBase{2, ""}, "=HYPERLINK(\"http://x\")", 1, "N/A", 1234.5, false, nil, time.Time{}, "x", ""},
+	}
+}
+
+var exportHeader = []string{"board", "name", "name_override", "onu_id", "rx_power (dBm)", "ratio", "online", "tags", "seen"}
+
+func writeExport(t *testing.T, format Format, data interface{}, locale Locale) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if err := Write(rec, format, "onu-list", data, locale); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func readCSV(t *testing.T, body io.Reader, comma rune) [][]string {
+	t.Helper()
+	r := csv.NewReader(body)
+	r.Comma = comma
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestWriteCSV(t *testing.T) {
+	rec := writeExport(t, CSV, exportRows(), LocaleEN)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="onu-list.csv"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	want := [][]string{
+		exportHeader,
+		{"1", "dasar", "pelanggan-001", "3", "-20.5", "0.25", "true", "a, b", "2026-01-02T03:04:05Z"},
+		{"2", "", `'=HYPERLINK("http://x")`, "1", "N/A", "1234.5", "false", "", ""},
+	}
+	got := readCSV(t, rec.Body, ',')
+	if len(got) != len(want) {
+		t.Fatalf("rows = %q", got)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriteCSVLocaleID(t *testing.T) {
+	rec := writeExport(t, CSV, exportRows(), LocaleID)
+
+	if !strings.HasPrefix(rec.Body.String(), strings.Join(exportHeader, ";")+"\n") {
+		t.Fatalf("header line = %q", strings.SplitN(rec.Body.String(), "\n", 2)[0])
+	}
+	got := readCSV(t, rec.Body, ';')
+	// Angka memakai koma desimal; teks tidak diubah
+	for i, want := range [][]string{
+		{"-20,5", "0,25", "a, b"},
+		{"N/A", "1234,5", ""},
+	} {
+		row := got[i+1]
+		if cells := []string{row[4], row[5], row[7]}; !slices.Equal(cells, want) {
+			t.Errorf("row %d = %q, want %q", i+1, cells, want)
+		}
+	}
+}
+
+func TestWriteSingleStruct(t *testing.T) {
+	row := exportRows()[0]
+	got := readCSV(t, writeExport(t, CSV, &row, LocaleEN).Body, ',')
+	if len(got) != 2 || got[1][3] != "3" {
+		t.Fatalf("rows = %q", got)
+	}
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	var nilRow *exportRow
+	for _, data := range []interface{}{[]string{"a"}, 42, nilRow, map[string]int{"a": 1}} {
+		rec := httptest.NewRecorder()
+		if err := Write(rec, CSV, "x", data, LocaleEN); err != ErrUnsupported {
+			t.Errorf("Write(%T) err = %v, want ErrUnsupported", data, err)
+		}
+		if rec.Body.Len() != 0 || rec.Header().Get("Content-Disposition") != "" {
+			t.Errorf("Write(%T) wrote a response", data)
+		}
+	}
+}
+
+// xlsxSheet bagian sheet1.xml yang dibaca kembali
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Style  string `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// TestWriteXLSX membuka kembali workbook: semua part ada dan XML valid,
+// lalu isi sheet dibandingkan dengan data
+func TestWriteXLSX(t *testing.T) {
+	rec := writeExport(t, XLSX, exportRows(), LocaleID)
+	if ct := rec.Header().Get("Content-Type"); ct != ContentTypeXLSX {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body := rec.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = b
+
+		var v struct{}
+		if err := xml.Unmarshal(b, &v); err != nil {
+			t.Errorf("%s: invalid XML: %v", f.Name, err)
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(sheet.Rows))
+	}
+	for i, c := range sheet.Rows[0].Cells {
+		if c.Inline != exportHeader[i] || c.Style != "1" || c.Ref != columnName(i)+"1" {
+			t.Errorf("header cell %d = %+v", i, c)
+		}
+	}
+
+	// Angka ditulis sebagai nilai numerik (titik desimal, tidak mengikuti
+	// locale), teks sebagai inline string tanpa escape formula
+	row := sheet.Rows[2].Cells
+	if row[2].Type != "inlineStr" || row[2].Inline != `=HYPERLINK("http://x")` {
+		t.Errorf("text cell = %+v", row[2])
+	}
+	if row[5].Type != "" || row[5].Value != "1234.5" {
+		t.Errorf("number cell = %+v", row[5])
+	}
+	if row[4].Inline != "N/A" || row[8].Ref != "I3" || row[8].Inline != "" {
+		t.Errorf("cells = %+v", row)
+	}
+	if v := sheet.Rows[1].Cells[4]; v.Value != "-20.5" {
+		t.Errorf("unit cell = %+v, want numeric -20.5", v)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		accept  string
+		want    Format
+		wantErr bool
+	}{
+		{"default json", "", "", JSON, false},
+		{"format csv", "format=csv", "", CSV, false},
+		{"format huruf besar", "format=XLSX", "", XLSX, false},
+		{"format mengalahkan Accept", "format=json", ContentTypeCSV, JSON, false},
+		{"format tidak dikenal", "format=pdf", "", "", true},
+		{"Accept csv", "", "text/csv", CSV, false},
+		{"Accept xlsx dengan parameter", "", ContentTypeXLSX + "; q=0.9", XLSX, false},
+		{"Accept pertama yang dikenal", "", "text/html, text/csv, application/json", CSV, false},
+		{"Accept wildcard", "", "*/*", JSON, false},
+		{"Accept tidak dikenal", "", "text/html", JSON, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, err := Negotiate(r)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Negotiate() = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	for query, want := range map[string]Locale{"": LocaleEN, "locale=en": LocaleEN, "locale=ID": LocaleID} {
+		got, err := ParseLocale(httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		if err != nil || got != want {
+			t.Errorf("ParseLocale(%q) = %+v, %v", query, got, err)
+		}
+	}
+	if _, err := ParseLocale(httptest.NewRequest(http.MethodGet, "/?locale=fr", nil)); err == nil {
+		t.Error("unknown locale accepted")
+	}
+}
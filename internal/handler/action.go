@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// Action godoc
+// @Summary Aksi Remote ONU (Reboot, Factory Reset, Enable/Disable)
+// @Description Menjalankan aksi pada ONU lewat SNMP SET. reboot, factory_reset dan disable memutus layanan pelanggan sehingga harus dikonfirmasi: request pertama tanpa confirm_token mengembalikan 202 dengan confirm_token (berlaku 2 menit, sekali pakai, hanya untuk caller, ONU dan aksi yang sama), lalu kirim ulang request dengan token tersebut. enable langsung dijalankan.
+// @Description Firmware yang tidak mendukung OID aksi mengembalikan 501; reboot tetap bisa lewat POST /api/v1/cli/onu/reset.
+// @Tags ONU
+// @Accept json
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Param request body model.ONUActionRequest true "Aksi dan token konfirmasi"
+// @Success 200 {object} response.Response{data=model.ONUActionResult}
+// @Success 202 {object} response.Response{data=model.ONUActionResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 501 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/actions [post]
+func (h *ONUHandler) Action(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+	boardID, err := strconv.Atoi(chi.URLParam(r, "board_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid board ID")
+		return
+	}
+	ponID, err := strconv.Atoi(chi.URLParam(r, "pon_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid PON ID")
+		return
+	}
+	onuID, err := strconv.Atoi(chi.URLParam(r, "onu_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid ONU ID")
+		return
+	}
+
+	var req model.ONUActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if !model.ValidONUAction(req.Action) {
+		response.BadRequest(w, "action must be reboot, factory_reset, enable or disable")
+		return
+	}
+
+	result := model.ONUActionResult{Board: boardID, PON: ponID, ONUID: onuID, Action: req.Action}
+	if !confirmONUAction(w, r, h.confirms, oltID, &result, req.ConfirmToken) {
+		return
+	}
+
+	if err := h.service.ONUAction(r.Context(), oltID, boardID, ponID, onuID, req.Action); err != nil {
+		actionError(w, err)
+		return
+	}
+	result.Status = model.ActionDone
+	result.Message = service.ONUActionMessage(boardID, ponID, onuID, req.Action)
+	response.JSON(w, http.StatusOK, result)
+}
+
+// confirmONUAction langkah konfirmasi aksi destruktif. Tanpa token, token
+// baru dikirim dengan status 202. Mengembalikan true jika aksi boleh
+// dijalankan; selain itu respons sudah ditulis.
+func confirmONUAction(w http.ResponseWriter, r *http.Request, confirms *service.Confirmations, target string, result *model.ONUActionResult, token string) bool {
+	if !model.DestructiveONUAction(result.Action) {
+		return true
+	}
+
+	caller := ""
+	if user := middleware.UserFromContext(r.Context()); user != nil {
+		caller = user.Username
+	}
+	subject := service.ConfirmationSubject(caller, target, result.Board, result.PON, result.ONUID, result.Action)
+
+	if token == "" {
+		token, expires := confirms.Issue(subject)
+		result.Status = model.ActionConfirmationRequired
+		result.ConfirmToken = token
+		result.ExpiresAt = expires.UTC().Format(time.RFC3339)
+		result.Message = "Resend the request with confirm_token to " + result.Action + " this ONU"
+		response.JSON(w, http.StatusAccepted, result)
+		return false
+	}
+	if err := confirms.Consume(token, subject); err != nil {
+		response.Error(w, http.StatusConflict, err.Error())
+		return false
+	}
+	return true
+}
+
+// actionError memetakan error aksi ONU ke status HTTP
+func actionError(w http.ResponseWriter, err error) {
+	var se *service.ServiceError
+	switch {
+	case errors.As(err, &se):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, driver.ErrNotSupported):
+		response.Error(w, http.StatusNotImplemented, err.Error()+" (for reboot use POST /api/v1/cli/onu/reset)")
+	default:
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+	}
+}
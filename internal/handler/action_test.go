@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+)
+
+// confirmCall memanggil confirmONUAction sebagai user operator
+func confirmCall(confirms *service.Confirmations, target string, result model.ONUActionResult, token string) (bool, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req = req.WithContext(middleware.WithUser(req.Context(), &model.User{Username: "operator"}))
+	ok := confirmONUAction(rec, req, confirms, target, &result, token)
+	return ok, rec
+}
+
+// issuedToken membaca confirm_token dari respons 202
+func issuedToken(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Data model.ONUActionResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Status != model.ActionConfirmationRequired || resp.Data.ConfirmToken == "" || resp.Data.ExpiresAt == "" {
+		t.Fatalf("data = %+v", resp.Data)
+	}
+	return resp.Data.ConfirmToken
+}
+
+func TestConfirmONUAction(t *testing.T) {
+	reboot := model.ONUActionResult{Board: 1, PON: 2, ONUID: 3, Action: model.ONUActionReboot}
+
+	t.Run("token diterbitkan lalu dipakai sekali", func(t *testing.T) {
+		confirms := service.NewConfirmations(time.Minute)
+		ok, rec := confirmCall(confirms, "olt-a", reboot, "")
+		if ok {
+			t.Fatal("action allowed without token")
+		}
+		token := issuedToken(t, rec)
+
+		if ok, rec := confirmCall(confirms, "olt-a", reboot, token); !ok || rec.Body.Len() != 0 {
+			t.Fatalf("confirmed = %v, body %s", ok, rec.Body)
+		}
+		ok, rec = confirmCall(confirms, "olt-a", reboot, token)
+		if ok || rec.Code != http.StatusConflict {
+			t.Fatalf("replay = %v, status %d, want 409", ok, rec.Code)
+		}
+	})
+
+	t.Run("token kedaluwarsa", func(t *testing.T) {
+		confirms := service.NewConfirmations(-time.Second)
+		_, rec := confirmCall(confirms, "olt-a", reboot, "")
+		ok, rec := confirmCall(confirms, "olt-a", reboot, issuedToken(t, rec))
+		if ok || rec.Code != http.StatusConflict {
+			t.Fatalf("expired = %v, status %d, want 409", ok, rec.Code)
+		}
+	})
+
+	mismatch := []struct {
+		name   string
+		target string
+		modify func(r *model.ONUActionResult)
+	}{
+		{"ONU lain", "olt-a", func(r *model.ONUActionResult) { r.ONUID = 4 }},
+		{"aksi lain", "olt-a", func(r *model.ONUActionResult) { r.Action = model.ONUActionFactoryReset }},
+		{"OLT lain", "olt-b", func(r *model.ONUActionResult) {}},
+	}
+	for _, tt := range mismatch {
+		t.Run(tt.name, func(t *testing.T) {
+			confirms := service.NewConfirmations(time.Minute)
+			_, rec := confirmCall(confirms, "olt-a", reboot, "")
+			token := issuedToken(t, rec)
+
+			other := reboot
+			tt.modify(&other)
+			ok, rec := confirmCall(confirms, tt.target, other, token)
+			if ok || rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "confirm_token") {
+				t.Fatalf("mismatch = %v, status %d: %s", ok, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	t.Run("enable tanpa token", func(t *testing.T) {
+		enable := reboot
+		enable.Action = model.ONUActionEnable
+		ok, rec := confirmCall(service.NewConfirmations(time.Minute), "olt-a", enable, "")
+		if !ok || rec.Body.Len() != 0 {
+			t.Fatalf("enable = %v, body %s", ok, rec.Body)
+		}
+	})
+}
+
+// TestQueryActionConfirmation memastikan query aksi destruktif berhenti di
+// 202 sebelum menyentuh OLT dan token terikat ke IP OLT
+func TestQueryActionConfirmation(t *testing.T) {
+	h := NewQueryHandler(nil, nil, nil, service.NewConfirmations(time.Minute))
+	query := func(ip, body string) *httptest.ResponseRecorder {
+		body = `{"ip":"` + ip + `","community":"private","query":"onu_reboot","board":1,"pon":2` + body + `}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(body))
+		user := &model.User{Username: "operator", Role: model.RoleOperator}
+		req = req.WithContext(middleware.WithUser(req.Context(), user))
+		rec := httptest.NewRecorder()
+		h.Query(rec, req)
+		return rec
+	}
+
+	if rec := query("10.0.0.1", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("without onu_id status = %d, want 400", rec.Code)
+	}
+	token := issuedToken(t, query("10.0.0.1", `,"onu_id":3`))
+	if rec := query("10.0.0.2", `,"onu_id":3,"confirm_token":"`+token+`"`); rec.Code != http.StatusConflict {
+		t.Fatalf("other OLT status = %d, want 409", rec.Code)
+	}
+	if rec := query("10.0.0.1", `,"onu_id":4,"confirm_token":"`+token+`"`); rec.Code != http.StatusConflict {
+		t.Fatalf("other ONU status = %d, want 409", rec.Code)
+	}
+}
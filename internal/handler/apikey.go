@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKeyHandler menangani penerbitan dan pencabutan API key.
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+// NewAPIKeyHandler membuat handler API key baru.
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// List godoc
+// @Summary List API Key
+// @Description Mengambil daftar API key (tanpa nilai key), termasuk yang sudah dicabut/kedaluwarsa.
+// @Tags Users
+// @Produce json
+// @Success 200 {array} model.APIKey
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.service.List())
+}
+
+// Create godoc
+// @Summary Buat API Key
+// @Description Menerbitkan API key dengan role dan scope OLT. Nilai key hanya ditampilkan sekali di respons ini. Gunakan sebagai "Authorization: Bearer {key}" atau header "X-API-Key".
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body model.APIKeyRequest true "Data API Key"
+// @Success 201 {object} model.IssuedAPIKey
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	var createdBy string
+	if user := middleware.UserFromContext(r.Context()); user != nil {
+		createdBy = user.Username
+	}
+
+	key, err := h.service.Issue(req, createdBy)
+	if err != nil {
+		var se *service.ServiceError
+		if errors.As(err, &se) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		response.InternalError(w, err.Error())
+		return
+	}
+	response.JSON(w, http.StatusCreated, key)
+}
+
+// Revoke godoc
+// @Summary Cabut API Key
+// @Tags Users
+// @Produce json
+// @Param key_id path string true "ID API Key"
+// @Success 200 {object} model.APIKey
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/api-keys/{key_id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	key, err := h.service.Revoke(chi.URLParam(r, "key_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, err.Error())
+		return
+	}
+	response.JSON(w, http.StatusOK, key)
+}
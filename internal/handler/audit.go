@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/audit"
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/rs/zerolog/log"
+)
+
+// AuditHandler menampilkan dan mengekspor audit log operasi tulis.
+type AuditHandler struct {
+	store *audit.Store
+}
+
+// NewAuditHandler membuat handler audit baru.
+func NewAuditHandler(store *audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// AuditList hasil pencarian audit log
+type AuditList struct {
+	Entries []audit.Entry `json:"entries"`
+	// NextBefore nilai parameter before untuk halaman berikutnya (0 = habis)
+	NextBefore int64 `json:"next_before,omitempty"`
+}
+
+// List godoc
+// @Summary Cari Audit Log
+// @Description Mengambil catatan audit operasi tulis, terbaru lebih dulu. Halaman berikutnya diambil dengan before=next_before.
+// @Tags Audit
+// @Produce json
+// @Param user query string false "Username caller"
+// @Param olt_id query string false "ID OLT target"
+// @Param method query string false "HTTP method"
+// @Param path query string false "Prefix path endpoint"
+// @Param result query string false "success atau failure"
+// @Param since query string false "RFC3339, inklusif"
+// @Param until query string false "RFC3339, eksklusif"
+// @Param before query int false "Hanya entry dengan ID lebih kecil"
+// @Param limit query int false "Jumlah entry (default 100, max 1000)"
+// @Success 200 {object} AuditList
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilter(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	entries, err := h.store.Query(filter)
+	if err != nil {
+		response.InternalError(w, err.Error())
+		return
+	}
+
+	result := AuditList{Entries: entries}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = audit.DefaultLimit
+	}
+	if len(entries) == min(limit, audit.MaxLimit) {
+		result.NextBefore = entries[len(entries)-1].ID
+	}
+	response.JSON(w, http.StatusOK, result)
+}
+
+// Export godoc
+// @Summary Export Audit Log
+// @Description Mengunduh semua catatan audit yang cocok dengan filter (tanpa limit) sebagai JSON Lines, urut dari yang terlama.
+// @Tags Audit
+// @Produce plain
+// @Param user query string false "Username caller"
+// @Param olt_id query string false "ID OLT target"
+// @Param since query string false "RFC3339, inklusif"
+// @Param until query string false "RFC3339, eksklusif"
+// @Success 200 {string} string "JSON Lines"
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/audit/export [get]
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter, err := auditFilter(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	filter.Limit = 0
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-`+time.Now().UTC().Format("20060102-150405")+`.jsonl"`)
+	if err := h.store.Export(w, filter); err != nil {
+		// Header sudah terkirim; hanya bisa dicatat
+		log.Error().Err(err).Msg("Failed to export audit log")
+	}
+}
+
+// auditFilter membaca filter dari query string. User yang dibatasi ke OLT
+// tertentu hanya melihat entry untuk OLT tersebut.
+func auditFilter(r *http.Request) (audit.Filter, error) {
+	q := r.URL.Query()
+	f := audit.Filter{
+		User:   q.Get("user"),
+		OLTID:  q.Get("olt_id"),
+		Method: q.Get("method"),
+		Path:   q.Get("path"),
+		Result: q.Get("result"),
+	}
+
+	var err error
+	if v := q.Get("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, errors.New("Invalid 'since' parameter")
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if f.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, errors.New("Invalid 'until' parameter")
+		}
+	}
+	if v := q.Get("before"); v != "" {
+		if f.Before, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return f, errors.New("Invalid 'before' parameter")
+		}
+	}
+	if f.Limit, err = queryInt(r, "limit"); err != nil {
+		return f, errors.New("Invalid 'limit' parameter")
+	}
+
+	if user := middleware.UserFromContext(r.Context()); user != nil && user.Scoped() {
+		f.OLTs = user.OLTs
+	}
+	return f, nil
+}
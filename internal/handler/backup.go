@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ardani/snmp-zte/internal/backup"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// BackupHandler menangani riwayat backup running-config per OLT.
+type BackupHandler struct {
+	service *service.BackupService
+}
+
+// NewBackupHandler membuat handler backup baru.
+func NewBackupHandler(service *service.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// BackupList daftar versi backup satu OLT
+type BackupList struct {
+	OLTID string `json:"olt_id"`
+	// UnsavedChanges status versi terbaru: running-config berbeda dengan startup-config
+	UnsavedChanges *bool            `json:"unsaved_changes,omitempty"`
+	Versions       []backup.Version `json:"versions"`
+}
+
+// BackupDetail metadata dan isi satu versi backup
+type BackupDetail struct {
+	backup.Version
+	Content string `json:"content"`
+}
+
+// List godoc
+// @Summary List Backup Config
+// @Description Mengambil daftar versi backup running-config milik OLT, terbaru lebih dulu.
+// @Tags Backup
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Success 200 {object} BackupList
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/backups [get]
+func (h *BackupHandler) List(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	versions, err := h.service.List(oltID)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+
+	result := BackupList{OLTID: oltID, Versions: versions}
+	if result.Versions == nil {
+		result.Versions = []backup.Version{}
+	}
+	if len(versions) > 0 {
+		result.UnsavedChanges = versions[0].Unsaved
+	}
+	response.JSON(w, http.StatusOK, result)
+}
+
+// Create godoc
+// @Summary Backup Config Sekarang
+// @Description Mengambil running-config OLT via Telnet dan menyimpannya sebagai versi baru. Jika isi sama dengan versi terakhir, tidak ada versi baru (created=false).
+// @Tags Backup
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Success 201 {object} service.BackupResult
+// @Success 200 {object} service.BackupResult
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/backups [post]
+func (h *BackupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	result, err := h.service.Capture(r.Context(), oltID, backup.SourceManual)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+	response.JSON(w, status, result)
+}
+
+// Get godoc
+// @Summary Ambil Versi Backup
+// @Description Mengambil metadata dan isi satu versi backup. Gunakan format=raw untuk mengambil isi config sebagai text/plain.
+// @Tags Backup
+// @Produce json
+// @Produce plain
+// @Param olt_id path string true "ID OLT"
+// @Param version_id path int true "ID Versi"
+// @Param format query string false "raw untuk text/plain"
+// @Success 200 {object} BackupDetail
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/backups/{version_id} [get]
+func (h *BackupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+	versionID, err := strconv.Atoi(chi.URLParam(r, "version_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid version ID")
+		return
+	}
+
+	version, content, err := h.service.Get(oltID, versionID)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "raw" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("ETag", `"`+version.Hash+`"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, BackupDetail{Version: version, Content: string(content)})
+}
+
+// Diff godoc
+// @Summary Diff Dua Versi Backup
+// @Description Membandingkan dua versi backup (unified diff). Tanpa parameter, versi terbaru dibandingkan dengan versi sebelumnya.
+// @Tags Backup
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param from query int false "ID versi lama (default: versi sebelum 'to')"
+// @Param to query int false "ID versi baru (default: versi terbaru)"
+// @Success 200 {object} backup.Diff
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/backups/diff [get]
+func (h *BackupHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	from, err := queryInt(r, "from")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'from' version")
+		return
+	}
+	to, err := queryInt(r, "to")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'to' version")
+		return
+	}
+
+	diff, err := h.service.Diff(oltID, from, to)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, diff)
+}
+
+func (h *BackupHandler) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOLTNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, backup.ErrVersionNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, service.ErrCLIConnect):
+		response.Error(w, http.StatusGatewayTimeout, err.Error())
+	default:
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// queryInt membaca query parameter integer opsional (kosong = 0)
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
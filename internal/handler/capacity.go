@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// Capacity godoc
+// @Summary Laporan Kapasitas & Utilisasi PON
+// @Description Untuk setiap PON di board: jumlah ONU dibanding slot maksimum, jumlah bandwidth assured dibanding line rate GPON, utilisasi trafik selama window, dan jarak ONU terjauh. PON ditandai oversubscribed, full, near_full, high_utilization atau long_reach. Board wajib; seluruh OLT tidak didukung karena tidak selesai dalam batas waktu request. Tersedia sebagai JSON, CSV atau XLSX.
+// @Tags ONU
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param olt_id path string true "ID OLT"
+// @Param board query int true "ID Board"
+// @Param window query int false "Lama pengukuran trafik dalam detik (default 10, 5-60)"
+// @Param format query string false "json (default), csv atau xlsx; bisa juga lewat header Accept"
+// @Param locale query string false "Format angka CSV: en (1.5, pemisah koma, default) atau id (1,5, pemisah titik koma)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/capacity [get]
+func (h *ONUHandler) Capacity(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	var opts service.CapacityOptions
+	var err error
+	if opts.Board, err = queryInt(r, "board"); err != nil {
+		response.BadRequest(w, "Invalid 'board' parameter")
+		return
+	}
+	window, err := queryInt(r, "window")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'window' parameter")
+		return
+	}
+	opts.Window = time.Duration(window) * time.Second
+	if err := opts.Validate(); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	report, err := h.service.Capacity(r.Context(), oltID, opts)
+	if err != nil {
+		var se *service.ServiceError
+		if errors.As(err, &se) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if out.file() {
+		out.write(w, exportName("capacity", oltID), report.PONs)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
@@ -5,18 +5,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/ardani/snmp-zte/internal/backup"
 	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/limiter"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
 	"github.com/ardani/snmp-zte/pkg/response"
 )
 
 // CLIHandler menangani CLI commands via Telnet
-type CLIHandler struct{}
+type CLIHandler struct {
+	drift   *service.DriftService
+	backups *service.BackupService
+	limits  *limiter.Limiter
+}
 
-// NewCLIHandler membuat handler CLI baru
-func NewCLIHandler() *CLIHandler {
-	return &CLIHandler{}
+// NewCLIHandler membuat handler CLI baru. backups dipakai oleh
+// config/backup dan config/restore saat memakai server TFTP internal;
+// limits membatasi jumlah sesi Telnet per OLT.
+func NewCLIHandler(backups *service.BackupService, limits *limiter.Limiter) *CLIHandler {
+	return &CLIHandler{
+		drift:   service.NewDriftService(),
+		backups: backups,
+		limits:  limits,
+	}
 }
 
 // CLIRequest permintaan CLI
@@ -62,6 +78,28 @@ func (h *CLIHandler) getClient(req CLIRequest) *cli.ZTEC320Client {
 	return cli.NewZTEC320Client(cfg)
 }
 
+// connect membuka sesi Telnet ke OLT setelah mendapat giliran dari limiter
+// perangkat. Jika gagal, respons error sudah dikirim dan ok bernilai false.
+// release menutup sesi dan mengembalikan slot Telnet.
+func (h *CLIHandler) connect(w http.ResponseWriter, r *http.Request, req CLIRequest) (client *cli.ZTEC320Client, release func(), ok bool) {
+	unlock, err := h.limits.AcquireTelnet(r.Context(), req.Host)
+	if err != nil {
+		deviceError(w, err, http.StatusServiceUnavailable, "Timed out waiting for OLT: "+err.Error())
+		return nil, nil, false
+	}
+
+	client = h.getClient(req)
+	if err := client.Connect(); err != nil {
+		unlock()
+		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+		return nil, nil, false
+	}
+	return client, func() {
+		client.Close()
+		unlock()
+	}, true
+}
+
 // respond helper
 func (h *CLIHandler) respond(w http.ResponseWriter, query string, data interface{}, start time.Time) {
 	response.JSON(w, http.StatusOK, CLIResponse{
@@ -96,12 +134,11 @@ func (h *CLIHandler) ShowClock(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	output, err := client.Execute(ctx, "show clock")
 	if err != nil {
@@ -133,12 +170,11 @@ func (h *CLIHandler) ShowCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	result, err := client.ShowCard(ctx)
 	if err != nil {
@@ -159,14 +195,13 @@ func (h *CLIHandler) ShowRack(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show rack")
+	output, err := client.ShowRack(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -185,14 +220,13 @@ func (h *CLIHandler) ShowShelf(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show shelf")
+	output, err := client.ShowShelf(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -211,14 +245,13 @@ func (h *CLIHandler) ShowFan(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show fan")
+	output, err := client.ShowFan(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -241,14 +274,13 @@ func (h *CLIHandler) ShowTcontProfile(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show gpon profile tcont")
+	output, err := client.ShowGPONProfileTcont(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -267,14 +299,13 @@ func (h *CLIHandler) ShowOnuType(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show onu-type gpon")
+	output, err := client.ShowONUTypeList(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -293,14 +324,13 @@ func (h *CLIHandler) ShowVlanProfile(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show gpon onu profile vlan")
+	output, err := client.ShowVlanProfile(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -340,12 +370,11 @@ func (h *CLIHandler) ShowONUState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	result, err := client.ShowGPONONUState(ctx, rack, shelf, req.Slot)
 	if err != nil {
@@ -383,12 +412,11 @@ func (h *CLIHandler) ShowONUUncfg(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	result, err := client.ShowGPONONUUnCfg(ctx, rack, shelf, req.Slot)
 	if err != nil {
@@ -426,12 +454,11 @@ func (h *CLIHandler) ShowONUConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	cmd := fmt.Sprintf("show running-config interface gpon-onu_%d/%d/%d:%d", rack, shelf, req.Slot, req.OnuID)
 	output, err := client.Execute(ctx, cmd)
@@ -470,12 +497,11 @@ func (h *CLIHandler) ShowONURunning(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	cmd := fmt.Sprintf("show onu running config gpon-onu_%d/%d/%d:%d", rack, shelf, req.Slot, req.OnuID)
 	output, err := client.Execute(ctx, cmd)
@@ -509,15 +535,13 @@ func (h *CLIHandler) ShowInterface(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	cmd := fmt.Sprintf("show interface %s", req.Name)
-	output, err := client.Execute(ctx, cmd)
+	output, err := client.ShowInterfaceByType(ctx, req.Name)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -536,14 +560,13 @@ func (h *CLIHandler) ShowMgmtInterface(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show interface mng1")
+	output, err := client.ShowInterfaceByType(ctx, "mng1")
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -583,15 +606,13 @@ func (h *CLIHandler) ShowServicePort(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	cmd := fmt.Sprintf("show service-port interface gpon-onu_%d/%d/%d:%d", rack, shelf, req.Slot, req.OnuID)
-	output, err := client.Execute(ctx, cmd)
+	output, err := client.ShowServicePort(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -614,14 +635,13 @@ func (h *CLIHandler) ShowIGMP(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show igmp")
+	output, err := client.ShowIGMP(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -644,14 +664,13 @@ func (h *CLIHandler) ShowUsers(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
-	output, err := client.Execute(ctx, "show username")
+	output, err := client.ShowLocalUsers(ctx)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -691,12 +710,11 @@ func (h *CLIHandler) ShowONUDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowONUDetail(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
@@ -734,12 +752,11 @@ func (h *CLIHandler) ShowONUBaseInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowGPONONUBaseInfo(ctx, rack, shelf, req.Slot)
 	if err != nil {
@@ -777,12 +794,11 @@ func (h *CLIHandler) ShowONUTraffic(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowONUTraffic(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
@@ -820,12 +836,11 @@ func (h *CLIHandler) ShowONUOptical(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowONUOptical(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
@@ -858,12 +873,11 @@ func (h *CLIHandler) ShowCardBySlot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowCardBySlot(ctx, req.Slot)
 	if err != nil {
@@ -884,12 +898,11 @@ func (h *CLIHandler) ShowSubCard(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowSubCard(ctx)
 	if err != nil {
@@ -922,12 +935,11 @@ func (h *CLIHandler) ShowIPProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowIPProfile(ctx, req.Name)
 	if err != nil {
@@ -956,12 +968,11 @@ func (h *CLIHandler) ShowSIPProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowSIPProfile(ctx, req.Name)
 	if err != nil {
@@ -990,12 +1001,11 @@ func (h *CLIHandler) ShowMGCProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowMGCProfile(ctx, req.Name)
 	if err != nil {
@@ -1020,12 +1030,11 @@ func (h *CLIHandler) ShowLineProfileList(w http.ResponseWriter, r *http.Request)
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowLineProfileList(ctx)
 	if err != nil {
@@ -1054,12 +1063,11 @@ func (h *CLIHandler) ShowLineProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowLineProfile(ctx, req.Name)
 	if err != nil {
@@ -1080,12 +1088,11 @@ func (h *CLIHandler) ShowRemoteProfileList(w http.ResponseWriter, r *http.Reques
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowRemoteProfileList(ctx)
 	if err != nil {
@@ -1114,12 +1121,11 @@ func (h *CLIHandler) ShowRemoteProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowRemoteProfile(ctx, req.Name)
 	if err != nil {
@@ -1144,12 +1150,11 @@ func (h *CLIHandler) ShowVLANList(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowVLANList(ctx)
 	if err != nil {
@@ -1178,12 +1183,11 @@ func (h *CLIHandler) ShowVLANByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowVLANByID(ctx, req.VlanID)
 	if err != nil {
@@ -1208,12 +1212,11 @@ func (h *CLIHandler) ShowIGMPMVlan(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowIGMPMVlan(ctx)
 	if err != nil {
@@ -1242,12 +1245,11 @@ func (h *CLIHandler) ShowIGMPMVlanByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowIGMPMVlanByID(ctx, req.VlanID)
 	if err != nil {
@@ -1268,12 +1270,11 @@ func (h *CLIHandler) ShowIGMPDynamicMember(w http.ResponseWriter, r *http.Reques
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	output, err := client.ShowIGMPDynamicMember(ctx)
 	if err != nil {
@@ -1294,12 +1295,11 @@ func (h *CLIHandler) ShowIGMPForwardingTable(w http.ResponseWriter, r *http.Requ
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	output, err := client.ShowIGMPForwardingTable(ctx)
 	if err != nil {
@@ -1320,12 +1320,11 @@ func (h *CLIHandler) ShowIGMPInterface(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	output, err := client.ShowIGMPInterface(ctx)
 	if err != nil {
@@ -1358,12 +1357,11 @@ func (h *CLIHandler) ShowInterfaceByType(w http.ResponseWriter, r *http.Request)
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowInterfaceByType(ctx, req.Name)
 	if err != nil {
@@ -1388,12 +1386,11 @@ func (h *CLIHandler) ShowOnlineUsers(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowOnlineUsers(ctx)
 	if err != nil {
@@ -1426,12 +1423,11 @@ func (h *CLIHandler) ShowDialPlanProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowDialPlanProfile(ctx, req.Name)
 	if err != nil {
@@ -1460,12 +1456,11 @@ func (h *CLIHandler) ShowVoipAccesscodeProfile(w http.ResponseWriter, r *http.Re
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowVoipAccesscodeProfile(ctx, req.Name)
 	if err != nil {
@@ -1494,12 +1489,11 @@ func (h *CLIHandler) ShowVoipAppsrvProfile(w http.ResponseWriter, r *http.Reques
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowVoipAppsrvProfile(ctx, req.Name)
 	if err != nil {
@@ -1520,12 +1514,11 @@ func (h *CLIHandler) ShowSNMPCommunity(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowSNMPCommunity(ctx)
 	if err != nil {
@@ -1546,12 +1539,11 @@ func (h *CLIHandler) ShowSNMPHost(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowSNMPHost(ctx)
 	if err != nil {
@@ -1572,12 +1564,11 @@ func (h *CLIHandler) ShowRunningConfig(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	output, err := client.ShowRunningConfig(ctx)
 	if err != nil {
@@ -1588,6 +1579,103 @@ func (h *CLIHandler) ShowRunningConfig(w http.ResponseWriter, r *http.Request) {
 	h.respond(w, "show_running-config", output, start)
 }
 
+// ShowRunningConfigParsed godoc
+// @Summary Show Running Config (Parsed)
+// @Description Running config dalam bentuk JSON terstruktur. Jika slot dan onu_id diisi, hanya konfigurasi ONU tersebut yang dikembalikan (termasuk daftar VLAN).
+// @Tags CLI-Config
+// @Router /api/v1/cli/config/running/parsed [post]
+func (h *CLIHandler) ShowRunningConfigParsed(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var req CLIRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	rack := req.Rack
+	shelf := req.Shelf
+	if rack == 0 {
+		rack = 1
+	}
+	if shelf == 0 {
+		shelf = 1
+	}
+
+	ctx := context.Background()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
+		return
+	}
+	defer release()
+
+	cfg, err := client.ShowRunningConfigParsed(ctx)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if req.Slot > 0 && req.OnuID > 0 {
+		onu := cfg.ONU(rack, shelf, req.Slot, req.OnuID)
+		if onu == nil {
+			response.NotFound(w, fmt.Sprintf("ONU gpon-onu_%d/%d/%d:%d not found in running-config", rack, shelf, req.Slot, req.OnuID))
+			return
+		}
+		h.respond(w, "show_running-config_onu", onu, start)
+		return
+	}
+
+	h.respond(w, "show_running-config_parsed", cfg, start)
+}
+
+// DriftRequest permintaan drift detection
+type DriftRequest struct {
+	CLIRequest
+	Desired   model.DesiredState `json:"desired"`
+	Reconcile bool               `json:"reconcile,omitempty"`
+}
+
+// ConfigDrift godoc
+// @Summary Detect Configuration Drift
+// @Description Bandingkan desired state (ONU dan autentikasi sn/pw/loid, nama, VLAN, T-CONT/GEM/service port, line/remote profile, service dan vlan port pon-onu-mng) dengan running-config OLT. SN dibandingkan tanpa memperhatikan huruf besar/kecil. Jika reconcile=true, response berisi perintah CLI untuk menyamakan OLT (tidak dieksekusi).
+// @Tags CLI-Config
+// @Accept json
+// @Produce json
+// @Param request body DriftRequest true "Connection + desired state"
+// @Success 200 {object} response.Response
+// @Router /api/v1/cli/config/drift [post]
+func (h *CLIHandler) ConfigDrift(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var req DriftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request")
+		return
+	}
+
+	for _, onu := range req.Desired.ONUs {
+		mode, value := onu.Auth()
+		if mode != model.ONUAuthSN && mode != model.ONUAuthPW && mode != model.ONUAuthLOID {
+			response.BadRequest(w, "auth_mode must be sn, pw or loid")
+			return
+		}
+		if onu.Slot == 0 || onu.ONUID == 0 || onu.Type == "" || value == "" {
+			response.BadRequest(w, "each desired onu requires slot, onu_id, type and sn (password for pw/loid)")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req.CLIRequest)
+	if !ok {
+		return
+	}
+	defer release()
+
+	cfg, err := client.ShowRunningConfigParsed(ctx)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.respond(w, "config_drift", h.drift.Compare(req.Desired, cfg, req.Reconcile), start)
+}
+
 // SaveConfig godoc
 // @Summary Save Config
 // @Tags CLI-Config
@@ -1597,13 +1685,12 @@ func (h *CLIHandler) SaveConfig(w http.ResponseWriter, r *http.Request) {
 	var req CLIRequest
 	json.NewDecoder(r.Body).Decode(&req)
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.SaveConfig(ctx)
 	if err != nil {
@@ -1619,6 +1706,7 @@ func (h *CLIHandler) SaveConfig(w http.ResponseWriter, r *http.Request) {
 
 // BackupConfig godoc
 // @Summary Backup Config to TFTP
+// @Description Menjalankan "copy running-config tftp://{name}/{sn}". Jika name (tftp_ip) kosong, server TFTP internal dipakai: file diunggah ke backup store OLT (host harus OLT terdaftar) dan versi yang terindeks dikembalikan.
 // @Tags CLI-Config
 // @Router /api/v1/cli/config/backup [post]
 func (h *CLIHandler) BackupConfig(w http.ResponseWriter, r *http.Request) {
@@ -1629,18 +1717,38 @@ func (h *CLIHandler) BackupConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" || req.SN == "" {
-		response.BadRequest(w, "name (tftp_ip) and sn (filename) are required")
+	embedded := req.Name == ""
+	var oltID string
+	if embedded {
+		var ok bool
+		if oltID, ok = h.backups.OLTIDByHost(req.Host); !ok {
+			response.BadRequest(w, "name (tftp_ip) is required unless host is a registered OLT")
+			return
+		}
+		if req.SN == "" {
+			req.SN = start.UTC().Format("20060102-150405") + ".cfg"
+		}
+		req.SN = oltID + "/" + path.Base(req.SN)
+	} else if req.SN == "" {
+		response.BadRequest(w, "sn (filename) is required")
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
+
+	if embedded {
+		host, err := h.backups.TFTPHost(client.LocalIP())
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		req.Name = host
+	}
 
 	output, err := client.BackupConfig(ctx, req.Name, req.SN)
 	if err != nil {
@@ -1648,16 +1756,25 @@ func (h *CLIHandler) BackupConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respond(w, "copy_running-config_tftp", map[string]interface{}{
+	data := map[string]interface{}{
 		"success":  true,
 		"message":  output,
 		"tftp_ip":  req.Name,
 		"filename": req.SN,
-	}, start)
+	}
+	// Upload sudah selesai saat perintah copy kembali; tampilkan versi hasilnya
+	if embedded {
+		if version, err := h.backups.Latest(oltID); err == nil && version.Source == backup.SourceUpload && !version.CreatedAt.Before(start.UTC().Truncate(time.Second)) {
+			data["version"] = version
+		}
+	}
+
+	h.respond(w, "copy_running-config_tftp", data, start)
 }
 
 // RestoreConfig godoc
 // @Summary Restore Config from TFTP
+// @Description Menjalankan "copy tftp://{name}/{sn} running-config". Jika name (tftp_ip) kosong, server TFTP internal dipakai dan sn adalah ID versi backup, checksum sha256, atau "latest" (default) milik OLT host tersebut.
 // @Tags CLI-Config
 // @Router /api/v1/cli/config/restore [post]
 func (h *CLIHandler) RestoreConfig(w http.ResponseWriter, r *http.Request) {
@@ -1668,18 +1785,46 @@ func (h *CLIHandler) RestoreConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" || req.SN == "" {
-		response.BadRequest(w, "name (tftp_ip) and sn (filename) are required")
+	embedded := req.Name == ""
+	if embedded {
+		oltID, ok := h.backups.OLTIDByHost(req.Host)
+		if !ok {
+			response.BadRequest(w, "name (tftp_ip) is required unless host is a registered OLT")
+			return
+		}
+		// Hanya versi milik OLT ini yang boleh di-restore ke OLT ini
+		if req.SN == "" {
+			req.SN = "latest"
+		}
+		if strings.ContainsAny(req.SN, "/\\") {
+			response.BadRequest(w, "sn must be a backup version ID, sha256 checksum or latest")
+			return
+		}
+		if _, err := h.backups.Resolve(oltID, req.SN); err != nil {
+			response.NotFound(w, fmt.Sprintf("backup %s not found for OLT %s", req.SN, oltID))
+			return
+		}
+		req.SN = oltID + "/" + req.SN
+	} else if req.SN == "" {
+		response.BadRequest(w, "sn (filename) is required")
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
+
+	if embedded {
+		host, err := h.backups.TFTPHost(client.LocalIP())
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		req.Name = host
+	}
 
 	output, err := client.RestoreConfig(ctx, req.Name, req.SN)
 	if err != nil {
@@ -1713,12 +1858,11 @@ func (h *CLIHandler) ShowInterfaceVLAN(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowInterfaceVLAN(ctx, req.VlanID)
 	if err != nil {
@@ -1739,12 +1883,11 @@ func (h *CLIHandler) ShowPowerSupply(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowPowerSupply(ctx)
 	if err != nil {
@@ -1765,12 +1908,11 @@ func (h *CLIHandler) ShowTemperature(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&req)
 
 	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	data, err := client.ShowTemperature(ctx)
 	if err != nil {
@@ -1816,13 +1958,12 @@ func (h *CLIHandler) AuthenticateONU(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.AuthenticateONU(ctx, rack, shelf, req.Slot, req.OnuID, req.OnuType, req.SN)
 	if err != nil {
@@ -1865,13 +2006,12 @@ func (h *CLIHandler) DeleteONU(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.DeleteONU(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
@@ -1916,13 +2056,12 @@ func (h *CLIHandler) RenameONU(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.RenameONU(ctx, rack, shelf, req.Slot, req.OnuID, req.Name)
 	if err != nil {
@@ -1964,13 +2103,12 @@ func (h *CLIHandler) ResetONU(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.ResetONU(ctx, rack, shelf, req.Slot, req.OnuID)
 	if err != nil {
@@ -2019,13 +2157,12 @@ func (h *CLIHandler) CreateTCONT(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateTCONT(ctx, rack, shelf, req.Slot, req.OnuID, tcontID, req.Name, req.SN)
 	if err != nil {
@@ -2077,13 +2214,12 @@ func (h *CLIHandler) CreateGEMPort(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateGEMPort(ctx, rack, shelf, req.Slot, req.OnuID, gemportID, tcontID, req.Name)
 	if err != nil {
@@ -2139,13 +2275,12 @@ func (h *CLIHandler) CreateServicePort(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateServicePort(ctx, rack, shelf, req.Slot, req.OnuID, servicePortID, vport, req.VlanID)
 	if err != nil {
@@ -2189,13 +2324,12 @@ func (h *CLIHandler) DeleteServicePort(w http.ResponseWriter, r *http.Request) {
 		shelf = 1
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.DeleteServicePort(ctx, rack, shelf, req.Slot, req.OnuID, req.Port)
 	if err != nil {
@@ -2231,13 +2365,12 @@ func (h *CLIHandler) CreateVLAN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateVLAN(ctx, req.VlanID, req.Name)
 	if err != nil {
@@ -2270,13 +2403,12 @@ func (h *CLIHandler) DeleteVLAN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.DeleteVLAN(ctx, req.VlanID)
 	if err != nil {
@@ -2313,13 +2445,12 @@ func (h *CLIHandler) AddPortToVLAN(w http.ResponseWriter, r *http.Request) {
 		mode = "tag"
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.AddPortToVLAN(ctx, req.Name, req.VlanID, mode)
 	if err != nil {
@@ -2357,13 +2488,12 @@ func (h *CLIHandler) CreateLineProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateLineProfile(ctx, req.Name)
 	if err != nil {
@@ -2395,13 +2525,12 @@ func (h *CLIHandler) CreateRemoteProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateRemoteProfile(ctx, req.Name)
 	if err != nil {
@@ -2433,13 +2562,12 @@ func (h *CLIHandler) CreateVLANProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateVLANProfile(ctx, req.Name, req.VlanID)
 	if err != nil {
@@ -2477,13 +2605,12 @@ func (h *CLIHandler) CreateTCONTProfile(w http.ResponseWriter, r *http.Request)
 		bandwidth = 10000
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateTCONTProfile(ctx, req.Name, req.OnuType, bandwidth)
 	if err != nil {
@@ -2513,13 +2640,12 @@ func (h *CLIHandler) EnableIGMP(w http.ResponseWriter, r *http.Request) {
 	var req CLIRequest
 	json.NewDecoder(r.Body).Decode(&req)
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.EnableIGMP(ctx)
 	if err != nil {
@@ -2550,13 +2676,12 @@ func (h *CLIHandler) CreateMVLAN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.CreateMVLAN(ctx, req.VlanID)
 	if err != nil {
@@ -2588,13 +2713,12 @@ func (h *CLIHandler) AddMVLANGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	client := h.getClient(req)
-	if err := client.Connect(); err != nil {
-		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	ctx := r.Context()
+	client, release, ok := h.connect(w, r, req)
+	if !ok {
 		return
 	}
-	defer client.Close()
+	defer release()
 
 	err := client.AddMVLANGroup(ctx, req.VlanID, req.Name)
 	if err != nil {
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// EthPorts godoc
+// @Summary Status Port Ethernet ONU
+// @Description Status port LAN (UNI) di sisi pelanggan: admin state, link up/down, speed dan duplex. Dibaca lewat SNMP jika OID tersedia, jika tidak lewat CLI (show gpon remote-onu interface eth); field source menunjukkan sumbernya.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Success 200 {object} response.Response{data=model.ONUEthPorts}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/eth [get]
+func (h *ONUHandler) EthPorts(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+
+	ports, err := h.service.GetONUEthPorts(r.Context(), oltID, boardID, ponID, onuID)
+	if err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, ports)
+}
+
+// SetEthPort godoc
+// @Summary Aktifkan/Nonaktifkan Port Ethernet ONU
+// @Description Mengunci (enabled=false) atau membuka (enabled=true) satu port LAN ONU. Lewat SNMP jika didukung, jika tidak lewat CLI pon-onu-mng (interface eth eth_0/{port} state lock|unlock).
+// @Tags ONU
+// @Accept json
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Param port path int true "Nomor port Ethernet (1 = eth_0/1)"
+// @Param request body model.ONUEthPortRequest true "Status port"
+// @Success 200 {object} response.Response{data=model.ONUEthPortResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/eth/{port} [put]
+func (h *ONUHandler) SetEthPort(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+	port, err := strconv.Atoi(chi.URLParam(r, "port"))
+	if err != nil {
+		response.BadRequest(w, "Invalid port")
+		return
+	}
+
+	var req model.ONUEthPortRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		response.BadRequest(w, "Request body must contain 'enabled'")
+		return
+	}
+
+	source, err := h.service.SetONUEthPortState(r.Context(), oltID, boardID, ponID, onuID, port, *req.Enabled)
+	if err != nil {
+		onuCLIError(w, err)
+		return
+	}
+
+	state := "disable"
+	if *req.Enabled {
+		state = "enable"
+	}
+	response.JSON(w, http.StatusOK, model.ONUEthPortResult{
+		Board:      boardID,
+		PON:        ponID,
+		ONUID:      onuID,
+		Port:       port,
+		AdminState: state,
+		Source:     source,
+	})
+}
+
+// onuParams membaca board, PON dan ONU ID dari path. Jika gagal respons
+// 400 sudah ditulis.
+func onuParams(w http.ResponseWriter, r *http.Request) (boardID, ponID, onuID int, ok bool) {
+	var err error
+	if boardID, err = strconv.Atoi(chi.URLParam(r, "board_id")); err != nil {
+		response.BadRequest(w, "Invalid board ID")
+		return
+	}
+	if ponID, err = strconv.Atoi(chi.URLParam(r, "pon_id")); err != nil {
+		response.BadRequest(w, "Invalid PON ID")
+		return
+	}
+	if onuID, err = strconv.Atoi(chi.URLParam(r, "onu_id")); err != nil {
+		response.BadRequest(w, "Invalid ONU ID")
+		return
+	}
+	return boardID, ponID, onuID, true
+}
+
+// onuCLIError memetakan error operasi ONU yang bisa lewat CLI ke status HTTP
+func onuCLIError(w http.ResponseWriter, err error) {
+	var se *service.ServiceError
+	switch {
+	case errors.Is(err, service.ErrOLTNotFound), errors.Is(err, service.ErrONUNotConfigured):
+		response.NotFound(w, err.Error())
+	case errors.As(err, &se):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrCLIConnect):
+		response.Error(w, http.StatusGatewayTimeout, err.Error())
+	default:
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/export"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/rs/zerolog/log"
+)
+
+// exportRequest format respons yang diminta client: JSON (default), CSV
+// atau XLSX lewat ?format= atau header Accept
+type exportRequest struct {
+	format export.Format
+	locale export.Locale
+}
+
+// parseExport dipanggil sebelum query ke OLT agar format yang salah langsung
+// ditolak
+func parseExport(r *http.Request) (exportRequest, error) {
+	format, err := export.Negotiate(r)
+	if err != nil {
+		return exportRequest{}, err
+	}
+	locale, err := export.ParseLocale(r)
+	if err != nil {
+		return exportRequest{}, err
+	}
+	return exportRequest{format: format, locale: locale}, nil
+}
+
+func (e exportRequest) file() bool {
+	return e.format != export.JSON
+}
+
+// write mengirim data sebagai file CSV/XLSX bernama name-<waktu>
+func (e exportRequest) write(w http.ResponseWriter, name string, data interface{}) {
+	name += "-" + time.Now().UTC().Format("20060102-150405")
+	err := export.Write(w, e.format, name, data, e.locale)
+	if errors.Is(err, export.ErrUnsupported) {
+		response.Error(w, http.StatusNotAcceptable, "This result cannot be exported as "+string(e.format))
+		return
+	}
+	if err != nil {
+		// Header sudah terkirim; hanya bisa dicatat
+		log.Error().Err(err).Str("file", name).Msg("Failed to export")
+	}
+}
+
+// respond mengirim data sebagai JSON atau file export
+func (e exportRequest) respond(w http.ResponseWriter, name string, data interface{}) {
+	if e.file() {
+		e.write(w, name, data)
+		return
+	}
+	response.JSON(w, http.StatusOK, data)
+}
+
+// exportName menyusun nama file yang aman dipakai di Content-Disposition
+func exportName(parts ...string) string {
+	name := strings.Join(parts, "-")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/export"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func TestParseExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantFormat export.Format
+		wantLocale export.Locale
+		wantErr    string
+	}{
+		{"default", "", "", export.JSON, export.LocaleEN, ""},
+		{"format query", "format=csv&locale=id", "", export.CSV, export.LocaleID, ""},
+		{"Accept", "", export.ContentTypeXLSX, export.XLSX, export.LocaleEN, ""},
+		{"query mengalahkan Accept", "format=json", "text/csv", export.JSON, export.LocaleEN, ""},
+		{"Accept browser", "", "text/html,application/xhtml+xml,*/*;q=0.8", export.JSON, export.LocaleEN, ""},
+		{"format salah", "format=pdf", "", "", export.Locale{}, `Invalid format "pdf"`},
+		{"locale salah", "format=csv&locale=fr", "", "", export.Locale{}, `Invalid locale "fr"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/olts/olt1/board/1/pon/1?"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, err := parseExport(r)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.format != tt.wantFormat || got.locale != tt.wantLocale {
+				t.Fatalf("parseExport() = %+v", got)
+			}
+			if got.file() != (tt.wantFormat != export.JSON) {
+				t.Fatalf("file() = %v for %s", got.file(), got.format)
+			}
+		})
+	}
+}
+
+func TestExportRespond(t *testing.T) {
+	onus := []model.ONUInfo{{Board: 1, PON: 2, ID: 3, Name: "pelanggan", RXPower: "-20.5"}}
+
+	rec := httptest.NewRecorder()
+	exportRequest{format: export.CSV, locale: export.LocaleID}.respond(rec, exportName("onus", "olt/1", "1", "2"), onus)
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="onus-olt_1-1-2-`) || !strings.HasSuffix(cd, `.csv"`) {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if !strings.Contains(rec.Body.String(), ";pelanggan;") || !strings.Contains(rec.Body.String(), "-20,5") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	exportRequest{format: export.JSON}.respond(rec, "onus", onus)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("json Content-Type = %q", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	exportRequest{format: export.CSV}.respond(rec, "scalar", 42)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("unsupported data status = %d, want 406", rec.Code)
+	}
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/breaker"
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/driver/c320"
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Status kesehatan OLT
+const (
+	OLTStatusUp          = "up"          // SNMP dan Telnet terjangkau
+	OLTStatusDegraded    = "degraded"    // Salah satu tidak terjangkau atau circuit belum closed
+	OLTStatusUnreachable = "unreachable" // SNMP dan Telnet tidak terjangkau
+)
+
+// Batas waktu pengecekan kesehatan
+const (
+	healthProbeTimeout = 2 * time.Second // GET sysUpTime tanpa retry
+	healthConcurrency  = 8               // OLT yang dicek bersamaan di /health/olts
+	readyTimeout       = time.Second
+)
+
+// Pinger dependensi yang dicek readiness probe
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// BreakerStatus sumber status circuit breaker per host OLT (*breaker.Set)
+type BreakerStatus interface {
+	Status(host string) breaker.Status
+}
+
+// HealthHandler menangani status kesehatan OLT dan readiness service
+type HealthHandler struct {
+	olts     *service.OLTService
+	breakers BreakerStatus
+	redis    Pinger // nil jika Redis tidak dikonfigurasi
+
+	// Probe per protokol; diganti di test
+	probeSNMP   func(ctx context.Context, olt model.OLT) Reachability
+	probeTelnet func(olt model.OLT) Reachability
+}
+
+// NewHealthHandler membuat handler kesehatan. redisClient nil berarti
+// Redis tidak dikonfigurasi.
+func NewHealthHandler(olts *service.OLTService, breakers BreakerStatus, redisClient *redis.Client) *HealthHandler {
+	h := &HealthHandler{olts: olts, breakers: breakers, probeSNMP: probeSNMP, probeTelnet: probeTelnet}
+	if redisClient != nil {
+		h.redis = redisPinger{redisClient}
+	}
+	return h
+}
+
+// redisPinger menyesuaikan *redis.Client ke Pinger
+type redisPinger struct {
+	client *redis.Client
+}
+
+func (p redisPinger) Ping(ctx context.Context) error {
+	return p.client.Ping(ctx).Err()
+}
+
+// Reachability hasil pengecekan satu protokol
+type Reachability struct {
+	Reachable bool    `json:"reachable"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// OLTHealth status kesehatan satu OLT
+type OLTHealth struct {
+	OLTID              string         `json:"olt_id"`
+	Name               string         `json:"name"`
+	Host               string         `json:"host"`
+	Status             string         `json:"status" enums:"up,degraded,unreachable"`
+	SNMP               Reachability   `json:"snmp"`   // GET sysUpTime
+	Telnet             Reachability   `json:"telnet"` // Koneksi TCP ke port CLI
+	LastSuccessfulPoll time.Time      `json:"last_successful_poll,omitzero"`
+	Breaker            breaker.Status `json:"breaker"`
+	CheckedAt          time.Time      `json:"checked_at"`
+}
+
+// OLTsHealth ringkasan kesehatan semua OLT
+type OLTsHealth struct {
+	Status      string      `json:"status" enums:"up,degraded,unreachable"`
+	Total       int         `json:"total"`
+	Up          int         `json:"up"`
+	Degraded    int         `json:"degraded"`
+	Unreachable int         `json:"unreachable"`
+	OLTs        []OLTHealth `json:"olts"`
+}
+
+// ReadinessCheck hasil pengecekan satu dependensi
+type ReadinessCheck struct {
+	Status    string  `json:"status" enums:"up,down,disabled"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Readiness status kesiapan service menerima traffic
+type Readiness struct {
+	Status string                    `json:"status" enums:"ready,not_ready"`
+	Checks map[string]ReadinessCheck `json:"checks"`
+}
+
+// OLT godoc
+// @Summary Status Kesehatan OLT
+// @Description Mengecek reachability SNMP (GET sysUpTime) dan Telnet (koneksi TCP ke port CLI) beserta latency, waktu poll SNMP terakhir yang berhasil, dan status circuit breaker (closed/open/half_open).
+// @Tags OLT
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Success 200 {object} response.Response{data=OLTHealth}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/health [get]
+func (h *HealthHandler) OLT(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+	olt, err := h.olts.Get(oltID)
+	if err != nil {
+		response.NotFound(w, "OLT not found: "+oltID)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.check(r.Context(), *olt))
+}
+
+// OLTs godoc
+// @Summary Status Kesehatan Semua OLT
+// @Description Menjalankan pengecekan kesehatan (SNMP, Telnet, circuit breaker) untuk semua OLT yang bisa diakses user secara paralel.
+// @Tags System
+// @Produce json
+// @Success 200 {object} response.Response{data=OLTsHealth}
+// @Router /health/olts [get]
+func (h *HealthHandler) OLTs(w http.ResponseWriter, r *http.Request) {
+	olts := h.olts.List()
+	if user := middleware.UserFromContext(r.Context()); user != nil && user.Scoped() {
+		visible := make([]model.OLT, 0, len(olts))
+		for _, olt := range olts {
+			if user.CanAccessOLT(olt.ID) {
+				visible = append(visible, olt)
+			}
+		}
+		olts = visible
+	}
+
+	results := make([]OLTHealth, len(olts))
+	sem := make(chan struct{}, healthConcurrency)
+	var wg sync.WaitGroup
+	for i, olt := range olts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = h.check(r.Context(), olt)
+		}()
+	}
+	wg.Wait()
+
+	summary := OLTsHealth{Status: OLTStatusUp, Total: len(results), OLTs: results}
+	for _, res := range results {
+		switch res.Status {
+		case OLTStatusUp:
+			summary.Up++
+		case OLTStatusDegraded:
+			summary.Degraded++
+		default:
+			summary.Unreachable++
+		}
+	}
+	switch {
+	case summary.Total > 0 && summary.Unreachable == summary.Total:
+		summary.Status = OLTStatusUnreachable
+	case summary.Up < summary.Total:
+		summary.Status = OLTStatusDegraded
+	}
+
+	response.JSON(w, http.StatusOK, summary)
+}
+
+// Ready godoc
+// @Summary Readiness Probe
+// @Description 200 jika service siap menerima traffic, 503 jika dependensi (Redis) yang dikonfigurasi tidak tersedia. Gunakan /health untuk liveness.
+// @Tags System
+// @Produce json
+// @Success 200 {object} response.Response{data=Readiness}
+// @Failure 503 {object} response.Response{data=Readiness}
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ready := Readiness{Status: "ready", Checks: map[string]ReadinessCheck{}}
+
+	check := ReadinessCheck{Status: "disabled"}
+	if h.redis != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		start := time.Now()
+		err := h.redis.Ping(ctx)
+		cancel()
+		check = ReadinessCheck{Status: "up", LatencyMS: millis(time.Since(start))}
+		if err != nil {
+			check.Status = "down"
+			check.Error = err.Error()
+			ready.Status = "not_ready"
+		}
+	}
+	ready.Checks["redis"] = check
+
+	status := http.StatusOK
+	if ready.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	response.JSON(w, status, ready)
+}
+
+// check menjalankan pengecekan SNMP dan Telnet secara paralel
+func (h *HealthHandler) check(ctx context.Context, olt model.OLT) OLTHealth {
+	health := OLTHealth{
+		OLTID:     olt.ID,
+		Name:      olt.Name,
+		Host:      olt.IPAddress,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		health.SNMP = h.probeSNMP(ctx, olt)
+	}()
+	go func() {
+		defer wg.Done()
+		health.Telnet = h.probeTelnet(olt)
+	}()
+	wg.Wait()
+
+	health.Breaker = h.breakers.Status(olt.IPAddress)
+	health.LastSuccessfulPoll = health.Breaker.LastSuccess
+
+	switch {
+	case !health.SNMP.Reachable && !health.Telnet.Reachable:
+		health.Status = OLTStatusUnreachable
+	case !health.SNMP.Reachable || !health.Telnet.Reachable || health.Breaker.State != breaker.Closed:
+		health.Status = OLTStatusDegraded
+	default:
+		health.Status = OLTStatusUp
+	}
+	return health
+}
+
+func probeSNMP(ctx context.Context, olt model.OLT) Reachability {
+	var drv driver.Driver
+	switch olt.Model {
+	case "C320", "c320":
+		drv = c320.New(olt.IPAddress, uint16(olt.Port), olt.Community)
+	default:
+		return Reachability{Error: fmt.Sprintf("unsupported OLT model: %s", olt.Model)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+	start := time.Now()
+	err := drv.Probe(ctx)
+	return reachability(start, err)
+}
+
+func probeTelnet(olt model.OLT) Reachability {
+	start := time.Now()
+	err := cli.TestConnection(cli.Config{Host: olt.IPAddress, Port: olt.CLIPort})
+	return reachability(start, err)
+}
+
+func reachability(start time.Time, err error) Reachability {
+	res := Reachability{Reachable: err == nil, LatencyMS: millis(time.Since(start))}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
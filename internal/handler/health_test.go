@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/breaker"
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(ctx context.Context) error { return p.err }
+
+// fakeBreakers status per host; host lain closed
+type fakeBreakers map[string]breaker.Status
+
+func (b fakeBreakers) Status(host string) breaker.Status {
+	if s, ok := b[host]; ok {
+		return s
+	}
+	return breaker.Status{Host: host, State: breaker.Closed}
+}
+
+// healthHandler handler dengan OLT olts dan probe palsu: host di snmpDown /
+// telnetDown dianggap tidak terjangkau
+func healthHandler(olts []config.OLTConfig, breakers fakeBreakers, snmpDown, telnetDown []string) *HealthHandler {
+	h := NewHealthHandler(service.NewOLTService(&config.Config{OLTs: olts}), breakers, nil)
+	h.probeSNMP = func(ctx context.Context, olt model.OLT) Reachability {
+		if slices.Contains(snmpDown, olt.IPAddress) {
+			return Reachability{Error: "request timeout"}
+		}
+		return Reachability{Reachable: true, LatencyMS: 1.5}
+	}
+	h.probeTelnet = func(olt model.OLT) Reachability {
+		if slices.Contains(telnetDown, olt.IPAddress) {
+			return Reachability{Error: "connection refused"}
+		}
+		return Reachability{Reachable: true, LatencyMS: 2}
+	}
+	return h
+}
+
+func healthOLTs(n int) []config.OLTConfig {
+	olts := make([]config.OLTConfig, n)
+	for i := range olts {
+		id := string(rune('a' + i))
+		olts[i] = config.OLTConfig{ID: "olt-" + id, Name: "OLT " + id, Model: "C320", IPAddress: "10.0.0." + string(rune('1'+i))}
+	}
+	return olts
+}
+
+func decodeData(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	resp := struct {
+		Data interface{} `json:"data"`
+	}{Data: v}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		redis      Pinger
+		wantCode   int
+		wantStatus string
+		wantRedis  string
+	}{
+		{"tanpa redis", nil, http.StatusOK, "ready", "disabled"},
+		{"redis up", fakePinger{}, http.StatusOK, "ready", "up"},
+		{"redis down", fakePinger{err: errors.New("dial tcp: connection refused")}, http.StatusServiceUnavailable, "not_ready", "down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := healthHandler(nil, nil, nil, nil)
+			h.redis = tt.redis
+			rec := httptest.NewRecorder()
+			h.Ready(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var ready Readiness
+			decodeData(t, rec, &ready)
+			redis := ready.Checks["redis"]
+			if ready.Status != tt.wantStatus || redis.Status != tt.wantRedis {
+				t.Fatalf("readiness = %+v", ready)
+			}
+			if (redis.Error != "") != (tt.wantRedis == "down") {
+				t.Fatalf("redis error = %q", redis.Error)
+			}
+		})
+	}
+}
+
+func TestHealthOLTs(t *testing.T) {
+	lastPoll := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		olts       int
+		breakers   fakeBreakers
+		snmpDown   []string
+		telnetDown []string
+		user       *model.User
+		want       []string // Status tiap OLT sesuai urutan konfigurasi
+		wantStatus string
+	}{
+		{name: "semua up", olts: 2, want: []string{OLTStatusUp, OLTStatusUp}, wantStatus: OLTStatusUp},
+		{
+			name:       "campuran",
+			olts:       4,
+			breakers:   fakeBreakers{"10.0.0.3": {Host: "10.0.0.3", State: breaker.Open, LastSuccess: lastPoll}},
+			snmpDown:   []string{"10.0.0.4"},
+			telnetDown: []string{"10.0.0.2", "10.0.0.4"},
+			want:       []string{OLTStatusUp, OLTStatusDegraded, OLTStatusDegraded, OLTStatusUnreachable},
+			wantStatus: OLTStatusDegraded,
+		},
+		{
+			name:       "hanya snmp down",
+			olts:       1,
+			snmpDown:   []string{"10.0.0.1"},
+			want:       []string{OLTStatusDegraded},
+			wantStatus: OLTStatusDegraded,
+		},
+		{
+			name:       "breaker half open",
+			olts:       1,
+			breakers:   fakeBreakers{"10.0.0.1": {State: breaker.HalfOpen}},
+			want:       []string{OLTStatusDegraded},
+			wantStatus: OLTStatusDegraded,
+		},
+		{
+			name:       "semua unreachable",
+			olts:       2,
+			snmpDown:   []string{"10.0.0.1", "10.0.0.2"},
+			telnetDown: []string{"10.0.0.1", "10.0.0.2"},
+			want:       []string{OLTStatusUnreachable, OLTStatusUnreachable},
+			wantStatus: OLTStatusUnreachable,
+		},
+		{name: "tanpa OLT", olts: 0, want: []string{}, wantStatus: OLTStatusUp},
+		{
+			name:       "user dibatasi OLT",
+			olts:       3,
+			telnetDown: []string{"10.0.0.2"},
+			user:       &model.User{Username: "noc", OLTs: []string{"olt-a", "olt-c"}},
+			want:       []string{OLTStatusUp, OLTStatusUp},
+			wantStatus: OLTStatusUp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := healthHandler(healthOLTs(tt.olts), tt.breakers, tt.snmpDown, tt.telnetDown)
+			req := httptest.NewRequest(http.MethodGet, "/health/olts", nil)
+			if tt.user != nil {
+				req = req.WithContext(middleware.WithUser(req.Context(), tt.user))
+			}
+			rec := httptest.NewRecorder()
+			h.OLTs(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			var got OLTsHealth
+			decodeData(t, rec, &got)
+
+			statuses := []string{}
+			counts := map[string]int{}
+			for _, olt := range got.OLTs {
+				statuses = append(statuses, olt.Status)
+				counts[olt.Status]++
+			}
+			if !slices.Equal(statuses, tt.want) {
+				t.Fatalf("olt statuses = %v, want %v", statuses, tt.want)
+			}
+			if got.Status != tt.wantStatus || got.Total != len(tt.want) ||
+				got.Up != counts[OLTStatusUp] || got.Degraded != counts[OLTStatusDegraded] || got.Unreachable != counts[OLTStatusUnreachable] {
+				t.Fatalf("summary = %+v", got)
+			}
+			for _, olt := range got.OLTs {
+				if tt.user != nil && !tt.user.CanAccessOLT(olt.OLTID) {
+					t.Fatalf("scoped user sees %s", olt.OLTID)
+				}
+				if b, ok := tt.breakers[olt.Host]; ok && (olt.Breaker.State != b.State || !olt.LastSuccessfulPoll.Equal(b.LastSuccess)) {
+					t.Fatalf("%s breaker = %+v, poll %v", olt.OLTID, olt.Breaker, olt.LastSuccessfulPoll)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthOLT(t *testing.T) {
+	h := healthHandler(healthOLTs(2), nil, nil, []string{"10.0.0.2"})
+	r := chi.NewRouter()
+	r.Get("/api/v1/olts/{olt_id}/health", h.OLT)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/olts/olt-b/health", nil))
+	var got OLTHealth
+	decodeData(t, rec, &got)
+	if rec.Code != http.StatusOK || got.OLTID != "olt-b" || got.Status != OLTStatusDegraded ||
+		!got.SNMP.Reachable || got.Telnet.Reachable || got.Telnet.Error == "" {
+		t.Fatalf("health = %d %+v", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/olts/olt-x/health", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown OLT status = %d, want 404", rec.Code)
+	}
+}
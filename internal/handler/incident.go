@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/incident"
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// IncidentHandler menampilkan insiden gangguan massal hasil pemantauan
+// status ONU.
+type IncidentHandler struct {
+	service *service.MonitorService
+}
+
+// NewIncidentHandler membuat handler insiden baru.
+func NewIncidentHandler(service *service.MonitorService) *IncidentHandler {
+	return &IncidentHandler{service: service}
+}
+
+// List godoc
+// @Summary Daftar Insiden Gangguan
+// @Description ONU yang turun hampir bersamaan (monitor.outage.window_seconds) di satu atau beberapa PON digabung menjadi satu insiden, diklasifikasikan sebagai fiber_cut (mayoritas LOS), power_outage (mayoritas Dying Gasp/PowerOff), card_fault (kartu PON tidak InService) atau unknown. Terbaru lebih dulu.
+// @Tags OLT
+// @Produce json
+// @Param olt_id query string false "ID OLT"
+// @Param status query string false "open atau resolved"
+// @Param type query string false "fiber_cut, power_outage, card_fault atau unknown"
+// @Param since query string false "RFC3339, dimulai pada atau setelah waktu ini"
+// @Param limit query int false "Jumlah insiden (default 100, max 1000)"
+// @Success 200 {array} incident.Incident
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/incidents [get]
+// @Router /api/v1/olts/{olt_id}/incidents [get]
+func (h *IncidentHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := incident.Filter{
+		OLTID:  q.Get("olt_id"),
+		Status: q.Get("status"),
+		Type:   q.Get("type"),
+	}
+	if oltID := chi.URLParam(r, "olt_id"); oltID != "" {
+		f.OLTID = oltID
+	}
+
+	var err error
+	if v := q.Get("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			response.BadRequest(w, "Invalid 'since' parameter")
+			return
+		}
+	}
+	if f.Limit, err = queryInt(r, "limit"); err != nil {
+		response.BadRequest(w, "Invalid 'limit' parameter")
+		return
+	}
+
+	// User dengan scope OLT hanya melihat insiden OLT miliknya
+	if user := middleware.UserFromContext(r.Context()); user != nil && user.Scoped() {
+		f.OLTs = user.OLTs
+	}
+
+	incidents, err := h.service.Incidents(f)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, incidents)
+}
+
+// Get godoc
+// @Summary Detail Insiden Gangguan
+// @Description Satu insiden beserta ONU terdampak per PON, ONU yang belum online kembali dan jumlah per alasan offline.
+// @Tags OLT
+// @Produce json
+// @Param incident_id path int true "ID Insiden"
+// @Success 200 {object} incident.Incident
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/incidents/{incident_id} [get]
+func (h *IncidentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "incident_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid incident ID")
+		return
+	}
+
+	inc, err := h.service.Incident(id)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	// Insiden OLT lain diperlakukan tidak ada
+	if user := middleware.UserFromContext(r.Context()); user != nil && !user.CanAccessOLT(inc.OLTID) {
+		h.error(w, incident.ErrNotFound)
+		return
+	}
+	response.JSON(w, http.StatusOK, inc)
+}
+
+func (h *IncidentHandler) error(w http.ResponseWriter, err error) {
+	var se *service.ServiceError
+	switch {
+	case errors.Is(err, service.ErrMonitorDisabled):
+		response.Error(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, incident.ErrNotFound):
+		response.NotFound(w, err.Error())
+	case errors.As(err, &se):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalError(w, err.Error())
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/breaker"
+	"github.com/ardani/snmp-zte/internal/limiter"
+	"github.com/ardani/snmp-zte/pkg/response"
+)
+
+// deviceError mengirim respons untuk error operasi ke OLT. Penolakan oleh
+// limiter perangkat dikirim sebagai 429/503 dan circuit OLT yang terbuka
+// sebagai 503, keduanya dengan header Retry-After. Request yang melewati
+// deadline-nya dikirim sebagai 504; error lain memakai status dan message
+// yang diberikan.
+func deviceError(w http.ResponseWriter, err error, status int, message string) {
+	var le *limiter.Error
+	if errors.As(err, &le) {
+		w.Header().Set("Retry-After", retryAfter(le.RetryAfter))
+		response.Error(w, le.Status, le.Message)
+		return
+	}
+	var be *breaker.Error
+	if errors.As(err, &be) {
+		w.Header().Set("Retry-After", retryAfter(be.RetryAfter))
+		response.Error(w, http.StatusServiceUnavailable, be.Error())
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.Error(w, http.StatusGatewayTimeout, "OLT request timed out")
+		return
+	}
+	response.Error(w, status, message)
+}
+
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
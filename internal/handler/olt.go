@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/ardani/snmp-zte/internal/middleware"
 	"github.com/ardani/snmp-zte/internal/model"
 	"github.com/ardani/snmp-zte/internal/service"
 	"github.com/ardani/snmp-zte/pkg/response"
@@ -30,6 +31,18 @@ func NewOLTHandler(service *service.OLTService) *OLTHandler {
 // @Router /api/v1/olts [get]
 func (h *OLTHandler) List(w http.ResponseWriter, r *http.Request) {
 	olts := h.service.List()
+
+	// User dengan scope OLT hanya melihat OLT miliknya
+	if user := middleware.UserFromContext(r.Context()); user != nil && user.Scoped() {
+		visible := make([]model.OLT, 0, len(olts))
+		for _, olt := range olts {
+			if user.CanAccessOLT(olt.ID) {
+				visible = append(visible, olt)
+			}
+		}
+		olts = visible
+	}
+
 	response.JSON(w, http.StatusOK, olts)
 }
 
@@ -80,6 +93,9 @@ func (h *OLTHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Community   string `json:"community"`
 		BoardCount  int    `json:"board_count"`
 		PonPerBoard int    `json:"pon_per_board"`
+		CLIPort     int    `json:"cli_port"`
+		CLIUsername string `json:"cli_username"`
+		CLIPassword string `json:"cli_password"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -129,6 +145,9 @@ func (h *OLTHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Community:   req.Community,
 		BoardCount:  req.BoardCount,
 		PonPerBoard: req.PonPerBoard,
+		CLIPort:     req.CLIPort,
+		CLIUsername: req.CLIUsername,
+		CLIPassword: req.CLIPassword,
 	}
 
 	if err := h.service.Create(olt); err != nil {
@@ -166,6 +185,9 @@ func (h *OLTHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Community   string `json:"community"`
 		BoardCount  int    `json:"board_count"`
 		PonPerBoard int    `json:"pon_per_board"`
+		CLIPort     int    `json:"cli_port"`
+		CLIUsername string `json:"cli_username"`
+		CLIPassword string `json:"cli_password"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -182,6 +204,9 @@ func (h *OLTHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Community:   req.Community,
 		BoardCount:  req.BoardCount,
 		PonPerBoard: req.PonPerBoard,
+		CLIPort:     req.CLIPort,
+		CLIUsername: req.CLIUsername,
+		CLIPassword: req.CLIPassword,
 	}
 
 	if err := h.service.Update(oltID, olt); err != nil {
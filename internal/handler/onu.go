@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -11,23 +12,38 @@ import (
 
 // ONUHandler menangani permintaan query ONU (modem pelanggan).
 type ONUHandler struct {
-	service *service.ONUService
+	service  *service.ONUService
+	confirms *service.Confirmations
 }
 
-// NewONUHandler membuat instance ONU handler baru.
-func NewONUHandler(service *service.ONUService) *ONUHandler {
-	return &ONUHandler{service: service}
+// NewONUHandler membuat instance ONU handler baru. confirms menyimpan token
+// konfirmasi aksi ONU destruktif.
+func NewONUHandler(service *service.ONUService, confirms *service.Confirmations) *ONUHandler {
+	return &ONUHandler{service: service, confirms: confirms}
 }
 
 // List godoc
 // @Summary List ONU di Port PON
-// @Description Mengambil daftar semua ONU (modem) yang terdaftar di Board dan Port PON tertentu.
+// @Description Mengambil daftar ONU (modem) yang terdaftar di Board dan Port PON tertentu, urut berdasarkan onu_id. Mendukung filter, urutan dan paginasi cursor.
 // @Tags ONU
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param olt_id path string true "ID OLT"
 // @Param board_id path int true "ID Board/Slot"
 // @Param pon_id path int true "ID Port PON"
+// @Param status query string false "Filter status, dipisah koma (Online,LOS,Offline,...)"
+// @Param rx_min query number false "RX power minimum (dBm)"
+// @Param rx_max query number false "RX power maksimum (dBm)"
+// @Param name_contains query string false "Substring nama ONU"
+// @Param sn_contains query string false "Substring serial number"
+// @Param sort query string false "onu_id (default), rx_power, distance"
+// @Param order query string false "asc (default) atau desc"
+// @Param limit query int false "Jumlah ONU per halaman (default semua)"
+// @Param cursor query string false "Nilai X-Next-Cursor dari halaman sebelumnya"
+// @Param format query string false "json (default), csv atau xlsx; bisa juga lewat header Accept"
+// @Param locale query string false "Format angka CSV: en (1.5, pemisah koma, default) atau id (1,5, pemisah titik koma)"
 // @Success 200 {object} response.Response
+// @Header 200 {integer} X-Total-Count "Jumlah ONU setelah filter"
+// @Header 200 {string} X-Next-Cursor "Cursor halaman berikutnya (tidak ada jika halaman terakhir)"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id} [get]
@@ -45,24 +61,44 @@ func (h *ONUHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := onuListOptions(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
 	onuList, err := h.service.GetONUList(r.Context(), oltID, boardID, ponID)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.JSON(w, http.StatusOK, onuList)
+	onuList, page, err := service.ListONUs(onuList, opts)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	writePageHeaders(w, page)
+	out.respond(w, exportName("onu", oltID, "b"+strconv.Itoa(boardID), "p"+strconv.Itoa(ponID)), onuList)
 }
 
 // Detail godoc
 // @Summary Detail Lengkap ONU
 // @Description Mengambil informasi teknis mendalam untuk satu ONU spesifik (Power, Status, Uptime, dll).
 // @Tags ONU
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param olt_id path string true "ID OLT"
 // @Param board_id path int true "ID Board/Slot"
 // @Param pon_id path int true "ID Port PON"
 // @Param onu_id path int true "ID ONU"
+// @Param format query string false "json (default), csv atau xlsx; bisa juga lewat header Accept"
+// @Param locale query string false "Format angka CSV: en (1.5, pemisah koma, default) atau id (1,5, pemisah titik koma)"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -86,25 +122,37 @@ func (h *ONUHandler) Detail(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid ONU ID")
 		return
 	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
 
 	detail, err := h.service.GetONUDetail(r.Context(), oltID, boardID, ponID, onuID)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.JSON(w, http.StatusOK, detail)
+	out.respond(w, exportName("onu", oltID, "b"+strconv.Itoa(boardID), "p"+strconv.Itoa(ponID), "onu"+strconv.Itoa(onuID)), detail)
 }
 
 // EmptySlots godoc
 // @Summary Cari Slot ONU Kosong
 // @Description Mengembalikan daftar ID ONU yang belum terpakai di port PON tertentu.
 // @Tags ONU
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param olt_id path string true "ID OLT"
 // @Param board_id path int true "ID Board/Slot"
 // @Param pon_id path int true "ID Port PON"
+// @Param order query string false "asc (default) atau desc"
+// @Param limit query int false "Jumlah slot per halaman (default semua)"
+// @Param cursor query string false "Nilai X-Next-Cursor dari halaman sebelumnya"
+// @Param format query string false "json (default), csv atau xlsx; bisa juga lewat header Accept"
+// @Param locale query string false "Format angka CSV: en (1.5, pemisah koma, default) atau id (1,5, pemisah titik koma)"
 // @Success 200 {object} response.Response
+// @Header 200 {integer} X-Total-Count "Jumlah slot kosong"
+// @Header 200 {string} X-Next-Cursor "Cursor halaman berikutnya (tidak ada jika halaman terakhir)"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/empty [get]
@@ -122,13 +170,31 @@ func (h *ONUHandler) EmptySlots(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := onuListOptions(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
 	slots, err := h.service.GetEmptySlots(r.Context(), oltID, boardID, ponID)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	slots, page, err := service.ListSlots(slots, opts)
+	if err != nil {
+		response.BadRequest(w, err.Error())
 		return
 	}
 
-	response.JSON(w, http.StatusOK, slots)
+	writePageHeaders(w, page)
+	out.respond(w, exportName("empty-slots", oltID, "b"+strconv.Itoa(boardID), "p"+strconv.Itoa(ponID)), slots)
 }
 
 // ClearCache godoc
@@ -169,3 +235,49 @@ func (h *ONUHandler) ClearCache(w http.ResponseWriter, r *http.Request) {
 		"pon_id":   ponID,
 	})
 }
+
+// onuListOptions membaca filter, urutan dan paginasi daftar ONU dari query
+// string. Nama parameter sama dengan field di body /api/v1/query.
+func onuListOptions(r *http.Request) (service.ONUListOptions, error) {
+	q := r.URL.Query()
+	opts := service.ONUListOptions{
+		Status: q.Get("status"),
+		Name:   q.Get("name_contains"),
+		SN:     q.Get("sn_contains"),
+		Sort:   q.Get("sort"),
+		Order:  q.Get("order"),
+		Cursor: q.Get("cursor"),
+	}
+
+	var err error
+	if opts.RXMin, err = queryFloat(r, "rx_min"); err != nil {
+		return opts, errors.New("Invalid 'rx_min' parameter")
+	}
+	if opts.RXMax, err = queryFloat(r, "rx_max"); err != nil {
+		return opts, errors.New("Invalid 'rx_max' parameter")
+	}
+	if opts.Limit, err = queryInt(r, "limit"); err != nil {
+		return opts, errors.New("Invalid 'limit' parameter")
+	}
+	return opts, opts.Validate()
+}
+
+func queryFloat(r *http.Request, name string) (*float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// writePageHeaders menulis jumlah total dan cursor halaman berikutnya
+func writePageHeaders(w http.ResponseWriter, page service.PageInfo) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// Optical godoc
+// @Summary Analisis Budget Optik & Kesehatan Fiber
+// @Description Untuk setiap PON membandingkan RX power ONU, RX power di OLT (upstream) dan jarak: link loss per ONU, excess loss dibanding median peer setelah koreksi jarak (0.35 dB/km), outlier (excess >= 3 dB, dugaan konektor kotor atau drop buruk), dan kelompok outlier berdekatan yang kemungkinan berbagi cabang splitter bermasalah. Board wajib; seluruh OLT tidak didukung karena tidak selesai dalam batas waktu request.
+// @Tags ONU
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param olt_id path string true "ID OLT"
+// @Param board query int true "ID Board"
+// @Param pon query int false "ID Port PON (default semua PON di board)"
+// @Param format query string false "json (default), csv atau xlsx (satu baris per ONU)"
+// @Param locale query string false "Format angka CSV: en (1.5, pemisah koma, default) atau id (1,5, pemisah titik koma)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/optical [get]
+func (h *ONUHandler) Optical(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	board, err := queryInt(r, "board")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'board' parameter")
+		return
+	}
+	pon, err := queryInt(r, "pon")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'pon' parameter")
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	report, err := h.service.OpticalAnalysis(r.Context(), oltID, board, pon)
+	if err != nil {
+		var se *service.ServiceError
+		if errors.As(err, &se) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if out.file() {
+		var onus []model.ONUOptical
+		for _, p := range report.PONs {
+			onus = append(onus, p.ONUs...)
+		}
+		out.write(w, exportName("optical", oltID), onus)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
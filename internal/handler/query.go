@@ -3,26 +3,44 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/ardani/snmp-zte/internal/breaker"
+	"github.com/ardani/snmp-zte/internal/counter"
 	"github.com/ardani/snmp-zte/internal/driver"
 	"github.com/ardani/snmp-zte/internal/driver/c320"
+	"github.com/ardani/snmp-zte/internal/limiter"
+	"github.com/ardani/snmp-zte/internal/middleware"
 	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
 	"github.com/ardani/snmp-zte/internal/snmp"
 	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/rs/zerolog/log"
 )
 
 // QueryHandler menangani query SNMP "stateless" (tanpa simpan data).
 type QueryHandler struct {
-	pool *snmp.Pool
+	pool     *snmp.Pool
+	limits   *limiter.Limiter
+	breakers *breaker.Set
+	meter    *counter.Meter
+	confirms *service.Confirmations
 }
 
-// NewQueryHandler membuat handler query baru.
-func NewQueryHandler() *QueryHandler {
+// NewQueryHandler membuat handler query baru. limits membatasi sesi SNMP
+// per OLT; breakers menolak query ke OLT yang tidak menjawab; meter
+// menghitung laju trafik dari sampel counter sebelumnya; confirms menyimpan
+// token konfirmasi aksi ONU destruktif.
+func NewQueryHandler(limits *limiter.Limiter, breakers *breaker.Set, meter *counter.Meter, confirms *service.Confirmations) *QueryHandler {
 	return &QueryHandler{
-		pool: snmp.GetPool(),
+		pool:     snmp.GetPool(),
+		limits:   limits,
+		breakers: breakers,
+		meter:    meter,
+		confirms: confirms,
 	}
 }
 
@@ -44,15 +62,46 @@ type QueryRequest struct {
 	
 	// Parameter Provisioning (untuk create/rename)
 	Name string `json:"name,omitempty" example:"customer-john"`
+
+	// Token konfirmasi untuk onu_reboot, onu_factory_reset dan onu_disable
+	ConfirmToken string `json:"confirm_token,omitempty"`
+
+	// Filter, urutan dan paginasi untuk onu_list dan empty_slots
+	service.ONUListOptions
+}
+
+// writeQueries query yang mengubah data di OLT (SNMP SET)
+var writeQueries = map[string]bool{
+	"onu_create":        true,
+	"onu_delete":        true,
+	"onu_rename":        true,
+	"onu_reboot":        true,
+	"onu_factory_reset": true,
+	"onu_enable":        true,
+	"onu_disable":       true,
+}
+
+// actionQueries query aksi remote ONU dan aksinya
+var actionQueries = map[string]string{
+	"onu_reboot":        model.ONUActionReboot,
+	"onu_factory_reset": model.ONUActionFactoryReset,
+	"onu_enable":        model.ONUActionEnable,
+	"onu_disable":       model.ONUActionDisable,
+}
+
+// WriteQuery true jika query mengubah data di OLT (SNMP SET)
+func WriteQuery(query string) bool {
+	return writeQueries[query]
 }
 
 // QueryResponse merepresentasikan respons query
 type QueryResponse struct {
-	Query     string      `json:"query"`
-	Summary   string      `json:"summary,omitempty"`
-	Data      interface{} `json:"data"`
-	Timestamp string      `json:"timestamp"`
-	Duration  string      `json:"duration"`
+	Query     string            `json:"query"`
+	Summary   string            `json:"summary,omitempty"`
+	Data      interface{}       `json:"data"`
+	Page      *service.PageInfo `json:"page,omitempty"` // Hanya untuk query yang mengembalikan daftar
+	Timestamp string            `json:"timestamp"`
+	Duration  string            `json:"duration"`
 }
 
 // OLTInfoResponse merepresentasikan detail lengkap informasi OLT.
@@ -80,10 +129,15 @@ type OLTInfoResponse struct {
 // @Description - pon_port_stats: Statistik traffic per PON port
 // @Description - onu_errors: Error counter per ONU (CRC, FEC, dropped, WAJIB isi onu_id)
 // @Description - voltage_info: Informasi voltage/power supply OLT
+// @Description - onu_reboot, onu_factory_reset, onu_enable, onu_disable: Aksi remote ONU (WAJIB isi onu_id). Selain onu_enable, request pertama mengembalikan 202 dengan confirm_token; kirim ulang dengan confirm_token untuk menjalankan.
+// @Description Hasil berupa daftar atau objek (onu_list, onu_detail, empty_slots, all_boards, pon_port_stats, pon_info, dll) bisa diunduh sebagai CSV/XLSX lewat ?format=csv|xlsx atau header Accept; ?locale=id memakai koma desimal dan pemisah titik koma di CSV.
+// @Description onu_list dan empty_slots mendukung filter (status, rx_min, rx_max, name_contains, sn_contains), urutan (sort, order) dan paginasi (limit, cursor = page.next_cursor).
 // @Tags Query
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param request body QueryRequest true "Detail Query (IP, Community, Model, dan Jenis Query)"
+// @Param format query string false "json (default), csv atau xlsx"
+// @Param locale query string false "Format angka CSV: en (default) atau id"
 // @Success 200 {object} response.Response{data=QueryResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -111,6 +165,39 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Query is required")
 		return
 	}
+	if err := req.ONUListOptions.Validate(); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if out.file() && writeQueries[req.Query] {
+		response.BadRequest(w, "Query "+req.Query+" cannot be exported as "+string(out.format))
+		return
+	}
+
+	// Query provisioning (SNMP SET) butuh permission provision
+	if writeQueries[req.Query] {
+		if user := middleware.UserFromContext(r.Context()); user == nil || !user.Can(model.PermProvision) {
+			response.Error(w, http.StatusForbidden, "Forbidden - query "+req.Query+" requires permission "+model.PermProvision)
+			return
+		}
+	}
+
+	// Aksi ONU destruktif dikonfirmasi sebelum menyentuh OLT
+	if action, ok := actionQueries[req.Query]; ok {
+		if req.OnuID == 0 {
+			response.BadRequest(w, "onu_id is required for "+req.Query)
+			return
+		}
+		pending := model.ONUActionResult{Board: req.Board, PON: req.Pon, ONUID: req.OnuID, Action: action}
+		if !confirmONUAction(w, r, h.confirms, req.IP, &pending, req.ConfirmToken) {
+			return
+		}
+	}
 
 	// Set defaults
 	if req.Port == 0 {
@@ -131,6 +218,21 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tolak langsung jika OLT sedang tidak terjangkau (circuit terbuka)
+	circuit := h.breakers.For(req.IP)
+	if err := circuit.Allow(ctx, drv.Probe); err != nil {
+		deviceError(w, err, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	// Tunggu giliran sesi SNMP ke OLT (batas per perangkat)
+	release, err := h.limits.AcquireSNMP(ctx, req.IP)
+	if err != nil {
+		deviceError(w, err, http.StatusServiceUnavailable, "Timed out waiting for OLT: "+err.Error())
+		return
+	}
+	defer release()
+
 	// Hubungkan ke OLT
 	if err := drv.Connect(); err != nil {
 		response.Error(w, http.StatusGatewayTimeout, "Gagal terhubung ke OLT: "+err.Error())
@@ -258,6 +360,20 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 				"status_str": statusStr,
 			}
 		}
+	// Manajemen remote ONU (SNMP SET)
+	case "onu_reboot", "onu_factory_reset", "onu_enable", "onu_disable":
+		action := actionQueries[req.Query]
+		err = service.RunONUAction(ctx, drv, req.Board, req.Pon, req.OnuID, action)
+		if err == nil {
+			result = model.ONUActionResult{
+				Board:   req.Board,
+				PON:     req.Pon,
+				ONUID:   req.OnuID,
+				Action:  action,
+				Status:  model.ActionDone,
+				Message: service.ONUActionMessage(req.Board, req.Pon, req.OnuID, action),
+			}
+		}
 	// Phase 4: Statistics
 	case "distance_info":
 		if req.OnuID == 0 {
@@ -284,11 +400,25 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Query failed: "+err.Error())
+	if errors.Is(err, driver.ErrNotSupported) {
+		actionError(w, err)
+		return
+	}
+	if err := circuit.Done(err); err != nil {
+		deviceError(w, err, http.StatusInternalServerError, "Query failed: "+err.Error())
 		return
 	}
 
+	// Laju trafik dari selisih dengan sampel counter sebelumnya
+	switch stats := result.(type) {
+	case *model.ONUTraffic:
+		key := fmt.Sprintf("%s:onu:%d/%d/%d", req.IP, req.Board, req.Pon, req.OnuID)
+		stats.Rates = h.rates(ctx, key, stats.Sample, stats.RxBytes, stats.TxBytes, stats.RxPackets, stats.TxPackets)
+	case *model.PONPortStats:
+		key := fmt.Sprintf("%s:pon:%d/%d", req.IP, req.Board, req.Pon)
+		stats.Rates = h.rates(ctx, key, stats.Sample, stats.RxBytes, stats.TxBytes, stats.RxPackets, stats.TxPackets)
+	}
+
 	// Susun jawaban (response)
 	resp := QueryResponse{
 		Query:     req.Query,
@@ -308,9 +438,41 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Filter, urutan dan paginasi untuk hasil berupa daftar
+	var page service.PageInfo
+	switch list := result.(type) {
+	case []model.ONUInfo:
+		resp.Data, page, err = service.ListONUs(list, req.ONUListOptions)
+		resp.Page = &page
+	case []model.ONUSlot:
+		resp.Data, page, err = service.ListSlots(list, req.ONUListOptions)
+		resp.Page = &page
+	}
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	if out.file() {
+		if resp.Page != nil {
+			writePageHeaders(w, *resp.Page)
+		}
+		out.write(w, exportName("query", req.Query, req.IP), resp.Data)
+		return
+	}
 	response.JSON(w, http.StatusOK, resp)
 }
 
+// rates menghitung laju trafik. Store sampel yang gagal (misal Redis mati)
+// hanya dicatat; counter mentah tetap dikirim tanpa laju.
+func (h *QueryHandler) rates(ctx context.Context, key string, sample model.CounterSample, rxBytes, txBytes, rxPkts, txPkts int64) *model.TrafficRate {
+	rate, err := h.meter.Traffic(ctx, key, sample, rxBytes, txBytes, rxPkts, txPkts)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to compute traffic rate")
+	}
+	return rate
+}
+
 // OLTInfoRequest merepresentasikan permintaan info OLT
 type OLTInfoRequest struct {
 	IP        string `json:"ip" example:"192.168.1.1"`
@@ -363,6 +525,19 @@ func (h *QueryHandler) OLTInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	circuit := h.breakers.For(req.IP)
+	if err := circuit.Allow(ctx, drv.Probe); err != nil {
+		deviceError(w, err, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	release, err := h.limits.AcquireSNMP(ctx, req.IP)
+	if err != nil {
+		deviceError(w, err, http.StatusServiceUnavailable, "Timed out waiting for OLT: "+err.Error())
+		return
+	}
+	defer release()
+
 	if err := drv.Connect(); err != nil {
 		response.Error(w, http.StatusGatewayTimeout, "Failed to connect to OLT")
 		return
@@ -370,8 +545,8 @@ func (h *QueryHandler) OLTInfo(w http.ResponseWriter, r *http.Request) {
 	defer drv.Close()
 
 	info, err := drv.GetSystemInfo(ctx)
-	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to get OLT info")
+	if err := circuit.Done(err); err != nil {
+		deviceError(w, err, http.StatusInternalServerError, "Failed to get OLT info")
 		return
 	}
 
@@ -384,22 +559,32 @@ func (h *QueryHandler) OLTInfo(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// StatsResponse statistik pool SNMP global dan limiter per OLT
+type StatsResponse struct {
+	snmp.PoolStats
+	Devices []limiter.DeviceStats `json:"devices"`
+}
+
 // PoolStats godoc
 // @Summary Get SNMP Pool Stats
-// @Description Get connection pool statistics
+// @Description Get connection pool statistics and per-OLT session limiter state (active/queued SNMP and Telnet sessions)
 // @Tags System
 // @Produce json
-// @Success 200 {object} response.Response
+// @Success 200 {object} StatsResponse
 // @Router /stats [get]
 func (h *QueryHandler) PoolStats(w http.ResponseWriter, r *http.Request) {
-	stats := h.pool.Stats()
-	response.JSON(w, http.StatusOK, stats)
+	response.JSON(w, http.StatusOK, StatsResponse{
+		PoolStats: h.pool.Stats(),
+		Devices:   h.limits.Stats(),
+	})
 }
 
 func (h *QueryHandler) getDriver(req QueryRequest) (driver.Driver, error) {
 	switch req.Model {
 	case "C320", "c320":
-		return c320.New(req.IP, uint16(req.Port), req.Community), nil
+		d := c320.New(req.IP, uint16(req.Port), req.Community)
+		d.SetBreaker(h.breakers.For(req.IP))
+		return d, nil
 	default:
 		return nil, fmt.Errorf("unsupported OLT model: %s (supported: C320)", req.Model)
 	}
@@ -408,7 +593,9 @@ func (h *QueryHandler) getDriver(req QueryRequest) (driver.Driver, error) {
 func (h *QueryHandler) getDriverFromOLTInfo(req OLTInfoRequest) (driver.Driver, error) {
 	switch req.Model {
 	case "C320", "c320":
-		return c320.New(req.IP, uint16(req.Port), req.Community), nil
+		d := c320.New(req.IP, uint16(req.Port), req.Community)
+		d.SetBreaker(h.breakers.For(req.IP))
+		return d, nil
 	default:
 		return nil, fmt.Errorf("unsupported OLT model: %s", req.Model)
 	}
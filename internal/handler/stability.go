@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// StabilityHandler menangani laporan stabilitas ONU dari riwayat status
+// yang dikumpulkan pemantauan berkala.
+type StabilityHandler struct {
+	service *service.MonitorService
+}
+
+// NewStabilityHandler membuat handler stabilitas baru.
+func NewStabilityHandler(service *service.MonitorService) *StabilityHandler {
+	return &StabilityHandler{service: service}
+}
+
+// OLT godoc
+// @Summary Stabilitas ONU per PON
+// @Description Dari riwayat perubahan status hasil pemantauan berkala (monitor.enabled): jumlah flap (online menjadi tidak online), flap per hari, MTBF, availability, alasan offline dominan dan skor 0-100 (stable >= 90, degraded >= 60, unstable) per ONU dan per PON. ONU terburuk lebih dulu.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board query int false "ID Board (default seluruh OLT)"
+// @Param pon query int false "ID Port PON (butuh board)"
+// @Param window query int false "Rentang dalam jam (default 168, maks retensi)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/stability [get]
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/stability [get]
+func (h *StabilityHandler) OLT(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	board, pon, err := scope(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	window, err := queryInt(r, "window")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'window' parameter")
+		return
+	}
+
+	report, err := h.service.Stability(oltID, board, pon, time.Duration(window)*time.Hour)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// ONU godoc
+// @Summary Stabilitas & Riwayat Status ONU
+// @Description Stabilitas satu ONU beserta setiap perubahan status (waktu poll, status asal/tujuan, alasan offline) dalam rentang window.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Param window query int false "Rentang dalam jam (default 168, maks retensi)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/stability [get]
+func (h *StabilityHandler) ONU(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	board, pon, err := scope(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	onuID, err := strconv.Atoi(chi.URLParam(r, "onu_id"))
+	if err != nil {
+		response.BadRequest(w, "Invalid ONU ID")
+		return
+	}
+	window, err := queryInt(r, "window")
+	if err != nil {
+		response.BadRequest(w, "Invalid 'window' parameter")
+		return
+	}
+
+	st, err := h.service.ONUStability(oltID, board, pon, onuID, time.Duration(window)*time.Hour)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, st)
+}
+
+func (h *StabilityHandler) error(w http.ResponseWriter, err error) {
+	var se *service.ServiceError
+	switch {
+	case errors.Is(err, service.ErrMonitorDisabled):
+		response.Error(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, service.ErrNotObserved):
+		response.NotFound(w, err.Error())
+	case errors.As(err, &se):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalError(w, err.Error())
+	}
+}
+
+// scope membaca board dan PON dari path (route per PON) atau query string
+func scope(r *http.Request) (board, pon int, err error) {
+	if v := chi.URLParam(r, "board_id"); v != "" {
+		if board, err = strconv.Atoi(v); err != nil {
+			return 0, 0, errors.New("Invalid board ID")
+		}
+		if pon, err = strconv.Atoi(chi.URLParam(r, "pon_id")); err != nil {
+			return 0, 0, errors.New("Invalid PON ID")
+		}
+		return board, pon, nil
+	}
+	if board, err = queryInt(r, "board"); err != nil {
+		return 0, 0, errors.New("Invalid 'board' parameter")
+	}
+	if pon, err = queryInt(r, "pon"); err != nil {
+		return 0, 0, errors.New("Invalid 'pon' parameter")
+	}
+	return board, pon, nil
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// TopTraffic godoc
+// @Summary Top-N ONU Pemakai Bandwidth
+// @Description Mengukur laju trafik semua ONU online selama window (counter dibaca dua kali), lalu mengembalikan N ONU terbesar per arah beserta nama, SN dan SLA profil bandwidth. ONU yang melebihi SLA ditandai exceeds_sla. Cakupan satu board (?board=) atau satu PON (path board/pon atau ?board=&pon=); seluruh OLT tidak didukung karena tidak selesai dalam batas waktu request.
+// @Tags ONU
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param olt_id path string true "ID OLT"
+// @Param board query int true "ID Board"
+// @Param pon query int false "ID Port PON (default semua PON di board)"
+// @Param n query int false "Jumlah ONU (default 10, maks 100)"
+// @Param direction query string false "downstream (default) atau upstream"
+// @Param window query int false "Lama pengukuran dalam detik (default 10, 5-60)"
+// @Param format query string false "json (default), csv atau xlsx; bisa juga lewat header Accept"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/traffic/top [get]
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/traffic/top [get]
+func (h *ONUHandler) TopTraffic(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	opts, err := topTrafficOptions(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	out, err := parseExport(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	top, err := h.service.TopTraffic(r.Context(), oltID, opts)
+	if err != nil {
+		var se *service.ServiceError
+		if errors.As(err, &se) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		deviceError(w, err, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if out.file() {
+		out.write(w, exportName("top-traffic", oltID, opts.Direction), top.Entries)
+		return
+	}
+	response.JSON(w, http.StatusOK, top)
+}
+
+// topTrafficOptions membaca cakupan (lihat scope) beserta n, direction dan
+// window
+func topTrafficOptions(r *http.Request) (service.TopTrafficOptions, error) {
+	opts := service.TopTrafficOptions{Direction: r.URL.Query().Get("direction")}
+
+	var err error
+	if opts.Board, opts.PON, err = scope(r); err != nil {
+		return opts, err
+	}
+	if opts.N, err = queryInt(r, "n"); err != nil {
+		return opts, errors.New("Invalid 'n' parameter")
+	}
+	window, err := queryInt(r, "window")
+	if err != nil {
+		return opts, errors.New("Invalid 'window' parameter")
+	}
+	opts.Window = time.Duration(window) * time.Second
+	return opts, opts.Validate()
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ardani/snmp-zte/internal/middleware"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/service"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// UserHandler menangani pengelolaan user API dan role-nya.
+type UserHandler struct {
+	service *service.UserService
+}
+
+// NewUserHandler membuat handler user baru.
+func NewUserHandler(service *service.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// MeResponse user yang sedang login beserta permission-nya
+type MeResponse struct {
+	model.User
+	Permissions []string `json:"permissions"`
+}
+
+// Me godoc
+// @Summary User Saat Ini
+// @Description Mengembalikan user yang sedang login, role, scope OLT dan permission-nya.
+// @Tags Users
+// @Produce json
+// @Success 200 {object} MeResponse
+// @Router /api/v1/auth/me [get]
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user := middleware.UserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	response.JSON(w, http.StatusOK, MeResponse{User: *user, Permissions: model.RolePermissions[user.Role]})
+}
+
+// List godoc
+// @Summary List User
+// @Description Mengambil daftar semua user API (khusus admin).
+// @Tags Users
+// @Produce json
+// @Success 200 {array} model.User
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/users [get]
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.service.List())
+}
+
+// Get godoc
+// @Summary Ambil Detail User
+// @Tags Users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} model.User
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/users/{username} [get]
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.Get(chi.URLParam(r, "username"))
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, user)
+}
+
+// Create godoc
+// @Summary Tambah User
+// @Description Menambah user API. Role: read-only, operator, admin. olts membatasi akses ke ID OLT tertentu (kosong = semua).
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body model.UserRequest true "Data User"
+// @Success 201 {object} model.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/v1/users [post]
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.Create(req)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, user)
+}
+
+// Update godoc
+// @Summary Ubah User
+// @Description Mengubah role, scope OLT, status disabled atau password. Field yang tidak dikirim tidak diubah.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param request body model.UserRequest true "Perubahan"
+// @Success 200 {object} model.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/users/{username} [put]
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req model.UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.Update(chi.URLParam(r, "username"), req)
+	if err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, user)
+}
+
+// Delete godoc
+// @Summary Hapus User
+// @Tags Users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/users/{username} [delete]
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if err := h.service.Delete(username); err != nil {
+		h.error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, map[string]string{"message": "User deleted: " + username})
+}
+
+func (h *UserHandler) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, service.ErrUserExists):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrLastAdmin):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		var se *service.ServiceError
+		if errors.As(err, &se) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		response.InternalError(w, err.Error())
+	}
+}
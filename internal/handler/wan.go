@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// WAN godoc
+// @Summary Koneksi WAN ONU
+// @Description Koneksi WAN ONU lewat CLI (show gpon remote-onu wan-ip): mode (pppoe/dhcp/static), status, username PPPoE, IP, gateway dan DNS. Password PPPoE tidak ditampilkan.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Success 200 {object} response.Response{data=model.ONUWAN}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/wan [get]
+func (h *ONUHandler) WAN(w http.ResponseWriter, r *http.Request) {
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+
+	wan, err := h.service.GetONUWAN(r.Context(), chi.URLParam(r, "olt_id"), boardID, ponID, onuID)
+	if err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, wan)
+}
+
+// WiFi godoc
+// @Summary SSID WiFi ONU
+// @Description SSID WiFi ONU lewat CLI (show gpon remote-onu wifi): nama, status, hidden, mode autentikasi dan jumlah perangkat terhubung. Password WiFi tidak ditampilkan.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Success 200 {object} response.Response{data=model.ONUWiFi}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/wifi [get]
+func (h *ONUHandler) WiFi(w http.ResponseWriter, r *http.Request) {
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+
+	wifi, err := h.service.GetONUWiFi(r.Context(), chi.URLParam(r, "olt_id"), boardID, ponID, onuID)
+	if err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, wifi)
+}
+
+// Config godoc
+// @Summary Konfigurasi ONU di OLT
+// @Description Konfigurasi interface dan pon-onu-mng ONU (show onu running config) dalam bentuk terstruktur, termasuk WAN (mode, username, vlan-profile) dan SSID. Password dan key tidak ditampilkan.
+// @Tags ONU
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Success 200 {object} response.Response{data=cli.ONUConfig}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/config [get]
+func (h *ONUHandler) Config(w http.ResponseWriter, r *http.Request) {
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.service.GetONUConfig(r.Context(), chi.URLParam(r, "olt_id"), boardID, ponID, onuID)
+	if err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cfg)
+}
+
+// SetPPPoE godoc
+// @Summary Atur Kredensial PPPoE ONU
+// @Description Mengatur WAN PPPoE ONU lewat pon-onu-mng (wan-ip {index} mode pppoe username ... password ... vlan-profile ... host ...). Password disembunyikan di audit log.
+// @Tags ONU
+// @Accept json
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Param index path int true "Index WAN (1-8)"
+// @Param request body model.ONUPPPoERequest true "Kredensial PPPoE"
+// @Success 200 {object} response.Response{data=model.ONUConfigResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/wan/{index}/pppoe [put]
+func (h *ONUHandler) SetPPPoE(w http.ResponseWriter, r *http.Request) {
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil {
+		response.BadRequest(w, "Invalid WAN index")
+		return
+	}
+
+	var req model.ONUPPPoERequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if err := h.service.SetONUPPPoE(r.Context(), chi.URLParam(r, "olt_id"), boardID, ponID, onuID, index, req); err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, model.ONUConfigResult{
+		Board:   boardID,
+		PON:     ponID,
+		ONUID:   onuID,
+		Target:  fmt.Sprintf("wan-ip %d", index),
+		Message: fmt.Sprintf("PPPoE credentials set for ONU %d/%d:%d", boardID, ponID, onuID),
+	})
+}
+
+// SetWiFi godoc
+// @Summary Ubah SSID/Password WiFi ONU
+// @Description Mengubah nama SSID dan/atau password WPA2-PSK lewat pon-onu-mng. Field kosong tidak diubah. Password disembunyikan di audit log.
+// @Tags ONU
+// @Accept json
+// @Produce json
+// @Param olt_id path string true "ID OLT"
+// @Param board_id path int true "ID Board/Slot"
+// @Param pon_id path int true "ID Port PON"
+// @Param onu_id path int true "ID ONU"
+// @Param ssid path int true "Index SSID (1 = wifi_0/1)"
+// @Param request body model.ONUWiFiRequest true "Nama dan/atau password SSID"
+// @Success 200 {object} response.Response{data=model.ONUConfigResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/wifi/{ssid} [put]
+func (h *ONUHandler) SetWiFi(w http.ResponseWriter, r *http.Request) {
+	boardID, ponID, onuID, ok := onuParams(w, r)
+	if !ok {
+		return
+	}
+	ssid, err := strconv.Atoi(chi.URLParam(r, "ssid"))
+	if err != nil {
+		response.BadRequest(w, "Invalid SSID index")
+		return
+	}
+
+	var req model.ONUWiFiRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if err := h.service.SetONUSSID(r.Context(), chi.URLParam(r, "olt_id"), boardID, ponID, onuID, ssid, req); err != nil {
+		onuCLIError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, model.ONUConfigResult{
+		Board:   boardID,
+		PON:     ponID,
+		ONUID:   onuID,
+		Target:  fmt.Sprintf("wifi_0/%d", ssid),
+		Message: fmt.Sprintf("SSID updated for ONU %d/%d:%d", boardID, ponID, onuID),
+	})
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	s, err := NewStore(filepath.Join(t.TempDir(), "events.jsonl"), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Now()
+
+	// Poll pertama hanya baseline
+	if events := s.Observe("olt-1", 1, 1, map[int]string{1: "Online", 2: "Online"}, now); len(events) != 0 {
+		t.Fatalf("baseline: events = %+v, want none", events)
+	}
+
+	events := s.Observe("olt-1", 1, 1, map[int]string{1: "LOS", 2: "Online", 3: "Online"}, now.Add(time.Minute))
+	if len(events) != 1 || events[0].ONUID != 1 || !events[0].Down() {
+		t.Fatalf("events = %+v, want ONU 1 Online -> LOS", events)
+	}
+	if err := s.Append(events); err != nil {
+		t.Fatal(err)
+	}
+
+	// ONU 2 dihapus dari PON: dilupakan, bukan event
+	s.Observe("olt-1", 1, 1, map[int]string{1: "LOS", 3: "Online"}, now.Add(2*time.Minute))
+	if got := s.Statuses("olt-1", 1, 1); len(got) != 2 || got[2] != "" {
+		t.Errorf("statuses = %v, want ONU 1 dan 3", got)
+	}
+
+	// Event dimuat ulang dari file
+	s.Close()
+	s, err = NewStore(s.path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Events(Filter{OLTID: "olt-1", ONUID: 1}); len(got) != 1 || got[0].To != "LOS" {
+		t.Errorf("reload: events = %+v", got)
+	}
+}
+
+func TestStability(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+	at := func(h float64) time.Time { return from.Add(time.Duration(h * float64(time.Hour))) }
+
+	// Dua kali turun, masing-masing 6 jam: online 36 dari 48 jam
+	events := []Event{
+		{At: at(10), From: "Online", To: "LOS", Reason: "LOSi"},
+		{At: at(16), From: "LOS", To: "Online"},
+		{At: at(30), From: "Online", To: "Dying Gasp", Reason: "PowerOff"},
+		{At: at(32), From: "Dying Gasp", To: "Offline"},
+		{At: at(36), From: "Offline", To: "Online"},
+	}
+	st := Stability(events, "Online", from, to)
+	if st.Flaps != 2 || st.FlapsPerDay != 1 || st.Availability != 75 {
+		t.Errorf("flaps=%d per_day=%v availability=%v, want 2, 1, 75", st.Flaps, st.FlapsPerDay, st.Availability)
+	}
+	if st.MTBF == nil || *st.MTBF != 18*3600 {
+		t.Errorf("mtbf = %v, want 64800", st.MTBF)
+	}
+	if st.DominantReason != "LOSi" || st.Score != 65 || st.Grade != GradeDegraded {
+		t.Errorf("reason=%q score=%d grade=%q", st.DominantReason, st.Score, st.Grade)
+	}
+
+	// Tanpa event dan selalu online
+	st = Stability(nil, "Online", from, to)
+	if st.Score != 100 || st.Grade != GradeStable || st.MTBF != nil {
+		t.Errorf("stabil: %+v", st)
+	}
+
+	// Tidak pernah online selama rentang
+	if st = Stability(nil, "Offline", from, to); st.Grade != GradeOffline {
+		t.Errorf("offline: grade = %q", st.Grade)
+	}
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Tingkat stabilitas ONU
+const (
+	GradeStable   = "stable"
+	GradeDegraded = "degraded"
+	GradeUnstable = "unstable"
+	GradeOffline  = "offline" // Tidak pernah online selama rentang, tidak ikut skor PON
+)
+
+// Parameter skor stabilitas
+const (
+	FlapPenalty   = 10.0           // Pengurang skor per flap per hari
+	StableScore   = 90             // Skor minimum stable
+	DegradedScore = 60             // Skor minimum degraded
+	MinSpan       = 24 * time.Hour // Pembagi minimum flaps per hari, agar satu flap sesaat setelah start tidak terbaca puluhan flap per hari
+)
+
+// Stability menghitung stabilitas satu ONU dalam rentang [from, to) dari
+// event ONU tersebut (urut waktu) dan status pada poll terakhir.
+//
+// Availability adalah porsi waktu online; MTBF lama online dibagi jumlah
+// flap. Skor = availability - FlapPenalty x flaps per hari, dibatasi 0-100.
+func Stability(events []Event, current string, from, to time.Time) model.ONUStability {
+	online := model.StatusOnline.String()
+	st := model.ONUStability{Status: current}
+	if n := len(events); n > 0 {
+		if st.Status == "" {
+			st.Status = events[n-1].To
+		}
+		st.LastChange = events[n-1].At.UTC().Format(time.RFC3339)
+	}
+
+	// Status di awal rentang adalah status asal event pertama
+	state := st.Status
+	if len(events) > 0 {
+		state = events[0].From
+	}
+	wasOnline := state == online
+
+	var up time.Duration
+	last := from
+	reasons := make(map[string]int)
+	for _, e := range events {
+		if state == online {
+			up += e.At.Sub(last)
+		}
+		last, state = e.At, e.To
+		wasOnline = wasOnline || state == online
+		if e.Down() {
+			st.Flaps++
+			reasons[e.Cause()]++
+		}
+	}
+	if state == online {
+		up += to.Sub(last)
+	}
+
+	span := to.Sub(from)
+	if span > 0 {
+		st.Availability = round2(100 * up.Seconds() / span.Seconds())
+	}
+	st.FlapsPerDay = round2(float64(st.Flaps) / (max(span, MinSpan).Hours() / 24))
+	if st.Flaps > 0 {
+		mtbf := math.Round(up.Seconds() / float64(st.Flaps))
+		st.MTBF = &mtbf
+	}
+	st.DominantReason = Dominant(reasons)
+
+	if !wasOnline {
+		st.Grade = GradeOffline
+		return st
+	}
+	st.Score = int(math.Round(math.Max(0, math.Min(100, st.Availability-FlapPenalty*st.FlapsPerDay))))
+	st.Grade = Grade(st.Score)
+	return st
+}
+
+// Grade tingkat stabilitas untuk skor
+func Grade(score int) string {
+	switch {
+	case score >= StableScore:
+		return GradeStable
+	case score >= DegradedScore:
+		return GradeDegraded
+	default:
+		return GradeUnstable
+	}
+}
+
+// Dominant alasan dengan jumlah terbanyak; seri diurutkan alfabetis
+func Dominant(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
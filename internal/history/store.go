@@ -0,0 +1,290 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Event satu perubahan status ONU yang terlihat oleh pemantauan berkala.
+// Waktu adalah waktu poll, bukan waktu kejadian di OLT, sehingga akurasinya
+// sebatas interval poll.
+type Event struct {
+	At     time.Time `json:"at"`
+	OLTID  string    `json:"olt_id"`
+	Board  int       `json:"board"`
+	PON    int       `json:"pon"`
+	ONUID  int       `json:"onu_id"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason,omitempty"` // Alasan offline dari OLT, hanya saat ONU turun
+}
+
+// Down true jika ONU berubah dari online menjadi tidak online (satu flap)
+func (e Event) Down() bool {
+	online := model.StatusOnline.String()
+	return e.From == online && e.To != online
+}
+
+// Cause alasan turun: alasan offline dari OLT, atau status tujuan jika
+// alasan tidak terbaca
+func (e Event) Cause() string {
+	if e.Reason == "" || e.Reason == model.ReasonUnknown.String() {
+		return e.To
+	}
+	return e.Reason
+}
+
+// Key identitas satu ONU
+type Key struct {
+	OLTID string
+	Board int
+	PON   int
+	ONUID int
+}
+
+// Filter kriteria pencarian event. Field nol tidak dipakai.
+type Filter struct {
+	OLTID string
+	Board int
+	PON   int
+	ONUID int
+	Since time.Time
+}
+
+func (f *Filter) match(e *Event) bool {
+	switch {
+	case f.OLTID != "" && e.OLTID != f.OLTID:
+		return false
+	case f.Board != 0 && e.Board != f.Board:
+		return false
+	case f.PON != 0 && e.PON != f.PON:
+		return false
+	case f.ONUID != 0 && e.ONUID != f.ONUID:
+		return false
+	case !f.Since.IsZero() && e.At.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// Store riwayat perubahan status ONU. Event disimpan append-only dalam
+// format JSON Lines dan dimuat ke memori saat start (hanya yang masih dalam
+// retensi). Status terakhir setiap ONU hanya di memori: poll pertama
+// setelah start menjadi baseline, sehingga perubahan selama server mati
+// tidak tercatat sebagai flap serentak.
+type Store struct {
+	path      string
+	retention time.Duration
+
+	mu     sync.RWMutex
+	file   *os.File
+	events []Event // Urut waktu
+	state  map[Key]string
+	since  time.Time
+}
+
+// NewStore membuka (atau membuat) file riwayat di path. Event lebih tua
+// dari retention tidak dimuat dan terhapus dari file saat Compact.
+func NewStore(path string, retention time.Duration) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	s := &Store{
+		path:      path,
+		retention: retention,
+		file:      f,
+		state:     make(map[Key]string),
+		since:     time.Now(),
+	}
+	cutoff := s.cutoff(time.Now())
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		var e Event
+		if len(line) == 0 || json.Unmarshal(line, &e) != nil || e.At.Before(cutoff) {
+			continue
+		}
+		s.events = append(s.events, e)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	sort.SliceStable(s.events, func(i, j int) bool { return s.events[i].At.Before(s.events[j].At) })
+	if len(s.events) > 0 && s.events[0].At.Before(s.since) {
+		s.since = s.events[0].At
+	}
+	return s, nil
+}
+
+// Observe membandingkan status ONU di satu PON dengan poll sebelumnya dan
+// mengembalikan perubahannya (belum disimpan, lihat Append). ONU yang baru
+// terlihat tidak menghasilkan event; ONU yang hilang dari PON dilupakan.
+func (s *Store) Observe(oltID string, board, pon int, statuses map[int]string, at time.Time) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	for onuID, status := range statuses {
+		key := Key{oltID, board, pon, onuID}
+		prev, ok := s.state[key]
+		s.state[key] = status
+		if ok && prev != status {
+			events = append(events, Event{At: at, OLTID: oltID, Board: board, PON: pon, ONUID: onuID, From: prev, To: status})
+		}
+	}
+	for key := range s.state {
+		if key.OLTID == oltID && key.Board == board && key.PON == pon {
+			if _, ok := statuses[key.ONUID]; !ok {
+				delete(s.state, key)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ONUID < events[j].ONUID })
+	return events
+}
+
+// Append menyimpan event ke file dan memori
+func (s *Store) Append(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history event: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Events mengembalikan event yang cocok dengan filter, urut waktu
+func (s *Store) Events(f Filter) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	start := 0
+	if !f.Since.IsZero() {
+		start = sort.Search(len(s.events), func(i int) bool { return !s.events[i].At.Before(f.Since) })
+	}
+	var out []Event
+	for i := start; i < len(s.events); i++ {
+		if f.match(&s.events[i]) {
+			out = append(out, s.events[i])
+		}
+	}
+	return out
+}
+
+// Statuses status terakhir setiap ONU di satu PON dari poll terakhir
+func (s *Store) Statuses(oltID string, board, pon int) map[int]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make(map[int]string)
+	for key, status := range s.state {
+		if key.OLTID == oltID && key.Board == board && key.PON == pon {
+			statuses[key.ONUID] = status
+		}
+	}
+	return statuses
+}
+
+// Since awal rentang data: event tertua yang dimuat atau waktu start
+func (s *Store) Since() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.since
+}
+
+// Retention lama event disimpan
+func (s *Store) Retention() time.Duration {
+	return s.retention
+}
+
+// Compact membuang event di luar retensi dari memori dan menulis ulang
+// file. Mengembalikan jumlah event yang dibuang.
+func (s *Store) Compact(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.cutoff(now)
+	n := sort.Search(len(s.events), func(i int) bool { return !s.events[i].At.Before(cutoff) })
+	if n == 0 {
+		return 0, nil
+	}
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact history file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range s.events[n:] {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return 0, fmt.Errorf("failed to compact history file: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("failed to compact history file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compact history file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return 0, fmt.Errorf("failed to compact history file: %w", err)
+	}
+
+	reopened, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reopen history file: %w", err)
+	}
+	s.file.Close()
+	s.file = reopened
+	s.events = append([]Event(nil), s.events[n:]...)
+	if s.since.Before(cutoff) {
+		s.since = cutoff
+	}
+	return n, nil
+}
+
+// Close menutup file riwayat
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *Store) cutoff(now time.Time) time.Time {
+	if s.retention <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-s.retention)
+}
@@ -0,0 +1,337 @@
+package incident
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/history"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Jenis insiden
+const (
+	TypeFiberCut    = "fiber_cut"    // Mayoritas ONU LOS: feeder atau splitter putus
+	TypePowerOutage = "power_outage" // Mayoritas ONU Dying Gasp: listrik area padam
+	TypeCardFault   = "card_fault"   // Kartu PON tidak InService saat insiden
+	TypeUnknown     = "unknown"      // Penyebab campuran
+)
+
+// Status insiden
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+)
+
+// Cakupan insiden
+const (
+	ScopePON   = "pon"
+	ScopeBoard = "board" // Beberapa PON di satu board
+	ScopeOLT   = "olt"   // PON di beberapa board
+)
+
+// Incident satu gangguan massal: banyak ONU yang turun hampir bersamaan
+// digabung menjadi satu insiden.
+type Incident struct {
+	ID          int64          `json:"id"`
+	OLTID       string         `json:"olt_id"`
+	Type        string         `json:"type"`
+	Status      string         `json:"status"`
+	Scope       string         `json:"scope"`
+	Board       int            `json:"board,omitempty"`        // Diisi jika semua PON di satu board
+	BoardStatus string         `json:"board_status,omitempty"` // Status kartu saat klasifikasi
+	PONs        []PONImpact    `json:"pons"`
+	Affected    int            `json:"affected"` // ONU yang turun
+	Down        int            `json:"down"`     // ONU yang belum online kembali
+	Causes      map[string]int `json:"causes"`   // Alasan offline (atau status) dan jumlah ONU
+	StartedAt   time.Time      `json:"started_at"`
+	LastDownAt  time.Time      `json:"last_down_at"`
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// PONImpact ONU terdampak di satu PON
+type PONImpact struct {
+	Board  int   `json:"board"`
+	PON    int   `json:"pon"`
+	Online int   `json:"online_before"` // ONU online sebelum insiden
+	ONUs   []int `json:"onus"`
+	Down   []int `json:"down"`
+}
+
+// Port satu port PON
+type Port struct{ Board, PON int }
+
+// Config ambang deteksi insiden
+type Config struct {
+	Window       time.Duration // ONU yang turun dalam rentang ini dianggap serentak
+	MinONUs      int           // ONU turun minimum per PON
+	MinRatio     float64       // Porsi minimum ONU online di PON yang turun
+	ResolveRatio float64       // Porsi ONU terdampak yang online kembali agar insiden selesai
+}
+
+// BoardStatus membaca status kartu (model.CardStatus) untuk klasifikasi
+type BoardStatus func(board int) string
+
+// Detector mengelompokkan perubahan status ONU hasil poll menjadi insiden.
+// ONU yang turun dicatat per PON selama Window; PON yang memenuhi MinONUs
+// dan MinRatio membuka insiden, dan PON lain yang memenuhi ambang selama
+// insiden masih aktif (ada ONU turun dalam Window terakhir) digabung ke
+// insiden yang sama.
+type Detector struct {
+	cfg   Config
+	store *Store
+
+	mu     sync.Mutex
+	recent map[string]map[Port][]history.Event // ONU turun yang belum masuk insiden, per OLT
+}
+
+// NewDetector membuat detector yang menyimpan insiden di store
+func NewDetector(cfg Config, store *Store) *Detector {
+	return &Detector{cfg: cfg, store: store, recent: make(map[string]map[Port][]history.Event)}
+}
+
+// Observe memproses event satu poll OLT. online adalah jumlah ONU online
+// per PON setelah poll. Mengembalikan insiden yang baru dibuka dan yang
+// selesai pada poll ini.
+func (d *Detector) Observe(oltID string, events []history.Event, online map[Port]int, now time.Time, boardStatus BoardStatus) (opened, resolved []Incident, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	open := d.store.Open(oltID)
+	recent := d.recent[oltID]
+	if recent == nil {
+		recent = make(map[Port][]history.Event)
+		d.recent[oltID] = recent
+	}
+	changed := make(map[*Incident]bool)
+	reclassify := make(map[*Incident]bool)
+
+	onlineStatus := model.StatusOnline.String()
+	for _, e := range events {
+		p := Port{e.Board, e.PON}
+		switch {
+		case e.To == onlineStatus:
+			for _, inc := range open {
+				if inc.restore(p, e.ONUID) {
+					changed[inc] = true
+				}
+			}
+			recent[p] = slices.DeleteFunc(recent[p], func(r history.Event) bool { return r.ONUID == e.ONUID })
+		case e.Down():
+			recent[p] = append(recent[p], e)
+		}
+	}
+	for p, evs := range recent {
+		evs = slices.DeleteFunc(evs, func(r history.Event) bool { return now.Sub(r.At) > d.cfg.Window })
+		if len(evs) == 0 {
+			delete(recent, p)
+			continue
+		}
+		recent[p] = evs
+	}
+
+	// Insiden aktif: terbuka dan masih ada ONU turun dalam Window terakhir
+	var active *Incident
+	for _, inc := range open {
+		if now.Sub(inc.LastDownAt) <= d.cfg.Window && (active == nil || inc.LastDownAt.After(active.LastDownAt)) {
+			active = inc
+		}
+	}
+
+	var qualified []Port
+	for p, evs := range recent {
+		if active != nil && active.impact(p) != nil {
+			active.add(p, evs, online[p])
+			changed[active] = true
+			delete(recent, p)
+			continue
+		}
+		before := online[p] + len(evs)
+		if len(evs) >= d.cfg.MinONUs && float64(len(evs)) >= d.cfg.MinRatio*float64(before) {
+			qualified = append(qualified, p)
+		}
+	}
+	sort.Slice(qualified, func(i, j int) bool {
+		if qualified[i].Board != qualified[j].Board {
+			return qualified[i].Board < qualified[j].Board
+		}
+		return qualified[i].PON < qualified[j].PON
+	})
+
+	var created *Incident
+	if len(qualified) > 0 {
+		if active == nil {
+			created = &Incident{OLTID: oltID, Status: StatusOpen, Causes: make(map[string]int)}
+			active = created
+		}
+		for _, p := range qualified {
+			active.add(p, recent[p], online[p])
+			delete(recent, p)
+		}
+		changed[active] = true
+		reclassify[active] = true
+	}
+
+	var save []*Incident
+	for inc := range changed {
+		if reclassify[inc] {
+			inc.classify(boardStatus)
+		}
+		inc.refresh()
+		if inc.Affected > 0 && float64(inc.Affected-inc.Down) >= d.cfg.ResolveRatio*float64(inc.Affected) {
+			inc.Status = StatusResolved
+			resolvedAt := now
+			inc.ResolvedAt = &resolvedAt
+		}
+		save = append(save, inc)
+	}
+	if len(save) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(save, func(i, j int) bool { return save[i].StartedAt.Before(save[j].StartedAt) })
+	if err := d.store.Save(save...); err != nil {
+		return nil, nil, err
+	}
+
+	for _, inc := range save {
+		if inc == created {
+			opened = append(opened, inc.clone())
+		}
+		if inc.Status == StatusResolved {
+			resolved = append(resolved, inc.clone())
+		}
+	}
+	return opened, resolved, nil
+}
+
+// impact mengembalikan dampak insiden di PON p, nil jika PON tidak terdampak
+func (inc *Incident) impact(p Port) *PONImpact {
+	for i := range inc.PONs {
+		if inc.PONs[i].Board == p.Board && inc.PONs[i].PON == p.PON {
+			return &inc.PONs[i]
+		}
+	}
+	return nil
+}
+
+// add menambahkan ONU yang turun di PON p. online jumlah ONU online di PON
+// setelah poll, untuk jumlah ONU online sebelum insiden.
+func (inc *Incident) add(p Port, events []history.Event, online int) {
+	pi := inc.impact(p)
+	if pi == nil {
+		inc.PONs = append(inc.PONs, PONImpact{Board: p.Board, PON: p.PON, Online: online + len(events)})
+		pi = &inc.PONs[len(inc.PONs)-1]
+	}
+	for _, e := range events {
+		if slices.Contains(pi.ONUs, e.ONUID) {
+			// Turun lagi sebelum insiden selesai
+			if !slices.Contains(pi.Down, e.ONUID) {
+				pi.Down = append(pi.Down, e.ONUID)
+			}
+			continue
+		}
+		pi.ONUs = append(pi.ONUs, e.ONUID)
+		pi.Down = append(pi.Down, e.ONUID)
+		inc.Causes[e.Cause()]++
+		if inc.StartedAt.IsZero() || e.At.Before(inc.StartedAt) {
+			inc.StartedAt = e.At
+		}
+		if e.At.After(inc.LastDownAt) {
+			inc.LastDownAt = e.At
+		}
+	}
+	slices.Sort(pi.ONUs)
+	slices.Sort(pi.Down)
+}
+
+// restore menandai ONU di PON p sudah online kembali
+func (inc *Incident) restore(p Port, onuID int) bool {
+	pi := inc.impact(p)
+	if pi == nil || !slices.Contains(pi.Down, onuID) {
+		return false
+	}
+	pi.Down = slices.DeleteFunc(pi.Down, func(id int) bool { return id == onuID })
+	return true
+}
+
+// refresh menghitung ulang jumlah ONU terdampak dan yang masih turun
+func (inc *Incident) refresh() {
+	inc.Affected, inc.Down = 0, 0
+	for _, pi := range inc.PONs {
+		inc.Affected += len(pi.ONUs)
+		inc.Down += len(pi.Down)
+	}
+}
+
+// classify menentukan cakupan dan jenis insiden. Kartu yang tidak
+// InService saat insiden di satu board berarti card fault; selain itu
+// jenis mengikuti penyebab mayoritas ONU.
+func (inc *Incident) classify(boardStatus BoardStatus) {
+	var boards []int
+	for _, pi := range inc.PONs {
+		if !slices.Contains(boards, pi.Board) {
+			boards = append(boards, pi.Board)
+		}
+	}
+	switch {
+	case len(inc.PONs) == 1:
+		inc.Scope = ScopePON
+	case len(boards) == 1:
+		inc.Scope = ScopeBoard
+	default:
+		inc.Scope = ScopeOLT
+	}
+
+	inc.Board, inc.BoardStatus = 0, ""
+	if len(boards) == 1 {
+		inc.Board = boards[0]
+		if boardStatus != nil {
+			inc.BoardStatus = boardStatus(inc.Board)
+			switch inc.BoardStatus {
+			case model.CardStatusFault.String(), model.CardStatusOffline.String(), model.CardStatusInit.String():
+				inc.Type = TypeCardFault
+				return
+			}
+		}
+	}
+
+	var power, fiber, total int
+	for cause, n := range inc.Causes {
+		total += n
+		switch cause {
+		case model.StatusDyingGasp.String(), model.ReasonPowerOff.String():
+			power += n
+		case model.StatusLOS.String(), model.ReasonLOSi.String(), model.ReasonLOFi.String():
+			fiber += n
+		}
+	}
+	switch {
+	case power*2 > total:
+		inc.Type = TypePowerOutage
+	case fiber*2 > total:
+		inc.Type = TypeFiberCut
+	default:
+		inc.Type = TypeUnknown
+	}
+}
+
+// clone salinan dalam agar insiden bisa dibaca tanpa lock
+func (inc *Incident) clone() Incident {
+	c := *inc
+	c.PONs = make([]PONImpact, len(inc.PONs))
+	for i, pi := range inc.PONs {
+		pi.ONUs = slices.Clone(pi.ONUs)
+		pi.Down = slices.Clone(pi.Down)
+		c.PONs[i] = pi
+	}
+	c.Causes = make(map[string]int, len(inc.Causes))
+	for k, v := range inc.Causes {
+		c.Causes[k] = v
+	}
+	if inc.ResolvedAt != nil {
+		t := *inc.ResolvedAt
+		c.ResolvedAt = &t
+	}
+	return c
+}
@@ -0,0 +1,159 @@
+package incident
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/history"
+)
+
+func down(at time.Time, board, pon int, to, reason string, onuIDs ...int) []history.Event {
+	var events []history.Event
+	for _, id := range onuIDs {
+		events = append(events, history.Event{At: at, OLTID: "olt1", Board: board, PON: pon, ONUID: id, From: "Online", To: to, Reason: reason})
+	}
+	return events
+}
+
+func up(at time.Time, board, pon int, onuIDs ...int) []history.Event {
+	var events []history.Event
+	for _, id := range onuIDs {
+		events = append(events, history.Event{At: at, OLTID: "olt1", Board: board, PON: pon, ONUID: id, From: "LOS", To: "Online"})
+	}
+	return events
+}
+
+func newDetector(t *testing.T) *Detector {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "incidents.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDetector(Config{Window: 3 * time.Minute, MinONUs: 4, MinRatio: 0.5, ResolveRatio: 0.8}, store)
+}
+
+func inService(int) string { return "InService" }
+
+func TestDetectorFiberCut(t *testing.T) {
+	d := newDetector(t)
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Dua ONU turun belum cukup untuk insiden
+	opened, _, err := d.Observe("olt1", down(t0, 1, 1, "LOS", "LOS", 1, 2), map[Port]int{{1, 1}: 8}, t0, inService)
+	if err != nil || len(opened) != 0 {
+		t.Fatalf("opened = %v, err = %v; want none", opened, err)
+	}
+
+	// Empat ONU lagi pada poll berikutnya: 6 dari 10 turun dalam window
+	t1 := t0.Add(time.Minute)
+	opened, _, err = d.Observe("olt1", down(t1, 1, 1, "LOS", "LOS", 3, 4, 5, 6), map[Port]int{{1, 1}: 4}, t1, inService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 1 {
+		t.Fatalf("opened = %d incidents, want 1", len(opened))
+	}
+	inc := opened[0]
+	if inc.Type != TypeFiberCut || inc.Scope != ScopePON || inc.Affected != 6 || inc.PONs[0].Online != 10 || !inc.StartedAt.Equal(t0) {
+		t.Fatalf("incident = %+v", inc)
+	}
+
+	// PON lain di board yang sama ikut turun dalam window: digabung
+	t2 := t1.Add(time.Minute)
+	opened, _, err = d.Observe("olt1", down(t2, 1, 2, "Dying Gasp", "", 1, 2, 3, 4), map[Port]int{{1, 1}: 4, {1, 2}: 0}, t2, inService)
+	if err != nil || len(opened) != 0 {
+		t.Fatalf("opened = %v, err = %v; want merge into existing incident", opened, err)
+	}
+	list := d.store.List(Filter{OLTID: "olt1"})
+	if len(list) != 1 || list[0].Scope != ScopeBoard || list[0].Board != 1 || list[0].Affected != 10 || list[0].Type != TypeFiberCut {
+		t.Fatalf("incidents = %+v", list)
+	}
+
+	// Selesai setelah 80% ONU terdampak online kembali
+	t3 := t2.Add(10 * time.Minute)
+	_, resolved, err := d.Observe("olt1", append(up(t3, 1, 1, 1, 2, 3, 4, 5), up(t3, 1, 2, 1, 2, 3)...), nil, t3, inService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].Down != 2 || resolved[0].ResolvedAt == nil {
+		t.Fatalf("resolved = %+v", resolved)
+	}
+	if open := d.store.Open("olt1"); len(open) != 0 {
+		t.Fatalf("open incidents = %d, want 0", len(open))
+	}
+}
+
+func TestDetectorClassify(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		events      []history.Event
+		boardStatus BoardStatus
+		want        string
+		scope       string
+	}{
+		{
+			name:        "power outage across boards",
+			events:      append(down(t0, 1, 1, "Dying Gasp", "", 1, 2, 3, 4), down(t0, 2, 1, "Offline", "PowerOff", 1, 2, 3, 4)...),
+			boardStatus: inService,
+			want:        TypePowerOutage,
+			scope:       ScopeOLT,
+		},
+		{
+			name:        "card fault",
+			events:      down(t0, 3, 5, "LOS", "LOS", 1, 2, 3, 4),
+			boardStatus: func(int) string { return "Fault" },
+			want:        TypeCardFault,
+			scope:       ScopePON,
+		},
+		{
+			name:        "mixed causes",
+			events:      append(down(t0, 1, 1, "LOS", "LOS", 1, 2), down(t0, 1, 1, "Dying Gasp", "", 3, 4)...),
+			boardStatus: inService,
+			want:        TypeUnknown,
+			scope:       ScopePON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDetector(t)
+			opened, _, err := d.Observe("olt1", tt.events, nil, t0, tt.boardStatus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(opened) != 1 || opened[0].Type != tt.want || opened[0].Scope != tt.scope {
+				t.Fatalf("opened = %+v, want type %s scope %s", opened, tt.want, tt.scope)
+			}
+		})
+	}
+}
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&Incident{OLTID: "olt1", Status: StatusOpen}, &Incident{OLTID: "olt2", Status: StatusResolved}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.List(Filter{}); len(got) != 2 || got[0].ID != 2 {
+		t.Fatalf("list = %+v, want 2 incidents newest first", got)
+	}
+	if got := reopened.List(Filter{OLTs: []string{"olt1"}}); len(got) != 1 || got[0].OLTID != "olt1" {
+		t.Fatalf("scoped list = %+v", got)
+	}
+	if err := reopened.Save(&Incident{OLTID: "olt1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get(3); err != nil {
+		t.Fatalf("new incident after reopen: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package incident
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNotFound dikembalikan saat insiden tidak ditemukan
+var ErrNotFound = errors.New("incident not found")
+
+// Batas jumlah insiden per query dan yang disimpan
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+	MaxStored    = 5000 // Insiden selesai tertua dibuang melewati batas ini
+)
+
+// Filter kriteria pencarian insiden. Field kosong tidak dipakai.
+type Filter struct {
+	OLTID  string
+	Status string
+	Type   string
+	Since  time.Time // Dimulai pada atau setelah waktu ini
+	OLTs   []string  // Hanya insiden untuk OLT ini (scope user), kosong = semua
+	Limit  int
+}
+
+func (f *Filter) match(inc *Incident) bool {
+	switch {
+	case f.OLTID != "" && inc.OLTID != f.OLTID:
+		return false
+	case f.Status != "" && inc.Status != f.Status:
+		return false
+	case f.Type != "" && inc.Type != f.Type:
+		return false
+	case !f.Since.IsZero() && inc.StartedAt.Before(f.Since):
+		return false
+	case len(f.OLTs) > 0 && !slices.Contains(f.OLTs, inc.OLTID):
+		return false
+	}
+	return true
+}
+
+// Store penyimpanan insiden dalam satu file JSON yang ditulis ulang setiap
+// ada perubahan. Jumlah insiden kecil dibanding event status ONU.
+type Store struct {
+	path      string
+	mu        sync.RWMutex
+	incidents []*Incident // Urut ID
+	lastID    int64
+}
+
+// NewStore membuka (atau membuat) file insiden di path
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create incident directory: %w", err)
+	}
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read incidents: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.incidents); err != nil {
+			return nil, fmt.Errorf("failed to parse incidents: %w", err)
+		}
+	}
+	for _, inc := range s.incidents {
+		s.lastID = max(s.lastID, inc.ID)
+	}
+	return s, nil
+}
+
+// Save menyimpan insiden baru (ID 0) atau menggantikan insiden dengan ID
+// yang sama. Insiden yang disimpan tidak boleh diubah lagi oleh pemanggil.
+func (s *Store) Save(incidents ...*Incident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inc := range incidents {
+		if inc.ID == 0 {
+			s.lastID++
+			inc.ID = s.lastID
+			s.incidents = append(s.incidents, inc)
+			continue
+		}
+		for i := range s.incidents {
+			if s.incidents[i].ID == inc.ID {
+				s.incidents[i] = inc
+			}
+		}
+	}
+	s.trim()
+	return s.write()
+}
+
+// Open salinan insiden yang belum selesai milik oltID, untuk diubah lalu
+// disimpan kembali lewat Save
+func (s *Store) Open(oltID string) []*Incident {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var open []*Incident
+	for _, inc := range s.incidents {
+		if inc.OLTID == oltID && inc.Status == StatusOpen {
+			c := inc.clone()
+			open = append(open, &c)
+		}
+	}
+	return open
+}
+
+// List mengembalikan salinan insiden yang cocok dengan filter, terbaru
+// lebih dulu
+func (s *Store) List(f Filter) []Incident {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []Incident{}
+	for i := len(s.incidents) - 1; i >= 0 && len(out) < limit; i-- {
+		if f.match(s.incidents[i]) {
+			out = append(out, s.incidents[i].clone())
+		}
+	}
+	return out
+}
+
+// Get mengembalikan salinan satu insiden
+func (s *Store) Get(id int64) (Incident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, inc := range s.incidents {
+		if inc.ID == id {
+			return inc.clone(), nil
+		}
+	}
+	return Incident{}, ErrNotFound
+}
+
+// trim membuang insiden selesai tertua jika melewati MaxStored
+func (s *Store) trim() {
+	excess := len(s.incidents) - MaxStored
+	if excess <= 0 {
+		return
+	}
+	kept := s.incidents[:0]
+	for _, inc := range s.incidents {
+		if excess > 0 && inc.Status == StatusResolved {
+			excess--
+			continue
+		}
+		kept = append(kept, inc)
+	}
+	s.incidents = kept
+}
+
+// write menulis semua insiden ke file sementara lalu rename agar file
+// tidak pernah terpotong
+func (s *Store) write() error {
+	data, err := json.MarshalIndent(s.incidents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal incidents: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write incidents: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write incidents: %w", err)
+	}
+	return nil
+}
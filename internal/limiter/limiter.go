@@ -0,0 +1,308 @@
+// Package limiter membatasi beban per perangkat OLT: jumlah sesi SNMP
+// bersamaan, sesi SNMP baru per detik dan jumlah sesi Telnet. Request yang
+// melebihi batas menunggu di antrean sampai QueueTimeout, lalu ditolak
+// dengan *Error (429/503 + Retry-After).
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+)
+
+// Error penolakan karena batas perangkat terlampaui
+type Error struct {
+	Status     int           // http.StatusTooManyRequests atau http.StatusServiceUnavailable
+	RetryAfter time.Duration // Perkiraan kapan request boleh dicoba lagi
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Limits batas beban satu perangkat. Nilai 0 berarti tanpa batas.
+// Rate SNMP dihitung per sesi (satu AcquireSNMP), bukan per PDU: jumlah PDU
+// dalam satu sesi dibatasi oleh MaxConcurrentSNMP dan timeout driver.
+type Limits struct {
+	MaxConcurrentSNMP     int
+	SNMPSessionsPerSecond float64
+	SNMPBurst             int
+	MaxTelnetSessions     int
+	QueueTimeout          time.Duration
+	MaxQueue              int
+}
+
+// FromConfig mengubah config.DeviceLimits menjadi Limits
+func FromConfig(c config.DeviceLimits) Limits {
+	return Limits{
+		MaxConcurrentSNMP:     c.MaxConcurrentSNMP,
+		SNMPSessionsPerSecond: c.SNMPSessionsPerSecond,
+		SNMPBurst:             c.SNMPBurst,
+		MaxTelnetSessions:     c.MaxTelnetSessions,
+		QueueTimeout:          time.Duration(c.QueueTimeoutSeconds) * time.Second,
+		MaxQueue:              c.MaxQueue,
+	}
+}
+
+// DeviceStats kondisi limiter satu perangkat
+type DeviceStats struct {
+	Host          string  `json:"host"`
+	ActiveSNMP    int     `json:"active_snmp"`
+	QueuedSNMP    int     `json:"queued_snmp"`
+	MaxSNMP       int     `json:"max_concurrent_snmp"`
+	ActiveTelnet  int     `json:"active_telnet"`
+	QueuedTelnet  int     `json:"queued_telnet"`
+	MaxTelnet     int     `json:"max_telnet_sessions"`
+	TokensSNMP    float64 `json:"snmp_tokens"`
+	RejectedTotal int64   `json:"rejected_total"`
+}
+
+// Limiter menyimpan state batas untuk setiap host
+type Limiter struct {
+	limits  func(host string) Limits
+	mu      sync.Mutex
+	devices map[string]*device
+	now     func() time.Time
+}
+
+// New membuat limiter. limits dipanggil setiap acquire sehingga perubahan
+// konfigurasi OLT langsung berlaku.
+func New(limits func(host string) Limits) *Limiter {
+	return &Limiter{
+		limits:  limits,
+		devices: make(map[string]*device),
+		now:     time.Now,
+	}
+}
+
+// NewFromConfig membuat limiter yang membaca batas dari cfg (global + override per OLT)
+func NewFromConfig(cfg *config.Config) *Limiter {
+	return New(func(host string) Limits {
+		return FromConfig(cfg.DeviceLimitsFor(host))
+	})
+}
+
+type device struct {
+	mu       sync.Mutex
+	snmp     slots
+	telnet   slots
+	tokens   float64
+	last     time.Time
+	rejected int64
+}
+
+// AcquireSNMP menunggu giliran untuk satu sesi SNMP ke host dan mengambil
+// satu token rate sesi. release wajib dipanggil setelah sesi selesai (aman
+// dipanggil lebih dari sekali).
+func (l *Limiter) AcquireSNMP(ctx context.Context, host string) (release func(), err error) {
+	lim := l.limits(host)
+	d := l.device(host)
+
+	if err := l.reserveToken(ctx, d, lim); err != nil {
+		return nil, err
+	}
+	if err := d.acquire(ctx, &d.snmp, lim.MaxConcurrentSNMP, lim, "SNMP"); err != nil {
+		return nil, err
+	}
+	return d.releaser(&d.snmp, lim.MaxConcurrentSNMP), nil
+}
+
+// AcquireTelnet menunggu giliran untuk satu sesi Telnet ke host
+func (l *Limiter) AcquireTelnet(ctx context.Context, host string) (release func(), err error) {
+	lim := l.limits(host)
+	d := l.device(host)
+
+	if err := d.acquire(ctx, &d.telnet, lim.MaxTelnetSessions, lim, "Telnet"); err != nil {
+		return nil, err
+	}
+	return d.releaser(&d.telnet, lim.MaxTelnetSessions), nil
+}
+
+// Stats mengembalikan kondisi limiter semua host yang pernah diakses
+func (l *Limiter) Stats() []DeviceStats {
+	l.mu.Lock()
+	hosts := make([]string, 0, len(l.devices))
+	for host := range l.devices {
+		hosts = append(hosts, host)
+	}
+	l.mu.Unlock()
+	sort.Strings(hosts)
+
+	stats := make([]DeviceStats, 0, len(hosts))
+	for _, host := range hosts {
+		lim := l.limits(host)
+		d := l.device(host)
+		d.mu.Lock()
+		stats = append(stats, DeviceStats{
+			Host:          host,
+			ActiveSNMP:    d.snmp.active,
+			QueuedSNMP:    len(d.snmp.waiters),
+			MaxSNMP:       lim.MaxConcurrentSNMP,
+			ActiveTelnet:  d.telnet.active,
+			QueuedTelnet:  len(d.telnet.waiters),
+			MaxTelnet:     lim.MaxTelnetSessions,
+			TokensSNMP:    d.tokens,
+			RejectedTotal: d.rejected,
+		})
+		d.mu.Unlock()
+	}
+	return stats
+}
+
+func (l *Limiter) device(host string) *device {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d, ok := l.devices[host]
+	if !ok {
+		d = &device{}
+		l.devices[host] = d
+	}
+	return d
+}
+
+// reserveToken mengambil satu token dari token bucket sesi SNMP host. Jika token
+// baru tersedia dalam QueueTimeout, token dipesan lalu ditunggu; jika lebih
+// lama, request ditolak 429.
+func (l *Limiter) reserveToken(ctx context.Context, d *device, lim Limits) error {
+	if lim.SNMPSessionsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(max(lim.SNMPBurst, 1))
+
+	d.mu.Lock()
+	now := l.now()
+	if d.last.IsZero() {
+		d.tokens = burst
+	} else {
+		d.tokens = min(burst, d.tokens+now.Sub(d.last).Seconds()*lim.SNMPSessionsPerSecond)
+	}
+	d.last = now
+
+	var wait time.Duration
+	if d.tokens < 1 {
+		wait = time.Duration((1 - d.tokens) / lim.SNMPSessionsPerSecond * float64(time.Second))
+		if lim.QueueTimeout > 0 && wait > lim.QueueTimeout {
+			d.rejected++
+			d.mu.Unlock()
+			return &Error{
+				Status:     http.StatusTooManyRequests,
+				RetryAfter: wait,
+				Message:    fmt.Sprintf("OLT SNMP session rate limit exceeded (%g sessions/s)", lim.SNMPSessionsPerSecond),
+			}
+		}
+	}
+	d.tokens--
+	d.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Kembalikan token yang sudah dipesan
+		d.mu.Lock()
+		d.tokens++
+		d.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// slots semaphore FIFO yang batasnya boleh berubah antar acquire
+type slots struct {
+	active  int
+	waiters []chan struct{}
+}
+
+func (d *device) acquire(ctx context.Context, s *slots, limit int, lim Limits, kind string) error {
+	d.mu.Lock()
+	if limit <= 0 || (s.active < limit && len(s.waiters) == 0) {
+		s.active++
+		d.mu.Unlock()
+		return nil
+	}
+	if lim.MaxQueue > 0 && len(s.waiters) >= lim.MaxQueue {
+		d.rejected++
+		d.mu.Unlock()
+		return &Error{
+			Status:     http.StatusTooManyRequests,
+			RetryAfter: time.Second,
+			Message:    fmt.Sprintf("Too many queued %s requests for this OLT (max %d)", kind, lim.MaxQueue),
+		}
+	}
+	ch := make(chan struct{})
+	s.waiters = append(s.waiters, ch)
+	d.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if lim.QueueTimeout > 0 {
+		t := time.NewTimer(lim.QueueTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-timeout:
+		if d.abandon(s, ch) {
+			return nil
+		}
+		d.mu.Lock()
+		d.rejected++
+		d.mu.Unlock()
+		return &Error{
+			Status:     http.StatusServiceUnavailable,
+			RetryAfter: lim.QueueTimeout,
+			Message:    fmt.Sprintf("OLT busy: all %d %s sessions in use, waited %s", limit, kind, lim.QueueTimeout),
+		}
+	case <-ctx.Done():
+		if d.abandon(s, ch) {
+			d.release(s, limit)
+		}
+		return ctx.Err()
+	}
+}
+
+// abandon mengeluarkan ch dari antrean. Mengembalikan true jika slot
+// ternyata sudah diberikan ke ch sebelum sempat dikeluarkan.
+func (d *device) abandon(s *slots, ch chan struct{}) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, w := range s.waiters {
+		if w == ch {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return false
+		}
+	}
+	return true
+}
+
+func (d *device) release(s *slots, limit int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// Slot diteruskan ke antrean terdepan, kecuali batas baru lebih kecil
+	if len(s.waiters) > 0 && (limit <= 0 || s.active <= limit) {
+		ch := s.waiters[0]
+		s.waiters = s.waiters[1:]
+		close(ch)
+		return
+	}
+	s.active--
+}
+
+func (d *device) releaser(s *slots, limit int) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { d.release(s, limit) })
+	}
+}
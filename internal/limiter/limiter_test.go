@@ -0,0 +1,222 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+)
+
+const host = "10.0.0.1"
+
+func newTestLimiter(lim Limits) (*Limiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(func(string) Limits { return lim })
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func stats(l *Limiter) DeviceStats {
+	for _, s := range l.Stats() {
+		if s.Host == host {
+			return s
+		}
+	}
+	return DeviceStats{}
+}
+
+// waitQueued menunggu sampai n request SNMP mengantre
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for stats(l).QueuedSNMP != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", stats(l).QueuedSNMP, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFIFOHandOff(t *testing.T) {
+	l, _ := newTestLimiter(Limits{MaxConcurrentSNMP: 1, QueueTimeout: 5 * time.Second})
+	ctx := context.Background()
+
+	first, err := l.AcquireSNMP(ctx, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan int, 3)
+	releases := make(chan func(), 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			release, err := l.AcquireSNMP(ctx, host)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			releases <- release
+		}()
+		waitQueued(t, l, i)
+	}
+
+	first()
+	first() // Release kedua tidak boleh membebaskan slot lagi
+	for want := 1; want <= 3; want++ {
+		if got := <-order; got != want {
+			t.Fatalf("acquired by %d, want %d", got, want)
+		}
+		if s := stats(l); s.ActiveSNMP != 1 {
+			t.Fatalf("active = %d during hand-off, want 1", s.ActiveSNMP)
+		}
+		(<-releases)()
+	}
+	if s := stats(l); s.ActiveSNMP != 0 || s.QueuedSNMP != 0 {
+		t.Fatalf("stats = %+v, want idle", s)
+	}
+}
+
+func TestQueuedCancellation(t *testing.T) {
+	l, _ := newTestLimiter(Limits{MaxConcurrentSNMP: 1, QueueTimeout: 5 * time.Second})
+
+	held, err := l.AcquireSNMP(context.Background(), host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := l.AcquireSNMP(ctx, host)
+		cancelled <- err
+	}()
+	waitQueued(t, l, 1)
+
+	next := make(chan func(), 1)
+	go func() {
+		release, err := l.AcquireSNMP(context.Background(), host)
+		if err != nil {
+			t.Error(err)
+		}
+		next <- release
+	}()
+	waitQueued(t, l, 2)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled waiter err = %v", err)
+	}
+	waitQueued(t, l, 1)
+
+	// Slot diteruskan ke antrean berikutnya, bukan ke waiter yang batal
+	held()
+	(<-next)()
+	if s := stats(l); s.ActiveSNMP != 0 || s.QueuedSNMP != 0 {
+		t.Fatalf("stats = %+v, want no leaked slot", s)
+	}
+}
+
+func TestQueueLimits(t *testing.T) {
+	l, _ := newTestLimiter(Limits{MaxConcurrentSNMP: 1, QueueTimeout: 20 * time.Millisecond, MaxQueue: 1})
+	held, err := l.AcquireSNMP(context.Background(), host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held()
+
+	timedOut := make(chan error, 1)
+	go func() {
+		_, err := l.AcquireSNMP(context.Background(), host)
+		timedOut <- err
+	}()
+	waitQueued(t, l, 1)
+
+	var le *Error
+	if _, err := l.AcquireSNMP(context.Background(), host); !errors.As(err, &le) || le.Status != http.StatusTooManyRequests {
+		t.Fatalf("queue full err = %v, want 429", err)
+	}
+	if err := <-timedOut; !errors.As(err, &le) || le.Status != http.StatusServiceUnavailable {
+		t.Fatalf("queue timeout err = %v, want 503", err)
+	}
+	if s := stats(l); s.RejectedTotal != 2 || s.QueuedSNMP != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestSessionRateRefill(t *testing.T) {
+	l, now := newTestLimiter(Limits{SNMPSessionsPerSecond: 2, SNMPBurst: 2, QueueTimeout: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	// Burst penuh saat pertama kali
+	for i := range 2 {
+		release, err := l.AcquireSNMP(ctx, host)
+		if err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+		release()
+	}
+
+	// Token berikutnya baru ada 500ms lagi, lebih lama dari QueueTimeout
+	var le *Error
+	if _, err := l.AcquireSNMP(ctx, host); !errors.As(err, &le) || le.Status != http.StatusTooManyRequests {
+		t.Fatalf("empty bucket err = %v, want 429", err)
+	}
+	if le.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("retry after = %v, want 500ms", le.RetryAfter)
+	}
+
+	// Refill 2 token/detik, dibatasi burst
+	*now = now.Add(10 * time.Second)
+	for i := range 2 {
+		release, err := l.AcquireSNMP(ctx, host)
+		if err != nil {
+			t.Fatalf("acquire after refill %d: %v", i, err)
+		}
+		release()
+	}
+	if s := stats(l); s.TokensSNMP != 0 {
+		t.Fatalf("tokens = %v, want 0 (refill capped at burst)", s.TokensSNMP)
+	}
+}
+
+func TestSessionRateCancelReturnsToken(t *testing.T) {
+	l, _ := newTestLimiter(Limits{SNMPSessionsPerSecond: 1, SNMPBurst: 1, QueueTimeout: 5 * time.Second})
+
+	release, err := l.AcquireSNMP(context.Background(), host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// Token berikutnya dipesan (menunggu 1 detik) lalu request dibatalkan
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.AcquireSNMP(ctx, host); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if s := stats(l); s.TokensSNMP != 0 {
+		t.Fatalf("tokens = %v, want reservation returned", s.TokensSNMP)
+	}
+}
+
+func TestFromConfigLegacyRate(t *testing.T) {
+	// Nama lama snmp_requests_per_second tetap dibaca lewat merge config
+	cfg := &config.Config{
+		Limits: config.DeviceLimits{SNMPSessionsPerSecond: 10},
+		OLTs: []config.OLTConfig{{
+			ID:        "olt-a",
+			IPAddress: host,
+			Limits:    &config.DeviceLimits{SNMPRequestsPerSecond: 3},
+		}},
+	}
+	if got := FromConfig(cfg.DeviceLimitsFor(host)).SNMPSessionsPerSecond; got != 3 {
+		t.Fatalf("sessions/s = %v, want 3", got)
+	}
+	if got := FromConfig(cfg.DeviceLimitsFor("10.0.0.2")).SNMPSessionsPerSecond; got != 10 {
+		t.Fatalf("global sessions/s = %v, want 10", got)
+	}
+}
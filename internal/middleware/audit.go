@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/audit"
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// maxAuditError batas body response error yang disimpan untuk pesan error
+const maxAuditError = 4096
+
+// maxAuditBody batas body request yang dibaca untuk params audit
+const maxAuditBody = 1 << 20
+
+// Auditor mencatat request tulis beserta perintah yang dikirim ke OLT ke
+// audit log. Pasang setelah Authenticate agar identitas caller tersedia.
+type Auditor struct {
+	store     *audit.Store
+	oltByHost func(host string) (string, bool)
+}
+
+// NewAuditor membuat auditor. oltByHost memetakan host/ip di body request
+// ke ID OLT terdaftar.
+func NewAuditor(store *audit.Store, oltByHost func(host string) (string, bool)) *Auditor {
+	return &Auditor{store: store, oltByHost: oltByHost}
+}
+
+// Middleware mencatat setiap request yang melewatinya
+func (a *Auditor) Middleware(next http.Handler) http.Handler {
+	return a.When(nil)(next)
+}
+
+// When hanya mencatat request yang body JSON-nya memenuhi match (mis.
+// endpoint /query yang hanya sebagian query-nya menulis ke OLT).
+func (a *Auditor) When(match func(params map[string]interface{}) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Hanya maxAuditBody pertama yang dibaca untuk params; body
+			// dikembalikan utuh agar handler tetap menerima seluruh request
+			var params map[string]interface{}
+			if r.Body != nil {
+				body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody))
+				if err == nil {
+					r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+					json.Unmarshal(body, &params)
+				}
+			}
+			if match != nil && !match(params) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &audit.Recorder{}
+			ww := &auditWriter{WrapResponseWriter: chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)}
+			next.ServeHTTP(ww, r.WithContext(audit.WithRecorder(r.Context(), rec)))
+
+			entry := &audit.Entry{
+				Time:       start.UTC(),
+				SourceIP:   sourceIP(r.RemoteAddr),
+				RequestID:  chiMiddleware.GetReqID(r.Context()),
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Target:     a.target(r, params),
+				Params:     audit.Sanitize(params),
+				Commands:   rec.Commands(),
+				Status:     ww.Status(),
+				Result:     audit.ResultSuccess,
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+			if entry.Status == 0 {
+				entry.Status = http.StatusOK
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				entry.Route = rctx.RoutePattern()
+			}
+			if user := UserFromContext(r.Context()); user != nil {
+				entry.User = user.Username
+				entry.Role = user.Role
+				entry.AuthMethod = user.AuthMethod
+				entry.TokenID = user.TokenID
+			}
+			if entry.Status >= http.StatusBadRequest {
+				entry.Result = audit.ResultFailure
+				entry.Error = errorMessage(ww.errBody.Bytes())
+			}
+
+			if err := a.store.Append(entry); err != nil {
+				log.Error().Err(err).Str("path", entry.Path).Str("user", entry.User).Msg("Failed to write audit entry")
+			}
+		})
+	}
+}
+
+// target menentukan OLT/board/PON/ONU dari URL param atau body request
+func (a *Auditor) target(r *http.Request, params map[string]interface{}) audit.Target {
+	t := audit.Target{
+		OLTID: chi.URLParam(r, "olt_id"),
+		Board: urlInt(r, "board_id"),
+		PON:   urlInt(r, "pon_id"),
+		ONU:   urlInt(r, "onu_id"),
+	}
+
+	t.Host = paramString(params, "host")
+	if t.Host == "" {
+		t.Host = paramString(params, "ip")
+	}
+	if t.OLTID == "" && t.Host != "" && a.oltByHost != nil {
+		t.OLTID, _ = a.oltByHost(t.Host)
+	}
+	if t.Board == 0 {
+		t.Board = paramInt(params, "board")
+	}
+	if t.PON == 0 {
+		t.PON = paramInt(params, "pon")
+	}
+	if t.ONU == 0 {
+		t.ONU = paramInt(params, "onu_id")
+	}
+
+	// Body CLI memakai gpon-olt_{rack}/{shelf}/{slot}: shelf adalah board
+	// (default 1) dan slot adalah PON. Field "port" di body CLI adalah port
+	// Telnet, bukan PON.
+	if t.PON == 0 {
+		if t.PON = paramInt(params, "slot"); t.PON != 0 && t.Board == 0 {
+			t.Board = max(paramInt(params, "shelf"), 1)
+		}
+	}
+	return t
+}
+
+// readCloser body request yang dibaca ulang, Close tetap ke body asli
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// auditWriter menyimpan body response error untuk diambil pesannya
+type auditWriter struct {
+	chiMiddleware.WrapResponseWriter
+	errBody bytes.Buffer
+}
+
+func (w *auditWriter) Write(p []byte) (int, error) {
+	if w.Status() >= http.StatusBadRequest && w.errBody.Len() < maxAuditError {
+		w.errBody.Write(p[:min(len(p), maxAuditError-w.errBody.Len())])
+	}
+	return w.WrapResponseWriter.Write(p)
+}
+
+// errorMessage mengambil field message dari response.ErrorResponse
+func errorMessage(body []byte) string {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Message != "" {
+		return resp.Message
+	}
+	return string(bytes.TrimSpace(body))
+}
+
+// sourceIP alamat client tanpa port. RemoteAddr sudah diganti oleh
+// chiMiddleware.RealIP jika request lewat proxy.
+func sourceIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+func urlInt(r *http.Request, key string) int {
+	n, _ := strconv.Atoi(chi.URLParam(r, key))
+	return n
+}
+
+func paramString(params map[string]interface{}, key string) string {
+	s, _ := params[key].(string)
+	return s
+}
+
+func paramInt(params map[string]interface{}, key string) int {
+	switch v := params[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/audit"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// auditRouter router dengan auditor, user operator di context dan dua route:
+// REST (target dari URL) dan CLI (target dari body)
+func auditRouter(t *testing.T, handler http.HandlerFunc) (http.Handler, *audit.Store) {
+	t.Helper()
+	store, err := audit.NewStore(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	oltByHost := func(host string) (string, bool) {
+		if host == "10.0.0.1" {
+			return "olt-a", true
+		}
+		return "", false
+	}
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			user := &model.User{Username: "operator", Role: model.RoleOperator, AuthMethod: model.AuthAPIKey, TokenID: "k1"}
+			next.ServeHTTP(w, req.WithContext(WithUser(req.Context(), user)))
+		})
+	})
+	r.Use(NewAuditor(store, oltByHost).Middleware)
+	r.Post("/olts/{olt_id}/board/{board_id}/pon/{pon_id}/onu/{onu_id}/reboot", handler)
+	r.Post("/cli/onu/auth", handler)
+	return r, store
+}
+
+func lastEntry(t *testing.T, store *audit.Store) audit.Entry {
+	t.Helper()
+	entries, err := store.Query(audit.Filter{Limit: 1})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Query() = %v, %v", entries, err)
+	}
+	return entries[0]
+}
+
+func TestAuditorRecordsFailure(t *testing.T) {
+	h, store := auditRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		audit.RecordCommand(r.Context(), audit.ProtocolCLI, "10.0.0.1", "wan-ip 1 mode pppoe username u1 password s3cr3tPw", nil)
+		response.Error(w, http.StatusGatewayTimeout, "Connection failed")
+	})
+
+	body := `{"host":"10.0.0.1","username":"zte","password":"s3cr3tPw","slot":3,"onu_id":5,"onu_type":"ZTE-F660"}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/cli/onu/auth", strings.NewReader(body)))
+
+	e := lastEntry(t, store)
+	if e.Status != http.StatusGatewayTimeout || e.Result != audit.ResultFailure || e.Error != "Connection failed" {
+		t.Errorf("status/result/error = %d/%s/%q", e.Status, e.Result, e.Error)
+	}
+	if want := (audit.Target{OLTID: "olt-a", Host: "10.0.0.1", Board: 1, PON: 3, ONU: 5}); e.Target != want {
+		t.Errorf("target = %+v, want %+v", e.Target, want)
+	}
+	if e.User != "operator" || e.AuthMethod != model.AuthAPIKey || e.TokenID != "k1" || e.Route != "/cli/onu/auth" {
+		t.Errorf("caller = %+v", e)
+	}
+	if e.Params["password"] != audit.Redacted || e.Params["username"] != "zte" {
+		t.Errorf("params = %v", e.Params)
+	}
+	if len(e.Commands) != 1 || e.Commands[0].Command != "wan-ip 1 mode pppoe username u1 password ***" {
+		t.Errorf("commands = %+v", e.Commands)
+	}
+
+	// Tidak ada secret di file log
+	var buf bytes.Buffer
+	if err := store.Export(&buf, audit.Filter{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "s3cr3tPw") {
+		t.Fatalf("secret stored: %s", buf.String())
+	}
+}
+
+func TestAuditorTarget(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { response.JSON(w, http.StatusOK, nil) }
+	tests := []struct {
+		name string
+		path string
+		body string
+		want audit.Target
+	}{
+		{"dari URL", "/olts/olt-b/board/2/pon/7/onu/9/reboot", `{}`, audit.Target{OLTID: "olt-b", Board: 2, PON: 7, ONU: 9}},
+		{"body CLI dengan shelf", "/cli/onu/auth", `{"host":"10.0.0.1","port":2323,"shelf":2,"slot":4,"onu_id":1}`, audit.Target{OLTID: "olt-a", Host: "10.0.0.1", Board: 2, PON: 4, ONU: 1}},
+		{"body CLI tanpa slot", "/cli/onu/auth", `{"host":"10.0.0.9","port":23}`, audit.Target{Host: "10.0.0.9"}},
+		{"body board/pon", "/cli/onu/auth", `{"ip":"10.0.0.1","board":1,"pon":"2","onu_id":3}`, audit.Target{OLTID: "olt-a", Host: "10.0.0.1", Board: 1, PON: 2, ONU: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, store := auditRouter(t, ok)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+			e := lastEntry(t, store)
+			if e.Target != tt.want {
+				t.Fatalf("target = %+v, want %+v", e.Target, tt.want)
+			}
+			if e.Status != http.StatusOK || e.Result != audit.ResultSuccess || e.Error != "" {
+				t.Fatalf("status/result/error = %d/%s/%q", e.Status, e.Result, e.Error)
+			}
+		})
+	}
+}
+
+// TestAuditorLargeBody memastikan body di atas batas audit tetap sampai utuh
+// ke handler.
+func TestAuditorLargeBody(t *testing.T) {
+	var got []byte
+	h, store := auditRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	payload, _ := json.Marshal(map[string]string{"host": "10.0.0.1", "config": strings.Repeat("x", 2*maxAuditBody)})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cli/onu/auth", bytes.NewReader(payload)))
+
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("handler received %d of %d bytes", len(got), len(payload))
+	}
+	if e := lastEntry(t, store); e.Status != http.StatusNoContent {
+		t.Fatalf("status = %d", e.Status)
+	}
+}
+
+func TestAuditorWhen(t *testing.T) {
+	store, err := audit.NewStore(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var body string
+	h := NewAuditor(store, nil).When(func(params map[string]interface{}) bool {
+		return params["query"] == "onu_rename"
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+
+	for _, q := range []string{`{"query":"onu_list"}`, `{"query":"onu_rename"}`} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(q)))
+		if body != q {
+			t.Fatalf("handler body = %q, want %q", body, q)
+		}
+	}
+	entries, _ := store.Query(audit.Filter{})
+	if len(entries) != 1 || entries[0].Params["query"] != "onu_rename" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
@@ -1,32 +1,154 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
-	"os"
+	"strings"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/pkg/response"
+	"github.com/go-chi/chi/v5"
 )
 
-// BasicAuth middleware untuk autentikasi sederhana
-// Username dan password diambil dari environment variable
-func BasicAuth() func(http.Handler) http.Handler {
-	username := os.Getenv("AUTH_USER")
-	password := os.Getenv("AUTH_PASS")
+type contextKey int
+
+const userKey contextKey = iota
+
+var errUnsupportedCredentials = errors.New("unsupported credentials")
+
+// Authenticator memverifikasi kredensial Basic Auth
+type Authenticator interface {
+	Authenticate(username, password string) (*model.User, error)
+}
+
+// WithUser menyimpan user yang sudah terautentikasi ke context
+func WithUser(ctx context.Context, user *model.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext mengembalikan user yang sedang login (nil jika tidak ada)
+func UserFromContext(ctx context.Context) *model.User {
+	user, _ := ctx.Value(userKey).(*model.User)
+	return user
+}
+
+// TokenAuthenticator memverifikasi bearer token (API key atau JWT)
+type TokenAuthenticator interface {
+	AuthenticateToken(token string) (*model.User, error)
+}
+
+// BasicAuth middleware autentikasi Basic Auth terhadap user store.
+// User yang lolos disimpan ke context untuk dipakai Require.
+func BasicAuth(auth Authenticator) func(http.Handler) http.Handler {
+	return Authenticate(auth, nil, nil)
+}
+
+// Authenticate middleware autentikasi yang menerima Basic Auth, API key
+// ("Authorization: Bearer szk_..." atau header X-API-Key) dan JWT bearer
+// token. apiKeys dan jwt boleh nil jika tidak diaktifkan. Identitas caller
+// disimpan ke context (lihat UserFromContext).
+func Authenticate(basic Authenticator, apiKeys, jwt TokenAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var user *model.User
+			var err error
+
+			token := r.Header.Get("X-API-Key")
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				token = strings.TrimSpace(bearer)
+			}
 
-	// Default credentials kalau env tidak diset (untuk development)
-	if username == "" {
-		username = "admin"
+			switch {
+			case token != "" && strings.HasPrefix(token, model.APIKeyPrefix) && apiKeys != nil:
+				user, err = apiKeys.AuthenticateToken(token)
+			case token != "" && !strings.HasPrefix(token, model.APIKeyPrefix) && jwt != nil:
+				user, err = jwt.AuthenticateToken(token)
+			case token != "":
+				err = errUnsupportedCredentials
+			default:
+				u, p, ok := r.BasicAuth()
+				if !ok {
+					unauthorized(w)
+					return
+				}
+				user, err = basic.Authenticate(u, p)
+			}
+
+			if err != nil {
+				unauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
 	}
-	if password == "" {
-		password = "testing123"
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="SNMP-ZTE API"`)
+	w.Header().Add("WWW-Authenticate", `Bearer realm="SNMP-ZTE API"`)
+	response.Error(w, http.StatusUnauthorized, "Unauthorized - kredensial tidak valid")
+}
+
+// Require memastikan user memiliki permission perm. Jika route memiliki
+// parameter {olt_id}, user juga harus memiliki akses ke OLT tersebut.
+func Require(perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := UserFromContext(r.Context())
+			if user == nil {
+				unauthorized(w)
+				return
+			}
+			if !user.Can(perm) {
+				response.Error(w, http.StatusForbidden, "Forbidden - role "+user.Role+" does not have permission "+perm)
+				return
+			}
+			if oltID := chi.URLParam(r, "olt_id"); oltID != "" && !user.CanAccessOLT(oltID) {
+				response.Error(w, http.StatusForbidden, "Forbidden - no access to OLT "+oltID)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
 	}
+}
 
+// RequireHostScope membatasi endpoint stateless (host/ip di body JSON) untuk
+// user yang akses OLT-nya dibatasi. lookup memetakan alamat OLT ke ID OLT;
+// alamat yang tidak terdaftar ditolak untuk user tersebut.
+func RequireHostScope(lookup func(host string) (string, bool)) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			u, p, ok := r.BasicAuth()
-			if !ok || u != username || p != password {
-				w.Header().Set("WWW-Authenticate", `Basic realm="SNMP-ZTE API"`)
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte(`{"code":401,"status":"ERROR","message":"Unauthorized - Username atau password salah"}`))
+			user := UserFromContext(r.Context())
+			if user == nil || !user.Scoped() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Baca body lalu kembalikan agar handler tetap bisa decode
+			body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			if err != nil {
+				response.BadRequest(w, "Invalid request")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var target struct {
+				Host string `json:"host"`
+				IP   string `json:"ip"`
+			}
+			json.Unmarshal(body, &target)
+			host := target.Host
+			if host == "" {
+				host = target.IP
+			}
+
+			oltID, ok := lookup(host)
+			if !ok || !user.CanAccessOLT(oltID) {
+				response.Error(w, http.StatusForbidden, "Forbidden - no access to OLT "+host)
 				return
 			}
 			next.ServeHTTP(w, r)
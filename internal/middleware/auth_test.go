@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequirePermissions(t *testing.T) {
+	// Matriks role × permission yang diharapkan, ditulis eksplisit agar
+	// perubahan model.RolePermissions yang tidak disengaja ketahuan
+	allowed := map[string]map[string]bool{
+		model.RoleReadOnly: {model.PermRead: true},
+		model.RoleOperator: {model.PermRead: true, model.PermProvision: true},
+		model.RoleAdmin: {
+			model.PermRead: true, model.PermProvision: true, model.PermManageOLTs: true,
+			model.PermRestoreConfig: true, model.PermManageUsers: true, model.PermViewAudit: true,
+		},
+		"unknown": {},
+	}
+	perms := []string{
+		model.PermRead, model.PermProvision, model.PermManageOLTs,
+		model.PermRestoreConfig, model.PermManageUsers, model.PermViewAudit,
+	}
+
+	for role, can := range allowed {
+		for _, perm := range perms {
+			t.Run(role+"/"+perm, func(t *testing.T) {
+				h := Require(perm)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req = req.WithContext(WithUser(req.Context(), &model.User{Username: "u", Role: role}))
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+
+				want := http.StatusForbidden
+				if can[perm] {
+					want = http.StatusOK
+				}
+				if rec.Code != want {
+					t.Fatalf("status = %d, want %d", rec.Code, want)
+				}
+			})
+		}
+	}
+
+	t.Run("tanpa user", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		Require(model.PermRead)(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401", rec.Code)
+		}
+	})
+}
+
+func TestRequireOLTScope(t *testing.T) {
+	tests := []struct {
+		name string
+		user model.User
+		path string
+		want int
+	}{
+		{"tanpa batasan", model.User{Role: model.RoleReadOnly}, "/olts/olt-b", http.StatusOK},
+		{"OLT dalam scope", model.User{Role: model.RoleOperator, OLTs: []string{"olt-a"}}, "/olts/olt-a", http.StatusOK},
+		{"OLT di luar scope", model.User{Role: model.RoleAdmin, OLTs: []string{"olt-a"}}, "/olts/olt-b", http.StatusForbidden},
+		{"route tanpa olt_id", model.User{Role: model.RoleReadOnly, OLTs: []string{"olt-a"}}, "/stats", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					next.ServeHTTP(w, req.WithContext(WithUser(req.Context(), &tt.user)))
+				})
+			})
+			ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+			r.With(Require(model.PermRead)).Get("/olts/{olt_id}", ok)
+			r.With(Require(model.PermRead)).Get("/stats", ok)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+// fakeAuth mencatat kredensial yang diterima dan menerima satu nilai valid
+type fakeAuth struct {
+	method string
+	valid  string
+	calls  []string
+}
+
+func (f *fakeAuth) Authenticate(username, password string) (*model.User, error) {
+	return f.AuthenticateToken(username + ":" + password)
+}
+
+func (f *fakeAuth) AuthenticateToken(token string) (*model.User, error) {
+	f.calls = append(f.calls, token)
+	if token != f.valid {
+		return nil, errUnsupportedCredentials
+	}
+	return &model.User{Username: f.method, Role: model.RoleReadOnly, AuthMethod: f.method}, nil
+}
+
+func TestAuthenticateDispatch(t *testing.T) {
+	const (
+		apiKey = model.APIKeyPrefix + "0a1b2c3d4e5f_secret"
+		jwt    = "eyJhbGciOiJIUzI1NiJ9.e30.sig"
+	)
+	tests := []struct {
+		name     string
+		header   map[string]string
+		basic    bool
+		noTokens bool // apiKeys dan jwt nil (hanya Basic)
+		want     int
+		wantBy   string // authenticator yang harus dipanggil
+	}{
+		{name: "X-API-Key", header: map[string]string{"X-API-Key": apiKey}, want: http.StatusOK, wantBy: model.AuthAPIKey},
+		{name: "Bearer API key", header: map[string]string{"Authorization": "Bearer " + apiKey}, want: http.StatusOK, wantBy: model.AuthAPIKey},
+		{name: "Bearer JWT", header: map[string]string{"Authorization": "Bearer " + jwt}, want: http.StatusOK, wantBy: model.AuthJWT},
+		{name: "Basic", basic: true, want: http.StatusOK, wantBy: model.AuthBasic},
+		{name: "Bearer menang atas X-API-Key", header: map[string]string{"Authorization": "Bearer " + jwt, "X-API-Key": "szk_lain"}, want: http.StatusOK, wantBy: model.AuthJWT},
+		{name: "API key salah", header: map[string]string{"X-API-Key": apiKey + "x"}, want: http.StatusUnauthorized, wantBy: model.AuthAPIKey},
+		{name: "JWT di X-API-Key", header: map[string]string{"X-API-Key": jwt}, want: http.StatusOK, wantBy: model.AuthJWT},
+		{name: "tanpa kredensial", want: http.StatusUnauthorized},
+		{name: "token saat bearer tidak aktif", header: map[string]string{"Authorization": "Bearer " + apiKey}, noTokens: true, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auths := map[string]*fakeAuth{
+				model.AuthBasic:  {method: model.AuthBasic, valid: "admin:secret"},
+				model.AuthAPIKey: {method: model.AuthAPIKey, valid: apiKey},
+				model.AuthJWT:    {method: model.AuthJWT, valid: jwt},
+			}
+			var mw func(http.Handler) http.Handler
+			if tt.noTokens {
+				mw = BasicAuth(auths[model.AuthBasic])
+			} else {
+				mw = Authenticate(auths[model.AuthBasic], auths[model.AuthAPIKey], auths[model.AuthJWT])
+			}
+
+			var got *model.User
+			h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = UserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.basic {
+				req.SetBasicAuth("admin", "secret")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && (got == nil || got.AuthMethod != tt.wantBy) {
+				t.Fatalf("user = %+v, want authenticated by %s", got, tt.wantBy)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Values("WWW-Authenticate") == nil {
+				t.Fatal("401 without WWW-Authenticate")
+			}
+			for method, a := range auths {
+				if called := len(a.calls) > 0; called != (method == tt.wantBy) {
+					t.Errorf("%s authenticator called = %v", method, called)
+				}
+			}
+		})
+	}
+}
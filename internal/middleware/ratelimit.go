@@ -1,97 +1,232 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/ratelimit"
+	"github.com/ardani/snmp-zte/pkg/response"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog/log"
 )
 
-// RateLimiter mengimplementasikan pembatasan rate (rate limiting) per IP
+// RateLimiter membatasi jumlah request per caller dalam sliding window.
+// Counter disimpan di ratelimit.Store sehingga bisa dibagi antar replika.
+// Pasang setelah Authenticate agar identitas caller sudah diketahui.
 type RateLimiter struct {
-	requests map[string]*clientInfo
-	mu       sync.RWMutex
-	rate     int           // jumlah request per jendela waktu
-	window   time.Duration // jendela waktu
+	store  ratelimit.Store
+	limit  int
+	window time.Duration
+	routes []config.RouteLimit
 }
 
-type clientInfo struct {
-	count     int
-	windowEnd time.Time
+// rateCheck satu bucket yang diperiksa untuk sebuah request
+type rateCheck struct {
+	key    string
+	limit  int
+	window time.Duration
+	result ratelimit.Result
 }
 
-// NewRateLimiter membuat rate limiter baru
-// rate: jumlah request yang diizinkan per jendela waktu
-// window: durasi jendela waktu
-func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
-	rl := &RateLimiter{
-		requests: make(map[string]*clientInfo),
-		rate:     rate,
-		window:   window,
+// NewRateLimiter membuat rate limiter dari konfigurasi. store menentukan
+// apakah counter berlaku per instance (memori) atau bersama (Redis).
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		limit:  cfg.Limit,
+		window: time.Duration(cfg.WindowSeconds) * time.Second,
+		routes: cfg.Routes,
 	}
+}
 
-	// Bersihkan entri lama setiap menit
-	go rl.cleanup()
+// Middleware menerapkan batas default caller dan batas per route yang
+// cocok. Header RateLimit-* mengikuti batas yang paling ketat.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, limit := rl.caller(r)
 
-	return rl
-}
+		checks := []rateCheck{{key: caller, limit: limit, window: rl.window}}
+		if route := rl.route(r); route != nil {
+			window := rl.window
+			if route.WindowSeconds > 0 {
+				window = time.Duration(route.WindowSeconds) * time.Second
+			}
+			checks = append(checks, rateCheck{
+				key:    caller + "|" + route.Method + " " + route.Path,
+				limit:  route.Limit,
+				window: window,
+			})
+		}
 
-func (rl *RateLimiter) cleanup() {
-	ticker := time.NewTicker(time.Minute)
-	for range ticker.C {
-		rl.mu.Lock()
-		now := time.Now()
-		for ip, info := range rl.requests {
-			if now.After(info.windowEnd) {
-				delete(rl.requests, ip)
+		var tightest *rateCheck
+		for i := range checks {
+			c := &checks[i]
+			if c.limit <= 0 {
+				continue
+			}
+			res, err := rl.store.Allow(r.Context(), c.key, c.limit, c.window)
+			if err != nil {
+				// Store tidak tersedia: request tetap dilayani (fail open)
+				log.Warn().Err(err).Str("key", c.key).Msg("Rate limit check failed")
+				continue
+			}
+			c.result = res
+			if !res.Allowed {
+				retry := strconv.Itoa(ceilSeconds(res.RetryAfter))
+				writeRateLimitHeaders(w, res, c.window)
+				w.Header().Set("Retry-After", retry)
+				log.Warn().Str("key", c.key).Int("limit", c.limit).Msg("Rate limit exceeded")
+				response.Error(w, http.StatusTooManyRequests, "Rate limit exceeded, retry in "+retry+"s")
+				return
+			}
+			if tightest == nil || res.Remaining < tightest.result.Remaining {
+				tightest = c
 			}
 		}
-		rl.mu.Unlock()
+		if tightest != nil {
+			writeRateLimitHeaders(w, tightest.result, tightest.window)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// caller key dan batas untuk identitas request. API key dihitung per key
+// (batas bisa diatur per key), user lain per username. Request anonim
+// (jika dipasang tanpa Authenticate) dihitung per IP.
+func (rl *RateLimiter) caller(r *http.Request) (string, int) {
+	user := UserFromContext(r.Context())
+	switch {
+	case user == nil:
+		return "ip:" + sourceIP(r.RemoteAddr), rl.limit
+	case user.AuthMethod == model.AuthAPIKey && user.RateLimit > 0:
+		return "key:" + user.TokenID, user.RateLimit
+	case user.AuthMethod == model.AuthAPIKey:
+		return "key:" + user.TokenID, rl.limit
+	default:
+		return "user:" + user.Username, rl.limit
 	}
 }
 
-func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := getRealIP(r)
+// route mengembalikan aturan route dengan prefix path terpanjang yang cocok
+func (rl *RateLimiter) route(r *http.Request) *config.RouteLimit {
+	var best *config.RouteLimit
+	for i := range rl.routes {
+		rt := &rl.routes[i]
+		if rt.Method != "" && !strings.EqualFold(rt.Method, r.Method) {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, rt.Path) {
+			continue
+		}
+		if best == nil || len(rt.Path) > len(best.Path) {
+			best = rt
+		}
+	}
+	return best
+}
 
-		rl.mu.Lock()
-		defer rl.mu.Unlock()
+// writeRateLimitHeaders menulis header RateLimit-* (draft IETF
+// httpapi-ratelimit-headers)
+func writeRateLimitHeaders(w http.ResponseWriter, res ratelimit.Result, window time.Duration) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	h.Set("RateLimit-Policy", strconv.Itoa(res.Limit)+";w="+strconv.Itoa(ceilSeconds(window)))
+}
 
-		now := time.Now()
-		info, exists := rl.requests[ip]
+func ceilSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
 
-		if !exists || now.After(info.windowEnd) {
-			// Jendela waktu baru
-			rl.requests[ip] = &clientInfo{
-				count:     1,
-				windowEnd: now.Add(rl.window),
-			}
-			next.ServeHTTP(w, r)
+// LoginLimiter membatasi login gagal per IP. Dipasang sebelum Authenticate
+// sehingga IP yang sudah melewati batas ditolak tanpa memverifikasi
+// password: tebak password dibatasi dan pbkdf2 yang mahal tidak dijalankan.
+type LoginLimiter struct {
+	store  ratelimit.Store
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	blocked map[string]time.Time // IP -> diblokir sampai
+}
+
+// NewLoginLimiter membuat pembatas login gagal: limit kegagalan per IP per
+// window. Counter di store (bisa dibagi antar replika); blokir berlaku per
+// instance sampai window counter turun lagi.
+func NewLoginLimiter(store ratelimit.Store, limit int, window time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		store:   store,
+		limit:   limit,
+		window:  window,
+		blocked: make(map[string]time.Time),
+	}
+}
+
+// Middleware menolak IP yang diblokir dengan 429 dan menghitung response 401
+// untuk request yang membawa kredensial.
+func (l *LoginLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := sourceIP(r.RemoteAddr)
+		if wait := l.blockedFor(ip); wait > 0 {
+			retry := strconv.Itoa(ceilSeconds(wait))
+			w.Header().Set("Retry-After", retry)
+			response.Error(w, http.StatusTooManyRequests, "Too many failed login attempts, retry in "+retry+"s")
 			return
 		}
 
-		// Jendela waktu yang sama
-		if info.count >= rl.rate {
-			log.Warn().Str("ip", ip).Msg("Rate limit exceeded")
-			http.Error(w, `{"code":429,"status":"ERROR","message":"rate limit exceeded"}`, http.StatusTooManyRequests)
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() != http.StatusUnauthorized || !hasCredentials(r) {
 			return
 		}
 
-		info.count++
-		next.ServeHTTP(w, r)
+		res, err := l.store.Allow(r.Context(), "authfail:"+ip, l.limit, l.window)
+		if err != nil {
+			log.Warn().Err(err).Str("ip", ip).Msg("Login failure count failed")
+			return
+		}
+		if !res.Allowed {
+			l.block(ip, res.RetryAfter)
+			log.Warn().Str("ip", ip).Int("limit", l.limit).Msg("Too many failed logins, IP blocked")
+		}
 	})
 }
 
-func getRealIP(r *http.Request) string {
-	// Periksa header X-Forwarded-For
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		return xff
+func (l *LoginLimiter) blockedFor(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.blocked[ip]
+	if !ok {
+		return 0
+	}
+	wait := time.Until(until)
+	if wait <= 0 {
+		delete(l.blocked, ip)
 	}
-	// Periksa header X-Real-IP
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
+	return wait
+}
+
+func (l *LoginLimiter) block(ip string, d time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, until := range l.blocked {
+		if now.After(until) {
+			delete(l.blocked, k)
+		}
 	}
-	// Kembali ke RemoteAddr jika header di atas tidak ada
-	return r.RemoteAddr
+	l.blocked[ip] = now.Add(d)
+}
+
+// hasCredentials true jika request membawa Basic Auth, bearer token atau
+// API key; request tanpa kredensial tidak dihitung sebagai login gagal
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != ""
 }
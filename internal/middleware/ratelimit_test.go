@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/ardani/snmp-zte/internal/ratelimit"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	var authCalls int
+	auth := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authCalls++
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	h := NewLoginLimiter(ratelimit.NewMemoryStore(), 3, time.Minute).Middleware(auth)
+
+	do := func(ip, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/olts", nil)
+		req.RemoteAddr = ip + ":40000"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Request tanpa kredensial tidak dihitung sebagai login gagal
+	for range 5 {
+		if rec := do("10.0.0.1", ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("anonymous status = %d, want 401", rec.Code)
+		}
+	}
+
+	for i := range 3 {
+		if rec := do("10.0.0.1", "Bearer bad"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want 401", i+1, rec.Code)
+		}
+	}
+	if rec := do("10.0.0.1", "Bearer bad"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("attempt over limit status = %d, want 401 (counted, then blocked)", rec.Code)
+	}
+
+	calls := authCalls
+	rec := do("10.0.0.1", "Bearer good")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("blocked IP status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("missing Retry-After")
+	}
+	if authCalls != calls {
+		t.Fatal("blocked request reached authentication")
+	}
+
+	if rec := do("10.0.0.2", "Bearer good"); rec.Code != http.StatusOK {
+		t.Fatalf("other IP status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Limit:         3,
+		WindowSeconds: 60,
+		Routes: []config.RouteLimit{
+			{Path: "/api/v1/cli", Method: http.MethodPost, Limit: 1},
+			{Path: "/api/v1/cli/onu", Method: http.MethodPost, Limit: 2, WindowSeconds: 10},
+		},
+	}
+	h := NewRateLimiter(ratelimit.NewMemoryStore(), cfg).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	operator := &model.User{Username: "operator", AuthMethod: model.AuthBasic}
+	do := func(method, path string, user *model.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "10.0.0.1:40000"
+		if user != nil {
+			req = req.WithContext(WithUser(req.Context(), user))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	wantHeaders := func(t *testing.T, rec *httptest.ResponseRecorder, limit, remaining string, policy string) {
+		t.Helper()
+		hdr := rec.Header()
+		if hdr.Get("RateLimit-Limit") != limit || hdr.Get("RateLimit-Remaining") != remaining || hdr.Get("RateLimit-Policy") != policy {
+			t.Fatalf("headers = %v, want limit %s remaining %s policy %s", hdr, limit, remaining, policy)
+		}
+		if reset, err := strconv.Atoi(hdr.Get("RateLimit-Reset")); err != nil || reset < 1 || reset > 60 {
+			t.Fatalf("RateLimit-Reset = %q", hdr.Get("RateLimit-Reset"))
+		}
+	}
+
+	t.Run("header dan 429", func(t *testing.T) {
+		for _, remaining := range []string{"2", "1", "0"} {
+			rec := do(http.MethodGet, "/api/v1/olts", operator)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			wantHeaders(t, rec, "3", remaining, "3;w=60")
+		}
+		rec := do(http.MethodGet, "/api/v1/olts", operator)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("over limit status = %d, want 429", rec.Code)
+		}
+		wantHeaders(t, rec, "3", "0", "3;w=60")
+		if retry, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || retry < 1 {
+			t.Fatalf("Retry-After = %q", rec.Header().Get("Retry-After"))
+		}
+
+		// Caller lain punya counter sendiri
+		if rec := do(http.MethodGet, "/api/v1/olts", &model.User{Username: "other"}); rec.Code != http.StatusOK {
+			t.Fatalf("other user status = %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("batas per route", func(t *testing.T) {
+		user := &model.User{Username: "route"}
+		rec := do(http.MethodPost, "/api/v1/cli/vlan/create", user)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		// Header mengikuti batas yang paling ketat (route)
+		wantHeaders(t, rec, "1", "0", "1;w=60")
+		if rec := do(http.MethodPost, "/api/v1/cli/vlan/create", user); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("route over limit status = %d, want 429", rec.Code)
+		}
+
+		// Method lain tidak terkena batas route, hanya batas default
+		rec = do(http.MethodGet, "/api/v1/cli/vlan", user)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET status = %d, want 200", rec.Code)
+		}
+		wantHeaders(t, rec, "3", "0", "3;w=60")
+
+		// Prefix terpanjang menang dan window route sendiri dipakai
+		user = &model.User{Username: "route-onu"}
+		for _, remaining := range []string{"1", "0"} {
+			rec := do(http.MethodPost, "/api/v1/cli/onu/auth", user)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("longest prefix status = %d, want 200", rec.Code)
+			}
+			wantHeaders(t, rec, "2", remaining, "2;w=10")
+		}
+		if rec := do(http.MethodPost, "/api/v1/cli/onu/auth", user); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("longest prefix over limit status = %d, want 429", rec.Code)
+		}
+	})
+
+	t.Run("batas per API key", func(t *testing.T) {
+		key1 := &model.User{Username: "svc", AuthMethod: model.AuthAPIKey, TokenID: "k1", RateLimit: 1}
+		key2 := &model.User{Username: "svc", AuthMethod: model.AuthAPIKey, TokenID: "k2"}
+
+		rec := do(http.MethodGet, "/api/v1/olts", key1)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		wantHeaders(t, rec, "1", "0", "1;w=60")
+		if rec := do(http.MethodGet, "/api/v1/olts", key1); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("key limit status = %d, want 429", rec.Code)
+		}
+
+		// Key lain milik user yang sama dihitung terpisah dengan batas default
+		rec = do(http.MethodGet, "/api/v1/olts", key2)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("other key status = %d, want 200", rec.Code)
+		}
+		wantHeaders(t, rec, "3", "2", "3;w=60")
+	})
+
+	t.Run("anonim per IP", func(t *testing.T) {
+		for range 3 {
+			do(http.MethodGet, "/api/v1/olts", nil)
+		}
+		if rec := do(http.MethodGet, "/api/v1/olts", nil); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("anonymous status = %d, want 429", rec.Code)
+		}
+	})
+}
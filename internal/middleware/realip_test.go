@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs([]string{"127.0.0.1", " 10.0.0.0/8 ", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"127.0.0.1/32", "10.0.0.0/8", "::1/128"}
+	for i, n := range nets {
+		if n.String() != want[i] {
+			t.Errorf("nets[%d] = %s, want %s", i, n, want[i])
+		}
+	}
+	if _, err := ParseCIDRs([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("invalid CIDR accepted")
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"127.0.0.1", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		remote string
+		xff    []string
+		xri    string
+		want   string
+	}{
+		{"peer tidak tepercaya mengabaikan XFF", "203.0.113.9:5000", []string{"1.2.3.4"}, "", "203.0.113.9"},
+		{"peer tidak tepercaya mengabaikan X-Real-IP", "203.0.113.9:5000", nil, "1.2.3.4", "203.0.113.9"},
+		{"tanpa header", "127.0.0.1:5000", nil, "", "127.0.0.1"},
+		{"satu proxy", "127.0.0.1:5000", []string{"198.51.100.7"}, "", "198.51.100.7"},
+		{"hop kanan pertama yang tidak tepercaya", "127.0.0.1:5000", []string{"1.2.3.4, 198.51.100.7, 10.1.1.1"}, "", "198.51.100.7"},
+		{"nilai palsu dari client diabaikan", "127.0.0.1:5000", []string{"6.6.6.6", "198.51.100.7"}, "", "198.51.100.7"},
+		{"semua hop tepercaya", "127.0.0.1:5000", []string{"10.0.0.5, 10.0.0.6"}, "", "10.0.0.5"},
+		{"hop tidak valid menghentikan pencarian", "127.0.0.1:5000", []string{"198.51.100.7, unknown, 10.0.0.6"}, "", "10.0.0.6"},
+		{"X-Real-IP dari proxy tepercaya", "10.0.0.2:5000", nil, "198.51.100.7", "198.51.100.7"},
+		{"XFF lebih diutamakan dari X-Real-IP", "10.0.0.2:5000", []string{"198.51.100.8"}, "198.51.100.7", "198.51.100.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.xri != "" {
+				req.Header.Set("X-Real-IP", tt.xri)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Fatalf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+// Aksi manajemen remote ONU
+const (
+	ONUActionReboot       = "reboot"
+	ONUActionFactoryReset = "factory_reset"
+	ONUActionEnable       = "enable"
+	ONUActionDisable      = "disable"
+)
+
+// Status hasil aksi ONU
+const (
+	ActionDone                 = "done"
+	ActionConfirmationRequired = "confirmation_required"
+)
+
+// ValidONUAction true jika action dikenal
+func ValidONUAction(action string) bool {
+	switch action {
+	case ONUActionReboot, ONUActionFactoryReset, ONUActionEnable, ONUActionDisable:
+		return true
+	}
+	return false
+}
+
+// DestructiveONUAction true jika aksi memutus layanan pelanggan sehingga
+// harus dikonfirmasi dengan token
+func DestructiveONUAction(action string) bool {
+	return action != ONUActionEnable
+}
+
+// ONUActionRequest body aksi ONU. Aksi destruktif dikirim dua kali: tanpa
+// confirm_token untuk meminta token, lalu dengan token tersebut.
+type ONUActionRequest struct {
+	Action       string `json:"action" example:"reboot" enums:"reboot,factory_reset,enable,disable"`
+	ConfirmToken string `json:"confirm_token,omitempty"`
+}
+
+// ONUActionResult hasil aksi ONU
+type ONUActionResult struct {
+	Board        int    `json:"board"`
+	PON          int    `json:"pon"`
+	ONUID        int    `json:"onu_id"`
+	Action       string `json:"action"`
+	Status       string `json:"status"`                  // done atau confirmation_required
+	ConfirmToken string `json:"confirm_token,omitempty"` // Hanya saat confirmation_required
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	Message      string `json:"message"`
+}
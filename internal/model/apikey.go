@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// APIKeyPrefix awalan setiap API key, dipakai untuk membedakannya dari JWT
+const APIKeyPrefix = "szk_"
+
+// Metode autentikasi caller
+const (
+	AuthBasic  = "basic"
+	AuthAPIKey = "api_key"
+	AuthJWT    = "jwt"
+)
+
+// APIKey metadata API key untuk integrasi (billing, script). Nilai key
+// hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash-nya.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	OLTs      []string   `json:"olts,omitempty"`
+	RateLimit int        `json:"rate_limit,omitempty"` // Request per window rate limit (0 = default global)
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active true jika key belum dicabut dan belum kedaluwarsa pada now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// APIKeyRequest data untuk membuat API key
+type APIKeyRequest struct {
+	Name          string     `json:"name"`
+	Role          string     `json:"role"`
+	OLTs          []string   `json:"olts,omitempty"`
+	ExpiresInDays int        `json:"expires_in_days,omitempty"`
+	RateLimit     int        `json:"rate_limit,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// IssuedAPIKey API key yang baru dibuat beserta nilai key-nya
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
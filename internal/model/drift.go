@@ -0,0 +1,146 @@
+package model
+
+// DesiredState adalah konfigurasi yang diharapkan (misal dari sistem billing)
+// yang akan dibandingkan dengan running-config OLT.
+type DesiredState struct {
+	VLANs          []DesiredVLAN         `json:"vlans,omitempty"`
+	TCONTProfiles  []DesiredTCONTProfile `json:"tcont_profiles,omitempty"`
+	LineProfiles   []DesiredProfile      `json:"line_profiles,omitempty"`
+	RemoteProfiles []DesiredProfile      `json:"remote_profiles,omitempty"`
+	ONUs           []DesiredONU          `json:"onus,omitempty"`
+
+	// Prune: jika true, ONU/service-port di OLT yang tidak ada di desired
+	// state dilaporkan sebagai "removed". Hanya port PON yang disebut di
+	// desired state yang diperiksa.
+	Prune bool `json:"prune,omitempty"`
+}
+
+// DesiredVLAN VLAN yang harus ada di OLT
+type DesiredVLAN struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// DesiredTCONTProfile T-CONT profile yang harus ada di OLT
+type DesiredTCONTProfile struct {
+	Name      string `json:"name"`
+	Type      int    `json:"type"`
+	Bandwidth int    `json:"bandwidth_kbps"`
+}
+
+// DesiredProfile onu-profile gpon line/remote yang harus ada di OLT. Lines
+// (opsional) isi blok profile; urutan baris tidak diperhatikan.
+type DesiredProfile struct {
+	Name  string   `json:"name"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+// Mode autentikasi ONU
+const (
+	ONUAuthSN   = "sn"
+	ONUAuthPW   = "pw"
+	ONUAuthLOID = "loid"
+)
+
+// DesiredONU konfigurasi yang diharapkan untuk satu ONU. AuthMode default
+// sn; untuk pw/loid nilai autentikasi diisi di Password, bukan SN.
+type DesiredONU struct {
+	Rack         int                  `json:"rack,omitempty"`
+	Shelf        int                  `json:"shelf,omitempty"`
+	Slot         int                  `json:"slot"`
+	ONUID        int                  `json:"onu_id"`
+	Type         string               `json:"type"`
+	AuthMode     string               `json:"auth_mode,omitempty"`
+	SN           string               `json:"sn,omitempty"`
+	Password     string               `json:"password,omitempty"`
+	Name         string               `json:"name,omitempty"`
+	TCONTs       []DesiredTCONT       `json:"tconts,omitempty"`
+	GEMPorts     []DesiredGEMPort     `json:"gemports,omitempty"`
+	ServicePorts []DesiredServicePort `json:"service_ports,omitempty"`
+	Services     []DesiredONUService  `json:"services,omitempty"`   // pon-onu-mng service
+	VLANPorts    []DesiredONUVLANPort `json:"vlan_ports,omitempty"` // pon-onu-mng vlan port
+}
+
+// Auth mode autentikasi dan nilainya (SN atau password/LOID)
+func (o DesiredONU) Auth() (mode, value string) {
+	switch o.AuthMode {
+	case "", ONUAuthSN:
+		return ONUAuthSN, o.SN
+	default:
+		return o.AuthMode, o.Password
+	}
+}
+
+// DesiredTCONT T-CONT yang diharapkan pada ONU
+type DesiredTCONT struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Profile string `json:"profile"`
+}
+
+// DesiredGEMPort GEM port yang diharapkan pada ONU
+type DesiredGEMPort struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name,omitempty"`
+	TCONT int    `json:"tcont"`
+}
+
+// DesiredONUService baris "service {name} gemport {id} vlan {vlan}" di
+// pon-onu-mng
+type DesiredONUService struct {
+	Name    string `json:"name"`
+	GEMPort int    `json:"gemport"`
+	VLAN    int    `json:"vlan,omitempty"`
+}
+
+// DesiredONUVLANPort baris "vlan port {port} mode {mode} vlan {vlan}" di
+// pon-onu-mng
+type DesiredONUVLANPort struct {
+	Port string `json:"port"`
+	Mode string `json:"mode"`
+	VLAN int    `json:"vlan,omitempty"`
+}
+
+// DesiredServicePort service port yang diharapkan pada ONU
+type DesiredServicePort struct {
+	ID       int `json:"id"`
+	Vport    int `json:"vport"`
+	UserVLAN int `json:"user_vlan,omitempty"` // default sama dengan vlan
+	VLAN     int `json:"vlan"`
+}
+
+// Jenis perubahan pada DriftItem.
+//   - added:   ada di desired state, belum ada di OLT
+//   - removed: ada di OLT, tidak ada di desired state (hanya jika prune)
+//   - changed: ada di keduanya tetapi nilainya berbeda
+const (
+	DriftAdded   = "added"
+	DriftRemoved = "removed"
+	DriftChanged = "changed"
+)
+
+// DriftItem satu perbedaan antara desired state dan OLT
+type DriftItem struct {
+	Change    string      `json:"change"`
+	Kind      string      `json:"kind"` // vlan, tcont_profile, line_profile, remote_profile, onu, name, tcont, gemport, service_port, onu_service, vlan_port
+	Interface string      `json:"interface,omitempty"`
+	Key       string      `json:"key"`
+	Desired   interface{} `json:"desired,omitempty"`
+	Actual    interface{} `json:"actual,omitempty"`
+}
+
+// ReconcileStep perintah CLI untuk menghilangkan satu drift.
+// Method adalah nama method ZTEC320Client yang setara.
+type ReconcileStep struct {
+	Method   string   `json:"method"`
+	Commands []string `json:"commands"`
+}
+
+// DriftReport hasil perbandingan desired state dengan OLT
+type DriftReport struct {
+	InSync    bool            `json:"in_sync"`
+	Added     []DriftItem     `json:"added"`
+	Removed   []DriftItem     `json:"removed"`
+	Changed   []DriftItem     `json:"changed"`
+	Reconcile []ReconcileStep `json:"reconcile,omitempty"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // OLT merepresentasikan perangkat OLT
 type OLT struct {
 	ID          string `json:"id"`
@@ -10,6 +12,9 @@ type OLT struct {
 	Community   string `json:"community"`
 	BoardCount  int    `json:"board_count"`
 	PonPerBoard int    `json:"pon_per_board"`
+	CLIPort     int    `json:"cli_port,omitempty"`
+	CLIUsername string `json:"cli_username,omitempty"`
+	CLIPassword string `json:"cli_password,omitempty"`
 }
 
 // OLTSummary merepresentasikan informasi ringkasan tentang OLT
@@ -32,8 +37,8 @@ type BoardInfo struct {
 	RealType  string `json:"real_type"`
 	Status    string `json:"status"`
 	PortCount int    `json:"port_count"`
-	CpuLoad   int    `json:"cpu_load"`
-	MemUsage  int    `json:"mem_usage"`
+	CpuLoad   int    `json:"cpu_load" unit:"%"`
+	MemUsage  int    `json:"mem_usage" unit:"%"`
 	SoftVer   string `json:"soft_ver"`
 }
 
@@ -42,23 +47,71 @@ type PONInfo struct {
 	BoardID  int     `json:"board_id"`
 	PonID    int     `json:"pon_id"`
 	Status   string  `json:"status"`
-	TxPower  float64 `json:"tx_power"`
-	RxPower  float64 `json:"rx_power"`
+	TxPower  float64 `json:"tx_power" unit:"dBm"`
+	RxPower  float64 `json:"rx_power" unit:"dBm"`
 	ONUCount int     `json:"onu_count"`
 	RxBytes  int64   `json:"rx_bytes"`
 	TxBytes  int64   `json:"tx_bytes"`
 }
 
+// CapacityReport kapasitas dan utilisasi setiap PON di OLT
+type CapacityReport struct {
+	OLTID     string        `json:"olt_id"`
+	Window    float64       `json:"window_seconds"` // Lama pengukuran trafik
+	PONs      []PONCapacity `json:"pons"`
+	Timestamp string        `json:"timestamp"`
+}
+
+// PONCapacity kapasitas satu port PON: slot ONU, bandwidth assured terhadap
+// line rate, utilisasi trafik dan jarak ONU terjauh
+type PONCapacity struct {
+	Board                 int      `json:"board"`
+	PON                   int      `json:"pon"`
+	ONUCount              int      `json:"onu_count"`
+	MaxONU                int      `json:"max_onu"`
+	FreeSlots             int      `json:"free_slots"`
+	AssuredUpstream       int64    `json:"assured_upstream" unit:"kbps"` // Jumlah SLA assured semua ONU
+	AssuredDownstream     int64    `json:"assured_downstream" unit:"kbps"`
+	LineRateUpstream      int64    `json:"line_rate_upstream" unit:"kbps"`
+	LineRateDownstream    int64    `json:"line_rate_downstream" unit:"kbps"`
+	UpstreamBps           float64  `json:"upstream_bps" unit:"bps"`
+	DownstreamBps         float64  `json:"downstream_bps" unit:"bps"`
+	UpstreamUtilization   float64  `json:"upstream_utilization" unit:"%"`
+	DownstreamUtilization float64  `json:"downstream_utilization" unit:"%"`
+	PeakUtilization       float64  `json:"peak_utilization" unit:"%"` // Arah dengan utilisasi tertinggi
+	MaxDistance           int      `json:"max_distance" unit:"m"`     // ONU terjauh
+	Oversubscribed        bool     `json:"oversubscribed"`
+	Flags                 []string `json:"flags"`
+}
+
 // ONUTraffic merepresentasikan statistik trafik ONU
 type ONUTraffic struct {
-	Board     int    `json:"board"`
-	PON       int    `json:"pon"`
-	ONUID     int    `json:"onu_id"`
-	RxBytes   int64  `json:"rx_bytes"`
-	TxBytes   int64  `json:"tx_bytes"`
-	RxPackets int64  `json:"rx_packets"`
-	TxPackets int64  `json:"tx_packets"`
-	Timestamp string `json:"timestamp"`
+	Board     int           `json:"board"`
+	PON       int           `json:"pon"`
+	ONUID     int           `json:"onu_id"`
+	RxBytes   int64         `json:"rx_bytes"`
+	TxBytes   int64         `json:"tx_bytes"`
+	RxPackets int64         `json:"rx_packets"`
+	TxPackets int64         `json:"tx_packets"`
+	Rates     *TrafficRate  `json:"rates"` // null pada sampel pertama atau setelah counter reset
+	Timestamp string        `json:"timestamp"`
+	Sample    CounterSample `json:"-"`
+}
+
+// TrafficRate laju trafik dari selisih dua sampel counter berturut-turut
+type TrafficRate struct {
+	RxBps    float64 `json:"rx_bps" unit:"bps"`
+	TxBps    float64 `json:"tx_bps" unit:"bps"`
+	RxPps    float64 `json:"rx_pps" unit:"pps"`
+	TxPps    float64 `json:"tx_pps" unit:"pps"`
+	Interval float64 `json:"interval_seconds" unit:"s"` // Jarak dengan sampel sebelumnya
+}
+
+// CounterSample metadata pembacaan counter untuk menghitung laju
+type CounterSample struct {
+	At     time.Time // Waktu counter dibaca
+	Uptime uint32    // sysUpTime OLT (1/100 detik) untuk mendeteksi reboot, 0 jika gagal dibaca
+	Bits   int       // 32 jika ada Counter32, 64 jika semuanya Counter64
 }
 
 // InterfaceStats merepresentasikan statistik interface
@@ -79,14 +132,16 @@ type TemperatureInfo struct {
 
 // PONPortStats merepresentasikan statistik traffic per PON port
 type PONPortStats struct {
-	Board     int    `json:"board"`
-	PON       int    `json:"pon"`
-	RxBytes   int64  `json:"rx_bytes"`
-	TxBytes   int64  `json:"tx_bytes"`
-	RxPackets int64  `json:"rx_packets"`
-	TxPackets int64  `json:"tx_packets"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
+	Board     int           `json:"board"`
+	PON       int           `json:"pon"`
+	RxBytes   int64         `json:"rx_bytes"`
+	TxBytes   int64         `json:"tx_bytes"`
+	RxPackets int64         `json:"rx_packets"`
+	TxPackets int64         `json:"tx_packets"`
+	Rates     *TrafficRate  `json:"rates"` // null pada sampel pertama atau setelah counter reset
+	Status    string        `json:"status"`
+	Timestamp string        `json:"timestamp"`
+	Sample    CounterSample `json:"-"`
 }
 
 // ONUErrors merepresentasikan error counter per ONU
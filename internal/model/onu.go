@@ -9,16 +9,17 @@ type ONUInfo struct {
 	Name         string `json:"name"`
 	Type         string `json:"type"`
 	SerialNumber string `json:"serial_number"`
-	RXPower      string `json:"rx_power"`
-	TXPower      string `json:"tx_power"`
-	Distance     string `json:"distance"`
+	RXPower      string `json:"rx_power" unit:"dBm"`
+	TXPower      string `json:"tx_power" unit:"dBm"`
+	OLTRxPower   string `json:"olt_rx_power" unit:"dBm"` // Daya dari ONU yang diterima OLT (upstream)
+	Distance     string `json:"distance" unit:"m"`
 	Status       string `json:"status"`
 }
 
 // ONUDetail merepresentasikan informasi rinci ONU
 type ONUDetail struct {
 	ONUInfo
-	TXPower              string `json:"tx_power"`
+	TXPower              string `json:"tx_power" unit:"dBm"`
 	IPAddress            string `json:"ip_address"`
 	Description          string `json:"description"`
 	LastOnline           string `json:"last_online"`
@@ -26,7 +27,7 @@ type ONUDetail struct {
 	Uptime               string `json:"uptime"`
 	LastDownTimeDuration string `json:"last_down_duration"`
 	OfflineReason        string `json:"offline_reason"`
-	Distance             string `json:"distance"`
+	Distance             string `json:"distance" unit:"m"`
 }
 
 // ONUBandwidth merepresentasikan bandwidth SLA per ONU
@@ -41,6 +42,32 @@ type ONUBandwidth struct {
 	MaxDownstream     int64 `json:"max_downstream"`     // kbps
 }
 
+// TopTraffic daftar ONU dengan laju trafik terbesar dalam satu window
+type TopTraffic struct {
+	OLTID     string            `json:"olt_id"`
+	Board     int               `json:"board,omitempty"` // 0 jika seluruh OLT
+	PON       int               `json:"pon,omitempty"`   // 0 jika semua PON
+	Direction string            `json:"direction"`       // upstream atau downstream
+	Window    float64           `json:"window_seconds"`
+	Sampled   int               `json:"sampled"` // Jumlah ONU online yang terukur
+	Entries   []TopTrafficEntry `json:"entries"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// TopTrafficEntry satu ONU dalam daftar top trafik
+type TopTrafficEntry struct {
+	Board         int     `json:"board"`
+	PON           int     `json:"pon"`
+	ONUID         int     `json:"onu_id"`
+	Name          string  `json:"name"`
+	SerialNumber  string  `json:"serial_number"`
+	UpstreamBps   float64 `json:"upstream_bps" unit:"bps"`   // ONU ke OLT
+	DownstreamBps float64 `json:"downstream_bps" unit:"bps"` // OLT ke ONU
+	MaxUpstream   int64   `json:"max_upstream" unit:"kbps"`  // SLA, 0 jika tidak diketahui
+	MaxDownstream int64   `json:"max_downstream" unit:"kbps"`
+	ExceedsSLA    bool    `json:"exceeds_sla"`
+}
+
 // ONUSlot merepresentasikan slot ONU yang tersedia
 type ONUSlot struct {
 	Board  int `json:"board"`
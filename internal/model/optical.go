@@ -0,0 +1,46 @@
+package model
+
+// OpticalReport analisis budget optik dan kesehatan fiber per PON
+type OpticalReport struct {
+	OLTID     string       `json:"olt_id"`
+	PONs      []PONOptical `json:"pons"`
+	Timestamp string       `json:"timestamp"`
+}
+
+// PONOptical hasil analisis satu PON
+type PONOptical struct {
+	Board      int              `json:"board"`
+	PON        int              `json:"pon"`
+	Basis      string           `json:"basis"`                 // link_loss atau rx_power
+	MedianLoss *float64         `json:"median_loss" unit:"dB"` // Median link loss ternormalisasi jarak; null untuk basis rx_power atau peer kurang
+	ONUs       []ONUOptical     `json:"onus"`
+	Branches   []SplitterBranch `json:"suspect_branches"` // Kelompok outlier yang kemungkinan satu cabang splitter
+}
+
+// ONUOptical power dan loss satu ONU dibanding peer di PON yang sama
+type ONUOptical struct {
+	Board        int      `json:"board"`
+	PON          int      `json:"pon"`
+	ONUID        int      `json:"onu_id"`
+	Name         string   `json:"name"`
+	SerialNumber string   `json:"serial_number"`
+	Distance     int      `json:"distance" unit:"m"`
+	RXPower      *float64 `json:"rx_power" unit:"dBm"`     // Diterima ONU (downstream)
+	TXPower      *float64 `json:"tx_power" unit:"dBm"`     // Dikirim ONU
+	OLTRxPower   *float64 `json:"olt_rx_power" unit:"dBm"` // Diterima OLT dari ONU (upstream)
+	LinkLoss     *float64 `json:"link_loss" unit:"dB"`     // tx_power - olt_rx_power
+	ExcessLoss   *float64 `json:"excess_loss" unit:"dB"`   // Selisih dengan median peer setelah koreksi jarak
+	Outlier      bool     `json:"outlier"`
+	Suspect      string   `json:"suspect,omitempty"` // connector_or_drop atau splitter_branch
+	Branch       int      `json:"branch,omitempty"`  // ID di suspect_branches
+}
+
+// SplitterBranch kelompok ONU outlier dengan jarak dan excess loss yang
+// mirip, kemungkinan berbagi cabang splitter atau kabel distribusi yang sama
+type SplitterBranch struct {
+	ID          int     `json:"id"`
+	ONUIDs      []int   `json:"onu_ids"`
+	MinDistance int     `json:"min_distance" unit:"m"`
+	MaxDistance int     `json:"max_distance" unit:"m"`
+	ExcessLoss  float64 `json:"excess_loss" unit:"dB"` // Rata-rata
+}
@@ -0,0 +1,49 @@
+package model
+
+// StabilityReport stabilitas ONU per PON dari riwayat perubahan status
+type StabilityReport struct {
+	OLTID     string         `json:"olt_id"`
+	Window    float64        `json:"window_hours"`   // Rentang yang diminta
+	Observed  float64        `json:"observed_hours"` // Rentang yang benar-benar terpantau
+	PONs      []PONStability `json:"pons"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// PONStability ringkasan stabilitas satu PON
+type PONStability struct {
+	Board          int            `json:"board"`
+	PON            int            `json:"pon"`
+	ONUCount       int            `json:"onu_count"`
+	FlappingONUs   int            `json:"flapping_onus"` // ONU dengan minimal satu flap
+	Flaps          int            `json:"flaps"`
+	FlapsPerDay    float64        `json:"flaps_per_day"`
+	DominantReason string         `json:"dominant_reason"`
+	Score          int            `json:"score"` // Rata-rata skor ONU
+	Grade          string         `json:"grade"`
+	ONUs           []ONUStability `json:"onus"` // Skor terendah lebih dulu, ONU offline di akhir
+}
+
+// ONUStability ringkasan stabilitas satu ONU
+type ONUStability struct {
+	Board          int           `json:"board"`
+	PON            int           `json:"pon"`
+	ONUID          int           `json:"onu_id"`
+	Status         string        `json:"status"` // Status pada poll terakhir
+	Flaps          int           `json:"flaps"`  // Online menjadi tidak online
+	FlapsPerDay    float64       `json:"flaps_per_day"`
+	MTBF           *float64      `json:"mtbf" unit:"s"` // Rata-rata lama online per gangguan, null jika tidak ada gangguan
+	Availability   float64       `json:"availability" unit:"%"`
+	DominantReason string        `json:"dominant_reason"` // Alasan offline terbanyak
+	LastChange     string        `json:"last_change"`
+	Score          int           `json:"score"`            // 0-100, makin tinggi makin stabil
+	Grade          string        `json:"grade"`            // stable, degraded, unstable, offline
+	Events         []StatusEvent `json:"events,omitempty"` // Hanya pada permintaan per ONU
+}
+
+// StatusEvent satu perubahan status ONU
+type StatusEvent struct {
+	At     string `json:"at"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Sumber data port Ethernet ONU
+const (
+	UNISourceSNMP = "snmp"
+	UNISourceCLI  = "cli"
+)
+
+// ONUEthPort status satu port Ethernet (UNI) di sisi pelanggan
+type ONUEthPort struct {
+	Port       int    `json:"port"`                 // Nomor port, 1 untuk eth_0/1
+	Name       string `json:"name"`                 // eth_0/1
+	AdminState string `json:"admin_state"`          // enable atau disable
+	LinkState  string `json:"link_state"`           // up atau down
+	Speed      string `json:"speed,omitempty"`      // 1000M, 100M, 10M
+	Duplex     string `json:"duplex,omitempty"`     // full atau half
+	SpeedMode  string `json:"speed_mode,omitempty"` // Setelan negosiasi, mis. auto
+}
+
+// ONUEthPorts daftar port Ethernet satu ONU
+type ONUEthPorts struct {
+	OLTID     string       `json:"olt_id"`
+	Board     int          `json:"board"`
+	PON       int          `json:"pon"`
+	ONUID     int          `json:"onu_id"`
+	Source    string       `json:"source"` // snmp atau cli
+	Ports     []ONUEthPort `json:"ports"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// ONUEthPortRequest body untuk mengaktifkan/menonaktifkan port Ethernet ONU
+type ONUEthPortRequest struct {
+	Enabled *bool `json:"enabled" example:"false"`
+}
+
+// ONUEthPortResult hasil perubahan status port Ethernet ONU
+type ONUEthPortResult struct {
+	Board      int    `json:"board"`
+	PON        int    `json:"pon"`
+	ONUID      int    `json:"onu_id"`
+	Port       int    `json:"port"`
+	AdminState string `json:"admin_state"` // enable atau disable
+	Source     string `json:"source"`      // snmp atau cli
+}
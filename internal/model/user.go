@@ -0,0 +1,88 @@
+package model
+
+import "time"
+
+// Role pengguna API
+const (
+	RoleReadOnly = "read-only" // Hanya membaca data SNMP/CLI
+	RoleOperator = "operator"  // Membaca + provisioning ONU
+	RoleAdmin    = "admin"     // Semua akses, termasuk kelola OLT, restore config, user dan audit log
+)
+
+// Permission hak akses yang diperiksa per route
+const (
+	PermRead          = "read"           // Membaca data SNMP/CLI dan backup
+	PermProvision     = "provision"      // Provisioning ONU, VLAN, profile, save/backup config
+	PermManageOLTs    = "manage_olts"    // Tambah/ubah/hapus OLT
+	PermRestoreConfig = "restore_config" // Restore config ke OLT
+	PermManageUsers   = "manage_users"   // Kelola user API
+	PermViewAudit     = "view_audit"     // Membaca dan mengekspor audit log
+)
+
+// RolePermissions daftar permission untuk setiap role
+var RolePermissions = map[string][]string{
+	RoleReadOnly: {PermRead},
+	RoleOperator: {PermRead, PermProvision},
+	RoleAdmin:    {PermRead, PermProvision, PermManageOLTs, PermRestoreConfig, PermManageUsers, PermViewAudit},
+}
+
+// ValidRole true jika role dikenal
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// User pengguna API (tanpa password)
+type User struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+
+	// OLTs membatasi akses hanya ke OLT dengan ID ini (kosong = semua OLT)
+	OLTs      []string  `json:"olts,omitempty"`
+	Disabled  bool      `json:"disabled,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+
+	// Diisi saat request diautentikasi, tidak disimpan
+	AuthMethod string `json:"auth_method,omitempty"` // basic, api_key, jwt
+	TokenID    string `json:"token_id,omitempty"`    // ID API key atau jti JWT
+	RateLimit  int    `json:"-"`                     // Batas request per window dari API key (0 = default)
+}
+
+// Can true jika role user memiliki permission perm
+func (u *User) Can(perm string) bool {
+	for _, p := range RolePermissions[u.Role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Scoped true jika akses user dibatasi ke OLT tertentu
+func (u *User) Scoped() bool {
+	return len(u.OLTs) > 0
+}
+
+// CanAccessOLT true jika user boleh mengakses OLT oltID
+func (u *User) CanAccessOLT(oltID string) bool {
+	if !u.Scoped() {
+		return true
+	}
+	for _, id := range u.OLTs {
+		if id == oltID {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRequest data untuk membuat atau mengubah user.
+// Pada update, field kosong/nil tidak diubah.
+type UserRequest struct {
+	Username string    `json:"username,omitempty"`
+	Password string    `json:"password,omitempty"`
+	Role     string    `json:"role,omitempty"`
+	OLTs     *[]string `json:"olts,omitempty"`
+	Disabled *bool     `json:"disabled,omitempty"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// ONUWANConnection satu koneksi WAN ONU. Password PPPoE tidak pernah
+// ditampilkan.
+type ONUWANConnection struct {
+	Index        int    `json:"index"`
+	Name         string `json:"name"`
+	Mode         string `json:"mode"`   // pppoe, dhcp atau static
+	Status       string `json:"status"` // Connected, Disconnected, ...
+	Username     string `json:"username,omitempty"`
+	IPAddress    string `json:"ip_address"`
+	SubnetMask   string `json:"subnet_mask,omitempty"`
+	Gateway      string `json:"gateway,omitempty"`
+	PrimaryDNS   string `json:"primary_dns,omitempty"`
+	SecondaryDNS string `json:"secondary_dns,omitempty"`
+	MACAddress   string `json:"mac_address,omitempty"`
+	VLAN         int    `json:"vlan,omitempty"`
+}
+
+// ONUWAN daftar koneksi WAN satu ONU
+type ONUWAN struct {
+	OLTID       string             `json:"olt_id"`
+	Board       int                `json:"board"`
+	PON         int                `json:"pon"`
+	ONUID       int                `json:"onu_id"`
+	Connections []ONUWANConnection `json:"connections"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// ONUSSID satu SSID WiFi ONU. Password WiFi tidak pernah ditampilkan.
+type ONUSSID struct {
+	Index      int    `json:"index"`     // 1 untuk wifi_0/1
+	Interface  string `json:"interface"` // wifi_0/1
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Hidden     bool   `json:"hidden"`
+	AuthMode   string `json:"auth_mode,omitempty"`
+	Encryption string `json:"encryption,omitempty"`
+	Standard   string `json:"standard,omitempty"`
+	Channel    string `json:"channel,omitempty"`
+	Clients    int    `json:"clients"` // Perangkat yang terhubung
+}
+
+// ONUWiFi daftar SSID satu ONU
+type ONUWiFi struct {
+	OLTID     string    `json:"olt_id"`
+	Board     int       `json:"board"`
+	PON       int       `json:"pon"`
+	ONUID     int       `json:"onu_id"`
+	SSIDs     []ONUSSID `json:"ssids"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ONUPPPoERequest body untuk mengatur kredensial PPPoE satu WAN ONU
+type ONUPPPoERequest struct {
+	Username    string `json:"username" example:"cust001@isp.net"`
+	Password    string `json:"password" example:"secret"`
+	VLANProfile string `json:"vlan_profile" example:"pppoe143"` // onu profile vlan di OLT
+	Host        int    `json:"host,omitempty" example:"1"`      // Default 1
+}
+
+// ONUWiFiRequest body untuk mengubah SSID. Field kosong tidak diubah.
+type ONUWiFiRequest struct {
+	Name     string `json:"name,omitempty" example:"RumahKu"`
+	Password string `json:"password,omitempty" example:"rahasia123"` // WPA2-PSK, 8-63 karakter
+}
+
+// ONUConfigResult hasil perubahan konfigurasi remote ONU
+type ONUConfigResult struct {
+	Board   int    `json:"board"`
+	PON     int    `json:"pon"`
+	ONUID   int    `json:"onu_id"`
+	Target  string `json:"target"` // wan-ip 1 atau wifi_0/1
+	Message string `json:"message"`
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan counter di memori proses. Hanya akurat untuk satu
+// instance API.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	calls   int
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	window time.Duration
+	index  int64 // Nomor window berjalan (now / window)
+	prev   int64
+	cur    int64
+}
+
+// NewMemoryStore membuat store counter di memori
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow mengimplementasikan Store
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	index := now.UnixNano() / int64(window)
+
+	b, ok := s.buckets[key]
+	if !ok || b.window != window {
+		b = &memoryBucket{window: window, index: index}
+		s.buckets[key] = b
+	}
+	switch {
+	case index == b.index+1:
+		b.prev, b.cur = b.cur, 0
+	case index > b.index+1:
+		b.prev, b.cur = 0, 0
+	}
+	b.index = index
+
+	c := counters{
+		prev:    b.prev,
+		cur:     b.cur,
+		elapsed: time.Duration(now.UnixNano() - index*int64(window)),
+	}
+	if c.estimate(window)+1 > float64(limit) {
+		return c.result(false, limit, window), nil
+	}
+	b.cur++
+	c.cur++
+
+	s.calls++
+	if s.calls%1000 == 0 {
+		s.sweep(now)
+	}
+	return c.result(true, limit, window), nil
+}
+
+// sweep menghapus bucket yang tidak aktif lebih dari dua window
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.UnixNano()/int64(b.window) > b.index+1 {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreSlidingWindow(t *testing.T) {
+	const window = time.Minute
+	start := time.Unix(6000, 0) // Awal window (6000 habis dibagi 60)
+	now := start
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	allow := func(key string) Result {
+		t.Helper()
+		res, err := s.Allow(context.Background(), key, 3, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	for i, want := range []int{2, 1, 0} {
+		res := allow("a")
+		if !res.Allowed || res.Remaining != want || res.Limit != 3 || res.Reset != window {
+			t.Fatalf("request %d = %+v, want allowed with remaining %d", i+1, res, want)
+		}
+	}
+
+	// Melewati limit: tunggu sampai bobot 3 request window ini turun ke 2
+	// di window berikutnya (60s + 20s)
+	res := allow("a")
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != 80*time.Second {
+		t.Fatalf("over limit = %+v, want denied with retry after 80s", res)
+	}
+	if res := allow("b"); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("other key = %+v, want independent counter", res)
+	}
+
+	// Masih di window yang sama
+	now = start.Add(30 * time.Second)
+	if res := allow("a"); res.Allowed || res.Reset != 30*time.Second || res.RetryAfter != 50*time.Second {
+		t.Fatalf("same window = %+v", res)
+	}
+
+	// Window berikutnya, sebelum bobot window lalu cukup turun
+	now = start.Add(70 * time.Second)
+	if res := allow("a"); res.Allowed || res.RetryAfter != 10*time.Second {
+		t.Fatalf("next window at 10s = %+v, want retry after 10s", res)
+	}
+
+	// 3 × 40/60 = 2 dari window lalu, tepat muat satu request lagi
+	now = start.Add(80 * time.Second)
+	if res := allow("a"); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("next window at 20s = %+v, want allowed", res)
+	}
+	if res := allow("a"); res.Allowed {
+		t.Fatalf("second request at 20s = %+v, want denied", res)
+	}
+
+	// Lebih dari satu window tanpa request: counter kembali penuh
+	now = start.Add(3 * window)
+	if res := allow("a"); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("after idle windows = %+v, want reset", res)
+	}
+}
+
+// TestMemoryStoreWindowChange memastikan counter dimulai ulang jika key yang
+// sama dipakai dengan window berbeda.
+func TestMemoryStoreWindowChange(t *testing.T) {
+	now := time.Unix(6000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Allow(ctx, "a", 1, time.Minute)
+	if res, _ := s.Allow(ctx, "a", 1, time.Minute); res.Allowed {
+		t.Fatalf("same window = %+v, want denied", res)
+	}
+	if res, _ := s.Allow(ctx, "a", 1, time.Hour); !res.Allowed {
+		t.Fatalf("new window = %+v, want allowed", res)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Unix(6000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Allow(ctx, "idle", 10, time.Minute)
+	now = now.Add(2 * time.Minute)
+	for range 999 {
+		s.Allow(ctx, "active", 1000, time.Minute)
+	}
+	if _, ok := s.buckets["idle"]; ok {
+		t.Fatal("idle bucket not swept")
+	}
+	if _, ok := s.buckets["active"]; !ok {
+		t.Fatal("active bucket swept")
+	}
+}
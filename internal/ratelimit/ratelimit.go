@@ -0,0 +1,77 @@
+// Package ratelimit menyediakan rate limiter sliding window untuk API.
+// Counter disimpan di Redis agar batas berlaku bersama di semua replika,
+// atau di memori untuk deployment satu instance.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Result hasil pengecekan satu request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Sisa waktu window berjalan
+	RetryAfter time.Duration // Hanya diisi jika Allowed false
+}
+
+// Store menyimpan counter rate limit
+type Store interface {
+	// Allow mencatat satu request untuk key jika masih di bawah limit
+	// dalam window terakhir.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+// counters nilai counter sliding window pada saat request:
+// prev = jumlah request di window sebelumnya, cur = di window berjalan,
+// elapsed = waktu yang sudah lewat di window berjalan.
+type counters struct {
+	prev, cur int64
+	elapsed   time.Duration
+}
+
+// estimate perkiraan jumlah request dalam satu window terakhir: request
+// window sebelumnya dibobot sesuai porsi yang masih masuk jangkauan.
+func (c counters) estimate(window time.Duration) float64 {
+	weight := float64(window-c.elapsed) / float64(window)
+	return float64(c.prev)*weight + float64(c.cur)
+}
+
+// result menyusun Result dari counter. Untuk request yang diizinkan c
+// adalah kondisi setelah request dicatat.
+func (c counters) result(allowed bool, limit int, window time.Duration) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(0, limit-int(math.Ceil(c.estimate(window)))),
+		Reset:     window - c.elapsed,
+	}
+	if !allowed {
+		res.RetryAfter = c.retryAfter(limit, window)
+	}
+	return res
+}
+
+// retryAfter waktu tunggu sampai satu request lagi muat di bawah limit
+func (c counters) retryAfter(limit int, window time.Duration) time.Duration {
+	room := float64(limit - 1)
+
+	// Masih di window berjalan: bobot prev turun seiring waktu
+	if c.prev > 0 && float64(c.cur) <= room {
+		need := window - time.Duration((room-float64(c.cur))/float64(c.prev)*float64(window))
+		if need >= c.elapsed {
+			return need - c.elapsed
+		}
+		return 0
+	}
+
+	// Tunggu window berikutnya, di mana cur menjadi prev
+	wait := window - c.elapsed
+	if c.cur > 0 && float64(c.cur) > room {
+		wait += window - time.Duration(room/float64(c.cur)*float64(window))
+	}
+	return wait
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript memeriksa dan mencatat request secara atomik. Waktu
+// diambil dari server Redis agar semua replika memakai jam yang sama.
+//
+// KEYS[1] prefix key (dengan hash tag agar aman di Redis Cluster)
+// ARGV[1] limit, ARGV[2] window dalam milidetik
+// Hasil: {allowed, prev, cur, elapsed_ms}
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local index = math.floor(now / window)
+local elapsed = now - index * window
+local curKey = KEYS[1] .. ':' .. index
+local prev = tonumber(redis.call('GET', KEYS[1] .. ':' .. (index - 1)) or '0')
+local cur = tonumber(redis.call('GET', curKey) or '0')
+if prev * (window - elapsed) / window + cur + 1 > limit then
+  return {0, prev, cur, elapsed}
+end
+cur = redis.call('INCR', curKey)
+redis.call('PEXPIRE', curKey, window * 2)
+return {1, prev, cur, elapsed}
+`)
+
+// RedisStore menyimpan counter di Redis sehingga batas berlaku bersama di
+// semua replika API.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore membuat store counter Redis
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, prefix: "ratelimit:"}
+}
+
+// Allow mengimplementasikan Store
+func (s *RedisStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	vals, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{s.prefix + "{" + key + "}"},
+		limit, window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	if len(vals) != 4 {
+		return Result{}, fmt.Errorf("rate limit script returned %d values", len(vals))
+	}
+
+	c := counters{
+		prev:    vals[1],
+		cur:     vals[2],
+		elapsed: time.Duration(vals[3]) * time.Millisecond,
+	}
+	return c.result(vals[0] == 1, limit, window), nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Error API key service
+var (
+	ErrAPIKeyNotFound = &ServiceError{Message: "API key not found"}
+	ErrInvalidAPIKey  = &ServiceError{Message: "invalid, expired or revoked API key"}
+)
+
+// storedAPIKey API key beserta hash-nya seperti yang disimpan di file
+type storedAPIKey struct {
+	model.APIKey
+	Hash string `json:"hash"` // sha256 dari key lengkap (hex)
+}
+
+// APIKeyService menerbitkan dan memverifikasi API key. Key disimpan
+// dalam bentuk hash sha256; key memiliki entropi 256 bit sehingga hash
+// tanpa salt sudah cukup.
+type APIKeyService struct {
+	path string
+	mu   sync.RWMutex
+	keys map[string]*storedAPIKey
+}
+
+// NewAPIKeyService memuat API key dari path (file boleh belum ada)
+func NewAPIKeyService(path string) (*APIKeyService, error) {
+	s := &APIKeyService{
+		path: path,
+		keys: make(map[string]*storedAPIKey),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	var keys []*storedAPIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file: %w", err)
+	}
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+	return s, nil
+}
+
+// Issue membuat API key baru. Nilai key hanya dikembalikan sekali di sini.
+func (s *APIKeyService) Issue(req model.APIKeyRequest, createdBy string) (*model.IssuedAPIKey, error) {
+	if req.Name == "" {
+		return nil, &ServiceError{Message: "name is required"}
+	}
+	if !model.ValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+	if req.RateLimit < 0 {
+		return nil, &ServiceError{Message: "rate_limit must not be negative"}
+	}
+
+	now := time.Now().UTC()
+	expiresAt := req.ExpiresAt
+	if expiresAt == nil && req.ExpiresInDays > 0 {
+		t := now.AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, &ServiceError{Message: "expires_at must be in the future"}
+	}
+
+	id := randomHex(6)
+	key := model.APIKeyPrefix + id + "_" + randomHex(32)
+
+	k := &storedAPIKey{
+		APIKey: model.APIKey{
+			ID:        id,
+			Name:      req.Name,
+			Role:      req.Role,
+			OLTs:      req.OLTs,
+			RateLimit: req.RateLimit,
+			CreatedBy: createdBy,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		},
+		Hash: hashAPIKey(key),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = k
+	if err := s.save(); err != nil {
+		delete(s.keys, id)
+		return nil, err
+	}
+	return &model.IssuedAPIKey{APIKey: k.APIKey, Key: key}, nil
+}
+
+// List mengembalikan semua API key (tanpa nilai key), terbaru lebih dulu
+func (s *APIKeyService) List() []model.APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]model.APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k.APIKey)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// Revoke mencabut API key. Key tetap tercatat dengan revoked_at.
+func (s *APIKeyService) Revoke(id string) (*model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	if k.RevokedAt == nil {
+		now := time.Now().UTC()
+		k.RevokedAt = &now
+		if err := s.save(); err != nil {
+			k.RevokedAt = nil
+			return nil, err
+		}
+	}
+	key := k.APIKey
+	return &key, nil
+}
+
+// AuthenticateToken memverifikasi API key dan mengembalikan identitas caller
+func (s *APIKeyService) AuthenticateToken(token string) (*model.User, error) {
+	rest, ok := strings.CutPrefix(token, model.APIKeyPrefix)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	s.mu.RLock()
+	k, ok := s.keys[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(token)), []byte(k.Hash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if !k.Active(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &model.User{
+		Username:   "apikey:" + k.Name,
+		Role:       k.Role,
+		OLTs:       k.OLTs,
+		AuthMethod: model.AuthAPIKey,
+		TokenID:    k.ID,
+		RateLimit:  k.RateLimit,
+	}, nil
+}
+
+// save menulis semua key ke file. Harus dipanggil dengan s.mu terkunci.
+func (s *APIKeyService) save() error {
+	keys := make([]*storedAPIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal API keys: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create API keys directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API keys file: %w", err)
+	}
+	return nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func newTestAPIKeyService(t *testing.T) (*APIKeyService, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	s, err := NewAPIKeyService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestAPIKeyIssueStoresHashOnly(t *testing.T) {
+	s, path := newTestAPIKeyService(t)
+	issued, err := s.Issue(model.APIKeyRequest{Name: "billing", Role: model.RoleOperator}, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(issued.Key, model.APIKeyPrefix+issued.ID+"_") {
+		t.Fatalf("key = %q, want prefix %s%s_", issued.Key, model.APIKeyPrefix, issued.ID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), issued.Key) || strings.Contains(string(data), model.APIKeyPrefix) {
+		t.Fatalf("API key plaintext stored: %s", data)
+	}
+	if !strings.Contains(string(data), hashAPIKey(issued.Key)) {
+		t.Fatalf("sha256 hash not stored: %s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("file mode = %v, %v", info.Mode().Perm(), err)
+	}
+
+	// Key tetap bisa diverifikasi setelah dimuat ulang dari file
+	reloaded, err := NewAPIKeyService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.AuthenticateToken(issued.Key); err != nil {
+		t.Fatalf("AuthenticateToken after reload: %v", err)
+	}
+	for _, k := range reloaded.List() {
+		if k.ID == issued.ID && k.CreatedBy != "admin" {
+			t.Fatalf("created_by = %q", k.CreatedBy)
+		}
+	}
+}
+
+func TestAPIKeyIssueValidation(t *testing.T) {
+	s, _ := newTestAPIKeyService(t)
+	past := time.Now().Add(-time.Hour)
+	for name, req := range map[string]model.APIKeyRequest{
+		"tanpa nama":               {Role: model.RoleReadOnly},
+		"role tidak dikenal":       {Name: "x", Role: "root"},
+		"rate limit negatif":       {Name: "x", Role: model.RoleReadOnly, RateLimit: -1},
+		"kedaluwarsa di masa lalu": {Name: "x", Role: model.RoleReadOnly, ExpiresAt: &past},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var se *ServiceError
+			if _, err := s.Issue(req, "admin"); !errors.As(err, &se) {
+				t.Fatalf("Issue() err = %v, want ServiceError", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticateToken(t *testing.T) {
+	s, _ := newTestAPIKeyService(t)
+	issue := func(req model.APIKeyRequest) *model.IssuedAPIKey {
+		t.Helper()
+		k, err := s.Issue(req, "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	valid := issue(model.APIKeyRequest{Name: "billing", Role: model.RoleOperator, OLTs: []string{"olt-a"}, RateLimit: 50, ExpiresInDays: 30})
+	expired := issue(model.APIKeyRequest{Name: "lama", Role: model.RoleOperator})
+	past := time.Now().Add(-time.Minute)
+	s.keys[expired.ID].ExpiresAt = &past
+	revoked := issue(model.APIKeyRequest{Name: "dicabut", Role: model.RoleAdmin})
+	if _, err := s.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		user, err := s.AuthenticateToken(valid.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "apikey:billing" || user.Role != model.RoleOperator || user.AuthMethod != model.AuthAPIKey ||
+			user.TokenID != valid.ID || user.RateLimit != 50 || !user.CanAccessOLT("olt-a") || user.CanAccessOLT("olt-b") {
+			t.Fatalf("user = %+v", user)
+		}
+	})
+
+	// Secret salah dengan ID yang benar
+	forged := valid.Key[:len(valid.Key)-4] + "0000"
+	if forged == valid.Key {
+		forged = valid.Key[:len(valid.Key)-4] + "1111"
+	}
+	for name, token := range map[string]string{
+		"kedaluwarsa":      expired.Key,
+		"dicabut":          revoked.Key,
+		"id tidak dikenal": model.APIKeyPrefix + "000000000000_" + strings.Repeat("ab", 32),
+		"secret salah":     forged,
+		"tanpa prefix":     strings.TrimPrefix(valid.Key, model.APIKeyPrefix),
+		"tanpa secret":     model.APIKeyPrefix + valid.ID,
+		"kosong":           "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if user, err := s.AuthenticateToken(token); !errors.Is(err, ErrInvalidAPIKey) {
+				t.Fatalf("AuthenticateToken() = %+v, %v; want ErrInvalidAPIKey", user, err)
+			}
+		})
+	}
+
+	t.Run("revoke id tidak dikenal", func(t *testing.T) {
+		if _, err := s.Revoke("nope"); !errors.Is(err, ErrAPIKeyNotFound) {
+			t.Fatalf("Revoke() err = %v", err)
+		}
+	})
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/backup"
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/limiter"
+	"github.com/ardani/snmp-zte/internal/tftp"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCLIConnect dikembalikan saat koneksi Telnet ke OLT gagal
+var ErrCLIConnect = errors.New("CLI connection failed")
+
+// ErrTFTPDisabled dikembalikan saat server TFTP internal tidak diaktifkan
+var ErrTFTPDisabled = errors.New("embedded TFTP server is disabled")
+
+// BackupService mengambil running-config setiap OLT secara berkala dan
+// menyimpannya sebagai versi di backup store.
+type BackupService struct {
+	cfg    *config.Config
+	store  *backup.Store
+	limits *limiter.Limiter
+}
+
+// BackupResult hasil satu kali pengambilan backup
+type BackupResult struct {
+	Version backup.Version `json:"version"`
+	Created bool           `json:"created"` // false jika config sama dengan versi terakhir
+	Pruned  int            `json:"pruned"`
+}
+
+// NewBackupService membuat instance backup service baru.
+func NewBackupService(cfg *config.Config, store *backup.Store, limits *limiter.Limiter) *BackupService {
+	return &BackupService{
+		cfg:    cfg,
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Start menjalankan backup terjadwal sampai ctx dibatalkan.
+// Tidak melakukan apa pun jika backup tidak diaktifkan di konfigurasi.
+func (s *BackupService) Start(ctx context.Context) {
+	if !s.cfg.Backup.Enabled {
+		return
+	}
+
+	interval := time.Duration(s.cfg.Backup.IntervalMinutes) * time.Minute
+	log.Info().Dur("interval", interval).Str("dir", s.store.Dir()).Msg("Scheduled config backup enabled")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunAll mengambil backup semua OLT secara berurutan
+func (s *BackupService) RunAll(ctx context.Context) {
+	for _, olt := range s.cfg.OLTs {
+		if ctx.Err() != nil {
+			return
+		}
+		result, err := s.Capture(ctx, olt.ID, backup.SourceScheduled)
+		if err != nil {
+			log.Warn().Err(err).Str("olt_id", olt.ID).Msg("Scheduled config backup failed")
+			continue
+		}
+		log.Info().Str("olt_id", olt.ID).Int("version", result.Version.ID).Bool("created", result.Created).Msg("Config backup done")
+	}
+}
+
+// Capture mengambil running-config (dan startup-config untuk deteksi
+// perubahan yang belum disimpan) lalu menyimpannya sebagai versi baru.
+func (s *BackupService) Capture(ctx context.Context, oltID, source string) (*BackupResult, error) {
+	olt, err := s.cfg.GetOLT(oltID)
+	if err != nil {
+		return nil, ErrOLTNotFound
+	}
+
+	// Backup terjadwal ikut antre bersama sesi Telnet dari API
+	release, err := s.limits.AcquireTelnet(ctx, olt.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	client := cli.NewZTEC320Client(cliConfig(*olt))
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCLIConnect, err)
+	}
+	defer client.Close()
+
+	running, err := client.ShowRunningConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read running-config: %w", err)
+	}
+
+	// Startup-config opsional; jika gagal dibaca status unsaved tidak diketahui
+	var unsaved *bool
+	if startup, err := client.ShowStartupConfig(ctx); err == nil {
+		changed := normalizeConfig(running) != normalizeConfig(startup)
+		unsaved = &changed
+	}
+
+	version, created, err := s.store.Save(oltID, []byte(running), backup.Meta{Source: source, Unsaved: unsaved})
+	if err != nil {
+		return nil, err
+	}
+
+	pruned, err := s.store.Prune(oltID, s.retention())
+	if err != nil {
+		log.Warn().Err(err).Str("olt_id", oltID).Msg("Backup retention failed")
+	}
+
+	return &BackupResult{Version: version, Created: created, Pruned: pruned}, nil
+}
+
+// List mengembalikan semua versi backup milik OLT, terbaru lebih dulu
+func (s *BackupService) List(oltID string) ([]backup.Version, error) {
+	if _, err := s.cfg.GetOLT(oltID); err != nil {
+		return nil, ErrOLTNotFound
+	}
+	return s.store.List(oltID)
+}
+
+// Get mengembalikan satu versi backup beserta isinya
+func (s *BackupService) Get(oltID string, id int) (backup.Version, []byte, error) {
+	if _, err := s.cfg.GetOLT(oltID); err != nil {
+		return backup.Version{}, nil, ErrOLTNotFound
+	}
+	return s.store.Get(oltID, id)
+}
+
+// Diff membandingkan dua versi backup. to=0 berarti versi terbaru,
+// from=0 berarti versi tepat sebelum to.
+func (s *BackupService) Diff(oltID string, from, to int) (*backup.Diff, error) {
+	if _, err := s.cfg.GetOLT(oltID); err != nil {
+		return nil, ErrOLTNotFound
+	}
+
+	if to == 0 {
+		latest, err := s.store.Latest(oltID)
+		if err != nil {
+			return nil, err
+		}
+		to = latest.ID
+	}
+	if from == 0 {
+		versions, err := s.store.List(oltID)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions {
+			if v.ID < to {
+				from = v.ID
+				break
+			}
+		}
+		if from == 0 {
+			return nil, backup.ErrVersionNotFound
+		}
+	}
+
+	_, oldContent, err := s.store.Get(oltID, from)
+	if err != nil {
+		return nil, err
+	}
+	_, newContent, err := s.store.Get(oltID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := backup.Compare(string(oldContent), string(newContent), 3)
+	diff.From = from
+	diff.To = to
+	return &diff, nil
+}
+
+// Latest mengembalikan versi backup terbaru milik OLT
+func (s *BackupService) Latest(oltID string) (backup.Version, error) {
+	return s.store.Latest(oltID)
+}
+
+// OLTIDByHost mencari ID OLT terdaftar berdasarkan alamat IP-nya
+func (s *BackupService) OLTIDByHost(host string) (string, bool) {
+	olt, err := s.cfg.FindOLTByIP(host)
+	if err != nil {
+		return "", false
+	}
+	return olt.ID, true
+}
+
+// TFTPHost mengembalikan alamat server TFTP internal yang dipakai OLT.
+// localIP adalah IP lokal koneksi Telnet ke OLT, dipakai jika
+// advertise_host tidak diisi.
+func (s *BackupService) TFTPHost(localIP string) (string, error) {
+	if !s.cfg.TFTP.Enabled {
+		return "", ErrTFTPDisabled
+	}
+	if s.cfg.TFTP.AdvertiseHost != "" {
+		return s.cfg.TFTP.AdvertiseHost, nil
+	}
+	if localIP == "" {
+		return "", errors.New("cannot determine TFTP server address, set tftp.advertise_host")
+	}
+	return localIP, nil
+}
+
+// Resolve mencari versi backup milik oltID dari nama file: "latest", ID
+// versi, atau checksum sha256. Hanya versi di index OLT tersebut yang
+// dikenali, sehingga checksum config OLT lain tidak bisa dipakai.
+func (s *BackupService) Resolve(oltID, name string) (backup.Version, error) {
+	switch {
+	case name == "latest":
+		return s.store.Latest(oltID)
+	case backup.IsHash(name):
+		return s.store.FindByHash(oltID, name)
+	}
+	id, err := strconv.Atoi(name)
+	if err != nil {
+		return backup.Version{}, backup.ErrVersionNotFound
+	}
+	version, _, err := s.store.Get(oltID, id)
+	return version, err
+}
+
+// ReadFile melayani download TFTP (restore) dari backup store. Nama file:
+//
+//	{olt_id}/latest      versi terbaru OLT
+//	{olt_id}/{version}   versi tertentu
+//	{sha256}             versi OLT pengirim dengan checksum tersebut
+//
+// Config berisi kredensial, jadi OLT pengirim (dari IP) hanya boleh
+// mengunduh versi miliknya sendiri.
+func (s *BackupService) ReadFile(remote net.IP, filename string) ([]byte, error) {
+	sender, ok := s.OLTIDByHost(remote.String())
+	if !ok {
+		return nil, tftp.ErrAccessViolation
+	}
+
+	oltID, name := splitTFTPPath(filename)
+	if oltID != "" && oltID != sender {
+		log.Warn().Str("remote", remote.String()).Str("filename", filename).Msg("TFTP download of another OLT's backup rejected")
+		return nil, tftp.ErrAccessViolation
+	}
+
+	version, err := s.Resolve(sender, name)
+	if err != nil {
+		return nil, tftp.ErrFileNotFound
+	}
+	_, content, err := s.store.Get(sender, version.ID)
+	if err != nil {
+		return nil, tftp.ErrFileNotFound
+	}
+	return content, nil
+}
+
+// WriteFile menyimpan upload TFTP (backup dari OLT) sebagai versi baru milik
+// OLT pengirim (dari IP). Prefix "{olt_id}/" pada nama file boleh ada, tetapi
+// upload ditolak jika prefix menyebut OLT terdaftar lain.
+func (s *BackupService) WriteFile(remote net.IP, filename string, data []byte) error {
+	sender, ok := s.OLTIDByHost(remote.String())
+	if !ok {
+		return tftp.ErrAccessViolation
+	}
+
+	oltID, name := splitTFTPPath(filename)
+	if oltID != "" && oltID != sender {
+		if _, err := s.cfg.GetOLT(oltID); err == nil {
+			log.Warn().Str("remote", remote.String()).Str("filename", filename).Msg("TFTP upload for another OLT rejected")
+			return tftp.ErrAccessViolation
+		}
+	}
+	oltID = sender
+
+	version, created, err := s.store.Save(oltID, data, backup.Meta{Source: backup.SourceUpload, Filename: name})
+	if err != nil {
+		return err
+	}
+	if _, err := s.store.Prune(oltID, s.retention()); err != nil {
+		log.Warn().Err(err).Str("olt_id", oltID).Msg("Backup retention failed")
+	}
+
+	log.Info().Str("olt_id", oltID).Int("version", version.ID).Str("sha256", version.Hash).Bool("created", created).Msg("TFTP upload indexed")
+	return nil
+}
+
+// splitTFTPPath memecah "{olt_id}/{name}" (slash awal diabaikan)
+func splitTFTPPath(filename string) (oltID, name string) {
+	filename = strings.TrimPrefix(strings.ReplaceAll(filename, "\\", "/"), "/")
+	if i := strings.LastIndex(filename, "/"); i >= 0 {
+		return filename[:i], filename[i+1:]
+	}
+	return "", filename
+}
+
+func (s *BackupService) retention() backup.Retention {
+	return backup.Retention{
+		KeepVersions: s.cfg.Backup.KeepVersions,
+		MaxAge:       time.Duration(s.cfg.Backup.MaxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// cliConfig membentuk konfigurasi Telnet dari konfigurasi OLT
+func cliConfig(olt config.OLTConfig) cli.Config {
+	return cli.Config{
+		Host:     olt.IPAddress,
+		Port:     olt.CLIPort,
+		Username: olt.CLIUsername,
+		Password: olt.CLIPassword,
+	}
+}
+
+// normalizeConfig membuang baris yang berubah di setiap output (banner,
+// komentar waktu) agar running dan startup-config bisa dibandingkan.
+func normalizeConfig(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "!"),
+			strings.HasPrefix(line, "Building configuration"),
+			strings.HasPrefix(line, "Current configuration"):
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
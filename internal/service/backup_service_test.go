@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/backup"
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/tftp"
+)
+
+var (
+	ipA = net.IPv4(10, 0, 0, 1)
+	ipB = net.IPv4(10, 0, 0, 2)
+)
+
+func newTestBackupService(t *testing.T) (*BackupService, *backup.Store) {
+	t.Helper()
+	store, err := backup.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{OLTs: []config.OLTConfig{
+		{ID: "olt-a", IPAddress: ipA.String()},
+		{ID: "olt-b", IPAddress: ipB.String()},
+	}}
+	return NewBackupService(cfg, store, nil), store
+}
+
+func TestBackupTFTPWrite(t *testing.T) {
+	s, store := newTestBackupService(t)
+
+	tests := []struct {
+		name     string
+		remote   net.IP
+		filename string
+		wantErr  error
+		wantOLT  string
+	}{
+		{"tanpa prefix dari IP pengirim", ipA, "startrun.dat", nil, "olt-a"},
+		{"prefix OLT sendiri", ipB, "olt-b/startrun.dat", nil, "olt-b"},
+		{"prefix bukan OLT diabaikan", ipA, "backup/startrun.dat", nil, "olt-a"},
+		{"prefix OLT lain ditolak", ipA, "olt-b/evil.cfg", tftp.ErrAccessViolation, ""},
+		{"pengirim tidak terdaftar", net.IPv4(10, 0, 0, 9), "olt-a/evil.cfg", tftp.ErrAccessViolation, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte("hostname " + tt.name)
+			err := s.WriteFile(tt.remote, tt.filename, content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteFile() err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			latest, err := store.Latest(tt.wantOLT)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, got, _ := store.Get(tt.wantOLT, latest.ID); string(got) != string(content) {
+				t.Fatalf("latest %s = %q, want uploaded config", tt.wantOLT, got)
+			}
+		})
+	}
+}
+
+func TestBackupTFTPRead(t *testing.T) {
+	s, store := newTestBackupService(t)
+	va, _, err := store.Save("olt-a", []byte("hostname a"), backup.Meta{Source: backup.SourceManual})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vb, _, err := store.Save("olt-b", []byte("hostname b"), backup.Meta{Source: backup.SourceManual})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		remote   net.IP
+		filename string
+		want     string
+		wantErr  error
+	}{
+		{"latest milik sendiri", ipA, "olt-a/latest", "hostname a", nil},
+		{"versi milik sendiri", ipB, "olt-b/1", "hostname b", nil},
+		{"checksum milik sendiri", ipA, va.Hash, "hostname a", nil},
+		{"latest OLT lain", ipA, "olt-b/latest", "", tftp.ErrAccessViolation},
+		{"checksum OLT lain", ipA, vb.Hash, "", tftp.ErrFileNotFound},
+		{"pengirim tidak terdaftar", net.IPv4(10, 0, 0, 9), "olt-a/latest", "", tftp.ErrAccessViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ReadFile(tt.remote, tt.filename)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadFile() err = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("ReadFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/counter"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Penanda PON pada laporan kapasitas
+const (
+	FlagOversubscribed  = "oversubscribed"   // Jumlah bandwidth assured melebihi line rate
+	FlagFull            = "full"             // Tidak ada slot ONU kosong
+	FlagNearFull        = "near_full"        // Slot terpakai >= NearFullRatio
+	FlagHighUtilization = "high_utilization" // Utilisasi puncak >= HighUtilization
+	FlagLongReach       = "long_reach"       // Ada ONU lebih jauh dari LongReachMeters
+)
+
+// Ambang penanda kapasitas
+const (
+	NearFullRatio   = 0.9
+	HighUtilization = 80.0  // persen
+	LongReachMeters = 20000 // jangkauan logis GPON kelas B+
+)
+
+// CapacityOptions cakupan laporan kapasitas. Board wajib diisi: SLA setiap
+// ONU di seluruh OLT tidak terbaca dalam WriteTimeout.
+type CapacityOptions struct {
+	Board  int
+	Window time.Duration // Lama pengukuran trafik PON
+}
+
+// Validate memeriksa opsi dan mengisi nilai default
+func (o *CapacityOptions) Validate() error {
+	if o.Board == 0 {
+		return &ServiceError{Message: "board is required"}
+	}
+	if o.Window == 0 {
+		o.Window = DefaultTopWindow
+	}
+	if o.Window < MinTopWindow || o.Window > MaxTopWindow {
+		return &ServiceError{Message: fmt.Sprintf("window must be between %d and %d seconds", int(MinTopWindow.Seconds()), int(MaxTopWindow.Seconds()))}
+	}
+	return nil
+}
+
+// Capacity menyusun laporan kapasitas setiap PON di board: jumlah ONU
+// dibanding slot maksimum, jumlah bandwidth assured dibanding line rate,
+// utilisasi trafik selama opts.Window dan jarak ONU terjauh. Sampel trafik
+// pertama diambil sebelum daftar ONU dan SLA dibaca, sehingga window
+// berjalan bersamaan.
+func (s *ONUService) Capacity(ctx context.Context, oltID string, opts CapacityOptions) (*model.CapacityReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := ponPorts(d, opts.Board, 0)
+	if err != nil {
+		return nil, err
+	}
+	info := d.GetModelInfo()
+
+	meter := counter.NewMeter(counter.NewMemoryStore(), 0)
+	start := time.Now()
+	if _, err := s.samplePONs(ctx, oltID, d, meter, ports); err != nil {
+		return nil, err
+	}
+
+	pons := make([]model.PONCapacity, len(ports))
+	for i, p := range ports {
+		pons[i], err = s.ponCapacity(ctx, oltID, d, info, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case <-time.After(time.Until(start.Add(opts.Window))):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	rates, err := s.samplePONs(ctx, oltID, d, meter, ports)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range ports {
+		c := &pons[i]
+		if rate := rates[ponKey(p)]; rate != nil {
+			c.UpstreamBps = rate.RxBps
+			c.DownstreamBps = rate.TxBps
+			c.UpstreamUtilization = utilization(rate.RxBps, c.LineRateUpstream)
+			c.DownstreamUtilization = utilization(rate.TxBps, c.LineRateDownstream)
+			c.PeakUtilization = math.Max(c.UpstreamUtilization, c.DownstreamUtilization)
+		}
+		c.Flags = capacityFlags(*c)
+		c.Oversubscribed = slices.Contains(c.Flags, FlagOversubscribed)
+	}
+
+	return &model.CapacityReport{
+		OLTID:     oltID,
+		Window:    opts.Window.Seconds(),
+		PONs:      pons,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ponCapacity jumlah slot, SLA dan jarak satu PON. Daftar ONU dan slot
+// kosong diambil lewat cache; SLA dibaca per ONU dan yang gagal dilewati.
+func (s *ONUService) ponCapacity(ctx context.Context, oltID string, d driver.Driver, info driver.ModelInfo, p port) (model.PONCapacity, error) {
+	c := model.PONCapacity{
+		Board:              p.board,
+		PON:                p.pon,
+		MaxONU:             info.MaxOnuPerPon,
+		LineRateUpstream:   info.PonUpstreamKbps,
+		LineRateDownstream: info.PonDownstreamKbps,
+	}
+
+	slots, err := s.GetEmptySlots(ctx, oltID, p.board, p.pon)
+	if err != nil {
+		return c, err
+	}
+	c.FreeSlots = len(slots)
+	c.ONUCount = c.MaxONU - c.FreeSlots
+
+	onus, err := s.GetONUList(ctx, oltID, p.board, p.pon)
+	if err != nil {
+		return c, err
+	}
+	for _, onu := range onus {
+		if m, err := strconv.ParseFloat(strings.TrimSpace(onu.Distance), 64); err == nil && int(m) > c.MaxDistance {
+			c.MaxDistance = int(m)
+		}
+	}
+	if len(onus) == 0 {
+		return c, nil
+	}
+
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return c, err
+	}
+	defer release()
+	for _, onu := range onus {
+		bw, err := d.GetONUBandwidth(ctx, p.board, p.pon, onu.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return c, ctx.Err()
+			}
+			continue
+		}
+		c.AssuredUpstream += bw.AssuredUpstream
+		c.AssuredDownstream += bw.AssuredDownstream
+	}
+	return c, nil
+}
+
+// samplePONs membaca counter semua PON dalam satu giliran sesi SNMP dan
+// mengembalikan laju yang sudah bisa dihitung meter
+func (s *ONUService) samplePONs(ctx context.Context, oltID string, d driver.Driver, meter *counter.Meter, ports []port) (map[string]*model.TrafficRate, error) {
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rates := make(map[string]*model.TrafficRate)
+	for _, p := range ports {
+		stats, err := d.GetPonPortStats(ctx, p.board, p.pon)
+		if err := s.done(oltID, err); err != nil {
+			return nil, err
+		}
+		rate, err := meter.Traffic(ctx, ponKey(p), stats.Sample, stats.RxBytes, stats.TxBytes, stats.RxPackets, stats.TxPackets)
+		if err != nil {
+			return nil, err
+		}
+		if rate != nil {
+			rates[ponKey(p)] = rate
+		}
+	}
+	return rates, nil
+}
+
+// capacityFlags penanda untuk PON yang perlu diperhatikan saat perencanaan
+func capacityFlags(c model.PONCapacity) []string {
+	flags := []string{}
+	if (c.LineRateUpstream > 0 && c.AssuredUpstream > c.LineRateUpstream) ||
+		(c.LineRateDownstream > 0 && c.AssuredDownstream > c.LineRateDownstream) {
+		flags = append(flags, FlagOversubscribed)
+	}
+	switch {
+	case c.FreeSlots == 0:
+		flags = append(flags, FlagFull)
+	case c.MaxONU > 0 && float64(c.ONUCount) >= NearFullRatio*float64(c.MaxONU):
+		flags = append(flags, FlagNearFull)
+	}
+	if c.PeakUtilization >= HighUtilization {
+		flags = append(flags, FlagHighUtilization)
+	}
+	if c.MaxDistance > LongReachMeters {
+		flags = append(flags, FlagLongReach)
+	}
+	return flags
+}
+
+// utilization persentase laju (bps) terhadap line rate (kbps), 2 desimal
+func utilization(bps float64, lineKbps int64) float64 {
+	if lineKbps <= 0 {
+		return 0
+	}
+	return math.Round(bps/float64(lineKbps*1000)*10000) / 100
+}
+
+func ponKey(p port) string {
+	return fmt.Sprintf("%d/%d", p.board, p.pon)
+}
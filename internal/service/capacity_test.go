@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func TestCapacityFlags(t *testing.T) {
+	// PON C320: 128 slot, GPON 1.244/2.488 Gbps
+	base := model.PONCapacity{
+		MaxONU:             128,
+		ONUCount:           64,
+		FreeSlots:          64,
+		LineRateUpstream:   1244160,
+		LineRateDownstream: 2488320,
+		MaxDistance:        5000,
+	}
+	tests := []struct {
+		name   string
+		modify func(c *model.PONCapacity)
+		want   []string
+	}{
+		{"normal", func(c *model.PONCapacity) {}, []string{}},
+		{"assured upstream melebihi line rate", func(c *model.PONCapacity) { c.AssuredUpstream = 1244161 }, []string{FlagOversubscribed}},
+		{"assured downstream melebihi line rate", func(c *model.PONCapacity) { c.AssuredDownstream = 3000000 }, []string{FlagOversubscribed}},
+		{"assured sama dengan line rate", func(c *model.PONCapacity) { c.AssuredUpstream = 1244160 }, []string{}},
+		{"line rate tidak diketahui", func(c *model.PONCapacity) {
+			c.LineRateUpstream, c.LineRateDownstream, c.AssuredUpstream = 0, 0, 1<<40
+		}, []string{}},
+		{"penuh", func(c *model.PONCapacity) { c.ONUCount, c.FreeSlots = 128, 0 }, []string{FlagFull}},
+		{"hampir penuh 90%", func(c *model.PONCapacity) { c.ONUCount, c.FreeSlots = 116, 12 }, []string{FlagNearFull}},
+		{"di bawah 90%", func(c *model.PONCapacity) { c.ONUCount, c.FreeSlots = 115, 13 }, []string{}},
+		{"utilisasi tinggi", func(c *model.PONCapacity) { c.PeakUtilization = HighUtilization }, []string{FlagHighUtilization}},
+		{"jarak 20 km belum long reach", func(c *model.PONCapacity) { c.MaxDistance = LongReachMeters }, []string{}},
+		{"long reach", func(c *model.PONCapacity) { c.MaxDistance = LongReachMeters + 1 }, []string{FlagLongReach}},
+		{"semua penanda", func(c *model.PONCapacity) {
+			c.AssuredDownstream, c.ONUCount, c.FreeSlots, c.PeakUtilization, c.MaxDistance = 1<<30, 128, 0, 95, 25000
+		}, []string{FlagOversubscribed, FlagFull, FlagHighUtilization, FlagLongReach}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := base
+			tt.modify(&c)
+			if got := capacityFlags(c); !slices.Equal(got, tt.want) {
+				t.Fatalf("capacityFlags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		bps      float64
+		lineKbps int64
+		want     float64
+	}{
+		{0, 1244160, 0},
+		{622080000, 1244160, 50},
+		{1244160000, 1244160, 100},
+		{1000000, 2488320, 0.04},
+		{123456789, 1000000, 12.35},
+		{2000000000, 1000000, 200}, // Laju melebihi line rate tidak dipotong
+		{1000, 0, 0},
+		{1000, -1, 0},
+	}
+	for _, tt := range tests {
+		if got := utilization(tt.bps, tt.lineKbps); got != tt.want {
+			t.Errorf("utilization(%v, %d) = %v, want %v", tt.bps, tt.lineKbps, got, tt.want)
+		}
+	}
+}
+
+func TestCapacityOptionsValidate(t *testing.T) {
+	opts := CapacityOptions{Board: 1}
+	if err := opts.Validate(); err != nil || opts.Window != DefaultTopWindow {
+		t.Fatalf("Validate() = %v, window %v", err, opts.Window)
+	}
+	var se *ServiceError
+	for _, opts := range []CapacityOptions{
+		{},
+		{Board: 1, Window: time.Second},
+		{Board: 1, Window: 5 * time.Minute},
+	} {
+		if err := opts.Validate(); !errors.As(err, &se) {
+			t.Fatalf("Validate(%+v) = %v, want ServiceError", opts, err)
+		}
+	}
+}
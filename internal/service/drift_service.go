@@ -0,0 +1,484 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// DriftService membandingkan desired state dengan running-config OLT dan
+// menyusun perintah CLI untuk menyamakannya (reconcile).
+type DriftService struct{}
+
+// NewDriftService membuat instance drift service baru.
+func NewDriftService() *DriftService {
+	return &DriftService{}
+}
+
+// Compare membandingkan desired state dengan running-config hasil parsing.
+// Jika reconcile true, report juga berisi langkah-langkah perintah CLI
+// (setara dengan method ZTEC320Client) untuk menghilangkan drift.
+// Perintah tidak dijalankan; eksekusi tetap lewat endpoint write.
+func (s *DriftService) Compare(desired model.DesiredState, actual *cli.RunningConfig, reconcile bool) *model.DriftReport {
+	d := &driftBuilder{
+		report: &model.DriftReport{
+			Added:   []model.DriftItem{},
+			Removed: []model.DriftItem{},
+			Changed: []model.DriftItem{},
+		},
+		reconcile: reconcile,
+	}
+
+	d.compareVLANs(desired.VLANs, actual.VLANs)
+	d.compareTCONTProfiles(desired.TCONTProfiles, actual.TCONTProfiles)
+	d.compareProfiles("line", "CreateLineProfile", desired.LineProfiles, actual.Profiles)
+	d.compareProfiles("remote", "CreateRemoteProfile", desired.RemoteProfiles, actual.Profiles)
+
+	ports := make(map[[3]int]map[int]bool)
+	for _, onu := range desired.ONUs {
+		if onu.Rack == 0 {
+			onu.Rack = 1
+		}
+		if onu.Shelf == 0 {
+			onu.Shelf = 1
+		}
+		key := [3]int{onu.Rack, onu.Shelf, onu.Slot}
+		if ports[key] == nil {
+			ports[key] = make(map[int]bool)
+		}
+		ports[key][onu.ONUID] = true
+
+		d.compareONU(onu, actual.ONU(onu.Rack, onu.Shelf, onu.Slot, onu.ONUID), desired.Prune)
+	}
+
+	// ONU yang terdaftar di OLT tetapi tidak ada di desired state
+	if desired.Prune {
+		for _, olt := range actual.OLTInterfaces {
+			wanted, ok := ports[[3]int{olt.Rack, olt.Shelf, olt.Slot}]
+			if !ok {
+				continue
+			}
+			for _, onu := range olt.ONUs {
+				if wanted[onu.ID] {
+					continue
+				}
+				d.add(model.DriftRemoved, "onu", olt.Name, fmt.Sprintf("onu %d", onu.ID), nil, onu)
+				d.step("DeleteONU", oltCommands(olt.Rack, olt.Shelf, olt.Slot, fmt.Sprintf("no onu %d", onu.ID)))
+			}
+		}
+	}
+
+	r := d.report
+	r.InSync = len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+	return r
+}
+
+// driftBuilder menampung report yang sedang disusun.
+type driftBuilder struct {
+	report    *model.DriftReport
+	reconcile bool
+}
+
+func (d *driftBuilder) add(change, kind, iface, key string, desired, actual interface{}) {
+	item := model.DriftItem{
+		Change:    change,
+		Kind:      kind,
+		Interface: iface,
+		Key:       key,
+		Desired:   desired,
+		Actual:    actual,
+	}
+	switch change {
+	case model.DriftAdded:
+		d.report.Added = append(d.report.Added, item)
+	case model.DriftRemoved:
+		d.report.Removed = append(d.report.Removed, item)
+	default:
+		d.report.Changed = append(d.report.Changed, item)
+	}
+}
+
+func (d *driftBuilder) step(method string, commands []string) {
+	if d.reconcile {
+		d.report.Reconcile = append(d.report.Reconcile, model.ReconcileStep{
+			Method:   method,
+			Commands: commands,
+		})
+	}
+}
+
+func (d *driftBuilder) compareVLANs(desired []model.DesiredVLAN, actual []cli.ConfigVLAN) {
+	existing := make(map[int]cli.ConfigVLAN)
+	for _, v := range actual {
+		existing[v.ID] = v
+	}
+
+	for _, want := range desired {
+		key := fmt.Sprintf("vlan %d", want.ID)
+		got, ok := existing[want.ID]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "vlan", "", key, want, nil)
+		case want.Name != "" && want.Name != got.Name:
+			d.add(model.DriftChanged, "vlan", "", key, want, got)
+		default:
+			continue
+		}
+
+		commands := []string{"configure terminal", fmt.Sprintf("vlan %d", want.ID)}
+		if want.Name != "" {
+			commands = append(commands, fmt.Sprintf("name %s", want.Name))
+		}
+		d.step("CreateVLAN", append(commands, "exit", "exit"))
+	}
+}
+
+func (d *driftBuilder) compareTCONTProfiles(desired []model.DesiredTCONTProfile, actual []cli.ConfigTCONTProfile) {
+	existing := make(map[string]cli.ConfigTCONTProfile)
+	for _, p := range actual {
+		existing[p.Name] = p
+	}
+
+	for _, want := range desired {
+		key := fmt.Sprintf("profile tcont %s", want.Name)
+		got, ok := existing[want.Name]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "tcont_profile", "", key, want, nil)
+		case got.Type != want.Type || tcontBandwidth(got) != want.Bandwidth:
+			d.add(model.DriftChanged, "tcont_profile", "", key, want, got)
+		default:
+			continue
+		}
+
+		d.step("CreateTCONTProfile", []string{
+			"configure terminal",
+			fmt.Sprintf("profile tcont %s type %d bandwidth %d", want.Name, want.Type, want.Bandwidth),
+			"exit",
+		})
+	}
+}
+
+// tcontBandwidth mengambil bandwidth utama profile sesuai tipenya.
+func tcontBandwidth(p cli.ConfigTCONTProfile) int {
+	switch {
+	case p.Maximum > 0:
+		return p.Maximum
+	case p.Assured > 0:
+		return p.Assured
+	}
+	return p.Fixed
+}
+
+// compareProfiles membandingkan onu-profile gpon line/remote. Isi profile
+// hanya dibandingkan jika desired menyebut Lines; reconcile menghapus baris
+// yang tidak diinginkan lalu menambahkan yang belum ada.
+func (d *driftBuilder) compareProfiles(kind, method string, desired []model.DesiredProfile, actual []cli.ConfigProfile) {
+	existing := make(map[string]cli.ConfigProfile)
+	for _, p := range actual {
+		if p.Kind == kind {
+			existing[p.Name] = p
+		}
+	}
+
+	for _, want := range desired {
+		header := fmt.Sprintf("onu-profile gpon %s %s", kind, want.Name)
+		got, ok := existing[want.Name]
+		wantLines := profileLines(want.Lines)
+		gotLines := profileLines(got.Lines)
+
+		var lines []string
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, kind+"_profile", "", header, want, nil)
+			lines = wantLines
+		case len(wantLines) > 0 && !slices.Equal(wantLines, gotLines):
+			d.add(model.DriftChanged, kind+"_profile", "", header, want, got)
+			for _, line := range gotLines {
+				if !slices.Contains(wantLines, line) {
+					lines = append(lines, "no "+line)
+				}
+			}
+			for _, line := range wantLines {
+				if !slices.Contains(gotLines, line) {
+					lines = append(lines, line)
+				}
+			}
+		default:
+			continue
+		}
+
+		commands := append([]string{"configure terminal", header}, lines...)
+		d.step(method, append(commands, "commit", "exit", "exit"))
+	}
+}
+
+// profileLines menormalkan spasi dan mengurutkan baris profile; "commit"
+// bukan bagian dari isi profile.
+func profileLines(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" && line != "commit" {
+			out = append(out, line)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (d *driftBuilder) compareONU(want model.DesiredONU, got *cli.ONUConfig, prune bool) {
+	oltIface := fmt.Sprintf("gpon-olt_%d/%d/%d", want.Rack, want.Shelf, want.Slot)
+	onuIface := fmt.Sprintf("gpon-onu_%d/%d/%d:%d", want.Rack, want.Shelf, want.Slot, want.ONUID)
+	key := fmt.Sprintf("onu %d", want.ONUID)
+
+	if got == nil || got.Registration == nil {
+		d.add(model.DriftAdded, "onu", oltIface, key, maskONU(want), nil)
+		d.provisionONU(want)
+		return
+	}
+
+	// Type/autentikasi berbeda: ONU harus didaftarkan ulang, konfigurasi lama
+	// ikut hilang
+	if got.Registration.Type != want.Type || !sameAuth(want, got.Registration) {
+		d.add(model.DriftChanged, "onu", oltIface, key, maskONU(want), got.Registration)
+		d.step("DeleteONU", oltCommands(want.Rack, want.Shelf, want.Slot, fmt.Sprintf("no onu %d", want.ONUID)))
+		d.provisionONU(want)
+		return
+	}
+
+	iface := got.Interface
+	if iface == nil {
+		iface = &cli.ConfigONUInterface{}
+	}
+
+	if want.Name != "" && want.Name != iface.ONUName {
+		d.add(model.DriftChanged, "name", onuIface, "name", want.Name, iface.ONUName)
+		d.step("RenameONU", oltCommands(want.Rack, want.Shelf, want.Slot, fmt.Sprintf("onu %d name %s", want.ONUID, want.Name)))
+	}
+
+	tconts := make(map[int]cli.ConfigTCONT)
+	for _, t := range iface.TCONTs {
+		tconts[t.ID] = t
+	}
+	for _, t := range want.TCONTs {
+		key := fmt.Sprintf("tcont %d", t.ID)
+		actual, ok := tconts[t.ID]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "tcont", onuIface, key, t, nil)
+		case actual.Profile != t.Profile:
+			d.add(model.DriftChanged, "tcont", onuIface, key, t, actual)
+		default:
+			continue
+		}
+		d.step("CreateTCONT", onuCommands(want, tcontCommand(t)))
+	}
+
+	gemports := make(map[int]cli.ConfigGEMPort)
+	for _, g := range iface.GEMPorts {
+		gemports[g.ID] = g
+	}
+	for _, g := range want.GEMPorts {
+		key := fmt.Sprintf("gemport %d", g.ID)
+		actual, ok := gemports[g.ID]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "gemport", onuIface, key, g, nil)
+		case actual.TCONT != g.TCONT:
+			d.add(model.DriftChanged, "gemport", onuIface, key, g, actual)
+		default:
+			continue
+		}
+		d.step("CreateGEMPort", onuCommands(want, gemportCommand(g)))
+	}
+
+	servicePorts := make(map[int]cli.ConfigServicePort)
+	for _, sp := range iface.ServicePorts {
+		servicePorts[sp.ID] = sp
+	}
+	wanted := make(map[int]bool)
+	for _, sp := range want.ServicePorts {
+		if sp.UserVLAN == 0 {
+			sp.UserVLAN = sp.VLAN
+		}
+		wanted[sp.ID] = true
+		key := fmt.Sprintf("service-port %d", sp.ID)
+		actual, ok := servicePorts[sp.ID]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "service_port", onuIface, key, sp, nil)
+		case actual.Vport != sp.Vport || actual.VLAN != sp.VLAN || actual.UserVLAN != sp.UserVLAN:
+			d.add(model.DriftChanged, "service_port", onuIface, key, sp, actual)
+			d.step("DeleteServicePort", onuCommands(want, fmt.Sprintf("no service-port %d", sp.ID)))
+		default:
+			continue
+		}
+		d.step("CreateServicePort", onuCommands(want, servicePortCommand(sp)))
+	}
+
+	mng := got.Management
+	if mng == nil {
+		mng = &cli.ConfigONUManagement{}
+	}
+	mngIface := "pon-onu-mng " + onuIface
+
+	services := make(map[string]cli.ConfigONUService)
+	for _, svc := range mng.Services {
+		services[svc.Name] = svc
+	}
+	for _, svc := range want.Services {
+		key := fmt.Sprintf("service %s", svc.Name)
+		actual, ok := services[svc.Name]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "onu_service", mngIface, key, svc, nil)
+		case actual.GEMPort != svc.GEMPort || actual.VLAN != svc.VLAN:
+			d.add(model.DriftChanged, "onu_service", mngIface, key, svc, actual)
+		default:
+			continue
+		}
+		d.step("SetONUService", mngCommands(want, onuServiceCommand(svc)))
+	}
+
+	vlanPorts := make(map[string]cli.ConfigONUVLANPort)
+	for _, vp := range mng.VLANPorts {
+		vlanPorts[vp.Port] = vp
+	}
+	for _, vp := range want.VLANPorts {
+		key := fmt.Sprintf("vlan port %s", vp.Port)
+		actual, ok := vlanPorts[vp.Port]
+		switch {
+		case !ok:
+			d.add(model.DriftAdded, "vlan_port", mngIface, key, vp, nil)
+		case !strings.EqualFold(actual.Mode, vp.Mode) || actual.VLAN != vp.VLAN:
+			d.add(model.DriftChanged, "vlan_port", mngIface, key, vp, actual)
+		default:
+			continue
+		}
+		d.step("SetONUVLANPort", mngCommands(want, vlanPortCommand(vp)))
+	}
+
+	if prune {
+		for _, sp := range iface.ServicePorts {
+			if wanted[sp.ID] {
+				continue
+			}
+			d.add(model.DriftRemoved, "service_port", onuIface, fmt.Sprintf("service-port %d", sp.ID), nil, sp)
+			d.step("DeleteServicePort", onuCommands(want, fmt.Sprintf("no service-port %d", sp.ID)))
+		}
+	}
+}
+
+// sameAuth true jika mode dan nilai autentikasi ONU sama. SN dibandingkan
+// tanpa memperhatikan huruf besar/kecil; password/LOID harus persis sama.
+func sameAuth(want model.DesiredONU, got *cli.ConfigONU) bool {
+	mode, value := want.Auth()
+	if !strings.EqualFold(got.AuthMode, mode) {
+		return false
+	}
+	if mode == model.ONUAuthSN {
+		return strings.EqualFold(got.SN, value)
+	}
+	return got.Password == value
+}
+
+// maskONU menyembunyikan password/LOID di item report
+func maskONU(onu model.DesiredONU) model.DesiredONU {
+	onu.Password = maskSecret(onu.Password)
+	return onu
+}
+
+// provisionONU menyusun semua langkah untuk mendaftarkan ONU dari nol.
+func (d *driftBuilder) provisionONU(want model.DesiredONU) {
+	mode, value := want.Auth()
+	d.step("AuthenticateONU", oltCommands(want.Rack, want.Shelf, want.Slot,
+		fmt.Sprintf("onu %d type %s %s %s", want.ONUID, want.Type, mode, value)))
+	if want.Name != "" {
+		d.step("RenameONU", oltCommands(want.Rack, want.Shelf, want.Slot,
+			fmt.Sprintf("onu %d name %s", want.ONUID, want.Name)))
+	}
+	for _, t := range want.TCONTs {
+		d.step("CreateTCONT", onuCommands(want, tcontCommand(t)))
+	}
+	for _, g := range want.GEMPorts {
+		d.step("CreateGEMPort", onuCommands(want, gemportCommand(g)))
+	}
+	for _, sp := range want.ServicePorts {
+		if sp.UserVLAN == 0 {
+			sp.UserVLAN = sp.VLAN
+		}
+		d.step("CreateServicePort", onuCommands(want, servicePortCommand(sp)))
+	}
+	for _, svc := range want.Services {
+		d.step("SetONUService", mngCommands(want, onuServiceCommand(svc)))
+	}
+	for _, vp := range want.VLANPorts {
+		d.step("SetONUVLANPort", mngCommands(want, vlanPortCommand(vp)))
+	}
+}
+
+func tcontCommand(t model.DesiredTCONT) string {
+	if t.Name == "" {
+		return fmt.Sprintf("tcont %d profile %s", t.ID, t.Profile)
+	}
+	return fmt.Sprintf("tcont %d name %s profile %s", t.ID, t.Name, t.Profile)
+}
+
+func gemportCommand(g model.DesiredGEMPort) string {
+	if g.Name == "" {
+		return fmt.Sprintf("gemport %d unicast tcont %d", g.ID, g.TCONT)
+	}
+	return fmt.Sprintf("gemport %d name %s unicast tcont %d", g.ID, g.Name, g.TCONT)
+}
+
+func servicePortCommand(sp model.DesiredServicePort) string {
+	return fmt.Sprintf("service-port %d vport %d user-vlan %d vlan %d", sp.ID, sp.Vport, sp.UserVLAN, sp.VLAN)
+}
+
+func onuServiceCommand(svc model.DesiredONUService) string {
+	if svc.VLAN == 0 {
+		return fmt.Sprintf("service %s gemport %d", svc.Name, svc.GEMPort)
+	}
+	return fmt.Sprintf("service %s gemport %d vlan %d", svc.Name, svc.GEMPort, svc.VLAN)
+}
+
+func vlanPortCommand(vp model.DesiredONUVLANPort) string {
+	if vp.VLAN == 0 {
+		return fmt.Sprintf("vlan port %s mode %s", vp.Port, vp.Mode)
+	}
+	return fmt.Sprintf("vlan port %s mode %s vlan %d", vp.Port, vp.Mode, vp.VLAN)
+}
+
+// oltCommands membungkus perintah dengan mode interface gpon-olt.
+func oltCommands(rack, shelf, slot int, cmds ...string) []string {
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("interface gpon-olt_%d/%d/%d", rack, shelf, slot),
+	}
+	commands = append(commands, cmds...)
+	return append(commands, "exit", "exit")
+}
+
+// onuCommands membungkus perintah dengan mode interface gpon-onu.
+func onuCommands(onu model.DesiredONU, cmds ...string) []string {
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("interface gpon-onu_%d/%d/%d:%d", onu.Rack, onu.Shelf, onu.Slot, onu.ONUID),
+	}
+	commands = append(commands, cmds...)
+	return append(commands, "exit", "exit")
+}
+
+// mngCommands membungkus perintah dengan mode pon-onu-mng.
+func mngCommands(onu model.DesiredONU, cmds ...string) []string {
+	commands := []string{
+		"configure terminal",
+		fmt.Sprintf("pon-onu-mng gpon-onu_%d/%d/%d:%d", onu.Rack, onu.Shelf, onu.Slot, onu.ONUID),
+	}
+	commands = append(commands, cmds...)
+	return append(commands, "exit", "exit")
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+const driftRunningConfig = `vlan 143
+  name INTERNET
+!
+gpon
+  profile tcont UP-100M type 4 maximum 102400
+!
+onu-profile gpon line LP-100M
+  tcont 1 profile UP-100M
+  gemport 1 tcont 1
+!
+interface gpon-olt_1/1/1
+  onu 1 type ZTE-F660 sn ZTEGC8A31F02
+  onu 2 type ALL-ONT sn HWTC1F14CAAD
+  onu 3 type ZTE-F660 pw 12345678
+!
+interface gpon-onu_1/1/1:1
+  name pelanggan-001
+  tcont 1 profile UP-100M
+  gemport 1 tcont 1
+  service-port 1 vport 1 user-vlan 143 vlan 143
+  service-port 2 vport 2 user-vlan 200 vlan 200
+!
+pon-onu-mng gpon-onu_1/1/1:1
+  service INTERNET gemport 1 vlan 143
+  vlan port eth_0/1 mode tag vlan 143
+!
+end`
+
+// driftDesired desired state yang sama dengan driftRunningConfig kecuali
+// service-port 2 dan ONU 2 (hanya terlihat jika prune)
+func driftDesired() model.DesiredState {
+	return model.DesiredState{
+		VLANs:         []model.DesiredVLAN{{ID: 143, Name: "INTERNET"}},
+		TCONTProfiles: []model.DesiredTCONTProfile{{Name: "UP-100M", Type: 4, Bandwidth: 102400}},
+		LineProfiles:  []model.DesiredProfile{{Name: "LP-100M", Lines: []string{"gemport 1  tcont 1", "tcont 1 profile UP-100M"}}},
+		ONUs: []model.DesiredONU{{
+			Slot:         1,
+			ONUID:        1,
+			Type:         "ZTE-F660",
+			SN:           "ZTEGC8A31F02",
+			Name:         "pelanggan-001",
+			TCONTs:       []model.DesiredTCONT{{ID: 1, Profile: "UP-100M"}},
+			GEMPorts:     []model.DesiredGEMPort{{ID: 1, TCONT: 1}},
+			ServicePorts: []model.DesiredServicePort{{ID: 1, Vport: 1, VLAN: 143}},
+			Services:     []model.DesiredONUService{{Name: "INTERNET", GEMPort: 1, VLAN: 143}},
+			VLANPorts:    []model.DesiredONUVLANPort{{Port: "eth_0/1", Mode: "tag", VLAN: 143}},
+		}, {
+			Slot:     1,
+			ONUID:    3,
+			Type:     "ZTE-F660",
+			AuthMode: model.ONUAuthPW,
+			Password: "12345678",
+		}},
+	}
+}
+
+func TestDriftCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(d *model.DesiredState)
+		reconcile   bool
+		wantItems   []string // "{change} {kind} {key}"
+		wantSteps   []string // Method tiap langkah reconcile
+		wantCommand string   // Perintah yang harus ada di salah satu langkah
+	}{
+		{
+			name:      "sinkron",
+			modify:    func(d *model.DesiredState) {},
+			reconcile: true,
+		},
+		{
+			name: "vlan dan onu baru",
+			modify: func(d *model.DesiredState) {
+				d.VLANs = append(d.VLANs, model.DesiredVLAN{ID: 200, Name: "VOIP"})
+				d.ONUs = append(d.ONUs, model.DesiredONU{
+					Slot: 1, ONUID: 4, Type: "ZTE-F660", SN: "ZTEGC0000004",
+					ServicePorts: []model.DesiredServicePort{{ID: 1, Vport: 1, VLAN: 200}},
+				})
+			},
+			reconcile:   true,
+			wantItems:   []string{"added vlan vlan 200", "added onu onu 4"},
+			wantSteps:   []string{"CreateVLAN", "AuthenticateONU", "CreateServicePort"},
+			wantCommand: "service-port 1 vport 1 user-vlan 200 vlan 200",
+		},
+		{
+			name: "nilai berubah",
+			modify: func(d *model.DesiredState) {
+				d.VLANs[0].Name = "INET"
+				d.TCONTProfiles[0].Bandwidth = 51200
+				d.ONUs[0].Name = "pelanggan-baru"
+				d.ONUs[0].ServicePorts[0].VLAN = 150
+			},
+			reconcile: true,
+			wantItems: []string{
+				"changed vlan vlan 143",
+				"changed tcont_profile profile tcont UP-100M",
+				"changed name name",
+				"changed service_port service-port 1",
+			},
+			wantSteps:   []string{"CreateVLAN", "CreateTCONTProfile", "RenameONU", "DeleteServicePort", "CreateServicePort"},
+			wantCommand: "profile tcont UP-100M type 4 bandwidth 51200",
+		},
+		{
+			name: "sn berbeda didaftarkan ulang",
+			modify: func(d *model.DesiredState) {
+				d.ONUs[0].SN = "ZTEGC9999999"
+			},
+			reconcile:   true,
+			wantItems:   []string{"changed onu onu 1"},
+			wantSteps:   []string{"DeleteONU", "AuthenticateONU", "RenameONU", "CreateTCONT", "CreateGEMPort", "CreateServicePort", "SetONUService", "SetONUVLANPort"},
+			wantCommand: "onu 1 type ZTE-F660 sn ZTEGC9999999",
+		},
+		{
+			name: "sn huruf kecil tetap sinkron",
+			modify: func(d *model.DesiredState) {
+				d.ONUs[0].SN = "ztegc8a31f02"
+			},
+			reconcile: true,
+		},
+		{
+			name: "password pw berbeda didaftarkan ulang",
+			modify: func(d *model.DesiredState) {
+				d.ONUs[1].Password = "87654321"
+			},
+			reconcile:   true,
+			wantItems:   []string{"changed onu onu 3"},
+			wantSteps:   []string{"DeleteONU", "AuthenticateONU"},
+			wantCommand: "onu 3 type ZTE-F660 pw 87654321",
+		},
+		{
+			name: "mode autentikasi berbeda",
+			modify: func(d *model.DesiredState) {
+				d.ONUs[1].AuthMode = model.ONUAuthLOID
+			},
+			reconcile:   true,
+			wantItems:   []string{"changed onu onu 3"},
+			wantSteps:   []string{"DeleteONU", "AuthenticateONU"},
+			wantCommand: "onu 3 type ZTE-F660 loid 12345678",
+		},
+		{
+			name: "line profile berubah dan remote profile baru",
+			modify: func(d *model.DesiredState) {
+				d.LineProfiles[0].Lines = []string{"tcont 1 profile UP-50M", "gemport 1 tcont 1"}
+				d.RemoteProfiles = []model.DesiredProfile{{Name: "RP-BRIDGE", Lines: []string{"service INTERNET gemport 1 vlan 143"}}}
+			},
+			reconcile: true,
+			wantItems: []string{
+				"changed line_profile onu-profile gpon line LP-100M",
+				"added remote_profile onu-profile gpon remote RP-BRIDGE",
+			},
+			wantSteps:   []string{"CreateLineProfile", "CreateRemoteProfile"},
+			wantCommand: "no tcont 1 profile UP-100M",
+		},
+		{
+			name: "line profile tanpa lines hanya diperiksa keberadaannya",
+			modify: func(d *model.DesiredState) {
+				d.LineProfiles[0].Lines = nil
+			},
+			reconcile: true,
+		},
+		{
+			name: "pon-onu-mng service dan vlan port",
+			modify: func(d *model.DesiredState) {
+				d.ONUs[0].Services[0].VLAN = 150
+				d.ONUs[0].VLANPorts = append(d.ONUs[0].VLANPorts, model.DesiredONUVLANPort{Port: "eth_0/2", Mode: "transparent"})
+			},
+			reconcile: true,
+			wantItems: []string{
+				"changed onu_service service INTERNET",
+				"added vlan_port vlan port eth_0/2",
+			},
+			wantSteps:   []string{"SetONUService", "SetONUVLANPort"},
+			wantCommand: "vlan port eth_0/2 mode transparent",
+		},
+		{
+			name: "prune",
+			modify: func(d *model.DesiredState) {
+				d.Prune = true
+			},
+			reconcile:   true,
+			wantItems:   []string{"removed service_port service-port 2", "removed onu onu 2"},
+			wantSteps:   []string{"DeleteServicePort", "DeleteONU"},
+			wantCommand: "no onu 2",
+		},
+		{
+			name: "tanpa reconcile",
+			modify: func(d *model.DesiredState) {
+				d.Prune = true
+				d.VLANs[0].Name = "INET"
+			},
+			wantItems: []string{"changed vlan vlan 143", "removed service_port service-port 2", "removed onu onu 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := driftDesired()
+			tt.modify(&desired)
+			report := NewDriftService().Compare(desired, cli.ParseRunningConfig(driftRunningConfig), tt.reconcile)
+
+			var items []string
+			for _, list := range [][]model.DriftItem{report.Added, report.Changed, report.Removed} {
+				for _, item := range list {
+					items = append(items, fmt.Sprintf("%s %s %s", item.Change, item.Kind, item.Key))
+				}
+			}
+			if !sameItems(items, tt.wantItems) {
+				t.Fatalf("items = %q, want %q", items, tt.wantItems)
+			}
+			if report.InSync != (len(tt.wantItems) == 0) {
+				t.Fatalf("in_sync = %v with %d items", report.InSync, len(items))
+			}
+
+			var steps []string
+			var commands []string
+			for _, s := range report.Reconcile {
+				steps = append(steps, s.Method)
+				commands = append(commands, s.Commands...)
+			}
+			if !slices.Equal(steps, tt.wantSteps) {
+				t.Fatalf("steps = %q, want %q", steps, tt.wantSteps)
+			}
+			if tt.wantCommand != "" && !slices.Contains(commands, tt.wantCommand) {
+				t.Fatalf("commands = %s, want %q", strings.Join(commands, "; "), tt.wantCommand)
+			}
+		})
+	}
+}
+
+// TestDriftMasksONUPassword memastikan password/LOID dari desired state
+// tidak ikut di item report (perintah reconcile tetap membutuhkannya).
+func TestDriftMasksONUPassword(t *testing.T) {
+	desired := driftDesired()
+	desired.ONUs[1].Password = "87654321"
+	report := NewDriftService().Compare(desired, cli.ParseRunningConfig(driftRunningConfig), false)
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"87654321", "12345678"} {
+		if strings.Contains(string(out), secret) {
+			t.Fatalf("report exposes %q: %s", secret, out)
+		}
+	}
+}
+
+// sameItems membandingkan tanpa memperhatikan urutan antar kategori
+func sameItems(got, want []string) bool {
+	got, want = slices.Clone(got), slices.Clone(want)
+	slices.Sort(got)
+	slices.Sort(want)
+	return slices.Equal(got, want)
+}
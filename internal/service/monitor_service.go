@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/config"
+	"github.com/ardani/snmp-zte/internal/history"
+	"github.com/ardani/snmp-zte/internal/incident"
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrMonitorDisabled dikembalikan saat data stabilitas diminta tetapi
+// pemantauan status ONU tidak diaktifkan
+var ErrMonitorDisabled = errors.New("ONU monitor is disabled (monitor.enabled)")
+
+// ErrNotObserved dikembalikan saat ONU belum pernah terlihat oleh pemantauan
+var ErrNotObserved = errors.New("ONU has not been observed by the monitor")
+
+// Rentang analisis stabilitas
+const (
+	DefaultStabilityWindow = 7 * 24 * time.Hour
+	MinStabilityWindow     = time.Hour
+)
+
+// compactInterval jarak antar pembersihan riwayat di luar retensi
+const compactInterval = 24 * time.Hour
+
+// MonitorService memantau status semua ONU secara berkala, mencatat setiap
+// perubahan status ke riwayat, menghitung stabilitas dari riwayat itu, dan
+// menggabungkan ONU yang turun serentak menjadi insiden.
+type MonitorService struct {
+	cfg       *config.Config
+	onus      *ONUService
+	store     *history.Store
+	incidents *incident.Store
+	detector  *incident.Detector
+}
+
+// NewMonitorService membuat instance monitor service baru. Poll memakai
+// driver, batas sesi dan circuit breaker milik onus.
+func NewMonitorService(cfg *config.Config, onus *ONUService, store *history.Store, incidents *incident.Store) *MonitorService {
+	outage := cfg.Monitor.Outage
+	return &MonitorService{
+		cfg:       cfg,
+		onus:      onus,
+		store:     store,
+		incidents: incidents,
+		detector: incident.NewDetector(incident.Config{
+			Window:       time.Duration(outage.WindowSeconds) * time.Second,
+			MinONUs:      outage.MinONUs,
+			MinRatio:     outage.MinRatio,
+			ResolveRatio: outage.ResolveRatio,
+		}, incidents),
+	}
+}
+
+// Start menjalankan poll terjadwal sampai ctx dibatalkan.
+// Tidak melakukan apa pun jika pemantauan tidak diaktifkan di konfigurasi.
+func (s *MonitorService) Start(ctx context.Context) {
+	if !s.cfg.Monitor.Enabled {
+		return
+	}
+
+	interval := time.Duration(s.cfg.Monitor.IntervalSeconds) * time.Second
+	log.Info().Dur("interval", interval).Msg("ONU status monitor enabled")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var compacted time.Time
+	for {
+		s.PollAll(ctx)
+		if time.Since(compacted) >= compactInterval {
+			if n, err := s.store.Compact(time.Now()); err != nil {
+				log.Warn().Err(err).Msg("ONU history compaction failed")
+			} else if n > 0 {
+				log.Info().Int("removed", n).Msg("ONU history compacted")
+			}
+			compacted = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollAll membaca status ONU semua OLT secara berurutan
+func (s *MonitorService) PollAll(ctx context.Context) {
+	for _, olt := range s.cfg.OLTs {
+		if ctx.Err() != nil {
+			return
+		}
+		events, err := s.Poll(ctx, olt.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("olt_id", olt.ID).Msg("ONU status poll failed")
+			continue
+		}
+		if len(events) > 0 {
+			log.Info().Str("olt_id", olt.ID).Int("changes", len(events)).Msg("ONU status changed")
+		}
+	}
+}
+
+// Poll membaca status semua ONU di satu OLT dalam satu giliran sesi SNMP
+// (satu walk per PON), mencatat perubahannya, dan mengembalikan event baru.
+// Alasan offline dibaca hanya untuk ONU yang baru turun. Event yang sudah
+// tercatat tetap diteruskan ke deteksi insiden walaupun poll gagal di
+// tengah jalan.
+func (s *MonitorService) Poll(ctx context.Context, oltID string) ([]history.Event, error) {
+	d, err := s.onus.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := ponPorts(d, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.onus.acquire(ctx, oltID, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var all []history.Event
+	online := make(map[incident.Port]int)
+	var pollErr error
+	for _, p := range ports {
+		statuses, err := d.GetONUStatuses(ctx, p.board, p.pon)
+		if err := s.onus.done(oltID, err); err != nil {
+			pollErr = err
+			break
+		}
+		events := s.store.Observe(oltID, p.board, p.pon, statuses, time.Now().UTC())
+		for _, status := range statuses {
+			if status == model.StatusOnline.String() {
+				online[incident.Port{Board: p.board, PON: p.pon}]++
+			}
+		}
+
+		var down []int
+		for _, e := range events {
+			if e.Down() {
+				down = append(down, e.ONUID)
+			}
+		}
+		if len(down) > 0 {
+			// Alasan offline best-effort: event tetap dicatat tanpa alasan
+			if reasons, err := d.GetONUOfflineReasons(ctx, p.board, p.pon, down); err == nil {
+				for i := range events {
+					if events[i].Down() {
+						events[i].Reason = reasons[events[i].ONUID]
+					}
+				}
+			}
+		}
+
+		if err := s.store.Append(events); err != nil {
+			pollErr = err
+			break
+		}
+		all = append(all, events...)
+	}
+
+	// Status kartu dibaca hanya saat insiden diklasifikasi, best-effort
+	boardStatus := func(board int) string {
+		info, err := d.GetBoardInfo(ctx, board)
+		if err != nil {
+			return ""
+		}
+		return info.Status
+	}
+	opened, resolved, err := s.detector.Observe(oltID, all, online, time.Now().UTC(), boardStatus)
+	if err != nil {
+		log.Warn().Err(err).Str("olt_id", oltID).Msg("Failed to save incident")
+	}
+	for _, inc := range opened {
+		log.Warn().Str("olt_id", oltID).Int64("incident_id", inc.ID).Str("type", inc.Type).
+			Str("scope", inc.Scope).Int("affected", inc.Affected).Msg("Outage detected")
+	}
+	for _, inc := range resolved {
+		log.Info().Str("olt_id", oltID).Int64("incident_id", inc.ID).Str("type", inc.Type).
+			Dur("duration", inc.ResolvedAt.Sub(inc.StartedAt)).Msg("Outage resolved")
+	}
+	return all, pollErr
+}
+
+// Incidents daftar insiden yang cocok dengan filter, terbaru lebih dulu
+func (s *MonitorService) Incidents(f incident.Filter) ([]incident.Incident, error) {
+	if !s.cfg.Monitor.Enabled {
+		return nil, ErrMonitorDisabled
+	}
+	switch f.Status {
+	case "", incident.StatusOpen, incident.StatusResolved:
+	default:
+		return nil, &ServiceError{Message: "status must be open or resolved"}
+	}
+	switch f.Type {
+	case "", incident.TypeFiberCut, incident.TypePowerOutage, incident.TypeCardFault, incident.TypeUnknown:
+	default:
+		return nil, &ServiceError{Message: "type must be fiber_cut, power_outage, card_fault or unknown"}
+	}
+	if f.Limit < 0 || f.Limit > incident.MaxLimit {
+		return nil, &ServiceError{Message: fmt.Sprintf("limit must be between 1 and %d", incident.MaxLimit)}
+	}
+	if f.OLTID != "" {
+		if _, err := s.onus.getDriver(f.OLTID); err != nil {
+			return nil, err
+		}
+	}
+	return s.incidents.List(f), nil
+}
+
+// Incident satu insiden berdasarkan ID
+func (s *MonitorService) Incident(id int64) (*incident.Incident, error) {
+	if !s.cfg.Monitor.Enabled {
+		return nil, ErrMonitorDisabled
+	}
+	inc, err := s.incidents.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &inc, nil
+}
+
+// Stability menyusun laporan stabilitas ONU per PON dalam rentang window
+// terakhir. Hanya ONU yang terlihat pada poll terakhir yang dilaporkan.
+func (s *MonitorService) Stability(oltID string, board, pon int, window time.Duration) (*model.StabilityReport, error) {
+	window, from, to, err := s.window(window)
+	if err != nil {
+		return nil, err
+	}
+	if pon != 0 && board == 0 {
+		return nil, &ServiceError{Message: "pon requires board"}
+	}
+	d, err := s.onus.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := ponPorts(d, board, pon)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.StabilityReport{
+		OLTID:    oltID,
+		Window:   round2(window.Hours()),
+		Observed: round2(to.Sub(from).Hours()),
+		PONs:     []model.PONStability{},
+	}
+	for _, p := range ports {
+		statuses := s.store.Statuses(oltID, p.board, p.pon)
+		if len(statuses) == 0 {
+			continue
+		}
+		events := s.store.Events(history.Filter{OLTID: oltID, Board: p.board, PON: p.pon, Since: from})
+		report.PONs = append(report.PONs, ponStability(p, statuses, events, from, to))
+	}
+	report.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	return report, nil
+}
+
+// ONUStability stabilitas satu ONU beserta riwayat perubahan statusnya
+// dalam rentang window terakhir
+func (s *MonitorService) ONUStability(oltID string, board, pon, onuID int, window time.Duration) (*model.ONUStability, error) {
+	_, from, to, err := s.window(window)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.onus.getDriver(oltID); err != nil {
+		return nil, err
+	}
+
+	current := s.store.Statuses(oltID, board, pon)[onuID]
+	events := s.store.Events(history.Filter{OLTID: oltID, Board: board, PON: pon, ONUID: onuID, Since: from})
+	if current == "" && len(events) == 0 {
+		return nil, ErrNotObserved
+	}
+
+	st := history.Stability(events, current, from, to)
+	st.Board, st.PON, st.ONUID = board, pon, onuID
+	st.Events = make([]model.StatusEvent, len(events))
+	for i, e := range events {
+		st.Events[i] = model.StatusEvent{At: e.At.UTC().Format(time.RFC3339), From: e.From, To: e.To, Reason: e.Reason}
+	}
+	return &st, nil
+}
+
+// window memvalidasi rentang (0 berarti default, paling lama retensi) dan
+// memotong awalnya ke awal data riwayat
+func (s *MonitorService) window(window time.Duration) (time.Duration, time.Time, time.Time, error) {
+	if !s.cfg.Monitor.Enabled {
+		return 0, time.Time{}, time.Time{}, ErrMonitorDisabled
+	}
+	maxWindow := s.store.Retention()
+	if window == 0 {
+		window = min(DefaultStabilityWindow, maxWindow)
+	}
+	if window < MinStabilityWindow || window > maxWindow {
+		return 0, time.Time{}, time.Time{}, &ServiceError{Message: fmt.Sprintf("window must be between %d and %d hours", int(MinStabilityWindow.Hours()), int(maxWindow.Hours()))}
+	}
+	to := time.Now()
+	from := to.Add(-window)
+	if since := s.store.Since(); from.Before(since) {
+		from = since
+	}
+	return window, from, to, nil
+}
+
+// ponStability stabilitas setiap ONU di satu PON dan ringkasannya
+func ponStability(p port, statuses map[int]string, events []history.Event, from, to time.Time) model.PONStability {
+	byONU := make(map[int][]history.Event)
+	reasons := make(map[string]int)
+	for _, e := range events {
+		byONU[e.ONUID] = append(byONU[e.ONUID], e)
+	}
+
+	result := model.PONStability{Board: p.board, PON: p.pon, ONUCount: len(statuses)}
+	scored, total := 0, 0
+	for onuID, status := range statuses {
+		st := history.Stability(byONU[onuID], status, from, to)
+		st.Board, st.PON, st.ONUID = p.board, p.pon, onuID
+		result.ONUs = append(result.ONUs, st)
+
+		result.Flaps += st.Flaps
+		if st.Flaps > 0 {
+			result.FlappingONUs++
+		}
+		for _, e := range byONU[onuID] {
+			if e.Down() {
+				reasons[e.Cause()]++
+			}
+		}
+		if st.Grade != history.GradeOffline {
+			scored++
+			total += st.Score
+		}
+	}
+	sort.Slice(result.ONUs, func(i, j int) bool {
+		a, b := result.ONUs[i], result.ONUs[j]
+		if offA, offB := a.Grade == history.GradeOffline, b.Grade == history.GradeOffline; offA != offB {
+			return offB
+		}
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		return a.ONUID < b.ONUID
+	})
+
+	result.FlapsPerDay = round2(float64(result.Flaps) / (max(to.Sub(from), history.MinSpan).Hours() / 24))
+	result.DominantReason = history.Dominant(reasons)
+	if scored == 0 {
+		result.Grade = history.GradeOffline
+		return result
+	}
+	result.Score = int(math.Round(float64(total) / float64(scored)))
+	result.Grade = history.Grade(result.Score)
+	return result
+}
@@ -36,6 +36,9 @@ func (s *OLTService) List() []model.OLT {
 			Community:   "***", // Don't expose community
 			BoardCount:  cfg.BoardCount,
 			PonPerBoard: cfg.PonPerBoard,
+			CLIPort:     cfg.CLIPort,
+			CLIUsername: cfg.CLIUsername,
+			CLIPassword: maskSecret(cfg.CLIPassword),
 		}
 	}
 	return olts
@@ -57,6 +60,9 @@ func (s *OLTService) Get(id string) (*model.OLT, error) {
 				Community:   "***",
 				BoardCount:  cfg.BoardCount,
 				PonPerBoard: cfg.PonPerBoard,
+				CLIPort:     cfg.CLIPort,
+				CLIUsername: cfg.CLIUsername,
+				CLIPassword: maskSecret(cfg.CLIPassword),
 			}, nil
 		}
 	}
@@ -64,6 +70,18 @@ func (s *OLTService) Get(id string) (*model.OLT, error) {
 	return nil, ErrOLTNotFound
 }
 
+// IDByHost mengembalikan ID OLT terdaftar dengan alamat IP host
+func (s *OLTService) IDByHost(host string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	olt, err := s.cfg.FindOLTByIP(host)
+	if err != nil {
+		return "", false
+	}
+	return olt.ID, true
+}
+
 // Create menambah OLT baru dan menyimpannya ke konfigurasi.
 func (s *OLTService) Create(olt model.OLT) error {
 	s.mu.Lock()
@@ -78,6 +96,9 @@ func (s *OLTService) Create(olt model.OLT) error {
 		Community:   olt.Community,
 		BoardCount:  olt.BoardCount,
 		PonPerBoard: olt.PonPerBoard,
+		CLIPort:     olt.CLIPort,
+		CLIUsername: olt.CLIUsername,
+		CLIPassword: olt.CLIPassword,
 	}
 
 	if err := s.cfg.AddOLT(cfg); err != nil {
@@ -102,6 +123,13 @@ func (s *OLTService) Update(id string, olt model.OLT) error {
 		Community:   olt.Community,
 		BoardCount:  olt.BoardCount,
 		PonPerBoard: olt.PonPerBoard,
+		CLIPort:     olt.CLIPort,
+		CLIUsername: olt.CLIUsername,
+		CLIPassword: olt.CLIPassword,
+	}
+	// Batas beban per OLT hanya diatur lewat file konfigurasi
+	if existing, err := s.cfg.GetOLT(id); err == nil {
+		cfg.Limits = existing.Limits
 	}
 
 	if err := s.cfg.UpdateOLT(id, cfg); err != nil {
@@ -123,6 +151,15 @@ func (s *OLTService) Delete(id string) error {
 	return config.Save(s.cfg)
 }
 
+// maskSecret menyembunyikan nilai rahasia tanpa menghilangkan informasi
+// apakah nilai tersebut sudah diisi
+func maskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "***"
+}
+
 // ErrOLTNotFound dikembalikan saat OLT tidak ditemukan
 var ErrOLTNotFound = &ServiceError{Message: "OLT not found"}
 
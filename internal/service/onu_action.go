@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/cache"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// ErrInvalidConfirmation dikembalikan saat token konfirmasi tidak dikenal,
+// sudah dipakai, kedaluwarsa, atau milik aksi lain
+var ErrInvalidConfirmation = errors.New("invalid or expired confirm_token, request a new one")
+
+// ConfirmationTTL lama token konfirmasi aksi destruktif berlaku
+const ConfirmationTTL = 2 * time.Minute
+
+// Confirmations token sekali pakai untuk aksi ONU destruktif. Token terikat
+// ke caller, target dan aksi sehingga tidak bisa dipakai untuk ONU lain.
+// Disimpan di memori: token hilang saat restart dan harus diminta ulang.
+type Confirmations struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]confirmation
+}
+
+type confirmation struct {
+	subject string
+	expires time.Time
+}
+
+// NewConfirmations membuat penyimpan token dengan masa berlaku ttl
+func NewConfirmations(ttl time.Duration) *Confirmations {
+	return &Confirmations{ttl: ttl, tokens: make(map[string]confirmation)}
+}
+
+// Issue membuat token baru untuk subject
+func (c *Confirmations) Issue(subject string) (token string, expires time.Time) {
+	now := time.Now()
+	token = randomHex(16)
+	expires = now.Add(c.ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for t, conf := range c.tokens {
+		if now.After(conf.expires) {
+			delete(c.tokens, t)
+		}
+	}
+	c.tokens[token] = confirmation{subject: subject, expires: expires}
+	return token, expires
+}
+
+// Consume memakai token untuk subject. Token hanya berlaku sekali.
+func (c *Confirmations) Consume(token, subject string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conf, ok := c.tokens[token]
+	if !ok || conf.subject != subject || time.Now().After(conf.expires) {
+		return ErrInvalidConfirmation
+	}
+	delete(c.tokens, token)
+	return nil
+}
+
+// ConfirmationSubject identitas aksi yang dikonfirmasi: caller, OLT (ID atau
+// IP), ONU dan aksi
+func ConfirmationSubject(caller, target string, boardID, ponID, onuID int, action string) string {
+	return fmt.Sprintf("%s|%s|%d/%d/%d|%s", caller, target, boardID, ponID, onuID, action)
+}
+
+// RunONUAction menjalankan aksi remote ONU lewat driver. Dipakai juga oleh
+// query stateless.
+func RunONUAction(ctx context.Context, d driver.Driver, boardID, ponID, onuID int, action string) error {
+	switch action {
+	case model.ONUActionReboot:
+		return d.RebootONU(ctx, boardID, ponID, onuID)
+	case model.ONUActionFactoryReset:
+		return d.RestoreONU(ctx, boardID, ponID, onuID)
+	case model.ONUActionEnable:
+		return d.SetONUAdminState(ctx, boardID, ponID, onuID, true)
+	case model.ONUActionDisable:
+		return d.SetONUAdminState(ctx, boardID, ponID, onuID, false)
+	}
+	return &ServiceError{Message: "action must be reboot, factory_reset, enable or disable"}
+}
+
+// ONUActionMessage ringkasan aksi yang berhasil
+func ONUActionMessage(boardID, ponID, onuID int, action string) string {
+	verb := map[string]string{
+		model.ONUActionReboot:       "rebooting",
+		model.ONUActionFactoryReset: "restoring factory default",
+		model.ONUActionEnable:       "enabled",
+		model.ONUActionDisable:      "disabled",
+	}[action]
+	return fmt.Sprintf("ONU %d/%d:%d %s", boardID, ponID, onuID, verb)
+}
+
+// ONUAction menjalankan aksi remote pada ONU di OLT terdaftar. Cache ONU
+// dibuang karena status ONU akan berubah.
+func (s *ONUService) ONUAction(ctx context.Context, oltID string, boardID, ponID, onuID int, action string) error {
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return err
+	}
+
+	if !d.ValidateBoardID(boardID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid board ID: %d", boardID)}
+	}
+	if !d.ValidatePonID(ponID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid PON ID: %d", ponID)}
+	}
+	if !d.ValidateOnuID(onuID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid ONU ID: %d", onuID)}
+	}
+	if !model.ValidONUAction(action) {
+		return &ServiceError{Message: "action must be reboot, factory_reset, enable or disable"}
+	}
+
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = RunONUAction(ctx, d, boardID, ponID, onuID, action)
+	if err := s.done(oltID, err); err != nil {
+		return err
+	}
+
+	s.cache.Delete(ctx, cache.ONUDetailKey(oltID, boardID, ponID, onuID))
+	s.cache.Delete(ctx, cache.ONUListKey(oltID, boardID, ponID))
+	return nil
+}
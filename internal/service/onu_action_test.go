@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfirmations(t *testing.T) {
+	subject := ConfirmationSubject("operator", "olt-a", 1, 2, 3, "reboot")
+	tests := []struct {
+		name    string
+		token   string // Kosong = token yang diterbitkan
+		subject string
+	}{
+		{"ONU lain", "", ConfirmationSubject("operator", "olt-a", 1, 2, 4, "reboot")},
+		{"PON lain", "", ConfirmationSubject("operator", "olt-a", 1, 3, 3, "reboot")},
+		{"aksi lain", "", ConfirmationSubject("operator", "olt-a", 1, 2, 3, "factory_reset")},
+		{"OLT lain", "", ConfirmationSubject("operator", "olt-b", 1, 2, 3, "reboot")},
+		{"caller lain", "", ConfirmationSubject("admin", "olt-a", 1, 2, 3, "reboot")},
+		{"token tidak dikenal", "00000000000000000000000000000000", subject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConfirmations(time.Minute)
+			token, expires := c.Issue(subject)
+			if len(token) != 32 || time.Until(expires) <= 0 || time.Until(expires) > time.Minute {
+				t.Fatalf("Issue() = %q, %v", token, expires)
+			}
+
+			wrong := tt.token
+			if wrong == "" {
+				wrong = token
+			}
+			if err := c.Consume(wrong, tt.subject); !errors.Is(err, ErrInvalidConfirmation) {
+				t.Fatalf("Consume() err = %v, want ErrInvalidConfirmation", err)
+			}
+			// Percobaan yang ditolak tidak menghabiskan token
+			if err := c.Consume(token, subject); err != nil {
+				t.Fatalf("Consume() with matching subject = %v", err)
+			}
+		})
+	}
+}
+
+func TestConfirmationsSingleUse(t *testing.T) {
+	c := NewConfirmations(time.Minute)
+	subject := ConfirmationSubject("operator", "olt-a", 1, 2, 3, "reboot")
+	token, _ := c.Issue(subject)
+	other, _ := c.Issue(subject)
+	if token == other {
+		t.Fatal("Issue() returned the same token twice")
+	}
+
+	if err := c.Consume(token, subject); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Consume(token, subject); !errors.Is(err, ErrInvalidConfirmation) {
+		t.Fatalf("replay err = %v, want ErrInvalidConfirmation", err)
+	}
+	if err := c.Consume(other, subject); err != nil {
+		t.Fatalf("second token = %v", err)
+	}
+}
+
+func TestConfirmationsExpired(t *testing.T) {
+	c := NewConfirmations(-time.Second)
+	subject := ConfirmationSubject("operator", "olt-a", 1, 2, 3, "reboot")
+	token, _ := c.Issue(subject)
+	if err := c.Consume(token, subject); !errors.Is(err, ErrInvalidConfirmation) {
+		t.Fatalf("expired err = %v, want ErrInvalidConfirmation", err)
+	}
+
+	// Token kedaluwarsa dibuang saat token baru dibuat
+	c.Issue(subject)
+	if _, ok := c.tokens[token]; ok || len(c.tokens) != 1 {
+		t.Fatalf("tokens = %v, want expired token swept", c.tokens)
+	}
+}
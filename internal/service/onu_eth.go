@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// GetONUEthPorts mengambil status port Ethernet ONU. SNMP dicoba lebih dulu;
+// jika driver tidak mendukung, data dibaca lewat CLI (OMCI remote-onu).
+func (s *ONUService) GetONUEthPorts(ctx context.Context, oltID string, boardID, ponID, onuID int) (*model.ONUEthPorts, error) {
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateONU(d, boardID, ponID, onuID); err != nil {
+		return nil, err
+	}
+
+	result := &model.ONUEthPorts{
+		OLTID:     oltID,
+		Board:     boardID,
+		PON:       ponID,
+		ONUID:     onuID,
+		Source:    model.UNISourceSNMP,
+		Timestamp: time.Now(),
+	}
+
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := d.GetONUEthPorts(ctx, boardID, ponID, onuID)
+	release()
+	if err = s.done(oltID, err); err == nil {
+		result.Ports = ports
+		return result, nil
+	}
+	if !errors.Is(err, driver.ErrNotSupported) {
+		return nil, err
+	}
+
+	client, closeCLI, err := s.openCLI(ctx, oltID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCLI()
+
+	cliPorts, err := client.ShowONUEthPorts(ctx, 1, boardID, ponID, onuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ONU Ethernet ports: %w", err)
+	}
+	result.Source = model.UNISourceCLI
+	result.Ports = make([]model.ONUEthPort, 0, len(cliPorts))
+	for _, p := range cliPorts {
+		result.Ports = append(result.Ports, model.ONUEthPort{
+			Port:       p.Port,
+			Name:       p.Interface,
+			AdminState: p.AdminState,
+			LinkState:  p.LinkState,
+			Speed:      p.Speed,
+			Duplex:     p.Duplex,
+			SpeedMode:  p.SpeedMode,
+		})
+	}
+	return result, nil
+}
+
+// SetONUEthPortState mengaktifkan/menonaktifkan satu port Ethernet ONU,
+// lewat SNMP jika didukung driver dan CLI pon-onu-mng jika tidak.
+// Mengembalikan sumber yang dipakai (snmp atau cli).
+func (s *ONUService) SetONUEthPortState(ctx context.Context, oltID string, boardID, ponID, onuID, port int, enabled bool) (string, error) {
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return "", err
+	}
+	if err := validateONU(d, boardID, ponID, onuID); err != nil {
+		return "", err
+	}
+	if port < 1 || port > 8 {
+		return "", &ServiceError{Message: fmt.Sprintf("invalid Ethernet port: %d", port)}
+	}
+
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return "", err
+	}
+	err = d.SetONUEthPortState(ctx, boardID, ponID, onuID, port, enabled)
+	release()
+	if err = s.done(oltID, err); err == nil {
+		return model.UNISourceSNMP, nil
+	}
+	if !errors.Is(err, driver.ErrNotSupported) {
+		return "", err
+	}
+
+	client, closeCLI, err := s.openCLI(ctx, oltID)
+	if err != nil {
+		return "", err
+	}
+	defer closeCLI()
+
+	if err := client.SetONUEthPortState(ctx, 1, boardID, ponID, onuID, port, enabled); err != nil {
+		return "", err
+	}
+	return model.UNISourceCLI, nil
+}
+
+// openCLI membuka sesi Telnet ke OLT setelah mendapat giliran dari limiter.
+// Port ONU dialamatkan sebagai gpon-onu_1/{board}/{pon}:{onu}.
+func (s *ONUService) openCLI(ctx context.Context, oltID string) (*cli.ZTEC320Client, func(), error) {
+	olt, err := s.cfg.GetOLT(oltID)
+	if err != nil {
+		return nil, nil, ErrOLTNotFound
+	}
+
+	release, err := s.limits.AcquireTelnet(ctx, olt.IPAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := cli.NewZTEC320Client(cliConfig(*olt))
+	if err := client.Connect(); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("%w: %v", ErrCLIConnect, err)
+	}
+	return client, func() {
+		client.Close()
+		release()
+	}, nil
+}
+
+// validateONU memeriksa board, PON dan ONU ID terhadap batas model OLT
+func validateONU(d driver.Driver, boardID, ponID, onuID int) error {
+	if !d.ValidateBoardID(boardID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid board ID: %d", boardID)}
+	}
+	if !d.ValidatePonID(ponID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid PON ID: %d", ponID)}
+	}
+	if !d.ValidateOnuID(onuID) {
+		return &ServiceError{Message: fmt.Sprintf("invalid ONU ID: %d", onuID)}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Urutan daftar ONU
+const (
+	SortONUID    = "onu_id"
+	SortRXPower  = "rx_power"
+	SortDistance = "distance"
+)
+
+// ONUListOptions filter, urutan dan paginasi untuk endpoint yang
+// mengembalikan daftar ONU. Nilai kosong berarti tidak difilter; tanpa
+// limit semua hasil dikembalikan.
+type ONUListOptions struct {
+	Status string   `json:"status,omitempty" example:"Online,LOS"` // Dipisah koma, case-insensitive
+	RXMin  *float64 `json:"rx_min,omitempty" example:"-27"`        // dBm, inklusif
+	RXMax  *float64 `json:"rx_max,omitempty" example:"-8"`         // dBm, inklusif
+	Name   string   `json:"name_contains,omitempty"`               // Substring nama, case-insensitive
+	SN     string   `json:"sn_contains,omitempty"`                 // Substring serial number, case-insensitive
+	Sort   string   `json:"sort,omitempty" enums:"onu_id,rx_power,distance"`
+	Order  string   `json:"order,omitempty" enums:"asc,desc"`
+	Limit  int      `json:"limit,omitempty"`
+	Cursor string   `json:"cursor,omitempty"` // next_cursor dari halaman sebelumnya
+}
+
+// PageInfo informasi paginasi daftar
+type PageInfo struct {
+	Total      int    `json:"total"`                 // Jumlah item setelah filter
+	NextCursor string `json:"next_cursor,omitempty"` // Kosong jika halaman terakhir
+}
+
+// ListONUs menerapkan filter, urutan dan paginasi pada daftar ONU. Cursor
+// menyimpan posisi item terakhir (nilai urutan + ID ONU), sehingga halaman
+// berikutnya tetap konsisten walau ada ONU yang ditambah atau dihapus.
+func ListONUs(list []model.ONUInfo, opts ONUListOptions) ([]model.ONUInfo, PageInfo, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, PageInfo{}, err
+	}
+	statuses := opts.statuses()
+
+	filtered := make([]model.ONUInfo, 0, len(list))
+	for _, onu := range list {
+		if opts.match(onu, statuses) {
+			filtered = append(filtered, onu)
+		}
+	}
+	return paginate(filtered, opts, func(onu model.ONUInfo) listKey {
+		switch opts.Sort {
+		case SortRXPower:
+			return floatKey(onu.RXPower, onu.ID)
+		case SortDistance:
+			return floatKey(onu.Distance, onu.ID)
+		default:
+			return listKey{ok: true, value: float64(onu.ID), id: onu.ID}
+		}
+	})
+}
+
+// ListSlots menerapkan urutan dan paginasi pada daftar slot kosong. Slot
+// hanya bisa diurutkan berdasarkan onu_id dan tidak mendukung filter ONU.
+func ListSlots(slots []model.ONUSlot, opts ONUListOptions) ([]model.ONUSlot, PageInfo, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, PageInfo{}, err
+	}
+	if opts.Sort != "" && opts.Sort != SortONUID {
+		return nil, PageInfo{}, &ServiceError{Message: "Empty slots can only be sorted by onu_id"}
+	}
+	if opts.Status != "" || opts.RXMin != nil || opts.RXMax != nil || opts.Name != "" || opts.SN != "" {
+		return nil, PageInfo{}, &ServiceError{Message: "status, rx_min, rx_max, name_contains and sn_contains are not supported for empty slots"}
+	}
+	return paginate(slots, opts, func(slot model.ONUSlot) listKey {
+		return listKey{ok: true, value: float64(slot.ONUID), id: slot.ONUID}
+	})
+}
+
+// listKey posisi item dalam urutan. Item tanpa nilai (ok false, misal RX
+// power ONU offline) selalu diletakkan di akhir.
+type listKey struct {
+	ok    bool
+	value float64
+	id    int
+}
+
+func floatKey(s string, id int) listKey {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return listKey{ok: err == nil, value: v, id: id}
+}
+
+func paginate[T any](items []T, opts ONUListOptions, key func(T) listKey) ([]T, PageInfo, error) {
+	desc := opts.Order == "desc"
+	before := func(a, b listKey) bool {
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if a.ok && a.value != b.value {
+			return (a.value < b.value) != desc
+		}
+		return a.id < b.id
+	}
+
+	keys := make([]listKey, len(items))
+	for i, item := range items {
+		keys[i] = key(item)
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return before(keys[idx[i]], keys[idx[j]]) })
+
+	page := PageInfo{Total: len(items)}
+	start := 0
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		start = sort.Search(len(idx), func(i int) bool { return before(after, keys[idx[i]]) })
+	}
+	end := len(idx)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+		page.NextCursor = encodeCursor(opts, keys[idx[end-1]])
+	}
+
+	result := make([]T, 0, end-start)
+	for _, i := range idx[start:end] {
+		result = append(result, items[i])
+	}
+	return result, page, nil
+}
+
+// Cursor: base64url dari "sort|order|ok|value|id". Sort dan order ikut
+// disimpan agar cursor tidak dipakai dengan urutan yang berbeda.
+func encodeCursor(opts ONUListOptions, k listKey) string {
+	raw := strings.Join([]string{
+		opts.sortName(), opts.orderName(),
+		strconv.FormatBool(k.ok), strconv.FormatFloat(k.value, 'g', -1, 64), strconv.Itoa(k.id),
+	}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(opts ONUListOptions) (listKey, error) {
+	invalid := &ServiceError{Message: "Invalid cursor"}
+	raw, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+	if err != nil {
+		return listKey{}, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 5 {
+		return listKey{}, invalid
+	}
+	if parts[0] != opts.sortName() || parts[1] != opts.orderName() {
+		return listKey{}, &ServiceError{Message: "Cursor was issued for a different sort or order"}
+	}
+	var k listKey
+	if k.ok, err = strconv.ParseBool(parts[2]); err != nil {
+		return listKey{}, invalid
+	}
+	if k.value, err = strconv.ParseFloat(parts[3], 64); err != nil {
+		return listKey{}, invalid
+	}
+	if k.id, err = strconv.Atoi(parts[4]); err != nil {
+		return listKey{}, invalid
+	}
+	return k, nil
+}
+
+func (o ONUListOptions) sortName() string {
+	if o.Sort == "" {
+		return SortONUID
+	}
+	return o.Sort
+}
+
+func (o ONUListOptions) orderName() string {
+	if o.Order == "" {
+		return "asc"
+	}
+	return o.Order
+}
+
+// Validate memeriksa nilai opsi. Dipanggil handler sebelum query ke OLT agar
+// parameter yang salah tidak menghabiskan sesi SNMP.
+func (o ONUListOptions) Validate() error {
+	switch o.Sort {
+	case "", SortONUID, SortRXPower, SortDistance:
+	default:
+		return &ServiceError{Message: fmt.Sprintf("Invalid sort %q (supported: onu_id, rx_power, distance)", o.Sort)}
+	}
+	switch o.Order {
+	case "", "asc", "desc":
+	default:
+		return &ServiceError{Message: fmt.Sprintf("Invalid order %q (supported: asc, desc)", o.Order)}
+	}
+	if o.Limit < 0 {
+		return &ServiceError{Message: "limit must not be negative"}
+	}
+	if o.Cursor != "" {
+		if _, err := decodeCursor(o); err != nil {
+			return err
+		}
+	}
+	if o.RXMin != nil && o.RXMax != nil && *o.RXMin > *o.RXMax {
+		return &ServiceError{Message: "rx_min must not be greater than rx_max"}
+	}
+	for status := range o.statuses() {
+		if !knownStatuses[status] {
+			return &ServiceError{Message: fmt.Sprintf("Invalid status %q", status)}
+		}
+	}
+	return nil
+}
+
+// knownStatuses status ONU yang bisa difilter (hasil normalizeStatus)
+var knownStatuses = func() map[string]bool {
+	m := map[string]bool{"unknown": true}
+	for s := model.StatusLogging; s <= model.StatusOffline; s++ {
+		m[normalizeStatus(s.String())] = true
+	}
+	return m
+}()
+
+// normalizeStatus menyamakan "Dying Gasp", "dying_gasp" dan "dying-gasp"
+func normalizeStatus(s string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+func (o ONUListOptions) statuses() map[string]bool {
+	if o.Status == "" {
+		return nil
+	}
+	m := make(map[string]bool)
+	for _, s := range strings.Split(o.Status, ",") {
+		if s = normalizeStatus(s); s != "" {
+			m[s] = true
+		}
+	}
+	return m
+}
+
+func (o ONUListOptions) match(onu model.ONUInfo, statuses map[string]bool) bool {
+	if len(statuses) > 0 && !statuses[normalizeStatus(onu.Status)] {
+		return false
+	}
+	if o.RXMin != nil || o.RXMax != nil {
+		rx, err := strconv.ParseFloat(strings.TrimSpace(onu.RXPower), 64)
+		if err != nil {
+			return false
+		}
+		if (o.RXMin != nil && rx < *o.RXMin) || (o.RXMax != nil && rx > *o.RXMax) {
+			return false
+		}
+	}
+	if o.Name != "" && !strings.Contains(strings.ToLower(onu.Name), strings.ToLower(o.Name)) {
+		return false
+	}
+	if o.SN != "" && !strings.Contains(strings.ToLower(onu.SerialNumber), strings.ToLower(o.SN)) {
+		return false
+	}
+	return true
+}
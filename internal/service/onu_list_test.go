@@ -0,0 +1,308 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// listONUs ONU contoh: RX -20.5 dipakai dua ONU dan jarak 1200 dua ONU untuk
+// menguji tiebreak ID, ONU 2 (LOS) tanpa RX power dan jarak
+func listONUs() []model.ONUInfo {
+	return []model.ONUInfo{
+		{ID: 1, Name: "pelanggan-budi", SerialNumber: "ZTEGC0000001", RXPower: "-20.5", Distance: "1200", Status: "Online"},
+		{ID: 2, Name: "Toko Sari", SerialNumber: "HWTC00000002", RXPower: "N/A", Distance: "", Status: "LOS"},
+		{ID: 3, Name: "budi-2", SerialNumber: "ZTEGC0000003", RXPower: "-27", Distance: "800", Status: "Dying Gasp"},
+		{ID: 4, Name: "kantor", SerialNumber: "ALCL00000004", RXPower: "-8.00", Distance: "1200", Status: "Online"},
+		{ID: 5, Name: "PELANGGAN-ANI", SerialNumber: "ztegc0000005", RXPower: "-20.5", Distance: "3000", Status: "Online"},
+		{ID: 6, Name: "gudang", SerialNumber: "ZTEGC0000006", RXPower: "-30.1", Distance: "500", Status: "Offline"},
+	}
+}
+
+func onuIDs(list []model.ONUInfo) []int {
+	ids := []int{}
+	for _, onu := range list {
+		ids = append(ids, onu.ID)
+	}
+	return ids
+}
+
+func dbm(v float64) *float64 { return &v }
+
+func TestListONUs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ONUListOptions
+		want []int
+	}{
+		{"tanpa filter", ONUListOptions{}, []int{1, 2, 3, 4, 5, 6}},
+		{"status", ONUListOptions{Status: "online"}, []int{1, 4, 5}},
+		{"daftar status", ONUListOptions{Status: "Dying Gasp, los"}, []int{2, 3}},
+		{"status dengan underscore", ONUListOptions{Status: "dying_gasp"}, []int{3}},
+		{"rx_min dan rx_max inklusif", ONUListOptions{RXMin: dbm(-27), RXMax: dbm(-8)}, []int{1, 3, 4, 5}},
+		{"rx_min saja", ONUListOptions{RXMin: dbm(-20.5)}, []int{1, 4, 5}},
+		{"rx_max saja", ONUListOptions{RXMax: dbm(-20.5)}, []int{1, 3, 5, 6}},
+		{"nama", ONUListOptions{Name: "budi"}, []int{1, 3}},
+		{"nama case-insensitive", ONUListOptions{Name: "Pelanggan"}, []int{1, 5}},
+		{"sn case-insensitive", ONUListOptions{SN: "ztegc"}, []int{1, 3, 5, 6}},
+		{"filter digabung", ONUListOptions{Status: "online", Name: "pelanggan", SN: "0005"}, []int{5}},
+		{"tidak ada yang cocok", ONUListOptions{Name: "tidak-ada"}, []int{}},
+		{"onu_id desc", ONUListOptions{Order: "desc"}, []int{6, 5, 4, 3, 2, 1}},
+		{"rx_power asc", ONUListOptions{Sort: SortRXPower}, []int{6, 3, 1, 5, 4, 2}},
+		{"rx_power desc", ONUListOptions{Sort: SortRXPower, Order: "desc"}, []int{4, 1, 5, 3, 6, 2}},
+		{"distance asc", ONUListOptions{Sort: SortDistance}, []int{6, 3, 1, 4, 5, 2}},
+		{"distance desc", ONUListOptions{Sort: SortDistance, Order: "desc"}, []int{5, 1, 4, 3, 6, 2}},
+		{"limit", ONUListOptions{Sort: SortRXPower, Limit: 2}, []int{6, 3}},
+		{"limit melebihi jumlah", ONUListOptions{Limit: 10}, []int{1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page, err := ListONUs(listONUs(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := onuIDs(got); !slices.Equal(ids, tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+			if tt.opts.Limit == 0 && page.Total != len(tt.want) {
+				t.Fatalf("total = %d, want %d", page.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListONUsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ONUListOptions
+		want string
+	}{
+		{"sort", ONUListOptions{Sort: "name"}, "Invalid sort"},
+		{"order", ONUListOptions{Order: "up"}, "Invalid order"},
+		{"limit negatif", ONUListOptions{Limit: -1}, "limit must not be negative"},
+		{"rx_min > rx_max", ONUListOptions{RXMin: dbm(-8), RXMax: dbm(-27)}, "rx_min must not be greater"},
+		{"status", ONUListOptions{Status: "online,rusak"}, `Invalid status "rusak"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ListONUs(listONUs(), tt.opts)
+			var se *ServiceError
+			if !errors.As(err, &se) || !strings.Contains(se.Message, tt.want) {
+				t.Fatalf("err = %v, want ServiceError containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestListONUsPages menelusuri semua halaman lewat next_cursor dan
+// membandingkan hasilnya dengan daftar tanpa paginasi
+func TestListONUsPages(t *testing.T) {
+	for _, sort := range []string{"", SortONUID, SortRXPower, SortDistance} {
+		for _, order := range []string{"asc", "desc"} {
+			for _, limit := range []int{1, 2, 4} {
+				name := sort + "/" + order
+				all, _, err := ListONUs(listONUs(), ONUListOptions{Sort: sort, Order: order})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				opts := ONUListOptions{Sort: sort, Order: order, Limit: limit}
+				var walked []int
+				for pages := 0; ; pages++ {
+					if pages > len(all) {
+						t.Fatalf("%s limit %d: cursor does not advance", name, limit)
+					}
+					got, page, err := ListONUs(listONUs(), opts)
+					if err != nil {
+						t.Fatalf("%s limit %d: %v", name, limit, err)
+					}
+					if page.Total != len(all) {
+						t.Fatalf("%s limit %d: total = %d", name, limit, page.Total)
+					}
+					walked = append(walked, onuIDs(got)...)
+					if page.NextCursor == "" {
+						break
+					}
+					opts.Cursor = page.NextCursor
+				}
+				if want := onuIDs(all); !slices.Equal(walked, want) {
+					t.Fatalf("%s limit %d: walked %v, want %v", name, limit, walked, want)
+				}
+			}
+		}
+	}
+}
+
+// TestListONUsCursorStable memastikan halaman berikutnya dilanjutkan dari
+// posisi item terakhir walau daftar berubah di antara dua request
+func TestListONUsCursorStable(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ONUListOptions
+		first  []int
+		change func(list []model.ONUInfo) []model.ONUInfo
+		next   []int
+	}{
+		{
+			name:  "item terakhir dihapus",
+			opts:  ONUListOptions{Limit: 2},
+			first: []int{1, 2},
+			change: func(list []model.ONUInfo) []model.ONUInfo {
+				return slices.DeleteFunc(list, func(o model.ONUInfo) bool { return o.ID == 2 })
+			},
+			next: []int{3, 4},
+		},
+		{
+			name:  "item ditambah sebelum dan sesudah cursor",
+			opts:  ONUListOptions{Sort: SortRXPower, Limit: 2},
+			first: []int{6, 3},
+			change: func(list []model.ONUInfo) []model.ONUInfo {
+				return append(list,
+					model.ONUInfo{ID: 7, RXPower: "-25", Status: "Online"},
+					model.ONUInfo{ID: 8, RXPower: "-35", Status: "Online"},
+				)
+			},
+			next: []int{7, 1},
+		},
+		{
+			name:  "nilai sama dengan cursor, ID lebih besar",
+			opts:  ONUListOptions{Sort: SortRXPower, Order: "desc", Limit: 2},
+			first: []int{4, 1},
+			change: func(list []model.ONUInfo) []model.ONUInfo {
+				list = slices.DeleteFunc(list, func(o model.ONUInfo) bool { return o.ID == 1 })
+				return append(list, model.ONUInfo{ID: 0, RXPower: "-20.5", Status: "Online"})
+			},
+			next: []int{5, 3},
+		},
+		{
+			name:  "melewati ONU tanpa nilai",
+			opts:  ONUListOptions{Sort: SortDistance, Limit: 5},
+			first: []int{6, 3, 1, 4, 5},
+			change: func(list []model.ONUInfo) []model.ONUInfo {
+				return append(list, model.ONUInfo{ID: 9, Distance: "", Status: "LOS"})
+			},
+			next: []int{2, 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := listONUs()
+			got, page, err := ListONUs(list, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := onuIDs(got); !slices.Equal(ids, tt.first) || page.NextCursor == "" {
+				t.Fatalf("first page = %v (cursor %q), want %v", ids, page.NextCursor, tt.first)
+			}
+
+			opts := tt.opts
+			opts.Cursor = page.NextCursor
+			got, _, err = ListONUs(tt.change(list), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := onuIDs(got); !slices.Equal(ids, tt.next) {
+				t.Fatalf("next page = %v, want %v", ids, tt.next)
+			}
+		})
+	}
+}
+
+func TestListONUsCursorInvalid(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	_, page, err := ListONUs(listONUs(), ONUListOptions{Sort: SortRXPower, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts ONUListOptions
+		want string
+	}{
+		{"bukan base64", ONUListOptions{Cursor: "!!!"}, "Invalid cursor"},
+		{"jumlah bagian salah", ONUListOptions{Cursor: raw("onu_id|asc|true|1")}, "Invalid cursor"},
+		{"ok bukan bool", ONUListOptions{Cursor: raw("onu_id|asc|ya|1|1")}, "Invalid cursor"},
+		{"nilai bukan angka", ONUListOptions{Cursor: raw("onu_id|asc|true|x|1")}, "Invalid cursor"},
+		{"id bukan angka", ONUListOptions{Cursor: raw("onu_id|asc|true|1|x")}, "Invalid cursor"},
+		{"sort berbeda", ONUListOptions{Sort: SortDistance, Cursor: page.NextCursor}, "different sort or order"},
+		{"order berbeda", ONUListOptions{Sort: SortRXPower, Order: "desc", Cursor: page.NextCursor}, "different sort or order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ListONUs(listONUs(), tt.opts)
+			var se *ServiceError
+			if !errors.As(err, &se) || !strings.Contains(se.Message, tt.want) {
+				t.Fatalf("err = %v, want ServiceError containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		opts ONUListOptions
+		key  listKey
+	}{
+		{ONUListOptions{}, listKey{ok: true, value: 3, id: 3}},
+		{ONUListOptions{Sort: SortRXPower, Order: "desc"}, listKey{ok: true, value: -20.57, id: 12}},
+		{ONUListOptions{Sort: SortDistance}, listKey{ok: false, id: 128}},
+	}
+	for _, tt := range tests {
+		opts := tt.opts
+		opts.Cursor = encodeCursor(tt.opts, tt.key)
+		got, err := decodeCursor(opts)
+		if err != nil || got != tt.key {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v, %v", tt.key, got, err)
+		}
+	}
+
+	// Sort/order kosong sama dengan default onu_id/asc
+	opts := ONUListOptions{Sort: SortONUID, Order: "asc", Cursor: encodeCursor(ONUListOptions{}, listKey{ok: true, value: 1, id: 1})}
+	if _, err := decodeCursor(opts); err != nil {
+		t.Errorf("default cursor with explicit onu_id/asc: %v", err)
+	}
+}
+
+func TestListSlots(t *testing.T) {
+	slots := []model.ONUSlot{{ONUID: 7}, {ONUID: 2}, {ONUID: 5}, {ONUID: 3}}
+	slotIDs := func(list []model.ONUSlot) []int {
+		var ids []int
+		for _, s := range list {
+			ids = append(ids, s.ONUID)
+		}
+		return ids
+	}
+
+	got, page, err := ListSlots(slots, ONUListOptions{Order: "desc", Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := slotIDs(got); !slices.Equal(ids, []int{7, 5, 3}) || page.Total != 4 || page.NextCursor == "" {
+		t.Fatalf("first page = %v %+v", ids, page)
+	}
+	got, page, err = ListSlots(slots, ONUListOptions{Order: "desc", Limit: 3, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := slotIDs(got); !slices.Equal(ids, []int{2}) || page.NextCursor != "" {
+		t.Fatalf("second page = %v %+v", ids, page)
+	}
+
+	for _, opts := range []ONUListOptions{
+		{Sort: SortRXPower},
+		{Status: "online"},
+		{RXMin: dbm(-27)},
+		{Name: "budi"},
+		{SN: "ZTE"},
+	} {
+		var se *ServiceError
+		if _, _, err := ListSlots(slots, opts); !errors.As(err, &se) {
+			t.Errorf("ListSlots(%+v) err = %v, want ServiceError", opts, err)
+		}
+	}
+}
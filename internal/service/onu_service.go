@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ardani/snmp-zte/internal/breaker"
 	"github.com/ardani/snmp-zte/internal/cache"
 	"github.com/ardani/snmp-zte/internal/config"
 	"github.com/ardani/snmp-zte/internal/driver"
 	"github.com/ardani/snmp-zte/internal/driver/c320"
+	"github.com/ardani/snmp-zte/internal/limiter"
 	"github.com/ardani/snmp-zte/internal/model"
 	"github.com/redis/go-redis/v9"
 )
@@ -17,10 +19,14 @@ type ONUService struct {
 	cfg      *config.Config
 	cache    cache.Cache
 	drivers  map[string]driver.Driver
+	limits   *limiter.Limiter
+	breakers *breaker.Set
 }
 
 // NewONUService membuat instance ONU service baru dan menyiapkan driver.
-func NewONUService(cfg *config.Config, redisClient *redis.Client) *ONUService {
+// limits membatasi sesi SNMP per OLT saat data tidak ada di cache; breakers
+// menolak request ke OLT yang tidak menjawab.
+func NewONUService(cfg *config.Config, redisClient *redis.Client, limits *limiter.Limiter, breakers *breaker.Set) *ONUService {
 	var c cache.Cache
 	if redisClient != nil {
 		// Gunakan Redis jika tersedia
@@ -31,9 +37,11 @@ func NewONUService(cfg *config.Config, redisClient *redis.Client) *ONUService {
 	}
 
 	s := &ONUService{
-		cfg:     cfg,
-		cache:   c,
-		drivers: make(map[string]driver.Driver),
+		cfg:      cfg,
+		cache:    c,
+		drivers:  make(map[string]driver.Driver),
+		limits:   limits,
+		breakers: breakers,
 	}
 
 	// Siapkan driver untuk setiap OLT yang terdaftar di konfigurasi
@@ -51,7 +59,9 @@ func NewONUService(cfg *config.Config, redisClient *redis.Client) *ONUService {
 func (s *ONUService) createDriver(cfg config.OLTConfig) driver.Driver {
 	switch cfg.Model {
 	case "C320", "c320":
-		return c320.New(cfg.IPAddress, uint16(cfg.Port), cfg.Community)
+		d := c320.New(cfg.IPAddress, uint16(cfg.Port), cfg.Community)
+		d.SetBreaker(s.breakers.For(cfg.IPAddress))
+		return d
 	// C300 and C600 will be added later
 	// case "C300", "c300":
 	// 	return c300.New(cfg.IPAddress, uint16(cfg.Port), cfg.Community)
@@ -71,6 +81,29 @@ func (s *ONUService) getDriver(oltID string) (driver.Driver, error) {
 	return d, nil
 }
 
+// acquire menunggu giliran sesi SNMP ke OLT oltID. Jika circuit OLT
+// terbuka request langsung ditolak tanpa masuk antrean.
+func (s *ONUService) acquire(ctx context.Context, oltID string, d driver.Driver) (release func(), err error) {
+	olt, err := s.cfg.GetOLT(oltID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.breakers.For(olt.IPAddress).Allow(ctx, d.Probe); err != nil {
+		return nil, err
+	}
+	return s.limits.AcquireSNMP(ctx, olt.IPAddress)
+}
+
+// done memeriksa hasil operasi driver: jika circuit OLT terbuka selama
+// operasi berjalan, hasilnya tidak lengkap dan error circuit dikembalikan.
+func (s *ONUService) done(oltID string, err error) error {
+	olt, cerr := s.cfg.GetOLT(oltID)
+	if cerr != nil {
+		return err
+	}
+	return s.breakers.For(olt.IPAddress).Done(err)
+}
+
 // GetONUList mengambil daftar ONU dari driver dengan sistem Caching.
 func (s *ONUService) GetONUList(ctx context.Context, oltID string, boardID, ponID int) ([]model.ONUInfo, error) {
 	d, err := s.getDriver(oltID)
@@ -94,10 +127,16 @@ func (s *ONUService) GetONUList(ctx context.Context, oltID string, boardID, ponI
 	}
 
 	// 2. Jika tidak ada di cache, baru minta ke Driver SNMP
-	onuList, err := d.GetONUList(ctx, boardID, ponID)
+	release, err := s.acquire(ctx, oltID, d)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	onuList, err := d.GetONUList(ctx, boardID, ponID)
+	if err := s.done(oltID, err); err != nil {
+		return nil, err
+	}
 
 	// Tambahkan ID OLT ke masing-masing data ONU
 	for i := range onuList {
@@ -136,10 +175,16 @@ func (s *ONUService) GetONUDetail(ctx context.Context, oltID string, boardID, po
 	}
 
 	// Fetch from driver
-	detail, err := d.GetONUDetail(ctx, boardID, ponID, onuID)
+	release, err := s.acquire(ctx, oltID, d)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	detail, err := d.GetONUDetail(ctx, boardID, ponID, onuID)
+	if err := s.done(oltID, err); err != nil {
+		return nil, err
+	}
 
 	// Add OLT ID
 	detail.OLTID = oltID
@@ -173,10 +218,16 @@ func (s *ONUService) GetEmptySlots(ctx context.Context, oltID string, boardID, p
 	}
 
 	// Fetch from driver
-	slots, err := d.GetEmptySlots(ctx, boardID, ponID)
+	release, err := s.acquire(ctx, oltID, d)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	slots, err := d.GetEmptySlots(ctx, boardID, ponID)
+	if err := s.done(oltID, err); err != nil {
+		return nil, err
+	}
 
 	// Cache result
 	s.cache.Set(ctx, cacheKey, slots, cache.DefaultTTL)
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/cli"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// ErrONUNotConfigured dikembalikan saat ONU tidak punya konfigurasi di OLT
+var ErrONUNotConfigured = errors.New("ONU has no configuration on OLT")
+
+// GetONUWAN membaca koneksi WAN ONU (PPPoE, IP, status) lewat CLI OMCI
+func (s *ONUService) GetONUWAN(ctx context.Context, oltID string, boardID, ponID, onuID int) (*model.ONUWAN, error) {
+	client, closeCLI, err := s.onuCLI(ctx, oltID, boardID, ponID, onuID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCLI()
+
+	wans, err := client.ShowONUWAN(ctx, 1, boardID, ponID, onuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ONU WAN: %w", err)
+	}
+
+	result := &model.ONUWAN{
+		OLTID:       oltID,
+		Board:       boardID,
+		PON:         ponID,
+		ONUID:       onuID,
+		Connections: make([]model.ONUWANConnection, 0, len(wans)),
+		Timestamp:   time.Now(),
+	}
+	for _, w := range wans {
+		result.Connections = append(result.Connections, model.ONUWANConnection{
+			Index:        w.Index,
+			Name:         w.Name,
+			Mode:         w.Mode,
+			Status:       w.Status,
+			Username:     w.Username,
+			IPAddress:    w.IPAddress,
+			SubnetMask:   w.SubnetMask,
+			Gateway:      w.Gateway,
+			PrimaryDNS:   w.PrimaryDNS,
+			SecondaryDNS: w.SecondaryDNS,
+			MACAddress:   w.MACAddress,
+			VLAN:         w.VLAN,
+		})
+	}
+	return result, nil
+}
+
+// GetONUWiFi membaca SSID WiFi ONU lewat CLI OMCI
+func (s *ONUService) GetONUWiFi(ctx context.Context, oltID string, boardID, ponID, onuID int) (*model.ONUWiFi, error) {
+	client, closeCLI, err := s.onuCLI(ctx, oltID, boardID, ponID, onuID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCLI()
+
+	ssids, err := client.ShowONUWiFi(ctx, 1, boardID, ponID, onuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ONU WiFi: %w", err)
+	}
+
+	result := &model.ONUWiFi{
+		OLTID:     oltID,
+		Board:     boardID,
+		PON:       ponID,
+		ONUID:     onuID,
+		SSIDs:     make([]model.ONUSSID, 0, len(ssids)),
+		Timestamp: time.Now(),
+	}
+	for _, ss := range ssids {
+		result.SSIDs = append(result.SSIDs, model.ONUSSID{
+			Index:      ss.Index,
+			Interface:  ss.Interface,
+			Name:       ss.Name,
+			Enabled:    ss.State == "enable",
+			Hidden:     ss.Hidden,
+			AuthMode:   ss.AuthMode,
+			Encryption: ss.Encryption,
+			Standard:   ss.Standard,
+			Channel:    ss.Channel,
+			Clients:    ss.Clients,
+		})
+	}
+	return result, nil
+}
+
+// GetONUConfig membaca konfigurasi interface dan pon-onu-mng ONU. Password
+// PPPoE dan key WiFi tidak ikut dikembalikan.
+func (s *ONUService) GetONUConfig(ctx context.Context, oltID string, boardID, ponID, onuID int) (*cli.ONUConfig, error) {
+	client, closeCLI, err := s.onuCLI(ctx, oltID, boardID, ponID, onuID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCLI()
+
+	cfg, err := client.ShowONURunningConfig(ctx, 1, boardID, ponID, onuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ONU config: %w", err)
+	}
+	if cfg == nil {
+		return nil, ErrONUNotConfigured
+	}
+	return cfg, nil
+}
+
+// SetONUPPPoE mengatur kredensial PPPoE pada WAN index lewat pon-onu-mng
+func (s *ONUService) SetONUPPPoE(ctx context.Context, oltID string, boardID, ponID, onuID, index int, req model.ONUPPPoERequest) error {
+	if index < 1 || index > 8 {
+		return &ServiceError{Message: fmt.Sprintf("invalid WAN index: %d", index)}
+	}
+	if req.Host == 0 {
+		req.Host = 1
+	}
+	if req.Host < 1 || req.Host > 8 {
+		return &ServiceError{Message: fmt.Sprintf("invalid host: %d", req.Host)}
+	}
+	if err := cliValue("username", req.Username, 1, 64); err != nil {
+		return err
+	}
+	if err := cliValue("password", req.Password, 1, 64); err != nil {
+		return err
+	}
+	if err := cliValue("vlan_profile", req.VLANProfile, 1, 32); err != nil {
+		return err
+	}
+
+	client, closeCLI, err := s.onuCLI(ctx, oltID, boardID, ponID, onuID)
+	if err != nil {
+		return err
+	}
+	defer closeCLI()
+
+	return client.SetONUPPPoE(ctx, 1, boardID, ponID, onuID, index, req.Username, req.Password, req.VLANProfile, req.Host)
+}
+
+// SetONUSSID mengubah nama dan/atau password WPA2 SSID lewat pon-onu-mng
+func (s *ONUService) SetONUSSID(ctx context.Context, oltID string, boardID, ponID, onuID, ssid int, req model.ONUWiFiRequest) error {
+	if ssid < 1 || ssid > 8 {
+		return &ServiceError{Message: fmt.Sprintf("invalid SSID index: %d", ssid)}
+	}
+	if req.Name == "" && req.Password == "" {
+		return &ServiceError{Message: "name or password is required"}
+	}
+	if req.Name != "" {
+		if err := cliValue("name", req.Name, 1, 32); err != nil {
+			return err
+		}
+	}
+	if req.Password != "" {
+		if err := cliValue("password", req.Password, 8, 63); err != nil {
+			return err
+		}
+	}
+
+	client, closeCLI, err := s.onuCLI(ctx, oltID, boardID, ponID, onuID)
+	if err != nil {
+		return err
+	}
+	defer closeCLI()
+
+	return client.SetONUSSID(ctx, 1, boardID, ponID, onuID, ssid, req.Name, req.Password)
+}
+
+// onuCLI memvalidasi alamat ONU lalu membuka sesi CLI ke OLT
+func (s *ONUService) onuCLI(ctx context.Context, oltID string, boardID, ponID, onuID int) (*cli.ZTEC320Client, func(), error) {
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateONU(d, boardID, ponID, onuID); err != nil {
+		return nil, nil, err
+	}
+	return s.openCLI(ctx, oltID)
+}
+
+// cliValue memastikan nilai aman dikirim sebagai satu kata perintah CLI:
+// panjang min..max, tanpa spasi, '?' (memicu bantuan CLI) atau karakter
+// non-ASCII.
+func cliValue(field, value string, min, max int) error {
+	if len(value) < min || len(value) > max {
+		return &ServiceError{Message: fmt.Sprintf("%s must be %d-%d characters", field, min, max)}
+	}
+	for _, c := range value {
+		if c <= ' ' || c > '~' || c == '?' {
+			return &ServiceError{Message: fmt.Sprintf("%s must not contain spaces, '?' or non-ASCII characters", field)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Dugaan penyebab ONU outlier
+const (
+	SuspectConnector = "connector_or_drop" // Outlier tunggal: konektor kotor atau kabel drop
+	SuspectBranch    = "splitter_branch"   // Beberapa outlier berdekatan: cabang splitter bermasalah
+)
+
+// Dasar perbandingan loss dalam satu PON
+const (
+	BasisLinkLoss = "link_loss" // tx_power ONU - rx_power di OLT
+	BasisRXPower  = "rx_power"  // rx_power ONU (daya OLT sama untuk semua ONU di PON)
+)
+
+// Parameter analisis optik
+const (
+	FiberLossDBPerKm = 0.35 // Atenuasi fiber 1310 nm, untuk koreksi jarak
+	OutlierExcessDB  = 3.0  // Excess loss minimum untuk ditandai outlier
+	MinOpticalPeers  = 3    // Jumlah ONU minimum agar median bermakna
+	BranchDistanceM  = 500  // Jarak maksimum antar outlier dalam satu cabang
+	BranchExcessDB   = 2.0  // Selisih excess loss maksimum dalam satu cabang
+)
+
+// OpticalAnalysis membandingkan loss setiap ONU online dengan peer di PON
+// yang sama. Loss dinormalisasi dengan atenuasi fiber sesuai jarak, sehingga
+// ONU yang jauh tidak otomatis dianggap buruk; ONU dengan excess loss di atas
+// OutlierExcessDB ditandai outlier. Outlier yang jaraknya berdekatan dan
+// excess-nya mirip dikelompokkan sebagai dugaan cabang splitter bermasalah.
+// Board wajib diisi: RX power di OLT untuk seluruh OLT tidak terbaca dalam
+// WriteTimeout; pon 0 berarti semua PON di board.
+func (s *ONUService) OpticalAnalysis(ctx context.Context, oltID string, board, pon int) (*model.OpticalReport, error) {
+	if board == 0 {
+		return nil, &ServiceError{Message: "board is required"}
+	}
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := ponPorts(d, board, pon)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.OpticalReport{OLTID: oltID, PONs: []model.PONOptical{}}
+	for _, p := range ports {
+		list, err := s.GetONUList(ctx, oltID, p.board, p.pon)
+		if err != nil {
+			return nil, err
+		}
+		var online []model.ONUInfo
+		ids := []int{}
+		for _, onu := range list {
+			if onu.Status == model.StatusOnline.String() {
+				online = append(online, onu)
+				ids = append(ids, onu.ID)
+			}
+		}
+		if len(online) == 0 {
+			continue
+		}
+
+		release, err := s.acquire(ctx, oltID, d)
+		if err != nil {
+			return nil, err
+		}
+		oltRx, err := d.GetOLTRxPower(ctx, p.board, p.pon, ids)
+		release()
+		if err := s.done(oltID, err); err != nil {
+			return nil, err
+		}
+
+		report.PONs = append(report.PONs, analyzePON(p, online, oltRx))
+	}
+	report.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	return report, nil
+}
+
+// analyzePON menghitung loss, excess loss dan cabang splitter satu PON
+func analyzePON(p port, onus []model.ONUInfo, oltRx map[int]float64) model.PONOptical {
+	result := model.PONOptical{Board: p.board, PON: p.pon, Branches: []model.SplitterBranch{}}
+
+	withLoss := 0
+	for _, onu := range onus {
+		o := model.ONUOptical{
+			Board:        p.board,
+			PON:          p.pon,
+			ONUID:        onu.ID,
+			Name:         onu.Name,
+			SerialNumber: onu.SerialNumber,
+			RXPower:      parsePower(onu.RXPower),
+			TXPower:      parsePower(onu.TXPower),
+		}
+		if m, err := strconv.ParseFloat(strings.TrimSpace(onu.Distance), 64); err == nil {
+			o.Distance = int(m)
+		}
+		if rx, ok := oltRx[onu.ID]; ok {
+			o.OLTRxPower = &rx
+		}
+		if o.TXPower != nil && o.OLTRxPower != nil {
+			loss := round2(*o.TXPower - *o.OLTRxPower)
+			o.LinkLoss = &loss
+			withLoss++
+		}
+		result.ONUs = append(result.ONUs, o)
+	}
+
+	// Loss sebenarnya jika cukup ONU punya pembacaan dua arah; selain itu RX
+	// power ONU (makin rendah makin besar loss, daya kirim OLT sama)
+	result.Basis = BasisRXPower
+	loss := func(o model.ONUOptical) (float64, bool) {
+		if o.RXPower == nil {
+			return 0, false
+		}
+		return -*o.RXPower, true
+	}
+	if withLoss >= MinOpticalPeers {
+		result.Basis = BasisLinkLoss
+		loss = func(o model.ONUOptical) (float64, bool) {
+			if o.LinkLoss == nil {
+				return 0, false
+			}
+			return *o.LinkLoss, true
+		}
+	}
+
+	normalized := make(map[int]float64)
+	var values []float64
+	for _, o := range result.ONUs {
+		if l, ok := loss(o); ok {
+			n := l - FiberLossDBPerKm*float64(o.Distance)/1000
+			normalized[o.ONUID] = n
+			values = append(values, n)
+		}
+	}
+	if len(values) < MinOpticalPeers {
+		return result
+	}
+	median := median(values)
+	if result.Basis == BasisLinkLoss {
+		m := round2(median)
+		result.MedianLoss = &m
+	}
+
+	var outliers []*model.ONUOptical
+	for i := range result.ONUs {
+		o := &result.ONUs[i]
+		n, ok := normalized[o.ONUID]
+		if !ok {
+			continue
+		}
+		excess := round2(n - median)
+		o.ExcessLoss = &excess
+		if excess >= OutlierExcessDB {
+			o.Outlier = true
+			o.Suspect = SuspectConnector
+			outliers = append(outliers, o)
+		}
+	}
+	result.Branches = splitterBranches(outliers)
+	return result
+}
+
+// splitterBranches mengelompokkan outlier yang berurutan menurut jarak,
+// berjarak paling jauh BranchDistanceM dari tetangganya dan excess loss-nya
+// berbeda paling banyak BranchExcessDB. Kelompok berisi minimal dua ONU.
+func splitterBranches(outliers []*model.ONUOptical) []model.SplitterBranch {
+	sort.Slice(outliers, func(i, j int) bool { return outliers[i].Distance < outliers[j].Distance })
+
+	branches := []model.SplitterBranch{}
+	flush := func(group []*model.ONUOptical) {
+		if len(group) < 2 {
+			return
+		}
+		b := model.SplitterBranch{
+			ID:          len(branches) + 1,
+			MinDistance: group[0].Distance,
+			MaxDistance: group[len(group)-1].Distance,
+		}
+		var sum float64
+		for _, o := range group {
+			o.Suspect = SuspectBranch
+			o.Branch = b.ID
+			b.ONUIDs = append(b.ONUIDs, o.ONUID)
+			sum += *o.ExcessLoss
+		}
+		b.ExcessLoss = round2(sum / float64(len(group)))
+		branches = append(branches, b)
+	}
+
+	var group []*model.ONUOptical
+	for _, o := range outliers {
+		if len(group) > 0 {
+			prev := group[len(group)-1]
+			if o.Distance-prev.Distance > BranchDistanceM || math.Abs(*o.ExcessLoss-*group[0].ExcessLoss) > BranchExcessDB {
+				flush(group)
+				group = nil
+			}
+		}
+		group = append(group, o)
+	}
+	flush(group)
+	return branches
+}
+
+// parsePower membaca power dBm dari ONUInfo. "0.00" berarti tidak terbaca.
+func parsePower(s string) *float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
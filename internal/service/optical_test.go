@@ -0,0 +1,187 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func opticalONU(id int, rx, tx, distance string) model.ONUInfo {
+	return model.ONUInfo{ID: id, RXPower: rx, TXPower: tx, Distance: distance, Status: model.StatusOnline.String()}
+}
+
+func TestAnalyzePON(t *testing.T) {
+	p := port{board: 1, pon: 2}
+
+	t.Run("link loss dengan koreksi jarak", func(t *testing.T) {
+		onus := []model.ONUInfo{
+			opticalONU(1, "-20.00", "2.00", "0"),
+			opticalONU(2, "-21.00", "2.00", "1000"),
+			opticalONU(3, "-22.00", "2.00", "3000"), // Jauh, tetapi loss sesuai jarak
+			opticalONU(4, "-26.00", "2.00", "0"),
+		}
+		oltRx := map[int]float64{1: -20, 2: -20.35, 3: -21.05, 4: -26}
+
+		got := analyzePON(p, onus, oltRx)
+		if got.Board != 1 || got.PON != 2 || got.Basis != BasisLinkLoss {
+			t.Fatalf("board/pon/basis = %d/%d/%s", got.Board, got.PON, got.Basis)
+		}
+		if got.MedianLoss == nil || *got.MedianLoss != 22 {
+			t.Fatalf("median_loss = %v, want 22", got.MedianLoss)
+		}
+		wantLoss := []float64{22, 22.35, 23.05, 28}
+		wantExcess := []float64{0, 0, 0, 6}
+		for i, o := range got.ONUs {
+			if o.LinkLoss == nil || *o.LinkLoss != wantLoss[i] {
+				t.Errorf("onu %d link_loss = %v, want %v", o.ONUID, o.LinkLoss, wantLoss[i])
+			}
+			if o.ExcessLoss == nil || *o.ExcessLoss != wantExcess[i] {
+				t.Errorf("onu %d excess_loss = %v, want %v", o.ONUID, o.ExcessLoss, wantExcess[i])
+			}
+			if outlier := o.ONUID == 4; o.Outlier != outlier {
+				t.Errorf("onu %d outlier = %v", o.ONUID, o.Outlier)
+			}
+		}
+		if o := got.ONUs[3]; o.Suspect != SuspectConnector || o.Branch != 0 {
+			t.Errorf("suspect/branch = %q/%d", o.Suspect, o.Branch)
+		}
+		if len(got.Branches) != 0 {
+			t.Errorf("branches = %+v", got.Branches)
+		}
+	})
+
+	t.Run("rx power jika RX OLT kurang", func(t *testing.T) {
+		onus := []model.ONUInfo{
+			opticalONU(1, "-20.00", "2.00", "0"),
+			opticalONU(2, "-20.00", "2.00", "0"),
+			opticalONU(3, "-21.00", "0.00", "0"),
+			opticalONU(4, "-24.00", "2.00", "0"),
+		}
+		got := analyzePON(p, onus, map[int]float64{1: -20, 2: -20})
+		if got.Basis != BasisRXPower || got.MedianLoss != nil {
+			t.Fatalf("basis = %s, median_loss = %v", got.Basis, got.MedianLoss)
+		}
+		if o := got.ONUs[2]; o.TXPower != nil || o.LinkLoss != nil {
+			t.Errorf("tx 0.00 harus dianggap tidak terbaca: %+v", o)
+		}
+		// median RX -20.5: ONU 4 kurang 3.5 dB
+		if o := got.ONUs[3]; !o.Outlier || *o.ExcessLoss != 3.5 {
+			t.Errorf("onu 4 = outlier %v excess %v", o.Outlier, *o.ExcessLoss)
+		}
+		if o := got.ONUs[2]; o.Outlier || *o.ExcessLoss != 0.5 {
+			t.Errorf("onu 3 = outlier %v excess %v", o.Outlier, *o.ExcessLoss)
+		}
+	})
+
+	t.Run("peer kurang dari minimum", func(t *testing.T) {
+		onus := []model.ONUInfo{
+			opticalONU(1, "-20.00", "2.00", "0"),
+			opticalONU(2, "-30.00", "2.00", "0"),
+			opticalONU(3, "0.00", "2.00", "0"), // RX tidak terbaca
+		}
+		got := analyzePON(p, onus, nil)
+		for _, o := range got.ONUs {
+			if o.ExcessLoss != nil || o.Outlier {
+				t.Errorf("onu %d dibandingkan dengan %d peer", o.ONUID, len(onus)-1)
+			}
+		}
+	})
+
+	t.Run("outlier berdekatan menjadi cabang", func(t *testing.T) {
+		onus := []model.ONUInfo{
+			opticalONU(1, "-20.00", "", "0"),
+			opticalONU(2, "-20.00", "", "0"),
+			opticalONU(3, "-20.00", "", "0"),
+			opticalONU(7, "-20.00", "", "0"),
+			opticalONU(8, "-20.00", "", "0"),
+			opticalONU(4, "-25.00", "", "1000"),
+			opticalONU(5, "-25.35", "", "2000"),
+			opticalONU(6, "-26.20", "", "1200"),
+		}
+		got := analyzePON(p, onus, nil)
+		if len(got.Branches) != 1 {
+			t.Fatalf("branches = %+v", got.Branches)
+		}
+		if b := got.Branches[0]; !slices.Equal(b.ONUIDs, []int{4, 6}) || b.MinDistance != 1000 || b.MaxDistance != 1200 {
+			t.Fatalf("branch = %+v", b)
+		}
+		for _, o := range got.ONUs[5:] {
+			want := SuspectBranch
+			if o.ONUID == 5 {
+				want = SuspectConnector
+			}
+			if !o.Outlier || o.Suspect != want {
+				t.Errorf("onu %d = outlier %v suspect %q, want %q", o.ONUID, o.Outlier, o.Suspect, want)
+			}
+		}
+	})
+}
+
+func TestSplitterBranches(t *testing.T) {
+	type outlier struct {
+		id       int
+		distance int
+		excess   float64
+	}
+	tests := []struct {
+		name     string
+		outliers []outlier
+		want     [][]int
+	}{
+		{"tanpa outlier", nil, [][]int{}},
+		{"outlier tunggal", []outlier{{1, 1000, 4}}, [][]int{}},
+		{"dua outlier berdekatan", []outlier{{1, 1000, 4}, {2, 1500, 5}}, [][]int{{1, 2}}},
+		{"jarak lebih dari 500 m", []outlier{{1, 1000, 4}, {2, 1501, 4}}, [][]int{}},
+		{"excess berbeda lebih dari 2 dB", []outlier{{1, 1000, 4}, {2, 1100, 6.5}}, [][]int{}},
+		{"jarak diukur dari tetangga", []outlier{{1, 1000, 4}, {2, 1400, 4}, {3, 1800, 4}}, [][]int{{1, 2, 3}}},
+		{"excess dibanding anggota pertama", []outlier{{1, 1000, 4}, {2, 1200, 5.5}, {3, 1400, 6.5}, {4, 1600, 6.5}}, [][]int{{1, 2}, {3, 4}}},
+		{"input tidak urut", []outlier{{3, 3000, 4}, {1, 1000, 4}, {4, 3200, 4}, {2, 1100, 4}}, [][]int{{1, 2}, {3, 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var onus []*model.ONUOptical
+			for _, o := range tt.outliers {
+				excess := o.excess
+				onus = append(onus, &model.ONUOptical{ONUID: o.id, Distance: o.distance, ExcessLoss: &excess, Outlier: true, Suspect: SuspectConnector})
+			}
+
+			got := splitterBranches(onus)
+			if len(got) != len(tt.want) {
+				t.Fatalf("branches = %+v, want %v", got, tt.want)
+			}
+			inBranch := make(map[int]int)
+			for i, b := range got {
+				if b.ID != i+1 || !slices.Equal(b.ONUIDs, tt.want[i]) {
+					t.Fatalf("branch %d = %+v, want onus %v", i, b, tt.want[i])
+				}
+				for _, id := range b.ONUIDs {
+					inBranch[id] = b.ID
+				}
+			}
+			for _, o := range onus {
+				want := SuspectConnector
+				if inBranch[o.ONUID] != 0 {
+					want = SuspectBranch
+				}
+				if o.Suspect != want || o.Branch != inBranch[o.ONUID] {
+					t.Errorf("onu %d suspect/branch = %q/%d", o.ONUID, o.Suspect, o.Branch)
+				}
+			}
+		})
+	}
+
+	t.Run("rata-rata excess dan rentang jarak", func(t *testing.T) {
+		a, b, c := 4.0, 5.0, 5.5
+		got := splitterBranches([]*model.ONUOptical{
+			{ONUID: 1, Distance: 1200, ExcessLoss: &a},
+			{ONUID: 2, Distance: 900, ExcessLoss: &b},
+			{ONUID: 3, Distance: 1300, ExcessLoss: &c},
+		})
+		want := model.SplitterBranch{ID: 1, MinDistance: 900, MaxDistance: 1300, ONUIDs: []int{2, 1, 3}, ExcessLoss: 4.83}
+		if len(got) != 1 || got[0].ExcessLoss != want.ExcessLoss || got[0].MinDistance != want.MinDistance ||
+			got[0].MaxDistance != want.MaxDistance || !slices.Equal(got[0].ONUIDs, want.ONUIDs) {
+			t.Fatalf("branches = %+v, want %+v", got, want)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/counter"
+	"github.com/ardani/snmp-zte/internal/driver"
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+// Arah trafik untuk urutan top-N
+const (
+	DirectionUpstream   = "upstream"
+	DirectionDownstream = "downstream"
+)
+
+// Batas opsi top-N. Window maksimum di bawah WriteTimeout server (90 detik)
+// karena counter diambil dua kali dalam satu request.
+const (
+	DefaultTopN      = 10
+	MaxTopN          = 100
+	DefaultTopWindow = 10 * time.Second
+	MinTopWindow     = 5 * time.Second
+	MaxTopWindow     = 60 * time.Second
+)
+
+// TopTrafficOptions cakupan dan urutan top-N trafik. Board wajib diisi:
+// counter seluruh OLT dibaca dua kali tidak muat dalam WriteTimeout. PON 0
+// berarti semua PON di board.
+type TopTrafficOptions struct {
+	Board     int
+	PON       int
+	N         int
+	Direction string
+	Window    time.Duration
+}
+
+// Validate memeriksa opsi dan mengisi nilai default
+func (o *TopTrafficOptions) Validate() error {
+	if o.N == 0 {
+		o.N = DefaultTopN
+	}
+	if o.N < 1 || o.N > MaxTopN {
+		return &ServiceError{Message: fmt.Sprintf("n must be between 1 and %d", MaxTopN)}
+	}
+	switch o.Direction {
+	case "":
+		o.Direction = DirectionDownstream
+	case DirectionUpstream, DirectionDownstream:
+	default:
+		return &ServiceError{Message: fmt.Sprintf("Invalid direction %q (supported: upstream, downstream)", o.Direction)}
+	}
+	if o.Window == 0 {
+		o.Window = DefaultTopWindow
+	}
+	if o.Window < MinTopWindow || o.Window > MaxTopWindow {
+		return &ServiceError{Message: fmt.Sprintf("window must be between %d and %d seconds", int(MinTopWindow.Seconds()), int(MaxTopWindow.Seconds()))}
+	}
+	if o.Board == 0 {
+		return &ServiceError{Message: "board is required"}
+	}
+	return nil
+}
+
+// port satu port PON
+type port struct{ board, pon int }
+
+// TopTraffic mencari ONU dengan laju trafik terbesar. Counter ONU online
+// dibaca dua kali berjarak opts.Window, lalu setiap ONU di top-N dilengkapi
+// SLA dari profil bandwidth untuk menandai yang melebihi batas.
+func (s *ONUService) TopTraffic(ctx context.Context, oltID string, opts TopTrafficOptions) (*model.TopTraffic, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	d, err := s.getDriver(oltID)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := ponPorts(d, opts.Board, opts.PON)
+	if err != nil {
+		return nil, err
+	}
+
+	// ONU online per PON (dari cache daftar ONU jika ada)
+	onus := make(map[port][]model.ONUInfo)
+	for _, p := range ports {
+		list, err := s.GetONUList(ctx, oltID, p.board, p.pon)
+		if err != nil {
+			return nil, err
+		}
+		for _, onu := range list {
+			if onu.Status == model.StatusOnline.String() {
+				onus[p] = append(onus[p], onu)
+			}
+		}
+	}
+
+	// Meter sekali pakai: sampel pertama menjadi baseline, sampel kedua
+	// menghasilkan laju dengan penanganan wrap dan reboot yang sama
+	meter := counter.NewMeter(counter.NewMemoryStore(), 0)
+	if _, err := s.sampleTraffic(ctx, oltID, d, meter, ports, onus); err != nil {
+		return nil, err
+	}
+	select {
+	case <-time.After(opts.Window):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	rates, err := s.sampleTraffic(ctx, oltID, d, meter, ports, onus)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []model.TopTrafficEntry
+	for _, p := range ports {
+		for _, onu := range onus[p] {
+			rate, ok := rates[onuKey(p, onu.ID)]
+			if !ok {
+				continue
+			}
+			entries = append(entries, model.TopTrafficEntry{
+				Board:         p.board,
+				PON:           p.pon,
+				ONUID:         onu.ID,
+				Name:          onu.Name,
+				SerialNumber:  onu.SerialNumber,
+				UpstreamBps:   rate.RxBps,
+				DownstreamBps: rate.TxBps,
+			})
+		}
+	}
+	sampled := len(entries)
+
+	value := func(e model.TopTrafficEntry) float64 {
+		if opts.Direction == DirectionUpstream {
+			return e.UpstreamBps
+		}
+		return e.DownstreamBps
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return value(entries[i]) > value(entries[j]) })
+	if len(entries) > opts.N {
+		entries = entries[:opts.N]
+	}
+
+	if err := s.applySLA(ctx, oltID, d, entries); err != nil {
+		return nil, err
+	}
+
+	return &model.TopTraffic{
+		OLTID:     oltID,
+		Board:     opts.Board,
+		PON:       opts.PON,
+		Direction: opts.Direction,
+		Window:    opts.Window.Seconds(),
+		Sampled:   sampled,
+		Entries:   entries,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ponPorts daftar port PON dalam cakupan. Board 0 berarti seluruh OLT, PON
+// 0 berarti semua PON di board.
+func ponPorts(d driver.Driver, board, pon int) ([]port, error) {
+	info := d.GetModelInfo()
+	boards := []int{board}
+	if board == 0 {
+		boards = boards[:0]
+		for b := 1; b <= info.MaxBoards; b++ {
+			boards = append(boards, b)
+		}
+	} else if !d.ValidateBoardID(board) {
+		return nil, &ServiceError{Message: fmt.Sprintf("invalid board ID: %d", board)}
+	}
+	if pon != 0 && !d.ValidatePonID(pon) {
+		return nil, &ServiceError{Message: fmt.Sprintf("invalid PON ID: %d", pon)}
+	}
+
+	var ports []port
+	for _, b := range boards {
+		if pon != 0 {
+			ports = append(ports, port{b, pon})
+			continue
+		}
+		for p := 1; p <= info.MaxPonPerBoard; p++ {
+			ports = append(ports, port{b, p})
+		}
+	}
+	return ports, nil
+}
+
+// sampleTraffic membaca counter semua ONU dalam satu giliran sesi SNMP dan
+// mengembalikan laju yang sudah bisa dihitung meter
+func (s *ONUService) sampleTraffic(ctx context.Context, oltID string, d driver.Driver, meter *counter.Meter, ports []port, onus map[port][]model.ONUInfo) (map[string]*model.TrafficRate, error) {
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rates := make(map[string]*model.TrafficRate)
+	for _, p := range ports {
+		if len(onus[p]) == 0 {
+			continue
+		}
+		ids := make([]int, len(onus[p]))
+		for i, onu := range onus[p] {
+			ids[i] = onu.ID
+		}
+		traffic, err := d.GetONUTrafficCounters(ctx, p.board, p.pon, ids)
+		if err := s.done(oltID, err); err != nil {
+			return nil, err
+		}
+		for _, t := range traffic {
+			key := onuKey(p, t.ONUID)
+			rate, err := meter.Traffic(ctx, key, t.Sample, t.RxBytes, t.TxBytes, t.RxPackets, t.TxPackets)
+			if err != nil {
+				return nil, err
+			}
+			if rate != nil {
+				rates[key] = rate
+			}
+		}
+	}
+	return rates, nil
+}
+
+// applySLA mengisi batas bandwidth dari profil ONU. SLA yang tidak terbaca
+// dibiarkan 0 (tidak diketahui) dan tidak pernah ditandai melebihi batas.
+func (s *ONUService) applySLA(ctx context.Context, oltID string, d driver.Driver, entries []model.TopTrafficEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	release, err := s.acquire(ctx, oltID, d)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for i := range entries {
+		e := &entries[i]
+		bw, err := d.GetONUBandwidth(ctx, e.Board, e.PON, e.ONUID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		e.MaxUpstream = bw.MaxUpstream
+		e.MaxDownstream = bw.MaxDownstream
+		e.ExceedsSLA = (e.MaxUpstream > 0 && e.UpstreamBps > float64(e.MaxUpstream)*1000) ||
+			(e.MaxDownstream > 0 && e.DownstreamBps > float64(e.MaxDownstream)*1000)
+	}
+	return nil
+}
+
+func onuKey(p port, onuID int) string {
+	return fmt.Sprintf("%d/%d/%d", p.board, p.pon, onuID)
+}
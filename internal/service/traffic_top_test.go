@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTopTrafficOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TopTrafficOptions
+		want    TopTrafficOptions
+		wantErr bool
+	}{
+		{
+			name: "default",
+			opts: TopTrafficOptions{Board: 1},
+			want: TopTrafficOptions{Board: 1, N: DefaultTopN, Direction: DirectionDownstream, Window: DefaultTopWindow},
+		},
+		{
+			name: "per PON upstream",
+			opts: TopTrafficOptions{Board: 2, PON: 3, N: 5, Direction: DirectionUpstream, Window: 15 * time.Second},
+			want: TopTrafficOptions{Board: 2, PON: 3, N: 5, Direction: DirectionUpstream, Window: 15 * time.Second},
+		},
+		{name: "tanpa board (seluruh OLT)", opts: TopTrafficOptions{}, wantErr: true},
+		{name: "pon tanpa board", opts: TopTrafficOptions{PON: 1}, wantErr: true},
+		{name: "n terlalu besar", opts: TopTrafficOptions{Board: 1, N: MaxTopN + 1}, wantErr: true},
+		{name: "n negatif", opts: TopTrafficOptions{Board: 1, N: -1}, wantErr: true},
+		{name: "arah tidak dikenal", opts: TopTrafficOptions{Board: 1, Direction: "both"}, wantErr: true},
+		{name: "window terlalu pendek", opts: TopTrafficOptions{Board: 1, Window: time.Second}, wantErr: true},
+		{name: "window terlalu panjang", opts: TopTrafficOptions{Board: 1, Window: 2 * time.Minute}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.Validate()
+			if tt.wantErr {
+				var se *ServiceError
+				if !errors.As(err, &se) {
+					t.Fatalf("Validate() = %v, want ServiceError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts != tt.want {
+				t.Fatalf("opts = %+v, want %+v", opts, tt.want)
+			}
+		})
+	}
+}
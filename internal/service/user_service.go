@@ -0,0 +1,341 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// pbkdf2Iterations jumlah iterasi hash password. Hasil verifikasi di-cache
+// agar Basic Auth di setiap request tidak menghitung ulang hash.
+const pbkdf2Iterations = 210000
+
+// Error user service
+var (
+	ErrUserNotFound       = &ServiceError{Message: "user not found"}
+	ErrUserExists         = &ServiceError{Message: "user already exists"}
+	ErrInvalidCredentials = &ServiceError{Message: "invalid username or password"}
+	ErrInvalidRole        = &ServiceError{Message: "invalid role, must be one of: read-only, operator, admin"}
+	ErrLastAdmin          = &ServiceError{Message: "cannot remove or demote the last active admin"}
+)
+
+// storedUser user beserta hash password seperti yang disimpan di file
+type storedUser struct {
+	model.User
+	PasswordHash string `json:"password_hash"`
+}
+
+// UserService menyimpan user API beserta role-nya di file JSON.
+type UserService struct {
+	path  string
+	mu    sync.RWMutex
+	users map[string]*storedUser
+
+	// verified cache HMAC password terakhir yang lolos verifikasi per user.
+	// Kunci HMAC acak per proses, sehingga cache tidak bisa dipakai di luar.
+	verified map[string][]byte
+	cacheKey []byte
+}
+
+// NewUserService memuat user dari path. Jika file belum ada, dibuat user
+// admin dari environment AUTH_USER/AUTH_PASS (default admin/testing123)
+// agar kredensial lama tetap berlaku.
+func NewUserService(path string) (*UserService, error) {
+	s := &UserService{
+		path:     path,
+		users:    make(map[string]*storedUser),
+		verified: make(map[string][]byte),
+		cacheKey: make([]byte, 32),
+	}
+	rand.Read(s.cacheKey)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, s.bootstrap()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+
+	var users []*storedUser
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users file: %w", err)
+	}
+	for _, u := range users {
+		s.users[u.Username] = u
+	}
+	return s, nil
+}
+
+func (s *UserService) bootstrap() error {
+	username := os.Getenv("AUTH_USER")
+	password := os.Getenv("AUTH_PASS")
+	if username == "" {
+		username = "admin"
+	}
+	if password == "" {
+		password = "testing123"
+	}
+
+	now := time.Now().UTC()
+	s.users[username] = &storedUser{
+		User:         model.User{Username: username, Role: model.RoleAdmin, CreatedAt: now, UpdatedAt: now},
+		PasswordHash: hashPassword(password),
+	}
+	log.Info().Str("username", username).Str("file", s.path).Msg("Created initial admin user")
+	return s.save()
+}
+
+// Authenticate memverifikasi username dan password
+func (s *UserService) Authenticate(username, password string) (*model.User, error) {
+	s.mu.RLock()
+	u, ok := s.users[username]
+	var cached []byte
+	if ok {
+		cached = s.verified[username]
+	}
+	s.mu.RUnlock()
+
+	if !ok || u.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+
+	mac := hmac.New(sha256.New, s.cacheKey)
+	mac.Write([]byte(username + ":" + password))
+	sum := mac.Sum(nil)
+	if cached != nil && hmac.Equal(sum, cached) {
+		user := u.User
+		user.AuthMethod = model.AuthBasic
+		return &user, nil
+	}
+	if !verifyPassword(u.PasswordHash, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	s.mu.Lock()
+	// Pastikan user tidak berubah selama verifikasi
+	if current, ok := s.users[username]; ok && current.PasswordHash == u.PasswordHash {
+		s.verified[username] = sum
+	}
+	s.mu.Unlock()
+
+	user := u.User
+	user.AuthMethod = model.AuthBasic
+	return &user, nil
+}
+
+// List mengembalikan semua user, urut berdasarkan username
+func (s *UserService) List() []model.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]model.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u.User)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+// Get mengembalikan satu user
+func (s *UserService) Get(username string) (*model.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user := u.User
+	return &user, nil
+}
+
+// Create menambah user baru
+func (s *UserService) Create(req model.UserRequest) (*model.User, error) {
+	if req.Username == "" || strings.ContainsAny(req.Username, ": ") {
+		return nil, &ServiceError{Message: "username is required and must not contain ':' or spaces"}
+	}
+	if req.Password == "" {
+		return nil, &ServiceError{Message: "password is required"}
+	}
+	if !model.ValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.Username]; ok {
+		return nil, ErrUserExists
+	}
+
+	now := time.Now().UTC()
+	u := &storedUser{
+		User:         model.User{Username: req.Username, Role: req.Role, CreatedAt: now, UpdatedAt: now},
+		PasswordHash: hashPassword(req.Password),
+	}
+	if req.OLTs != nil {
+		u.OLTs = *req.OLTs
+	}
+	if req.Disabled != nil {
+		u.Disabled = *req.Disabled
+	}
+
+	s.users[u.Username] = u
+	if err := s.save(); err != nil {
+		delete(s.users, u.Username)
+		return nil, err
+	}
+	user := u.User
+	return &user, nil
+}
+
+// Update mengubah role, scope OLT, status atau password user
+func (s *UserService) Update(username string, req model.UserRequest) (*model.User, error) {
+	if req.Role != "" && !model.ValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	u := *existing
+	if req.Role != "" {
+		u.Role = req.Role
+	}
+	if req.OLTs != nil {
+		u.OLTs = *req.OLTs
+	}
+	if req.Disabled != nil {
+		u.Disabled = *req.Disabled
+	}
+	if req.Password != "" {
+		u.PasswordHash = hashPassword(req.Password)
+	}
+	u.UpdatedAt = time.Now().UTC()
+
+	if s.isLastAdmin(username) && (u.Role != model.RoleAdmin || u.Disabled || u.Scoped()) {
+		return nil, ErrLastAdmin
+	}
+
+	s.users[username] = &u
+	delete(s.verified, username)
+	if err := s.save(); err != nil {
+		s.users[username] = existing
+		return nil, err
+	}
+	user := u.User
+	return &user, nil
+}
+
+// Delete menghapus user
+func (s *UserService) Delete(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if s.isLastAdmin(username) {
+		return ErrLastAdmin
+	}
+
+	delete(s.users, username)
+	delete(s.verified, username)
+	if err := s.save(); err != nil {
+		s.users[username] = existing
+		return err
+	}
+	return nil
+}
+
+// isLastAdmin true jika username adalah satu-satunya admin aktif tanpa
+// batasan OLT. Harus dipanggil dengan s.mu terkunci.
+func (s *UserService) isLastAdmin(username string) bool {
+	u := s.users[username]
+	if u.Role != model.RoleAdmin || u.Disabled || u.Scoped() {
+		return false
+	}
+	for name, other := range s.users {
+		if name != username && other.Role == model.RoleAdmin && !other.Disabled && !other.Scoped() {
+			return false
+		}
+	}
+	return true
+}
+
+// save menulis semua user ke file. Harus dipanggil dengan s.mu terkunci.
+func (s *UserService) save() error {
+	users := make([]*storedUser, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal users: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create users directory: %w", err)
+	}
+	// File berisi hash password, hanya bisa dibaca pemilik proses
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write users file: %w", err)
+	}
+	return nil
+}
+
+// hashPassword menghasilkan "pbkdf2-sha256$iterasi$salt$hash" (base64)
+func hashPassword(password string) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	key, _ := pbkdf2.Key(sha256.New, password, salt, pbkdf2Iterations, 32)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", pbkdf2Iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func verifyPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
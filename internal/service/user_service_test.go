@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ardani/snmp-zte/internal/model"
+)
+
+func newTestUserService(t *testing.T) *UserService {
+	t.Helper()
+	t.Setenv("AUTH_USER", "root")
+	t.Setenv("AUTH_PASS", "root-pass")
+	s, err := NewUserService(filepath.Join(t.TempDir(), "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLastAdminProtection(t *testing.T) {
+	s := newTestUserService(t)
+	disabled := true
+	scope := []string{"olt-a"}
+
+	// root satu-satunya admin aktif tanpa batasan OLT
+	for name, req := range map[string]model.UserRequest{
+		"demote":  {Role: model.RoleOperator},
+		"disable": {Disabled: &disabled},
+		"scope":   {OLTs: &scope},
+	} {
+		if _, err := s.Update("root", req); !errors.Is(err, ErrLastAdmin) {
+			t.Fatalf("%s last admin: err = %v, want ErrLastAdmin", name, err)
+		}
+	}
+	if err := s.Delete("root"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("delete last admin: err = %v, want ErrLastAdmin", err)
+	}
+
+	// Admin dengan batasan OLT atau nonaktif tidak dihitung
+	if _, err := s.Create(model.UserRequest{Username: "scoped", Password: "x", Role: model.RoleAdmin, OLTs: &scope}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(model.UserRequest{Username: "off", Password: "x", Role: model.RoleAdmin, Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("root"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("delete with only scoped/disabled admins: err = %v, want ErrLastAdmin", err)
+	}
+
+	// Setelah ada admin aktif lain, root boleh diturunkan lalu dihapus
+	if _, err := s.Create(model.UserRequest{Username: "admin2", Password: "x", Role: model.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update("root", model.UserRequest{Role: model.RoleReadOnly}); err != nil {
+		t.Fatalf("demote with another admin: %v", err)
+	}
+	if err := s.Delete("root"); err != nil {
+		t.Fatalf("delete non-admin: %v", err)
+	}
+	if err := s.Delete("admin2"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("delete new last admin: err = %v, want ErrLastAdmin", err)
+	}
+}
+
+func TestUserValidation(t *testing.T) {
+	s := newTestUserService(t)
+	tests := []struct {
+		name string
+		req  model.UserRequest
+		want error
+	}{
+		{"role tidak dikenal", model.UserRequest{Username: "a", Password: "x", Role: "superuser"}, ErrInvalidRole},
+		{"sudah ada", model.UserRequest{Username: "root", Password: "x", Role: model.RoleReadOnly}, ErrUserExists},
+	}
+	for _, tt := range tests {
+		if _, err := s.Create(tt.req); !errors.Is(err, tt.want) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	var se *ServiceError
+	if _, err := s.Create(model.UserRequest{Username: "a b", Password: "x", Role: model.RoleReadOnly}); !errors.As(err, &se) {
+		t.Fatalf("username with space: err = %v, want ServiceError", err)
+	}
+}
+
+func TestAuthenticateCache(t *testing.T) {
+	s := newTestUserService(t)
+
+	u, err := s.Authenticate("root", "root-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Role != model.RoleAdmin || u.AuthMethod != model.AuthBasic {
+		t.Fatalf("user = %+v", u)
+	}
+	if _, err := s.Authenticate("root", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: err = %v", err)
+	}
+	if _, err := s.Authenticate("nobody", "root-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("unknown user: err = %v", err)
+	}
+
+	// Verifikasi berikutnya memakai cache tanpa pbkdf2: hash yang dirusak
+	// tidak dibaca selama password sama dengan yang tercache
+	s.mu.Lock()
+	hash := s.users["root"].PasswordHash
+	s.users["root"].PasswordHash = "broken"
+	s.mu.Unlock()
+	if _, err := s.Authenticate("root", "root-pass"); err != nil {
+		t.Fatalf("cached password: %v", err)
+	}
+	if _, err := s.Authenticate("root", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password with cache: err = %v", err)
+	}
+	s.mu.Lock()
+	s.users["root"].PasswordHash = hash
+	s.mu.Unlock()
+
+	// Ganti password membuang cache sehingga password lama tidak berlaku
+	if _, err := s.Create(model.UserRequest{Username: "admin2", Password: "x", Role: model.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update("root", model.UserRequest{Password: "new-pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate("root", "root-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("old password after change: err = %v", err)
+	}
+	if _, err := s.Authenticate("root", "new-pass"); err != nil {
+		t.Fatalf("new password: %v", err)
+	}
+
+	// User nonaktif ditolak walaupun password tercache
+	disabled := true
+	if _, err := s.Update("root", model.UserRequest{Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate("root", "new-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("disabled user: err = %v", err)
+	}
+
+	// Cache tidak ikut tersimpan: instance baru harus memverifikasi hash
+	reloaded, err := NewUserService(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.verified) != 0 {
+		t.Fatal("verified cache loaded from file")
+	}
+}
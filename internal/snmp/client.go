@@ -70,39 +70,55 @@ func (c *Client) Walk(oid string, fn func(gosnmp.SnmpPDU) error) error {
 	return c.client.Walk(oid, fn)
 }
 
-// GetWithContext melakukan SNMP GET dengan context
+// GetWithContext melakukan SNMP GET yang berhenti begitu ctx selesai
 func (c *Client) GetWithContext(ctx context.Context, oids []string) (*gosnmp.SnmpPacket, error) {
-	// gosnmp tidak mendukung context secara native, jadi kita menggunakan goroutine
-	type result struct {
-		pkt *gosnmp.SnmpPacket
-		err error
-	}
+	unbind := Bind(ctx, c.client)
+	defer unbind()
+	return c.client.Get(oids)
+}
 
-	resultCh := make(chan result, 1)
-	go func() {
-		pkt, err := c.client.Get(oids)
-		resultCh <- result{pkt, err}
-	}()
+// WalkWithContext melakukan SNMP WALK yang berhenti di antara PDU begitu
+// ctx selesai
+func (c *Client) WalkWithContext(ctx context.Context, oid string, fn func(gosnmp.SnmpPDU) error) error {
+	unbind := Bind(ctx, c.client)
+	defer unbind()
+	return Walk(ctx, c.client, oid, fn)
+}
 
-	select {
-	case res := <-resultCh:
-		return res.pkt, res.err
-	case <-ctx.Done():
-		return nil, ctx.Err()
+// Bind mengikat operasi pada g ke ctx: gosnmp memakai deadline ctx untuk
+// setiap request, tidak mengirim request baru setelah ctx selesai, dan read
+// yang sedang menunggu respons langsung dibatalkan saat ctx dibatalkan.
+// g harus sudah Connect dan tidak dipakai bersamaan oleh operasi lain.
+// unbind wajib dipanggil setelah operasi selesai.
+func Bind(ctx context.Context, g *gosnmp.GoSNMP) (unbind func()) {
+	g.Context = ctx
+	stop := context.AfterFunc(ctx, func() {
+		if g.Conn != nil {
+			g.Conn.SetDeadline(time.Now())
+		}
+	})
+	return func() {
+		stop()
+		g.Context = context.Background()
 	}
 }
 
-// WalkWithContext melakukan SNMP WALK dengan context
-func (c *Client) WalkWithContext(ctx context.Context, oid string, fn func(gosnmp.SnmpPDU) error) error {
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- c.client.Walk(oid, fn)
-	}()
-
-	select {
-	case err := <-errCh:
+// Walk melakukan SNMP WALK (GETNEXT) dan memeriksa ctx di antara PDU,
+// sehingga walk berhenti tanpa menunggu subtree selesai. Gunakan bersama
+// Bind agar request yang sedang menunggu juga dibatalkan.
+func Walk(ctx context.Context, g *gosnmp.GoSNMP, oid string, fn func(gosnmp.SnmpPDU) error) error {
+	if err := ctx.Err(); err != nil {
 		return err
-	case <-ctx.Done():
-		return ctx.Err()
 	}
+	err := g.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(pdu)
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		// Error dari read yang dibatalkan Bind dilaporkan sebagai error ctx
+		return ctxErr
+	}
+	return err
 }
@@ -0,0 +1,188 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ardani/snmp-zte/internal/snmp/snmptest"
+	"github.com/gosnmp/gosnmp"
+)
+
+// Timeout gosnmp sengaja dibuat jauh lebih lama dari batas waktu test:
+// operasi hanya boleh selesai cepat karena ctx, bukan karena timeout.
+const (
+	snmpTimeout = 5 * time.Second
+	returnLimit = 500 * time.Millisecond
+)
+
+func newTestClient(t *testing.T, agent *snmptest.Agent) *Client {
+	t.Helper()
+	c, err := NewClient(Config{Host: agent.Host, Port: agent.Port, Community: "public", Timeout: snmpTimeout, Retries: 1})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// checkGoroutines memastikan jumlah goroutine kembali ke baseline, yaitu
+// tidak ada walk atau read yang masih berjalan setelah fungsi kembali.
+func checkGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d > %d\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// checkNoMoreRequests memastikan agent tidak menerima PDU lagi setelah
+// operasi kembali.
+func checkNoMoreRequests(t *testing.T, agent *snmptest.Agent) {
+	t.Helper()
+	before := agent.Requests()
+	time.Sleep(100 * time.Millisecond)
+	if after := agent.Requests(); after != before {
+		t.Fatalf("agent masih menerima request setelah operasi kembali: %d -> %d", before, after)
+	}
+}
+
+// TestWalkWithContextCancel: walk yang tidak pernah selesai berhenti di
+// antara PDU begitu ctx dibatalkan.
+func TestWalkWithContextCancel(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Endless, 2*time.Millisecond)
+	c := newTestClient(t, agent)
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	pdus := 0
+	err := c.WalkWithContext(ctx, "1.3.6.1.4.1.3902", func(gosnmp.SnmpPDU) error {
+		pdus++
+		return nil
+	})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > returnLimit {
+		t.Fatalf("walk kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+	if pdus == 0 {
+		t.Fatal("walk tidak menerima PDU sebelum dibatalkan")
+	}
+	checkNoMoreRequests(t, agent)
+	checkGoroutines(t, baseline)
+}
+
+// TestGetWithContextCancel: GET ke OLT yang tidak menjawab kembali segera
+// saat ctx dibatalkan, tanpa menunggu timeout dan retry gosnmp.
+func TestGetWithContextCancel(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Silent, 0)
+	c := newTestClient(t, agent)
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.GetWithContext(ctx, []string{"1.3.6.1.2.1.1.3.0"})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > returnLimit {
+		t.Fatalf("get kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+	if n := agent.Requests(); n != 1 {
+		t.Fatalf("agent menerima %d request, want 1 (tanpa retry)", n)
+	}
+	checkNoMoreRequests(t, agent)
+	checkGoroutines(t, baseline)
+}
+
+// TestGetWithContextDeadline: deadline ctx yang lebih pendek dari timeout
+// gosnmp membatasi lama GET.
+func TestGetWithContextDeadline(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Silent, 0)
+	c := newTestClient(t, agent)
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetWithContext(ctx, []string{"1.3.6.1.2.1.1.3.0"})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > returnLimit {
+		t.Fatalf("get kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+	checkNoMoreRequests(t, agent)
+	checkGoroutines(t, baseline)
+}
+
+// TestClientReusableAfterCancel: client tetap bisa dipakai setelah request
+// sebelumnya dibatalkan.
+func TestClientReusableAfterCancel(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Endless, 0)
+	c := newTestClient(t, agent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetWithContext(ctx, []string{"1.3.6.1.2.1.1.3.0"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	res, err := c.GetWithContext(context.Background(), []string{"1.3.6.1.2.1.1.3.0"})
+	if err != nil {
+		t.Fatalf("get setelah cancel: %v", err)
+	}
+	if len(res.Variables) != 1 {
+		t.Fatalf("got %d variables, want 1", len(res.Variables))
+	}
+}
+
+// TestPoolQueryCancel: walk di dalam Pool.Query berhenti saat ctx
+// dibatalkan dan slot pool dilepas.
+func TestPoolQueryCancel(t *testing.T) {
+	agent := snmptest.NewAgent(t, snmptest.Endless, 2*time.Millisecond)
+	pool := NewPool(1)
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	cfg := Config{Host: agent.Host, Port: agent.Port, Community: "public", Timeout: snmpTimeout, Retries: 1}
+	err := pool.Query(ctx, cfg, func(g *gosnmp.GoSNMP) error {
+		return Walk(ctx, g, "1.3.6.1.4.1.3902", func(gosnmp.SnmpPDU) error { return nil })
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > returnLimit {
+		t.Fatalf("query kembali setelah %v, want < %v", elapsed, returnLimit)
+	}
+	if active := pool.Stats().ActiveConnections; active != 0 {
+		t.Fatalf("pool masih memegang %d slot", active)
+	}
+	checkNoMoreRequests(t, agent)
+	checkGoroutines(t, baseline)
+}
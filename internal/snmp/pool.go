@@ -49,6 +49,10 @@ func (p *Pool) Query(ctx context.Context, cfg Config, fn func(*gosnmp.GoSNMP) er
 	}
 	defer client.Conn.Close()
 
+	// 3. Request di dalam fn berhenti begitu ctx selesai.
+	unbind := Bind(ctx, client)
+	defer unbind()
+
 	return fn(client)
 }
 
@@ -0,0 +1,111 @@
+// Package snmptest menyediakan agent SNMP palsu di loopback untuk test yang
+// membutuhkan OLT tanpa perangkat asli.
+package snmptest
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Mode perilaku agent
+type Mode int
+
+const (
+	// Endless menjawab GET dengan nilai 1 dan setiap GETNEXT dengan OID
+	// turunan berikutnya, sehingga walk tidak pernah selesai sendiri.
+	Endless Mode = iota
+	// Silent tidak pernah menjawab (OLT mati atau paket hilang).
+	Silent
+)
+
+// Agent SNMP v2c palsu yang berjalan sampai test selesai
+type Agent struct {
+	Host string
+	Port uint16
+
+	mode     Mode
+	delay    time.Duration
+	conn     *net.UDPConn
+	requests atomic.Int64
+	wg       sync.WaitGroup
+}
+
+// NewAgent menjalankan agent di 127.0.0.1 dengan port acak. delay ditunggu
+// sebelum setiap respons agar operasi berjalan cukup lama untuk dibatalkan.
+// Agent ditutup otomatis lewat t.Cleanup.
+func NewAgent(t testing.TB, mode Mode, delay time.Duration) *Agent {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	a := &Agent{
+		Host:  addr.IP.String(),
+		Port:  uint16(addr.Port),
+		mode:  mode,
+		delay: delay,
+		conn:  conn,
+	}
+	a.wg.Add(1)
+	go a.serve()
+	t.Cleanup(func() {
+		conn.Close()
+		a.wg.Wait()
+	})
+	return a
+}
+
+// Requests jumlah request yang sudah diterima agent
+func (a *Agent) Requests() int64 {
+	return a.requests.Load()
+}
+
+func (a *Agent) serve() {
+	defer a.wg.Done()
+	decoder := &gosnmp.GoSNMP{}
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		a.requests.Add(1)
+		if a.mode == Silent {
+			continue
+		}
+
+		req, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil {
+			continue
+		}
+		resp := &gosnmp.SnmpPacket{
+			Version:   req.Version,
+			Community: req.Community,
+			PDUType:   gosnmp.GetResponse,
+			RequestID: req.RequestID,
+		}
+		for _, v := range req.Variables {
+			name := v.Name
+			if req.PDUType == gosnmp.GetNextRequest {
+				name += ".1"
+			}
+			resp.Variables = append(resp.Variables, gosnmp.SnmpPDU{
+				Name:  name,
+				Type:  gosnmp.Integer,
+				Value: 1,
+			})
+		}
+		out, err := resp.MarshalMsg()
+		if err != nil {
+			continue
+		}
+		time.Sleep(a.delay)
+		a.conn.WriteToUDP(out, from)
+	}
+}
@@ -0,0 +1,169 @@
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Opcode paket TFTP (RFC 1350, OACK dari RFC 2347)
+const (
+	opRRQ   uint16 = 1
+	opWRQ   uint16 = 2
+	opDATA  uint16 = 3
+	opACK   uint16 = 4
+	opERROR uint16 = 5
+	opOACK  uint16 = 6
+)
+
+// Kode error TFTP (RFC 1350 + RFC 2347)
+const (
+	ErrCodeNotDefined       uint16 = 0
+	ErrCodeFileNotFound     uint16 = 1
+	ErrCodeAccessViolation  uint16 = 2
+	ErrCodeDiskFull         uint16 = 3
+	ErrCodeIllegalOperation uint16 = 4
+	ErrCodeUnknownTID       uint16 = 5
+	ErrCodeFileExists       uint16 = 6
+	ErrCodeNoSuchUser       uint16 = 7
+	ErrCodeBadOption        uint16 = 8
+)
+
+// Error error TFTP yang dikirim ke client. Handler bisa mengembalikan
+// *Error untuk mengatur kode error; error lain dikirim sebagai "not defined".
+type Error struct {
+	Code    uint16
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("tftp error %d: %s", e.Code, e.Message)
+}
+
+// Error umum untuk dipakai handler
+var (
+	ErrFileNotFound    = &Error{Code: ErrCodeFileNotFound, Message: "file not found"}
+	ErrAccessViolation = &Error{Code: ErrCodeAccessViolation, Message: "access violation"}
+)
+
+var errMalformed = errors.New("malformed packet")
+
+// request RRQ/WRQ yang sudah di-parse
+type request struct {
+	op       uint16
+	filename string
+	mode     string
+	options  map[string]string
+}
+
+// parseRequest mem-parse RRQ/WRQ: opcode | filename 0 | mode 0 | (opt 0 value 0)*
+func parseRequest(p []byte) (*request, error) {
+	if len(p) < 4 {
+		return nil, errMalformed
+	}
+	req := &request{op: binary.BigEndian.Uint16(p), options: map[string]string{}}
+
+	fields := bytes.Split(p[2:], []byte{0})
+	// Paket harus diakhiri NUL, sehingga elemen terakhir selalu kosong
+	if len(fields) < 3 || len(fields[len(fields)-1]) != 0 {
+		return nil, errMalformed
+	}
+	fields = fields[:len(fields)-1]
+
+	req.filename = string(fields[0])
+	req.mode = strings.ToLower(string(fields[1]))
+	if req.filename == "" {
+		return nil, errMalformed
+	}
+
+	opts := fields[2:]
+	if len(opts)%2 != 0 {
+		return nil, errMalformed
+	}
+	for i := 0; i < len(opts); i += 2 {
+		req.options[strings.ToLower(string(opts[i]))] = string(opts[i+1])
+	}
+	return req, nil
+}
+
+func dataPacket(block uint16, data []byte) []byte {
+	p := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint16(p, opDATA)
+	binary.BigEndian.PutUint16(p[2:], block)
+	copy(p[4:], data)
+	return p
+}
+
+func ackPacket(block uint16) []byte {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint16(p, opACK)
+	binary.BigEndian.PutUint16(p[2:], block)
+	return p
+}
+
+func errorPacket(code uint16, msg string) []byte {
+	p := make([]byte, 4, 5+len(msg))
+	binary.BigEndian.PutUint16(p, opERROR)
+	binary.BigEndian.PutUint16(p[2:], code)
+	p = append(p, msg...)
+	return append(p, 0)
+}
+
+// oackPacket urutan opsi mengikuti keys agar paket deterministik
+func oackPacket(keys []string, options map[string]string) []byte {
+	p := make([]byte, 2)
+	binary.BigEndian.PutUint16(p, opOACK)
+	for _, k := range keys {
+		p = append(p, k...)
+		p = append(p, 0)
+		p = append(p, options[k]...)
+		p = append(p, 0)
+	}
+	return p
+}
+
+// parseError mengembalikan *Error dari paket ERROR
+func parseError(p []byte) *Error {
+	e := &Error{Code: binary.BigEndian.Uint16(p[2:])}
+	e.Message = string(bytes.TrimRight(p[4:], "\x00"))
+	return e
+}
+
+// toNetascii mengubah LF menjadi CRLF dan CR menjadi CR NUL (RFC 764)
+func toNetascii(data []byte) []byte {
+	out := make([]byte, 0, len(data)+len(data)/32)
+	for _, b := range data {
+		switch b {
+		case '\n':
+			out = append(out, '\r', '\n')
+		case '\r':
+			out = append(out, '\r', 0)
+		default:
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// fromNetascii kebalikan dari toNetascii
+func fromNetascii(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '\r' && i+1 < len(data) {
+			switch data[i+1] {
+			case '\n':
+				out = append(out, '\n')
+				i++
+				continue
+			case 0:
+				out = append(out, '\r')
+				i++
+				continue
+			}
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package tftp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func rawRequest(op uint16, fields ...string) []byte {
+	p := []byte{byte(op >> 8), byte(op)}
+	for _, f := range fields {
+		p = append(p, f...)
+		p = append(p, 0)
+	}
+	return p
+}
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		packet  []byte
+		want    *request
+		wantErr bool
+	}{
+		{
+			name:   "rrq tanpa opsi",
+			packet: rawRequest(opRRQ, "olt1/latest", "octet"),
+			want:   &request{op: opRRQ, filename: "olt1/latest", mode: "octet", options: map[string]string{}},
+		},
+		{
+			name:   "wrq dengan opsi, mode dan nama opsi case-insensitive",
+			packet: rawRequest(opWRQ, "startrun.dat", "NetASCII", "BLKSIZE", "1428", "tsize", "2048"),
+			want:   &request{op: opWRQ, filename: "startrun.dat", mode: "netascii", options: map[string]string{"blksize": "1428", "tsize": "2048"}},
+		},
+		{name: "terlalu pendek", packet: []byte{0, 1, 0}, wantErr: true},
+		{name: "tanpa NUL penutup", packet: append(rawRequest(opRRQ, "file"), "octet"...), wantErr: true},
+		{name: "nama file kosong", packet: rawRequest(opRRQ, "", "octet"), wantErr: true},
+		{name: "opsi tanpa nilai", packet: rawRequest(opRRQ, "file", "octet", "blksize"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRequest(tt.packet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRequest() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.op != tt.want.op || got.filename != tt.want.filename || got.mode != tt.want.mode || len(got.options) != len(tt.want.options) {
+				t.Fatalf("parseRequest() = %+v, want %+v", got, tt.want)
+			}
+			for k, v := range tt.want.options {
+				if got.options[k] != v {
+					t.Fatalf("option %s = %q, want %q", k, got.options[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	s := NewServer(nil)
+	s.MaxBlockSize = 1468
+	s.MaxFileSize = 4096
+
+	tests := []struct {
+		name        string
+		op          uint16
+		options     map[string]string
+		size        int
+		wantKeys    string
+		wantOptions map[string]string
+		wantBlksize int
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{
+			name:        "tanpa opsi",
+			op:          opRRQ,
+			options:     map[string]string{},
+			wantBlksize: defaultBlockSize,
+			wantTimeout: s.Timeout,
+		},
+		{
+			name:        "blksize dibatasi, timeout dan tsize RRQ diisi ukuran file",
+			op:          opRRQ,
+			options:     map[string]string{"blksize": "65464", "timeout": "3", "tsize": "0"},
+			size:        1000,
+			wantKeys:    "blksize,timeout,tsize",
+			wantOptions: map[string]string{"blksize": "1468", "timeout": "3", "tsize": "1000"},
+			wantBlksize: 1468,
+			wantTimeout: 3 * time.Second,
+		},
+		{
+			name:        "nilai tidak valid diabaikan",
+			op:          opWRQ,
+			options:     map[string]string{"blksize": "4", "timeout": "0", "tsize": "abc", "windowsize": "8"},
+			wantBlksize: defaultBlockSize,
+			wantTimeout: s.Timeout,
+		},
+		{
+			name:    "tsize WRQ melebihi batas",
+			op:      opWRQ,
+			options: map[string]string{"tsize": "8192"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &transfer{blksize: defaultBlockSize, timeout: s.Timeout}
+			keys, accepted, err := s.negotiate(tr, &request{op: tt.op, options: tt.options}, tt.size)
+			if tt.wantErr {
+				if te, ok := err.(*Error); !ok || te.Code != ErrCodeDiskFull {
+					t.Fatalf("err = %v, want disk full", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := joinKeys(keys); got != tt.wantKeys {
+				t.Fatalf("keys = %q, want %q", got, tt.wantKeys)
+			}
+			for k, v := range tt.wantOptions {
+				if accepted[k] != v {
+					t.Fatalf("accepted[%s] = %q, want %q", k, accepted[k], v)
+				}
+			}
+			if tr.blksize != tt.wantBlksize || tr.timeout != tt.wantTimeout {
+				t.Fatalf("blksize = %d timeout = %v, want %d %v", tr.blksize, tr.timeout, tt.wantBlksize, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func joinKeys(keys []string) string {
+	var b bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+	}
+	return b.String()
+}
+
+func TestNetascii(t *testing.T) {
+	in := []byte("hostname olt\nbanner a\rb\n")
+	encoded := toNetascii(in)
+	if want := []byte("hostname olt\r\nbanner a\r\x00b\r\n"); !bytes.Equal(encoded, want) {
+		t.Fatalf("toNetascii = %q, want %q", encoded, want)
+	}
+	if got := fromNetascii(encoded); !bytes.Equal(got, in) {
+		t.Fatalf("fromNetascii = %q, want %q", got, in)
+	}
+}
+
+func TestErrorPacket(t *testing.T) {
+	e := parseError(errorPacket(ErrCodeAccessViolation, "access violation"))
+	if e.Code != ErrCodeAccessViolation || e.Message != "access violation" {
+		t.Fatalf("parseError = %+v", e)
+	}
+}
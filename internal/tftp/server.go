@@ -0,0 +1,385 @@
+// Package tftp adalah server TFTP sederhana (RFC 1350) dengan negosiasi
+// opsi (RFC 2347) untuk blksize (RFC 2348), timeout dan tsize (RFC 2349).
+//
+// Server ini dipakai OLT untuk "copy running-config tftp://..." dan
+// "copy tftp://... running-config". Isi file seluruhnya ditampung di memori,
+// cukup untuk file config yang ukurannya paling banyak beberapa MB.
+package tftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultBlockSize = 512
+	minBlockSize     = 8
+	maxBlockSize     = 65464
+)
+
+var errTimeout = errors.New("transfer timed out")
+
+// Handler sumber dan tujuan file yang ditransfer
+type Handler interface {
+	// ReadFile dipanggil untuk RRQ (client mengunduh file)
+	ReadFile(remote net.IP, filename string) ([]byte, error)
+	// WriteFile dipanggil setelah WRQ selesai diterima seluruhnya. Jika
+	// mengembalikan error, ACK terakhir diganti dengan paket ERROR.
+	WriteFile(remote net.IP, filename string, data []byte) error
+}
+
+// Server server TFTP
+type Server struct {
+	handler Handler
+
+	Timeout      time.Duration // Timeout retransmisi default (client bisa minta lewat opsi timeout)
+	Retries      int           // Jumlah retransmisi sebelum transfer dibatalkan
+	MaxBlockSize int           // Batas atas blksize yang diterima
+	MaxFileSize  int           // Batas ukuran file upload
+
+	wg sync.WaitGroup
+}
+
+// NewServer membuat server TFTP baru dengan nilai default
+func NewServer(handler Handler) *Server {
+	return &Server{
+		handler:      handler,
+		Timeout:      5 * time.Second,
+		Retries:      5,
+		MaxBlockSize: maxBlockSize,
+		MaxFileSize:  64 << 20,
+	}
+}
+
+// ListenAndServe membuka port UDP di addr dan melayani request sampai ctx
+// dibatalkan.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return fmt.Errorf("invalid tftp listen address: %w", err)
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return fmt.Errorf("tftp listen failed: %w", err)
+	}
+	return s.Serve(ctx, conn)
+}
+
+// Serve melayani request di conn sampai ctx dibatalkan. Setiap transfer
+// memakai socket sendiri (TID baru) sesuai RFC 1350.
+func (s *Server) Serve(ctx context.Context, conn *net.UDPConn) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	local, _ := conn.LocalAddr().(*net.UDPAddr)
+	buf := make([]byte, 2048)
+	for {
+		n, peer, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			s.wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		packet := append([]byte(nil), buf[:n]...)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(ctx, local, peer, packet)
+		}()
+	}
+}
+
+// transfer state satu sesi transfer
+type transfer struct {
+	conn    *net.UDPConn
+	peer    *net.UDPAddr
+	blksize int
+	timeout time.Duration
+	retries int
+	buf     []byte
+}
+
+func (s *Server) handle(ctx context.Context, local, peer *net.UDPAddr, packet []byte) {
+	var laddr *net.UDPAddr
+	if local != nil {
+		laddr = &net.UDPAddr{IP: local.IP, Zone: local.Zone}
+	}
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		log.Warn().Err(err).Msg("TFTP: failed to open transfer socket")
+		return
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	t := &transfer{
+		conn:    conn,
+		peer:    peer,
+		blksize: defaultBlockSize,
+		timeout: s.Timeout,
+		retries: s.Retries,
+	}
+
+	req, err := parseRequest(packet)
+	if err != nil {
+		t.sendError(ErrCodeIllegalOperation, err.Error())
+		return
+	}
+	if req.op != opRRQ && req.op != opWRQ {
+		t.sendError(ErrCodeIllegalOperation, "expected RRQ or WRQ")
+		return
+	}
+	if req.mode != "octet" && req.mode != "netascii" {
+		t.sendError(ErrCodeIllegalOperation, "unsupported mode: "+req.mode)
+		return
+	}
+
+	logger := log.With().Str("peer", peer.String()).Str("file", req.filename).Logger()
+	start := time.Now()
+
+	var size int
+	if req.op == opRRQ {
+		size, err = s.handleRead(t, req)
+	} else {
+		size, err = s.handleWrite(t, req)
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("TFTP transfer failed")
+		return
+	}
+	logger.Info().Bool("upload", req.op == opWRQ).Int("bytes", size).Int("blksize", t.blksize).Dur("duration", time.Since(start)).Msg("TFTP transfer done")
+}
+
+// negotiate memproses opsi request dan mengembalikan opsi yang diterima
+// (urutan sesuai keys). size adalah ukuran file untuk tsize pada RRQ.
+func (s *Server) negotiate(t *transfer, req *request, size int) ([]string, map[string]string, error) {
+	var keys []string
+	accepted := map[string]string{}
+
+	if v, ok := req.options["blksize"]; ok {
+		if n, err := strconv.Atoi(v); err == nil && n >= minBlockSize {
+			if n > s.MaxBlockSize {
+				n = s.MaxBlockSize
+			}
+			t.blksize = n
+			keys = append(keys, "blksize")
+			accepted["blksize"] = strconv.Itoa(n)
+		}
+	}
+
+	if v, ok := req.options["timeout"]; ok {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= 255 {
+			t.timeout = time.Duration(n) * time.Second
+			keys = append(keys, "timeout")
+			accepted["timeout"] = v
+		}
+	}
+
+	if v, ok := req.options["tsize"]; ok {
+		n, err := strconv.Atoi(v)
+		if err == nil {
+			if req.op == opRRQ {
+				n = size
+			} else if n > s.MaxFileSize {
+				return nil, nil, &Error{Code: ErrCodeDiskFull, Message: "file too large"}
+			}
+			keys = append(keys, "tsize")
+			accepted["tsize"] = strconv.Itoa(n)
+		}
+	}
+
+	return keys, accepted, nil
+}
+
+func (s *Server) handleRead(t *transfer, req *request) (int, error) {
+	data, err := s.handler.ReadFile(t.peer.IP, req.filename)
+	if err != nil {
+		t.sendHandlerError(err)
+		return 0, err
+	}
+	if req.mode == "netascii" {
+		data = toNetascii(data)
+	}
+
+	keys, accepted, err := s.negotiate(t, req, len(data))
+	if err != nil {
+		t.sendHandlerError(err)
+		return 0, err
+	}
+	t.buf = make([]byte, t.blksize+4)
+
+	if len(keys) > 0 {
+		if _, err := t.exchange(oackPacket(keys, accepted), isACK(0)); err != nil {
+			return 0, err
+		}
+	}
+
+	block := uint16(1)
+	for off := 0; ; block++ {
+		end := off + t.blksize
+		if end > len(data) {
+			end = len(data)
+		}
+		if _, err := t.exchange(dataPacket(block, data[off:end]), isACK(block)); err != nil {
+			return 0, err
+		}
+		if end-off < t.blksize {
+			break
+		}
+		off = end
+	}
+	return len(data), nil
+}
+
+func (s *Server) handleWrite(t *transfer, req *request) (int, error) {
+	keys, accepted, err := s.negotiate(t, req, 0)
+	if err != nil {
+		t.sendHandlerError(err)
+		return 0, err
+	}
+	t.buf = make([]byte, t.blksize+4)
+
+	reply := ackPacket(0)
+	if len(keys) > 0 {
+		reply = oackPacket(keys, accepted)
+	}
+
+	var content bytes.Buffer
+	for block := uint16(1); ; block++ {
+		p, err := t.exchange(reply, isDATA(block))
+		if err != nil {
+			return 0, err
+		}
+
+		data := p[4:]
+		if content.Len()+len(data) > s.MaxFileSize {
+			t.sendError(ErrCodeDiskFull, "file too large")
+			return 0, errors.New("file too large")
+		}
+		content.Write(data)
+
+		if len(data) < t.blksize {
+			result := content.Bytes()
+			if req.mode == "netascii" {
+				result = fromNetascii(result)
+			}
+			if err := s.handler.WriteFile(t.peer.IP, req.filename, result); err != nil {
+				t.sendHandlerError(err)
+				return 0, err
+			}
+			t.send(ackPacket(block))
+			t.dally(block)
+			return len(result), nil
+		}
+		reply = ackPacket(block)
+	}
+}
+
+// exchange mengirim packet lalu menunggu balasan yang cocok dengan match,
+// dengan retransmisi saat timeout. Paket lain (misal ACK/DATA duplikat)
+// diabaikan agar tidak terjadi Sorcerer's Apprentice Syndrome.
+func (t *transfer) exchange(packet []byte, match func([]byte) bool) ([]byte, error) {
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if err := t.send(packet); err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(t.timeout)
+		for {
+			p, err := t.receive(deadline)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if binary.BigEndian.Uint16(p) == opERROR {
+				return nil, parseError(p)
+			}
+			if match(p) {
+				return p, nil
+			}
+		}
+	}
+	t.sendError(ErrCodeNotDefined, "timeout")
+	return nil, errTimeout
+}
+
+// receive membaca paket dari peer sampai deadline. Paket dari TID lain
+// dibalas ERROR dan tidak mengganggu transfer (RFC 1350 bagian 4).
+func (t *transfer) receive(deadline time.Time) ([]byte, error) {
+	t.conn.SetReadDeadline(deadline)
+	for {
+		n, addr, err := t.conn.ReadFromUDP(t.buf)
+		if err != nil {
+			return nil, err
+		}
+		if !addr.IP.Equal(t.peer.IP) || addr.Port != t.peer.Port {
+			t.conn.WriteToUDP(errorPacket(ErrCodeUnknownTID, "unknown transfer ID"), addr)
+			continue
+		}
+		if n < 4 {
+			continue
+		}
+		return t.buf[:n], nil
+	}
+}
+
+// dally menunggu satu periode timeout setelah ACK terakhir; jika ACK itu
+// hilang client akan mengirim ulang DATA terakhir dan ACK dikirim lagi.
+func (t *transfer) dally(block uint16) {
+	deadline := time.Now().Add(t.timeout)
+	for {
+		p, err := t.receive(deadline)
+		if err != nil {
+			return
+		}
+		if isDATA(block)(p) {
+			t.send(ackPacket(block))
+		}
+	}
+}
+
+func (t *transfer) send(p []byte) error {
+	_, err := t.conn.WriteToUDP(p, t.peer)
+	return err
+}
+
+func (t *transfer) sendError(code uint16, msg string) {
+	t.send(errorPacket(code, msg))
+}
+
+func (t *transfer) sendHandlerError(err error) {
+	var te *Error
+	if errors.As(err, &te) {
+		t.sendError(te.Code, te.Message)
+		return
+	}
+	t.sendError(ErrCodeNotDefined, err.Error())
+}
+
+func isACK(block uint16) func([]byte) bool {
+	return func(p []byte) bool {
+		return binary.BigEndian.Uint16(p) == opACK && binary.BigEndian.Uint16(p[2:]) == block
+	}
+}
+
+func isDATA(block uint16) func([]byte) bool {
+	return func(p []byte) bool {
+		return binary.BigEndian.Uint16(p) == opDATA && binary.BigEndian.Uint16(p[2:]) == block
+	}
+}
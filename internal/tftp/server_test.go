@@ -0,0 +1,275 @@
+package tftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memHandler menyimpan file di memori; upload ke nama "denied" ditolak
+type memHandler struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (h *memHandler) ReadFile(remote net.IP, filename string) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	data, ok := h.files[filename]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+	return data, nil
+}
+
+func (h *memHandler) WriteFile(remote net.IP, filename string, data []byte) error {
+	if filename == "denied" {
+		return ErrAccessViolation
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.files[filename] = append([]byte(nil), data...)
+	return nil
+}
+
+func (h *memHandler) get(filename string) []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.files[filename]
+}
+
+func startServer(t *testing.T, h Handler) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(h)
+	s.Timeout = 200 * time.Millisecond
+	s.Retries = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Serve(ctx, conn)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+// testClient client TFTP minimal untuk menguji server
+type testClient struct {
+	t    *testing.T
+	conn *net.UDPConn
+	tid  *net.UDPAddr // Alamat sesi transfer di server
+	buf  []byte
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn, buf: make([]byte, maxBlockSize+4)}
+}
+
+func (c *testClient) send(to *net.UDPAddr, p []byte) {
+	if _, err := c.conn.WriteToUDP(p, to); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) receive() ([]byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, addr, err := c.conn.ReadFromUDP(c.buf)
+	if err != nil {
+		return nil, err
+	}
+	c.tid = addr
+	p := append([]byte(nil), c.buf[:n]...)
+	if binary.BigEndian.Uint16(p) == opERROR {
+		return nil, parseError(p)
+	}
+	return p, nil
+}
+
+// oackBlksize membaca blksize dari OACK (default jika tidak ada)
+func oackBlksize(p []byte) int {
+	fields := bytes.Split(p[2:], []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		if string(fields[i]) == "blksize" {
+			n, _ := strconv.Atoi(string(fields[i+1]))
+			return n
+		}
+	}
+	return defaultBlockSize
+}
+
+func (c *testClient) download(server *net.UDPAddr, filename, mode string, options ...string) ([]byte, error) {
+	c.send(server, rawRequest(opRRQ, append([]string{filename, mode}, options...)...))
+
+	blksize := defaultBlockSize
+	var data []byte
+	for want := uint16(1); ; {
+		p, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch binary.BigEndian.Uint16(p) {
+		case opOACK:
+			blksize = oackBlksize(p)
+			c.send(c.tid, ackPacket(0))
+			continue
+		case opDATA:
+		default:
+			c.t.Fatalf("unexpected opcode %d", binary.BigEndian.Uint16(p))
+		}
+		if block := binary.BigEndian.Uint16(p[2:]); block != want {
+			c.t.Fatalf("block = %d, want %d", block, want)
+		}
+		data = append(data, p[4:]...)
+		c.send(c.tid, ackPacket(want))
+		if len(p)-4 < blksize {
+			return data, nil
+		}
+		want++
+	}
+}
+
+func (c *testClient) upload(server *net.UDPAddr, filename, mode string, data []byte, options ...string) error {
+	c.send(server, rawRequest(opWRQ, append([]string{filename, mode}, options...)...))
+
+	p, err := c.receive()
+	if err != nil {
+		return err
+	}
+	blksize := defaultBlockSize
+	switch binary.BigEndian.Uint16(p) {
+	case opOACK:
+		blksize = oackBlksize(p)
+	case opACK:
+	default:
+		c.t.Fatalf("unexpected opcode %d", binary.BigEndian.Uint16(p))
+	}
+
+	for block, off := uint16(1), 0; ; block++ {
+		end := min(off+blksize, len(data))
+		c.send(c.tid, dataPacket(block, data[off:end]))
+		p, err := c.receive()
+		if err != nil {
+			return err
+		}
+		if !isACK(block)(p) {
+			c.t.Fatalf("reply = %v, want ACK %d", p, block)
+		}
+		if end-off < blksize {
+			return nil
+		}
+		off = end
+	}
+}
+
+func TestServerDownload(t *testing.T) {
+	h := &memHandler{files: map[string][]byte{
+		"small":   bytes.Repeat([]byte("a"), 1300),
+		"aligned": bytes.Repeat([]byte("b"), 2*defaultBlockSize),
+		"text":    []byte("line1\nline2\n"),
+	}}
+	server := startServer(t, h)
+
+	tests := []struct {
+		name     string
+		filename string
+		mode     string
+		options  []string
+		want     []byte
+	}{
+		{"tanpa opsi", "small", "octet", nil, h.files["small"]},
+		{"blksize dinegosiasikan", "small", "octet", []string{"blksize", "600", "tsize", "0"}, h.files["small"]},
+		{"kelipatan blksize diakhiri blok kosong", "aligned", "octet", nil, h.files["aligned"]},
+		{"netascii", "text", "netascii", nil, []byte("line1\r\nline2\r\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestClient(t).download(server, tt.filename, tt.mode, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("downloaded %d bytes, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+
+	t.Run("file tidak ada", func(t *testing.T) {
+		_, err := newTestClient(t).download(server, "missing", "octet")
+		var te *Error
+		if !errors.As(err, &te) || te.Code != ErrCodeFileNotFound {
+			t.Fatalf("err = %v, want file not found", err)
+		}
+	})
+
+	t.Run("mode tidak didukung", func(t *testing.T) {
+		_, err := newTestClient(t).download(server, "small", "mail")
+		var te *Error
+		if !errors.As(err, &te) || te.Code != ErrCodeIllegalOperation {
+			t.Fatalf("err = %v, want illegal operation", err)
+		}
+	})
+}
+
+func TestServerUpload(t *testing.T) {
+	h := &memHandler{files: map[string][]byte{}}
+	server := startServer(t, h)
+
+	tests := []struct {
+		name     string
+		filename string
+		mode     string
+		data     []byte
+		options  []string
+		want     []byte
+	}{
+		{"tanpa opsi", "plain", "octet", bytes.Repeat([]byte("x"), 1000), nil, bytes.Repeat([]byte("x"), 1000)},
+		{"blksize kecil", "small-blocks", "octet", bytes.Repeat([]byte("y"), 250), []string{"blksize", "100", "tsize", "250"}, bytes.Repeat([]byte("y"), 250)},
+		{"netascii dikonversi ke LF", "text", "netascii", []byte("a\r\nb\r\x00c\r\n"), nil, []byte("a\nb\rc\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := newTestClient(t).upload(server, tt.filename, tt.mode, tt.data, tt.options...); err != nil {
+				t.Fatal(err)
+			}
+			if got := h.get(tt.filename); !bytes.Equal(got, tt.want) {
+				t.Fatalf("stored %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("ditolak handler", func(t *testing.T) {
+		err := newTestClient(t).upload(server, "denied", "octet", []byte("config"))
+		var te *Error
+		if !errors.As(err, &te) || te.Code != ErrCodeAccessViolation {
+			t.Fatalf("err = %v, want access violation", err)
+		}
+	})
+
+	t.Run("tsize melebihi batas", func(t *testing.T) {
+		err := newTestClient(t).upload(server, "big", "octet", []byte("x"), "tsize", strconv.Itoa(128<<20))
+		var te *Error
+		if !errors.As(err, &te) || te.Code != ErrCodeDiskFull {
+			t.Fatalf("err = %v, want disk full", err)
+		}
+	})
+}